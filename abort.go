@@ -0,0 +1,53 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"net/http"
+	"syscall"
+)
+
+// StatusClientClosedRequest is the non-standard status reporting a request
+// aborted by the client, as logged by nginx.
+const StatusClientClosedRequest = 499
+
+// clientAbortCoder is the Coder of the client aborts carrying no registered
+// code. Its code 2 is reserved next to the unknown one.
+var clientAbortCoder = NewCoder(2, StatusClientClosedRequest, "The client closed the request",
+	"https://github.com/rtmzk/errors/README.md", WithSeverity(SeverityInfo))
+
+// IsClientAbort reports whether err's chain holds an error caused by the
+// client going away: http.ErrAbortHandler, a broken pipe or a connection
+// reset by the peer. Such errors are not server failures, and ParseCoder
+// reports them with a dedicated 499 Coder of severity info when they carry
+// no registered code, so that they stay out of 5xx dashboards.
+func IsClientAbort(err error) bool {
+	if err == nil {
+		return false
+	}
+	return Is(err, http.ErrAbortHandler) || Is(err, syscall.EPIPE) || Is(err, syscall.ECONNRESET)
+}
+
+// fallbackCoder returns the Coder of an error without a registered code.
+// Context errors resolve to the Coder FromContextErr codes them with.
+func fallbackCoder(err error) Coder {
+	if IsClientAbort(err) {
+		return clientAbortCoder
+	}
+	if coder, ok := contextCoder(err); ok {
+		return coder
+	}
+	return unknownCoder
+}
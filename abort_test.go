@@ -0,0 +1,52 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestClientAbort(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(904701, 500, "write failed", ""))
+
+	reset := &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+	tests := []struct {
+		err   error
+		abort bool
+		code  int
+	}{
+		{http.ErrAbortHandler, true, 2},
+		{Wrap(syscall.EPIPE, "write response"), true, 2},
+		{fmt.Errorf("serve: %w", reset), true, 2},
+		{Wrapc(syscall.EPIPE, 904701, "write response"), true, 904701},
+		{io.EOF, false, 1},
+		{nil, false, 0},
+	}
+
+	for _, tt := range tests {
+		if got := IsClientAbort(tt.err); got != tt.abort {
+			t.Errorf("IsClientAbort(%v): got %v, want %v", tt.err, got, tt.abort)
+		}
+		if tt.err == nil {
+			continue
+		}
+		if got := ParseCoder(tt.err).Code(); got != tt.code {
+			t.Errorf("ParseCoder(%v).Code(): got %d, want %d", tt.err, got, tt.code)
+		}
+	}
+
+	if got := ParseCoder(syscall.EPIPE).HTTPStatus(); got != StatusClientClosedRequest {
+		t.Errorf("ParseCoder(EPIPE).HTTPStatus(): got %d, want %d", got, StatusClientClosedRequest)
+	}
+	if got := LogLevel(syscall.EPIPE); got != slog.LevelInfo {
+		t.Errorf("LogLevel(EPIPE): got %v, want %v", got, slog.LevelInfo)
+	}
+}
@@ -17,6 +17,8 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 )
 
 // MessageCountMap contains occurrence for each error message.
@@ -25,15 +27,24 @@ type MessageCountMap map[string]int
 // Aggregate represents an object that contains multiple errors, but does not
 // necessarily have singular semantic meaning.
 // The aggregate can be used with `errors.Is()` to check for the occurrence of
-// a specific error type.
-// Errors.As() is not supported, because the caller presumably cares about a
-// specific error of potentially multiple that match the given type.
+// a specific error type, and with IsCode to check for the occurrence of a
+// code. ParseCoder resolves an aggregate to the dominant Coder of its
+// members, see SetDominantCoder, and Codes lists the codes of its members.
 type Aggregate interface {
 	error
 	Errors() []error
 	Is(error) bool
 }
 
+// Join returns an Aggregate holding the non-nil errs, see NewAggregate.
+// If all errs are nil, Join returns nil.
+func Join(errs ...error) error {
+	if agg := NewAggregate(errs); agg != nil {
+		return agg
+	}
+	return nil
+}
+
 // NewAggregate converts a slice of errors into an Aggregate interface, which
 // is itself an implementation of the error interface.  If the slice is empty,
 // this returns nil.
@@ -124,6 +135,147 @@ func (agg aggregate) Errors() []error {
 	return []error(agg)
 }
 
+// Unwrap provides compatibility for Go 1.20 multi-error trees.
+func (agg aggregate) Unwrap() []error {
+	return []error(agg)
+}
+
+// Codes returns the distinct codes carried by err's chain and by the
+// members of the multi-errors it holds, such as Aggregates, in the order of
+// their first occurrence.
+func Codes(err error) []int {
+	var ret []int
+	seen := map[int]bool{}
+	walk(err, func(e error) bool {
+		if c, ok := e.(CodeError); ok && !seen[c.Code()] {
+			seen[c.Code()] = true
+			ret = append(ret, c.Code())
+		}
+		return false
+	})
+	return ret
+}
+
+// walk calls f for every error of err's chain, descending into the members
+// of the multi-errors it holds, such as Aggregates, the errors of
+// errors.Join or those of third-party libraries, until f returns true.
+// It reports whether f returned true.
+func walk(err error, f func(error) bool) bool {
+	for _, e := range list(err) {
+		if f(e) {
+			return true
+		}
+		if errs, ok := members(e); ok {
+			for _, member := range errs {
+				if walk(member, f) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// DominantFunc selects the Coder representing a multi-error among the
+// Coders of its members, given in order. It is set with SetDominantCoder.
+type DominantFunc func(coders []Coder) Coder
+
+// DominantFirst selects the Coder registered first among those of the
+// members, see Register. The Coders which are not registered, such as
+// those of errors implementing Coder themselves, come after them, in the
+// order of the members.
+func DominantFirst(coders []Coder) Coder {
+	dominant := coders[0]
+	first, ok := registrationOrder(dominant.Code())
+	for _, coder := range coders[1:] {
+		if seq, registered := registrationOrder(coder.Code()); registered && (!ok || seq < first) {
+			dominant, first, ok = coder, seq, true
+		}
+	}
+	return dominant
+}
+
+// registrationSeq holds the rank of the first registration of each code,
+// counted by registrations, see DominantFirst.
+var (
+	registrationSeq sync.Map // map[int]uint64
+	registrations   uint64
+)
+
+// recordRegistration ranks the registration of code, unless it is already
+// ranked. codeMux must be held.
+func recordRegistration(code int) {
+	if _, ok := registrationSeq.Load(code); !ok {
+		registrationSeq.Store(code, atomic.AddUint64(&registrations, 1))
+	}
+}
+
+// registrationOrder returns the rank of the first registration of code,
+// if it is registered.
+func registrationOrder(code int) (uint64, bool) {
+	if _, ok := lookupCoder(code); !ok {
+		return 0, false
+	}
+	seq, ok := registrationSeq.Load(code)
+	if !ok {
+		return 0, false
+	}
+	return seq.(uint64), true
+}
+
+// DominantSeverity selects the Coder of the most severe member, see
+// SeverityOf, and the first of them on ties.
+func DominantSeverity(coders []Coder) Coder {
+	dominant := coders[0]
+	max, _ := SeverityOf(dominant)
+	for _, coder := range coders[1:] {
+		if severity, _ := SeverityOf(coder); severity > max {
+			dominant, max = coder, severity
+		}
+	}
+	return dominant
+}
+
+// dominant selects the Coder of multi-errors.
+var dominant DominantFunc = DominantFirst
+
+// SetDominantCoder sets the function selecting the Coder which ParseCoder
+// returns for a multi-error without a code of its own. It defaults to
+// DominantFirst. A nil fn restores the default.
+func SetDominantCoder(fn DominantFunc) {
+	if fn == nil {
+		fn = DominantFirst
+	}
+	dominant = fn
+}
+
+// dominantCoder returns the dominant Coder of the members of the first
+// multi-error of err's chain, see Combine for the errors it returns.
+// It returns false if none of them is coded.
+func dominantCoder(err error) (Coder, bool) {
+	for _, e := range list(err) {
+		errs, ok := members(e)
+		if !ok {
+			continue
+		}
+		var coders []Coder
+		for _, member := range errs {
+			if coder := ParseCoder(member); coder != nil && coder.Code() != unknownCoder.Code() &&
+				coder.Code() != clientAbortCoder.Code() {
+				coders = append(coders, coder)
+			}
+		}
+		if len(coders) == 0 {
+			return nil, false
+		}
+		if _, ok := e.(combined); ok {
+			return DominantHTTPStatus(coders), true
+		}
+		return dominant(coders), true
+	}
+	return nil, false
+}
+
 // Matcher is used to match errors.  Returns true if the error matches.
 type Matcher func(error) bool
 
@@ -243,3 +395,64 @@ func AggregateGoroutines(funcs ...func() error) Aggregate {
 
 // ErrPreconditionViolated is returned when the precondition is violated
 var ErrPreconditionViolated = errors.New("precondition is violated")
+
+// Coalesce merges the errors of agg which have the same code and message
+// into a single error reporting how many times it occurred, keeping batch
+// responses readable when many items fail the same way. The errors are kept
+// in the order of their first occurrence and nested Aggregates are
+// flattened. A merged error unwraps to its first occurrence and implements
+// interface{ Count() int }.
+func Coalesce(agg Aggregate) Aggregate {
+	agg = Flatten(agg)
+	if agg == nil {
+		return nil
+	}
+
+	type key struct {
+		code int
+		msg  string
+	}
+	var (
+		keys   []key
+		merged = map[key]*coalesced{}
+	)
+	for _, err := range agg.Errors() {
+		code, _ := TopCode(err)
+		k := key{code, err.Error()}
+		if c, ok := merged[k]; ok {
+			c.count++
+			continue
+		}
+		keys = append(keys, k)
+		merged[k] = &coalesced{error: err, count: 1}
+	}
+
+	result := make([]error, 0, len(keys))
+	for _, k := range keys {
+		c := merged[k]
+		if c.count == 1 {
+			result = append(result, c.error)
+			continue
+		}
+		result = append(result, c)
+	}
+	return NewAggregate(result)
+}
+
+// coalesced is an error which occurred count times in an Aggregate.
+type coalesced struct {
+	error
+	count int
+}
+
+func (c *coalesced) Error() string {
+	return fmt.Sprintf("%v (repeated %v times)", c.error.Error(), c.count)
+}
+
+// Count returns how many times the error occurred.
+func (c *coalesced) Count() int { return c.count }
+
+func (c *coalesced) Cause() error { return c.error }
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (c *coalesced) Unwrap() error { return c.error }
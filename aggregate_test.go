@@ -0,0 +1,100 @@
+package errors
+
+import (
+	stderrors "errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestCoalesce(t *testing.T) {
+	agg := NewAggregate([]error{
+		WithCode(902401, "name is required"),
+		WithCode(902401, "name is required"),
+		WithCode(902402, "name is required"),
+		NewAggregate([]error{WithCode(902401, "name is required"), io.EOF}),
+		io.EOF,
+	})
+
+	got := Coalesce(agg)
+	want := "[name is required (repeated 3 times), name is required, EOF (repeated 2 times)]"
+	if got.Error() != want {
+		t.Errorf("Coalesce(): got %q, want %q", got.Error(), want)
+	}
+
+	first := got.Errors()[0]
+	if c, ok := first.(interface{ Count() int }); !ok || c.Count() != 3 {
+		t.Errorf("Coalesce()[0]: got %v, want a count of 3", first)
+	}
+	if code, _ := TopCode(first); code != 902401 {
+		t.Errorf("TopCode(Coalesce()[0]): got %d, want %d", code, 902401)
+	}
+	if !got.Is(io.EOF) {
+		t.Errorf("Coalesce().Is(io.EOF): got false, want true")
+	}
+
+	if Coalesce(nil) != nil {
+		t.Errorf("Coalesce(nil): got non-nil, want nil")
+	}
+}
+
+func TestAggregateCodes(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(906001, 400, "invalid", "", WithSeverity(SeverityWarn)))
+	MustRegister(NewCoder(906002, 503, "unavailable", "", WithSeverity(SeverityFatal)))
+
+	agg := Join(
+		io.EOF,
+		Wrap(WithCode(906001, "bad name"), "validate"),
+		NewAggregate([]error{WithCode(906002, "down"), WithCode(906001, "bad age")}),
+	)
+
+	if got, want := Codes(agg), []int{906001, 906002}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Codes(): got %v, want %v", got, want)
+	}
+	for _, code := range []int{906001, 906002} {
+		if !IsCode(Wrap(agg, "wrapped"), code) {
+			t.Errorf("IsCode(%d): got false, want true", code)
+		}
+	}
+	if IsCode(agg, 906003) {
+		t.Errorf("IsCode(906003): got true, want false")
+	}
+	if !IsCode(stderrors.Join(io.EOF, WithCode(906002, "down")), 906002) {
+		t.Errorf("IsCode(errors.Join): got false, want true")
+	}
+	if !stderrors.Is(agg, io.EOF) {
+		t.Errorf("errors.Is(io.EOF): got false, want true")
+	}
+
+	tests := []struct {
+		fn   DominantFunc
+		want int
+	}{
+		{nil, 906001},
+		{DominantFirst, 906001},
+		{DominantSeverity, 906002},
+	}
+
+	for _, tt := range tests {
+		restore := NewConfig(UseDominantCoder(tt.fn)).Apply()
+		got := ParseCoder(Wrap(agg, "wrapped")).Code()
+		restore()
+		if got != tt.want {
+			t.Errorf("ParseCoder(): got %d, want %d", got, tt.want)
+		}
+	}
+
+	reversed := Join(WithCode(906002, "down"), WithCode(906001, "bad name"))
+	if got := ParseCoder(reversed).Code(); got != 906001 {
+		t.Errorf("ParseCoder(reversed): got %d, want the first registered 906001", got)
+	}
+	if got := ParseCoder(Join(io.EOF)).Code(); got != unknownCoder.Code() {
+		t.Errorf("ParseCoder(uncoded): got %d, want %d", got, unknownCoder.Code())
+	}
+	if got := Join(nil, nil); got != nil {
+		t.Errorf("Join(nil, nil): got %v, want nil", got)
+	}
+}
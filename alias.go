@@ -0,0 +1,116 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// aliases maps the deprecated codes to their replacement, see
+// RegisterAlias. The map is replaced on write, so that lookups do not lock.
+var aliases atomic.Value // map[int]int
+
+// aliasMux serializes the writers of aliases.
+var aliasMux sync.Mutex
+
+// RegisterAlias deprecates the code old in favor of replacement, so that
+// codes are renumbered without breaking their callers: the errors with the
+// code old resolve to the Coder of replacement, see ParseCoder and
+// GetCoder, and IsCode matches either code for the other. Aliases chain,
+// so that a code renumbered twice resolves to the last replacement.
+// It panics if old and replacement are the same code, or if replacement
+// is itself an alias of old.
+func RegisterAlias(old, replacement int) {
+	aliasMux.Lock()
+	defer aliasMux.Unlock()
+
+	current := aliasesNow()
+	if to, ok := current[replacement]; ok {
+		replacement = to
+	}
+	if old == replacement {
+		panic(fmt.Sprintf("errors: register alias: code %d cannot replace itself", old))
+	}
+
+	next := make(map[int]int, len(current)+1)
+	for from, to := range current {
+		if to == old {
+			to = replacement
+		}
+		next[from] = to
+	}
+	next[old] = replacement
+	aliases.Store(next)
+}
+
+// Alias returns the replacement of the deprecated code, see RegisterAlias.
+func Alias(code int) (int, bool) {
+	to, ok := aliasesNow()[code]
+	return to, ok
+}
+
+func aliasesNow() map[int]int {
+	m, _ := aliases.Load().(map[int]int)
+	return m
+}
+
+// canonicalCode returns the replacement of code if it is deprecated, or
+// else code.
+func canonicalCode(code int) int {
+	if to, ok := aliasesNow()[code]; ok {
+		return to
+	}
+	return code
+}
+
+// warnDeprecated is 1 when the resolution of deprecated codes is logged.
+var warnDeprecated int32
+
+// warnedCodes holds the deprecated codes already logged.
+var warnedCodes sync.Map
+
+// SetDeprecationWarnings enables or disables the warning logged with
+// log/slog the first time ParseCoder resolves an error with a deprecated
+// code, see RegisterAlias, so that the callers still producing it are
+// found. It is disabled by default.
+func SetDeprecationWarnings(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&warnDeprecated, v)
+}
+
+func deprecationWarningsNow() bool {
+	return atomic.LoadInt32(&warnDeprecated) == 1
+}
+
+// warnAlias logs the first resolution of the deprecated code, if enabled.
+func warnAlias(code int) {
+	if !deprecationWarningsNow() {
+		return
+	}
+	to, ok := Alias(code)
+	if !ok {
+		return
+	}
+	if _, done := warnedCodes.LoadOrStore(code, true); done {
+		return
+	}
+	slog.Warn(fmt.Sprintf("errors: code %d is deprecated, use %d", code, to), "code", code, "replacement", to)
+}
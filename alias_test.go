@@ -0,0 +1,89 @@
+package errors
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRegisterAlias(t *testing.T) {
+	restore := NewConfig().Apply()
+	defer restore()
+
+	Register(NewCoder(913202, http.StatusNotFound, "user not found", ""))
+	Register(NewCoder(913203, http.StatusNotFound, "account not found", ""))
+	RegisterAlias(913201, 913202)
+	RegisterAlias(913202, 913203)
+
+	old := WithCode(913201, "no user 42")
+	tests := []struct {
+		err  error
+		code int
+		want bool
+	}{
+		{old, 913201, true},
+		{old, 913202, true},
+		{old, 913203, true},
+		{WithCode(913203, "no account"), 913201, true},
+		{WithCode(913203, "no account"), 913204, false},
+	}
+
+	for i, tt := range tests {
+		if got := IsCode(tt.err, tt.code); got != tt.want {
+			t.Errorf("test %d: IsCode(%d): got %v, want %v", i+1, tt.code, got, tt.want)
+		}
+	}
+
+	if got := ParseCoder(old).Code(); got != 913203 {
+		t.Errorf("ParseCoder(): got %d, want %d", got, 913203)
+	}
+	if to, ok := Alias(913201); !ok || to != 913203 {
+		t.Errorf("Alias(913201): got %d %v, want 913203 true", to, ok)
+	}
+	if coder, ok := GetCoder(913201); !ok || coder.Code() != 913203 {
+		t.Errorf("GetCoder(913201): got %v %v, want the replacement", coder, ok)
+	}
+	if info, ok := DescribeCode(913201); !ok || !info.Deprecated || info.Replacement != 913203 || info.Message != "account not found" {
+		t.Errorf("DescribeCode(913201): got %+v %v, want the deprecated code described by 913203", info, ok)
+	}
+	if info, _ := DescribeCode(913203); info.Deprecated || info.Replacement != 0 {
+		t.Errorf("DescribeCode(913203): got %+v, want a code which is not deprecated", info)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("RegisterAlias(913203, 913201): got no panic, want one")
+		}
+	}()
+	RegisterAlias(913203, 913201)
+}
+
+func TestDeprecationWarnings(t *testing.T) {
+	restore := NewConfig(WarnDeprecatedCodes(true)).Apply()
+	defer restore()
+
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer slog.SetDefault(prev)
+
+	Register(NewCoder(913212, http.StatusGone, "gone", ""))
+	RegisterAlias(913211, 913212)
+	for i := 0; i < 3; i++ {
+		_ = ParseCoder(WithCode(913211, "gone"))
+	}
+	if got := strings.Count(buf.String(), "code=913211 replacement=913212"); got != 1 {
+		t.Errorf("warnings: got %d in %q, want 1", got, buf.String())
+	}
+}
+
+func TestRegisterAliasRestored(t *testing.T) {
+	restore := NewConfig().Apply()
+	RegisterAlias(913221, 913222)
+	restore()
+	if _, ok := Alias(913221); ok {
+		t.Errorf("Alias(): got an alias, want none once the config is restored")
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyzer
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Analyzer reports the same problems as Check, package by package: the
+// calls creating coded errors with a literal code which neither the
+// package nor its dependencies register, and the codes which the package
+// registers twice or which one of its dependencies already registers. The
+// codes registered by the packages which are not dependencies, such as
+// the main package registering the codes of its libraries, are not known
+// to it: Check covers such programs. Usage with go vet:
+//
+//	go install github.com/rtmzk/errors/cmd/codevet
+//	go vet -vettool=$(which codevet) ./...
+var Analyzer = &analysis.Analyzer{
+	Name:      "codevet",
+	Doc:       "report the error codes which are never registered or registered twice",
+	URL:       "https://pkg.go.dev/github.com/rtmzk/errors/analyzer",
+	Run:       run,
+	FactTypes: []analysis.Fact{new(Registered)},
+}
+
+// Registered is the fact of the codes registered by a package, which
+// Analyzer exports to the packages importing it.
+type Registered struct {
+	// Codes maps the registered codes to their registration.
+	Codes map[int64]Registration
+}
+
+// Registration is where a code is registered.
+type Registration struct {
+	// Name is the source of the code expression, e.g. "ErrNotFound".
+	Name string
+
+	// Pos is the position of the code expression.
+	Pos string
+}
+
+// AFact implements analysis.Fact.
+func (*Registered) AFact() {}
+
+func (r *Registered) String() string {
+	codes := make([]string, 0, len(r.Codes))
+	for _, reg := range r.Codes {
+		codes = append(codes, reg.Name)
+	}
+	sort.Strings(codes)
+	return "registered(" + strings.Join(codes, ", ") + ")"
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	c := &checker{fset: pass.Fset}
+	for _, f := range pass.Files {
+		if !strings.HasSuffix(pass.Fset.Position(f.Pos()).Filename, "_test.go") {
+			c.checkFile(f, pass.TypesInfo)
+		}
+	}
+
+	deps := map[int64]Registration{}
+	for _, fact := range pass.AllPackageFacts() {
+		if r, ok := fact.Fact.(*Registered); ok {
+			for code, reg := range r.Codes {
+				deps[code] = reg
+			}
+		}
+	}
+
+	own := map[int64]Registration{}
+	for _, u := range c.registered {
+		if reg, ok := deps[u.code]; ok {
+			pass.Reportf(u.at, "%s", duplicated(u, reg.Name, reg.Pos))
+			continue
+		}
+		if reg, ok := own[u.code]; ok {
+			pass.Reportf(u.at, "%s", duplicated(u, reg.Name, reg.Pos))
+			continue
+		}
+		own[u.code] = Registration{Name: u.name, Pos: u.pos.String()}
+	}
+	for _, u := range c.literals {
+		_, inDeps := deps[u.code]
+		if _, inOwn := own[u.code]; !inOwn && !inDeps {
+			pass.Reportf(u.at, "%s", unregistered(u))
+		}
+	}
+
+	if len(own) > 0 {
+		pass.ExportPackageFact(&Registered{Codes: own})
+	}
+	return nil, nil
+}
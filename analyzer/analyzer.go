@@ -0,0 +1,314 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package analyzer statically checks the use of the error codes of
+// github.com/rtmzk/errors across the packages of a program, so that code
+// drift is caught at CI time rather than by panics at startup. It reports:
+//
+//   - the calls creating coded errors, such as errors.WithCode, with an
+//     integer literal code which none of the checked packages registers;
+//   - the codes registered more than once, with errors.Register,
+//     errors.MustRegister, errors.RegisterE or errors.MustRegisterTable,
+//     whether by colliding literals or by constants of different packages.
+//
+// The codes are evaluated by the type checker, so constant expressions
+// such as iota + 100001 are supported. Constants imported from other
+// packages are evaluated when the export data of their package is
+// available, and are ignored otherwise. Test files are not checked.
+//
+// The checks are run on a whole program by Check and the codevet command,
+// so that the codes registered by a package are known to the others, or
+// package by package by Analyzer, which plugs into go vet and the other
+// drivers of golang.org/x/tools/go/analysis.
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ImportPath is the import path of the errors package whose calls are
+// checked.
+const ImportPath = "github.com/rtmzk/errors"
+
+// constructors maps the functions creating coded errors to the index of
+// their code argument.
+var constructors = map[string]int{
+	"WithCode":        0,
+	"WithCodeNoStack": 0,
+	"NewCoded":        0,
+	"Wrapc":           1,
+	"Translate":       1,
+	"NewWithContext":  1,
+	"WrapC":           2,
+}
+
+// registrations are the functions registering a Coder built by NewCoder.
+var registrations = map[string]bool{
+	"Register":     true,
+	"MustRegister": true,
+	"RegisterE":    true,
+}
+
+// Diagnostic is a problem found by Check.
+type Diagnostic struct {
+	Pos     token.Position
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s", d.Pos, d.Message)
+}
+
+// use is a code found in the source.
+type use struct {
+	code int64
+	at   token.Pos
+	pos  token.Position
+	// name is the source of the code expression, e.g. "code.ErrNotFound".
+	name string
+}
+
+// checker accumulates the codes of the checked packages.
+type checker struct {
+	fset       *token.FileSet
+	registered []use
+	literals   []use
+}
+
+// Check checks the packages in dirs, one package per directory, and
+// returns the problems found, sorted by position.
+func Check(dirs ...string) ([]Diagnostic, error) {
+	c := &checker{fset: token.NewFileSet()}
+	for _, dir := range dirs {
+		if err := c.checkDir(dir); err != nil {
+			return nil, err
+		}
+	}
+	return c.diagnostics(), nil
+}
+
+// checkDir collects the codes of the package in dir.
+func (c *checker) checkDir(dir string) error {
+	pkgs, err := parser.ParseDir(c.fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(pkgs))
+	for name := range pkgs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var files []*ast.File
+		for _, f := range pkgs[name].Files {
+			files = append(files, f)
+		}
+		sort.Slice(files, func(i, j int) bool { return files[i].Pos() < files[j].Pos() })
+
+		// Errors, such as unresolved imports, are ignored since only the
+		// values of the constants matter.
+		info := &types.Info{Types: map[ast.Expr]types.TypeAndValue{}}
+		conf := types.Config{Importer: importer.Default(), Error: func(error) {}}
+		_, _ = conf.Check(name, c.fset, files, info)
+
+		for _, f := range files {
+			c.checkFile(f, info)
+		}
+	}
+	return nil
+}
+
+// checkFile collects the codes of the calls of f to the errors package.
+func (c *checker) checkFile(f *ast.File, info *types.Info) {
+	local := importName(f)
+	if local == "" {
+		return
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		fn := calledFunc(call, local)
+		if i, ok := constructors[fn]; ok && i < len(call.Args) {
+			if lit, ok := call.Args[i].(*ast.BasicLit); ok && lit.Kind == token.INT {
+				if u, ok := c.use(call.Args[i], info); ok {
+					c.literals = append(c.literals, u)
+				}
+			}
+		}
+		if registrations[fn] && len(call.Args) == 1 {
+			if inner, ok := call.Args[0].(*ast.CallExpr); ok && calledFunc(inner, local) == "NewCoder" && len(inner.Args) > 0 {
+				if u, ok := c.use(inner.Args[0], info); ok {
+					c.registered = append(c.registered, u)
+				}
+			}
+		}
+		if fn == "MustRegisterTable" && len(call.Args) == 1 {
+			if table, ok := call.Args[0].(*ast.CompositeLit); ok {
+				for _, elt := range table.Elts {
+					if code := specCode(elt); code != nil {
+						if u, ok := c.use(code, info); ok {
+							c.registered = append(c.registered, u)
+						}
+					}
+				}
+			}
+		}
+		return true
+	})
+}
+
+// use returns the code of the expression e, if it can be evaluated.
+func (c *checker) use(e ast.Expr, info *types.Info) (use, bool) {
+	tv, ok := info.Types[e]
+	if !ok || tv.Value == nil {
+		if lit, ok := e.(*ast.BasicLit); ok && lit.Kind == token.INT {
+			tv.Value = constant.MakeFromLiteral(lit.Value, lit.Kind, 0)
+		} else {
+			return use{}, false
+		}
+	}
+	code, ok := constant.Int64Val(constant.ToInt(tv.Value))
+	if !ok {
+		return use{}, false
+	}
+	return use{code: code, at: e.Pos(), pos: c.fset.Position(e.Pos()), name: exprString(e)}, true
+}
+
+// diagnostics returns the problems of the collected codes.
+func (c *checker) diagnostics() []Diagnostic {
+	var ret []Diagnostic
+
+	byCode := map[int64][]use{}
+	for _, u := range c.registered {
+		byCode[u.code] = append(byCode[u.code], u)
+	}
+	for _, uses := range byCode {
+		for _, u := range uses[1:] {
+			ret = append(ret, Diagnostic{
+				Pos:     u.pos,
+				Message: duplicated(u, uses[0].name, uses[0].pos.String()),
+			})
+		}
+	}
+
+	for _, u := range c.literals {
+		if _, ok := byCode[u.code]; !ok {
+			ret = append(ret, Diagnostic{
+				Pos:     u.pos,
+				Message: unregistered(u),
+			})
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		a, b := ret[i].Pos, ret[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Offset < b.Offset
+	})
+	return ret
+}
+
+// duplicated is the message reporting that the code of u is already
+// registered as name at pos.
+func duplicated(u use, name, pos string) string {
+	return fmt.Sprintf("code %d (%s) is already registered as %s at %s", u.code, u.name, name, pos)
+}
+
+// unregistered is the message reporting that the code of u is never
+// registered.
+func unregistered(u use) string {
+	return fmt.Sprintf("code %d is never registered", u.code)
+}
+
+// importName returns the name under which f imports the errors package,
+// or "" if it does not.
+func importName(f *ast.File) string {
+	for _, imp := range f.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		if path != ImportPath {
+			continue
+		}
+		if imp.Name != nil {
+			if imp.Name.Name == "_" || imp.Name.Name == "." {
+				return ""
+			}
+			return imp.Name.Name
+		}
+		return "errors"
+	}
+	return ""
+}
+
+// calledFunc returns the name of the function of the errors package, named
+// local in the file, called by call, or "".
+func calledFunc(call *ast.CallExpr, local string) string {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	if id, ok := sel.X.(*ast.Ident); !ok || id.Name != local {
+		return ""
+	}
+	return sel.Sel.Name
+}
+
+// specCode returns the Code expression of a CoderSpec literal, keyed or
+// not.
+func specCode(elt ast.Expr) ast.Expr {
+	lit, ok := elt.(*ast.CompositeLit)
+	if !ok || len(lit.Elts) == 0 {
+		return nil
+	}
+	for _, e := range lit.Elts {
+		if kv, ok := e.(*ast.KeyValueExpr); ok {
+			if id, ok := kv.Key.(*ast.Ident); ok && id.Name == "Code" {
+				return kv.Value
+			}
+		}
+	}
+	if _, ok := lit.Elts[0].(*ast.KeyValueExpr); ok {
+		return nil
+	}
+	return lit.Elts[0]
+}
+
+// exprString returns the source of a code expression.
+func exprString(e ast.Expr) string {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		return e.Value
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return exprString(e.X) + "." + e.Sel.Name
+	}
+	return "expression"
+}
@@ -0,0 +1,104 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePackage(t *testing.T, root, name, src string) string {
+	t.Helper()
+	dir := filepath.Join(root, name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+const codePkg = `package code
+
+import "github.com/rtmzk/errors"
+
+const (
+	ErrNotFound = iota + 100001
+	ErrConflict
+)
+
+func init() {
+	errors.MustRegister(errors.NewCoder(ErrNotFound, 404, "Not found", ""))
+	errors.MustRegisterTable([]errors.CoderSpec{
+		{Code: ErrConflict, HTTPStatus: 409, Message: "Conflict"},
+		{100003, 400, "Bad request", "", nil},
+	})
+}
+`
+
+const userPkg = `package user
+
+import (
+	"context"
+
+	errs "github.com/rtmzk/errors"
+)
+
+const ErrDuplicate = 100002
+
+func init() {
+	errs.Register(errs.NewCoder(ErrDuplicate, 500, "Duplicate", ""))
+	errs.RegisterE(errs.NewCoder(100001, 500, "Colliding", ""))
+}
+
+func find(ctx context.Context, err error) []error {
+	return []error{
+		errs.WithCode(100001, "registered"),
+		errs.Wrapc(err, 100003, "registered by table"),
+		errs.WithCode(100404, "never registered"),
+		errs.WrapC(ctx, err, 100405, "never registered either"),
+		errs.NewCoded(ErrUnregistered, "constants are not literals"),
+	}
+}
+
+const ErrUnregistered = 100999
+`
+
+func TestCheck(t *testing.T) {
+	root := t.TempDir()
+	code := writePackage(t, root, "code", codePkg)
+	user := writePackage(t, root, "user", userPkg)
+	writePackage(t, root, "other", "package other\n\nimport \"errors\"\n\nvar _ = errors.New(\"std\")\n")
+
+	diags, err := Check(code, user, filepath.Join(root, "other"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"user.go:12:30: code 100002 (ErrDuplicate) is already registered as ErrConflict",
+		"user.go:13:31: code 100001 (100001) is already registered as ErrNotFound",
+		"user.go:20:17: code 100404 is never registered",
+		"user.go:21:24: code 100405 is never registered",
+	}
+	if len(diags) != len(want) {
+		t.Fatalf("Check(): got %v, want %d diagnostics", diags, len(want))
+	}
+	for i, w := range want {
+		if got := diags[i].String(); !strings.Contains(got, w) {
+			t.Errorf("diagnostic %d: got %q, want it to contain %q", i, got, w)
+		}
+	}
+}
+
+func TestCheckErrors(t *testing.T) {
+	root := t.TempDir()
+	broken := writePackage(t, root, "broken", "package broken\n\nfunc {")
+	if _, err := Check(broken); err == nil {
+		t.Errorf("Check(broken): got no error, want a parse error")
+	}
+	if _, err := Check(filepath.Join(root, "missing")); err == nil {
+		t.Errorf("Check(missing): got no error, want one")
+	}
+}
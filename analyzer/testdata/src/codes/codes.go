@@ -0,0 +1,20 @@
+package codes // want package:`registered\(ErrConflict, ErrNotFound\)`
+
+import "github.com/rtmzk/errors"
+
+const (
+	ErrNotFound = iota + 100001
+	ErrConflict
+)
+
+func init() {
+	errors.MustRegister(errors.NewCoder(ErrNotFound, 404, "Not found", ""))
+	errors.MustRegisterTable([]errors.CoderSpec{
+		{Code: ErrConflict, HTTPStatus: 409, Message: "Conflict"},
+		{Code: 100001, HTTPStatus: 404, Message: "Missing"}, // want `code 100001 \(100001\) is already registered as ErrNotFound at .*codes.go:11:38`
+	})
+}
+
+func find() error {
+	return errors.WithCode(100099, "not there") // want `code 100099 is never registered`
+}
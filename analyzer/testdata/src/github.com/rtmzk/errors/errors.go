@@ -0,0 +1,22 @@
+// Package errors is a stub of github.com/rtmzk/errors for the tests of
+// Analyzer.
+package errors
+
+type Coder interface{ Code() int }
+
+type CoderSpec struct {
+	Code       int
+	HTTPStatus int
+	Message    string
+	Reference  string
+	Options    []interface{}
+}
+
+func NewCoder(code, status int, message, ref string) Coder        { return nil }
+func Register(Coder)                                              {}
+func MustRegister(Coder)                                          {}
+func MustRegisterTable([]CoderSpec)                               {}
+func WithCode(code int, format string, args ...interface{}) error { return nil }
+func Wrapc(err error, code int, format string, args ...interface{}) error {
+	return nil
+}
@@ -0,0 +1,23 @@
+package user // want package:`registered\(100010\)`
+
+import (
+	"codes"
+
+	errs "github.com/rtmzk/errors"
+)
+
+func init() {
+	errs.Register(errs.NewCoder(100002, 409, "Taken", "")) // want `code 100002 \(100002\) is already registered as ErrConflict at .*codes.go:13:10`
+	errs.Register(errs.NewCoder(100010, 400, "Invalid", ""))
+}
+
+func get(id string) error {
+	if id == "" {
+		return errs.WithCode(100010, "empty id")
+	}
+	if id == "taken" {
+		return errs.Wrapc(nil, 100001, "taken")
+	}
+	_ = codes.ErrNotFound
+	return errs.WithCode(100404, "no user %s", id) // want `code 100404 is never registered`
+}
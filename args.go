@@ -0,0 +1,99 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Redacted replaces the value of the secret arguments.
+const Redacted = "[REDACTED]"
+
+// redactSecrets is 1 when the values of the secret arguments are redacted.
+var redactSecrets int32 = 1
+
+// SetRedaction enables or disables the redaction of the secret arguments,
+// see SecretArg. Redaction is enabled by default. Disabling it records the
+// values of the secret arguments of new errors, which is only meant to
+// debug an incident for a limited time, see SetVerbosity.
+func SetRedaction(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&redactSecrets, v)
+}
+
+func redactionNow() bool {
+	return atomic.LoadInt32(&redactSecrets) == 1
+}
+
+// Argument is a named argument of a failed call, recorded as a field by
+// WrapArgs.
+type Argument struct {
+	Key    string
+	Value  interface{}
+	secret bool
+}
+
+// Arg returns the argument key with the given value.
+func Arg(key string, value interface{}) Argument {
+	return Argument{Key: key, Value: value}
+}
+
+// SecretArg returns the argument key whose value must never leave the
+// process, such as a token or a password. Only its presence is recorded:
+// its value is replaced by Redacted when the error is created, unless
+// redaction is disabled, see SetRedaction.
+func SecretArg(key string, value interface{}) Argument {
+	return Argument{Key: key, Value: value, secret: true}
+}
+
+// WrapArgs returns an error annotating err with the given code, the
+// constant message msg and the arguments of the failed call, which are
+// recorded as fields, see Fields:
+//
+//	errors.WrapArgs(err, code.ErrUserNotFound, "load user",
+//		errors.Arg("userID", id), errors.Arg("size", n))
+//
+// Unlike interpolating the arguments into the message, this keeps the
+// message stable and the arguments typed, and lets secrets be redacted.
+// If err is nil, WrapArgs returns nil.
+func WrapArgs(err error, code int, msg string, args ...Argument) error {
+	if err == nil {
+		return nil
+	}
+	fields := limitFields(pathFields(err), argFields(args))
+	st := codeCallers(code)
+	return created(newCoded(code, fmt.Errorf("%s", msg), err, fields, st, stackPC(st)))
+}
+
+// argFields returns the fields recording args.
+func argFields(args []Argument) map[string]interface{} {
+	if len(args) == 0 {
+		return nil
+	}
+	redact := redactionNow()
+	fields := make(map[string]interface{}, len(args))
+	for _, arg := range args {
+		if arg.secret && redact {
+			fields[arg.Key] = Redacted
+			continue
+		}
+		fields[arg.Key] = arg.Value
+	}
+	return fields
+}
@@ -0,0 +1,31 @@
+package errors
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestWrapArgs(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(903501, 404, "user not found", ""))
+
+	if got := WrapArgs(nil, 903501, "load user", Arg("userID", 42)); got != nil {
+		t.Errorf("WrapArgs(nil): got %v, want nil", got)
+	}
+
+	err := WrapArgs(io.EOF, 903501, "load user",
+		Arg("userID", 42), Arg("size", 7), SecretArg("token", "s3cr3t"))
+	if got, want := err.Error(), "load user"; got != want {
+		t.Errorf("Error(): got %q, want %q", got, want)
+	}
+	if !IsCode(err, 903501) || Cause(err) != io.EOF {
+		t.Errorf("WrapArgs: got code %d and cause %v, want 903501 and EOF", ParseCoder(err).Code(), Cause(err))
+	}
+	want := map[string]interface{}{"userID": 42, "size": 7, "token": Redacted}
+	if got := Fields(err); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields(): got %v, want %v", got, want)
+	}
+}
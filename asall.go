@@ -0,0 +1,44 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+// AsAll returns every error of err's chain which matches T, outermost first,
+// where As only finds the first one. The members of the multi-errors of the
+// chain are searched too, see Join. An error matches T if its concrete value
+// is assignable to T, or if it has a method As(interface{}) bool such that
+// As(&target) returns true, like for As:
+//
+//	for _, up := range errors.AsAll[*UpstreamError](err) {
+//		log.Printf("upstream %s failed: %v", up.Service, up)
+//	}
+//
+// AsAll returns nil if err is nil or if no error matches.
+func AsAll[T any](err error) []T {
+	var ret []T
+	walk(err, func(e error) bool {
+		if v, ok := e.(T); ok {
+			ret = append(ret, v)
+			return false
+		}
+		if x, ok := e.(interface{ As(interface{}) bool }); ok {
+			var v T
+			if x.As(&v) {
+				ret = append(ret, v)
+			}
+		}
+		return false
+	})
+	return ret
+}
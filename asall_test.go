@@ -0,0 +1,63 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+)
+
+type upstreamError struct {
+	service string
+	cause   error
+}
+
+func (e *upstreamError) Error() string { return e.service + " failed" }
+
+func (e *upstreamError) Unwrap() error { return e.cause }
+
+// asUpstream converts itself into an *upstreamError through As.
+type asUpstream struct{ service string }
+
+func (e asUpstream) Error() string { return "as " + e.service }
+
+func (e asUpstream) As(target interface{}) bool {
+	if p, ok := target.(**upstreamError); ok {
+		*p = &upstreamError{service: e.service}
+		return true
+	}
+	return false
+}
+
+func TestAsAll(t *testing.T) {
+	first := &upstreamError{service: "billing"}
+	second := &upstreamError{service: "ledger"}
+
+	tests := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{"nil", nil, nil},
+		{"no match", io.EOF, nil},
+		{"one", Wrap(first, "charge"), []string{"billing"}},
+		{"nested", Wrapf(fmt.Errorf("retry: %w", Wrap(first, "charge")), "order %d", 1), []string{"billing"}},
+		{"two layers", fmt.Errorf("%w", &upstreamError{"ledger", Wrap(first, "charge")}), []string{"ledger", "billing"}},
+		{"joined", Join(first, io.EOF, Wrap(second, "post")), []string{"billing", "ledger"}},
+		{"As method", Wrap(asUpstream{"search"}, "query"), []string{"search"}},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, e := range AsAll[*upstreamError](tt.err) {
+			got = append(got, e.service)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("AsAll(%s): got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if got := AsAll[interface{ Code() int }](Wrap(WithCode(1, "a"), "b")); len(got) != 1 {
+		t.Errorf("AsAll(interface): got %d matches, want 1", len(got))
+	}
+}
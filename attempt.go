@@ -0,0 +1,133 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"io"
+)
+
+// Fields recording the attempt of an error, see WithAttempt.
+const (
+	FieldAttempt     = "attempt"
+	FieldMaxAttempts = "max_attempts"
+)
+
+// Labels returned by AttemptLabel.
+const (
+	LabelFirstFailure     = "first_failure"
+	LabelRetry            = "retry"
+	LabelRetriesExhausted = "retries_exhausted"
+)
+
+// WithAttempt annotates err with the attempt n, counted from 1, out of max
+// attempts of a retried operation, so that a first failure can be told
+// apart from retries being exhausted. Alerting should usually only page on
+// the latter. A max of 0 means the attempts are unbounded.
+// If err is nil, WithAttempt returns nil.
+func WithAttempt(err error, n, max int) error {
+	if err == nil {
+		return nil
+	}
+	return &withAttempt{
+		error:   err,
+		attempt: n,
+		max:     max,
+	}
+}
+
+// Attempt returns the attempt and maximum attempts of the outermost
+// WithAttempt annotation of err's chain. It returns false if there is none.
+func Attempt(err error) (n, max int, ok bool) {
+	for _, e := range list(err) {
+		if a, ok := e.(*withAttempt); ok {
+			return a.attempt, a.max, true
+		}
+	}
+	return 0, 0, false
+}
+
+// IsFirstFailure reports whether err was annotated as the first attempt.
+func IsFirstFailure(err error) bool {
+	n, _, ok := Attempt(err)
+	return ok && n <= 1
+}
+
+// RetriesExhausted reports whether err was annotated as the last allowed
+// attempt.
+func RetriesExhausted(err error) bool {
+	n, max, ok := Attempt(err)
+	return ok && max > 0 && n >= max
+}
+
+// AttemptLabel returns a metric label telling whether err is a first
+// failure, an intermediate retry or the end of the retries. Errors without
+// an attempt annotation are reported as first failures.
+func AttemptLabel(err error) string {
+	switch {
+	case RetriesExhausted(err):
+		return LabelRetriesExhausted
+	case IsFirstFailure(err), !hasAttempt(err):
+		return LabelFirstFailure
+	}
+	return LabelRetry
+}
+
+func hasAttempt(err error) bool {
+	_, _, ok := Attempt(err)
+	return ok
+}
+
+type withAttempt struct {
+	error
+	attempt int
+	max     int
+}
+
+func (w *withAttempt) Error() string {
+	if w.max > 0 {
+		return fmt.Sprintf("%s (attempt %d of %d)", w.error.Error(), w.attempt, w.max)
+	}
+	return fmt.Sprintf("%s (attempt %d)", w.error.Error(), w.attempt)
+}
+
+func (w *withAttempt) fields() map[string]interface{} {
+	fields := map[string]interface{}{FieldAttempt: w.attempt}
+	if w.max > 0 {
+		fields[FieldMaxAttempts] = w.max
+	}
+	return fields
+}
+
+func (w *withAttempt) Cause() error { return w.error }
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (w *withAttempt) Unwrap() error { return w.error }
+
+func (w *withAttempt) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "%+v", w.Cause())
+			fmt.Fprintf(s, "\n%s", w.Error())
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, w.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", w.Error())
+	}
+}
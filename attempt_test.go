@@ -0,0 +1,52 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestWithAttempt(t *testing.T) {
+	if got := WithAttempt(nil, 1, 3); got != nil {
+		t.Errorf("WithAttempt(nil): got %v, want nil", got)
+	}
+
+	tests := []struct {
+		err       error
+		msg       string
+		first     bool
+		exhausted bool
+		label     string
+	}{
+		{io.EOF, "EOF", false, false, LabelFirstFailure},
+		{WithAttempt(io.EOF, 1, 3), "EOF (attempt 1 of 3)", true, false, LabelFirstFailure},
+		{WithAttempt(io.EOF, 2, 3), "EOF (attempt 2 of 3)", false, false, LabelRetry},
+		{Wrap(WithAttempt(io.EOF, 3, 3), "sync"), "sync: EOF (attempt 3 of 3)", false, true, LabelRetriesExhausted},
+		{WithAttempt(io.EOF, 7, 0), "EOF (attempt 7)", false, false, LabelRetry},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.msg {
+			t.Errorf("Error(): got %q, want %q", got, tt.msg)
+		}
+		if got := IsFirstFailure(tt.err); got != tt.first {
+			t.Errorf("IsFirstFailure(%v): got %v, want %v", tt.err, got, tt.first)
+		}
+		if got := RetriesExhausted(tt.err); got != tt.exhausted {
+			t.Errorf("RetriesExhausted(%v): got %v, want %v", tt.err, got, tt.exhausted)
+		}
+		if got := AttemptLabel(tt.err); got != tt.label {
+			t.Errorf("AttemptLabel(%v): got %q, want %q", tt.err, got, tt.label)
+		}
+	}
+
+	err := WithAttempt(io.EOF, 2, 5)
+	want := map[string]interface{}{FieldAttempt: 2, FieldMaxAttempts: 5}
+	if got := Fields(err); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields(): got %v, want %v", got, want)
+	}
+	if got := fmt.Sprintf("%s", err); got != "EOF (attempt 2 of 5)" {
+		t.Errorf("%%s: got %q", got)
+	}
+}
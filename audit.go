@@ -0,0 +1,129 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// auditConversions is 1 while the conversion audit is enabled.
+var auditConversions int32
+
+// SetConversionAudit enables or disables the recording of the call sites
+// where Translate and Ensure assign a code to an error which carries none,
+// reported by ConversionReport. It is disabled by default. It is meant to
+// guide the migration of a code base to coded errors: the sites reported
+// are those still relying on a default code.
+func SetConversionAudit(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&auditConversions, v)
+}
+
+func conversionAuditNow() bool {
+	return atomic.LoadInt32(&auditConversions) == 1
+}
+
+// ConversionSite is a call site where uncoded errors were given a code,
+// see SetConversionAudit.
+type ConversionSite struct {
+	// Site is the file:line of the call to Translate or Ensure.
+	Site string `json:"site"`
+
+	// Function is the function making the call.
+	Function string `json:"function"`
+
+	// Code is the code assigned.
+	Code int `json:"code"`
+
+	// Count is the number of errors converted.
+	Count uint64 `json:"count"`
+
+	// CauseType is the type of the root cause of the first error
+	// converted, e.g. "*fs.PathError".
+	CauseType string `json:"cause_type"`
+}
+
+type conversionKey struct {
+	pc   uintptr
+	code int
+}
+
+type conversionCounter struct {
+	count     uint64
+	causeType string
+}
+
+// conversions holds a *conversionCounter per conversionKey.
+var conversions sync.Map
+
+// recordConversion records the conversion of the uncoded error err to
+// code by the call at pc, if the audit is enabled.
+func recordConversion(pc uintptr, code int, err error) {
+	if !conversionAuditNow() {
+		return
+	}
+	key := conversionKey{pc: pc, code: code}
+	v, ok := conversions.Load(key)
+	if !ok {
+		errs := list(err)
+		v, _ = conversions.LoadOrStore(key, &conversionCounter{
+			causeType: fmt.Sprintf("%T", errs[len(errs)-1]),
+		})
+	}
+	atomic.AddUint64(&v.(*conversionCounter).count, 1)
+}
+
+// ConversionReport returns the call sites recorded since the audit was
+// enabled or the report reset, see SetConversionAudit, the most frequent
+// first.
+func ConversionReport() []ConversionSite {
+	var ret []ConversionSite
+	conversions.Range(func(k, v interface{}) bool {
+		key, counter := k.(conversionKey), v.(*conversionCounter)
+		f := Frame(key.pc)
+		ret = append(ret, ConversionSite{
+			Site:      fmt.Sprintf("%s:%d", f.file(), f.line()),
+			Function:  f.name(),
+			Code:      key.code,
+			Count:     atomic.LoadUint64(&counter.count),
+			CauseType: counter.causeType,
+		})
+		return true
+	})
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Count != ret[j].Count {
+			return ret[i].Count > ret[j].Count
+		}
+		if ret[i].Site != ret[j].Site {
+			return ret[i].Site < ret[j].Site
+		}
+		return ret[i].Code < ret[j].Code
+	})
+	return ret
+}
+
+// ResetConversionReport clears the call sites recorded so far.
+func ResetConversionReport() {
+	conversions.Range(func(k, _ interface{}) bool {
+		conversions.Delete(k)
+		return true
+	})
+}
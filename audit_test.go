@@ -0,0 +1,57 @@
+package errors
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func init() {
+	Register(NewCoder(911301, 500, "storage failed", ""))
+	Register(NewCoder(911302, 500, "request failed", ""))
+}
+
+func TestConversionReport(t *testing.T) {
+	restore := NewConfig(AuditConversions(true)).Apply()
+	defer restore()
+	ResetConversionReport()
+	defer ResetConversionReport()
+
+	for i := 0; i < 3; i++ {
+		_ = Translate(&os.PathError{Op: "open", Path: "/tmp/x", Err: syscall.ENOENT}, 911301, "open")
+	}
+	_ = Translate(WithCode(911302, "coded"), 911301, "already coded")
+	ctx := WithDefaultCode(context.Background(), 911302)
+	_ = Ensure(ctx, io.EOF)
+
+	report := ConversionReport()
+	if len(report) != 2 {
+		t.Fatalf("ConversionReport: got %d sites %+v, want 2", len(report), report)
+	}
+	want := []struct {
+		code      int
+		count     uint64
+		causeType string
+	}{
+		{911301, 3, "syscall.Errno"},
+		{911302, 1, "*errors.errorString"},
+	}
+	for i, w := range want {
+		got := report[i]
+		if got.Code != w.code || got.Count != w.count || got.CauseType != w.causeType {
+			t.Errorf("site %d: got %d x%d %s, want %d x%d %s", i, got.Code, got.Count, got.CauseType, w.code, w.count, w.causeType)
+		}
+		if !strings.Contains(got.Site, "audit_test.go:") || got.Function != "github.com/rtmzk/errors.TestConversionReport" {
+			t.Errorf("site %d: got %s in %s, want a line of the test", i, got.Site, got.Function)
+		}
+	}
+
+	SetConversionAudit(false)
+	_ = Ensure(ctx, io.EOF)
+	if got := ConversionReport()[1].Count; got != 1 {
+		t.Errorf("count with the audit disabled: got %d, want 1", got)
+	}
+}
@@ -4,6 +4,7 @@ package errors
 
 import (
 	"fmt"
+	"io"
 	"testing"
 
 	stderrors "errors"
@@ -108,3 +109,66 @@ func BenchmarkStackFormatting(b *testing.B) {
 	}
 	GlobalE = stackStr
 }
+
+func BenchmarkParseCoder(b *testing.B) {
+	const first, count = 100000, 5000
+
+	backends := []struct {
+		name    string
+		backend func() RegistryBackend
+	}{
+		{"map", MapBackend},
+		{"dense", func() RegistryBackend { return DenseBackend(first, first+count) }},
+	}
+
+	saved := codes
+	defer func() { codes = saved }()
+
+	for _, bb := range backends {
+		codes = bb.backend()
+		for c := first; c < first+count; c++ {
+			codes.set(defaultCoder{C: c, HTTP: 400})
+		}
+		errs := make([]error, 64)
+		for i := range errs {
+			errs[i] = WithCode(first+i*count/len(errs), "benchmark")
+		}
+
+		b.Run(bb.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				var coder Coder
+				for i := 0; pb.Next(); i++ {
+					coder = ParseCoder(errs[i%len(errs)])
+				}
+				GlobalE = coder
+			})
+		})
+	}
+}
+
+func BenchmarkConstructors(b *testing.B) {
+	Register(NewCoder(914301, 500, "benchmark", ""))
+
+	constructors := []struct {
+		name string
+		fn   func() error
+	}{
+		{"New", func() error { return New("benchmark") }},
+		{"Errorf", func() error { return Errorf("benchmark %d", 1) }},
+		{"Wrap", func() error { return Wrap(io.EOF, "benchmark") }},
+		{"Wrapf", func() error { return Wrapf(io.EOF, "benchmark %d", 1) }},
+		{"WithCode", func() error { return WithCode(914301, "benchmark") }},
+		{"WithCodeArgs", func() error { return WithCode(914301, "benchmark %d", 1) }},
+		{"Wrapc", func() error { return Wrapc(io.EOF, 914301, "benchmark") }},
+	}
+
+	for _, c := range constructors {
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				GlobalE = c.fn()
+			}
+		})
+	}
+}
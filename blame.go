@@ -0,0 +1,85 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import "net/http"
+
+// Blame is the party whose fault an error is, so that alerting pages the
+// on-call engineers for the failures of the system and its upstreams, but
+// not for the mistakes of users.
+type Blame string
+
+// Blames.
+const (
+	// BlameUser marks errors caused by the user, such as invalid input.
+	BlameUser Blame = "user"
+
+	// BlameSystem marks errors caused by the system itself.
+	BlameSystem Blame = "system"
+
+	// BlameUpstream marks errors caused by a dependency of the system, such
+	// as a third-party API.
+	BlameUpstream Blame = "upstream"
+)
+
+// FieldBlame is the field holding the blame set on an error by Blamed.
+const FieldBlame = "blame"
+
+// Blamed returns err blaming b, overriding the blame of its Coder, e.g.
+// when a code is shared by user and system failures.
+// If err is nil, Blamed returns nil.
+func Blamed(err error, b Blame) error {
+	return WithFields(err, FieldBlame, b)
+}
+
+// BlameOf returns the party blamed for err: the blame set by Blamed, then
+// the blame of its Coder, see WithBlame. Otherwise errors mapped to a 4xx
+// HTTP status blame the user, to 502 Bad Gateway or 504 Gateway Timeout the
+// upstream, and all other errors the system. A nil error blames nobody.
+func BlameOf(err error) Blame {
+	if err == nil {
+		return ""
+	}
+	if b, ok := Fields(err)[FieldBlame].(Blame); ok && b != "" {
+		return b
+	}
+	return blameOfCoder(ParseCoder(err))
+}
+
+// CodeBlame returns the party blamed for the errors with the given code,
+// ignoring the overrides of Blamed. Unregistered codes are resolved with
+// the unknown coder.
+func CodeBlame(code int) Blame {
+	coder, ok := GetCoder(code)
+	if !ok {
+		coder = unknownCoder
+	}
+	return blameOfCoder(coder)
+}
+
+// blameOfCoder returns the blame of coder, derived from its HTTP status when
+// it has none.
+func blameOfCoder(coder Coder) Blame {
+	if b, ok := coderBlame(coder); ok {
+		return b
+	}
+	switch status := coder.HTTPStatus(); {
+	case status == http.StatusBadGateway, status == http.StatusGatewayTimeout:
+		return BlameUpstream
+	case status >= 400 && status < 500:
+		return BlameUser
+	}
+	return BlameSystem
+}
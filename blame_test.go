@@ -0,0 +1,49 @@
+package errors
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestBlameOf(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(906101, 400, "invalid", ""))
+	MustRegister(NewCoder(906102, 500, "failed", ""))
+	MustRegister(NewCoder(906103, 504, "timeout", ""))
+	MustRegister(NewCoder(906104, 503, "payments down", "", WithBlame(BlameUpstream)))
+
+	tests := []struct {
+		err  error
+		want Blame
+	}{
+		{nil, ""},
+		{io.EOF, BlameSystem},
+		{WithCode(906101, "bad name"), BlameUser},
+		{Wrap(WithCode(906102, "db"), "load"), BlameSystem},
+		{WithCode(906103, "slow"), BlameUpstream},
+		{WithCode(906104, "psp"), BlameUpstream},
+		{Wrap(Blamed(WithCode(906101, "quota"), BlameSystem), "wrapped"), BlameSystem},
+	}
+
+	for i, tt := range tests {
+		if got := BlameOf(tt.err); got != tt.want {
+			t.Errorf("test %d: got %q, want %q", i+1, got, tt.want)
+		}
+	}
+
+	if got := CodeBlame(906104); got != BlameUpstream {
+		t.Errorf("CodeBlame(906104): got %q, want %q", got, BlameUpstream)
+	}
+	if info, _ := DescribeCode(906104); info.Blame != BlameUpstream {
+		t.Errorf("DescribeCode(906104).Blame: got %q, want %q", info.Blame, BlameUpstream)
+	}
+
+	resp := &http.Response{StatusCode: 400, Status: "400 Bad Request", Body: io.NopCloser(bytes.NewReader(nil))}
+	if got := BlameOf(DecodeResponse(resp)); got != BlameUpstream {
+		t.Errorf("BlameOf(DecodeResponse()): got %q, want %q", got, BlameUpstream)
+	}
+}
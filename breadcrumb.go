@@ -0,0 +1,74 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import "fmt"
+
+// Breadcrumb is the call site of one layer of an error chain, together with
+// the message added by that layer.
+type Breadcrumb struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"function"`
+	Message  string `json:"message,omitempty"`
+}
+
+// String returns the breadcrumb as "file:line: message".
+func (b Breadcrumb) String() string {
+	if b.Message == "" {
+		return fmt.Sprintf("%s:%d", b.File, b.Line)
+	}
+	return fmt.Sprintf("%s:%d: %s", b.File, b.Line, b.Message)
+}
+
+// Breadcrumbs returns the call sites where err was created and wrapped by
+// this package, outermost first. It gives a cheap view of the path the
+// error took, since each layer only contributes its call site, which is
+// recorded whether or not the layer captures a stack trace.
+func Breadcrumbs(err error) []Breadcrumb {
+	var ret []Breadcrumb
+	for _, e := range list(err) {
+		var (
+			pc  uintptr
+			msg string
+		)
+		switch e := e.(type) {
+		case *fundamental:
+			pc, msg = e.pc, e.msg
+		case *withStack:
+			pc = e.pc
+			if m, ok := e.error.(*withMessage); ok && m.pc == 0 {
+				msg = m.msg
+			}
+		case *withMessage:
+			pc, msg = e.pc, e.msg
+		case *withCode:
+			pc, msg = e.pc, e.err.Error()
+		case *handoff:
+			pc, msg = e.pc, e.msg
+		}
+		if pc == 0 {
+			continue
+		}
+		f := Frame(pc)
+		ret = append(ret, Breadcrumb{
+			File:     f.file(),
+			Line:     f.line(),
+			Function: f.name(),
+			Message:  msg,
+		})
+	}
+	return ret
+}
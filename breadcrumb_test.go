@@ -0,0 +1,75 @@
+package errors
+
+import (
+	"io"
+	"path"
+	"testing"
+)
+
+func TestBreadcrumbs(t *testing.T) {
+	err := New("origin")          // line 10
+	err = Wrap(err, "wrapped")    // line 11
+	err = WithMessage(err, "msg") // line 12
+	err = Wrapc(err, 901201, "coded")
+	err = WithStack(err)
+
+	want := []struct {
+		line int
+		msg  string
+	}{
+		{14, ""},
+		{13, "coded"},
+		{12, "msg"},
+		{11, "wrapped"},
+		{10, "origin"},
+	}
+
+	got := Breadcrumbs(err)
+	if len(got) != len(want) {
+		t.Fatalf("Breadcrumbs(): got %v, want %d entries", got, len(want))
+	}
+	for i, w := range want {
+		if path.Base(got[i].File) != "breadcrumb_test.go" || got[i].Line != w.line || got[i].Message != w.msg {
+			t.Errorf("Breadcrumbs()[%d]: got %v, want breadcrumb_test.go:%d: %s", i, got[i], w.line, w.msg)
+		}
+		if got[i].Function != "github.com/rtmzk/errors.TestBreadcrumbs" {
+			t.Errorf("Breadcrumbs()[%d].Function: got %q", i, got[i].Function)
+		}
+	}
+
+	if got := Breadcrumbs(io.EOF); len(got) != 0 {
+		t.Errorf("Breadcrumbs(io.EOF): got %v, want none", got)
+	}
+}
+
+func TestBreadcrumbsWithoutStacks(t *testing.T) {
+	SetCaptureStacks(false)
+	defer SetCaptureStacks(true)
+
+	err := New("origin")
+	err = Wrap(err, "wrapped")
+	err = Wrapc(err, 901201, "coded")
+
+	got := Breadcrumbs(err)
+	if len(got) != 3 {
+		t.Fatalf("Breadcrumbs(): got %v, want 3 entries", got)
+	}
+	for i, msg := range []string{"coded", "wrapped", "origin"} {
+		if path.Base(got[i].File) != "breadcrumb_test.go" || got[i].Message != msg {
+			t.Errorf("Breadcrumbs()[%d]: got %v, want breadcrumb_test.go: %s", i, got[i], msg)
+		}
+	}
+}
+
+func TestBreadcrumbsStackPC(t *testing.T) {
+	defer SetCaptureStacks(true)
+
+	var got []Breadcrumb
+	for _, capture := range []bool{true, false} {
+		SetCaptureStacks(capture)
+		got = append(got, Breadcrumbs(WithCode(901201, "coded"))...)
+	}
+	if len(got) != 2 || got[0] != got[1] {
+		t.Errorf("Breadcrumbs(): got %v, want the same call site with and without stacks", got)
+	}
+}
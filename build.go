@@ -0,0 +1,94 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import "fmt"
+
+// ErrorOption changes a setting of an error created by NewCoded.
+type ErrorOption func(*errorOptions)
+
+// errorOptions are the settings of NewCoded.
+type errorOptions struct {
+	cause   error
+	fields  map[string]interface{}
+	details []Detail
+	public  string
+	noStack bool
+}
+
+// WithField attaches the field key with the given value to the error, like
+// WithFields. Later fields replace earlier ones with the same key.
+func WithField(key string, value interface{}) ErrorOption {
+	return func(o *errorOptions) {
+		if o.fields == nil {
+			o.fields = map[string]interface{}{}
+		}
+		o.fields[key] = value
+	}
+}
+
+// WithCause sets the cause of the error, like Wrapc. A nil cause is
+// ignored, so that the error is still created.
+func WithCause(err error) ErrorOption {
+	return func(o *errorOptions) { o.cause = err }
+}
+
+// WithDetail attaches details to the error, like WithDetails.
+func WithDetail(details ...Detail) ErrorOption {
+	return func(o *errorOptions) { o.details = append(o.details, details...) }
+}
+
+// WithPublicMessage replaces the message served to clients, see Message,
+// leaving the message of the error unchanged.
+func WithPublicMessage(format string, args ...interface{}) ErrorOption {
+	msg := fmt.Sprintf(format, args...)
+	return func(o *errorOptions) { o.public = msg }
+}
+
+// WithoutStack skips the capture of the stack trace, like WithCodeNoStack.
+func WithoutStack() ErrorOption {
+	return func(o *errorOptions) { o.noStack = true }
+}
+
+// NewCoded returns an error with the given code and message, set up by
+// opts, e.g.
+//
+//	errors.NewCoded(code.ErrUserNotFound, "user not found",
+//		errors.WithField("user", id), errors.WithCause(err), errors.WithoutStack())
+//
+// It gathers the variants of WithCode, Wrapc and WithCodeNoStack in a
+// single constructor. The message is used as is, it is not a format.
+func NewCoded(code int, message string, opts ...ErrorOption) error {
+	var o errorOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var st *stack
+	if !o.noStack {
+		st = codeCallers(code)
+	}
+	w := newCoded(code, fmt.Errorf("%s", message), o.cause, nil, st, stackPC(st))
+	w.details = o.details
+	if o.cause != nil || o.fields != nil {
+		w.fields = limitFields(pathFields(o.cause), o.fields)
+	}
+
+	err := created(w)
+	if o.public != "" {
+		err = &withPublicMessage{error: err, msg: o.public}
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+package errors
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestNewCoded(t *testing.T) {
+	err := NewCoded(911401, "user not found",
+		WithField("user", 42), WithCause(io.EOF), WithDetail(BlobRef{Ref: "s3://x"}))
+
+	if got := err.Error(); got != "user not found" {
+		t.Errorf("Error(): got %q, want %q", got, "user not found")
+	}
+	if !IsCode(err, 911401) {
+		t.Errorf("IsCode(): got false, want true")
+	}
+	if got := Cause(err); got != io.EOF {
+		t.Errorf("Cause(): got %v, want %v", got, io.EOF)
+	}
+	if got, want := Fields(err), map[string]interface{}{"user": 42}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields(): got %v, want %v", got, want)
+	}
+	if got, want := BlobRefs(err), []string{"s3://x"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("BlobRefs(): got %v, want %v", got, want)
+	}
+	if st := err.(interface{ StackTrace() StackTrace }).StackTrace(); len(st) == 0 {
+		t.Errorf("StackTrace(): got none, want a stack")
+	}
+}
+
+func TestNewCodedOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []ErrorOption
+		wantStack bool
+		wantCause error
+	}{
+		{"no options", nil, true, nil},
+		{"no stack", []ErrorOption{WithoutStack()}, false, nil},
+		{"nil cause", []ErrorOption{WithCause(nil)}, true, nil},
+		{"field", []ErrorOption{WithField("k", "v")}, true, nil},
+	}
+
+	for _, tt := range tests {
+		err := NewCoded(911402, "50% done", tt.opts...)
+		if got := err.Error(); got != "50% done" {
+			t.Errorf("%s: Error(): got %q, want %q", tt.name, got, "50% done")
+		}
+		w := err.(*withCode)
+		if got := w.stack != nil; got != tt.wantStack {
+			t.Errorf("%s: has stack: got %v, want %v", tt.name, got, tt.wantStack)
+		}
+		if w.cause != tt.wantCause {
+			t.Errorf("%s: cause: got %v, want %v", tt.name, w.cause, tt.wantCause)
+		}
+	}
+}
+
+func TestNewCodedPublicMessage(t *testing.T) {
+	err := NewCoded(911403, "lookup of user 42 failed", WithPublicMessage("user %d not found", 42))
+
+	if got := err.Error(); got != "lookup of user 42 failed" {
+		t.Errorf("Error(): got %q, want %q", got, "lookup of user 42 failed")
+	}
+	if got := Message(err, ""); got != "user 42 not found" {
+		t.Errorf("Message(): got %q, want %q", got, "user 42 not found")
+	}
+	if !IsCode(err, 911403) {
+		t.Errorf("IsCode(): got false, want true")
+	}
+}
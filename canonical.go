@@ -0,0 +1,54 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// CanonicalString returns a stable rendering of err, suitable for building
+// idempotency or deduplication keys.
+//
+// The rendering holds the codes of the chain, outermost first, the quoted
+// error message and the JSON encoding of the attached details, separated by
+// "|". It never contains stack traces, and only depends on err itself: two
+// equal errors render the same in any process and locale.
+// CanonicalString returns "" if err is nil.
+func CanonicalString(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, e := range list(err) {
+		if c, ok := e.(CodeError); ok {
+			b.WriteString(strconv.Itoa(c.Code()))
+			b.WriteByte('|')
+		}
+	}
+
+	b.WriteString(strconv.Quote(err.Error()))
+
+	for _, d := range renderDetails(Details(err)) {
+		// encoding/json sorts map keys, which keeps the output stable.
+		byts, _ := json.Marshal(d)
+		b.WriteByte('|')
+		b.Write(byts)
+	}
+
+	return b.String()
+}
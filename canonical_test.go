@@ -0,0 +1,28 @@
+package errors
+
+import (
+	"io"
+	"testing"
+)
+
+func TestCanonicalString(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{io.EOF, `"EOF"`},
+		{Wrap(io.EOF, "read"), `"read: EOF"`},
+		{Wrapc(WithCode(901501, "declined"), 901502, "payment failed"), `901502|901501|"payment failed"`},
+		{
+			WithDetails(WithCode(901501, "declined"), QuotaFailure{Subject: "card:1", Limit: 10, Current: 11}),
+			`901501|"declined"|{"@type":"quota_failure","current":11,"limit":10,"subject":"card:1"}`,
+		},
+	}
+
+	for i, tt := range tests {
+		if got := CanonicalString(tt.err); got != tt.want {
+			t.Errorf("test %d: CanonicalString(%v): got %s, want %s", i+1, tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"net/http"
+	"time"
+)
+
+// The probes below read the optional metadata a Coder may expose through
+// extra methods, such as the coders built by NewCoder. They report false
+// when the Coder does not expose the metadata or leaves it unset, and then
+// return the documented default, so that formatters and transports agree on
+// the behavior of coders without metadata.
+
+// SeverityOf returns the severity of c. It defaults to SeverityError for
+// 5xx HTTP statuses and to SeverityInfo otherwise.
+func SeverityOf(c Coder) (Severity, bool) {
+	if v, ok := c.(interface{ Severity() Severity }); ok && v.Severity() != 0 {
+		return v.Severity(), true
+	}
+	if c != nil && c.HTTPStatus() >= http.StatusInternalServerError {
+		return SeverityError, false
+	}
+	return SeverityInfo, false
+}
+
+// DomainOf returns the domain of c. It defaults to "".
+func DomainOf(c Coder) (string, bool) {
+	if v, ok := c.(interface{ Domain() string }); ok && v.Domain() != "" {
+		return v.Domain(), true
+	}
+	return "", false
+}
+
+// RetryableOf reports whether the errors of c are retryable. A Coder with
+// a Retryable method, or else with a Temporary method, always reports true,
+// even when the method returns false. It defaults to true for the HTTP
+// statuses signalling a transient condition, 429, 502, 503 and 504, and to
+// false otherwise.
+func RetryableOf(c Coder) (retryable, ok bool) {
+	if v, ok := c.(interface{ Retryable() bool }); ok {
+		return v.Retryable(), true
+	}
+	if v, ok := c.(interface{ Temporary() bool }); ok {
+		return v.Temporary(), true
+	}
+	if c == nil {
+		return false, false
+	}
+	switch c.HTTPStatus() {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true, false
+	}
+	return false, false
+}
+
+// RetryAfterOf returns how long clients should wait before retrying the
+// errors of c. It defaults to 0.
+func RetryAfterOf(c Coder) (time.Duration, bool) {
+	if v, ok := c.(interface{ RetryAfter() time.Duration }); ok && v.RetryAfter() > 0 {
+		return v.RetryAfter(), true
+	}
+	return 0, false
+}
+
+// DedupWindowOf returns the window within which identical errors of c
+// reported to the same client are duplicates, see WithDedupWindow. It
+// defaults to 0: errors are never duplicates.
+func DedupWindowOf(c Coder) (time.Duration, bool) {
+	if v, ok := c.(interface{ DedupWindow() time.Duration }); ok && v.DedupWindow() > 0 {
+		return v.DedupWindow(), true
+	}
+	return 0, false
+}
+
+// OwnerOf returns the owner responsible for the code of c, see WithOwner.
+func OwnerOf(c Coder) (string, bool) {
+	if v, ok := c.(interface{ Owner() string }); ok && v.Owner() != "" {
+		return v.Owner(), true
+	}
+	return "", false
+}
+
+// SlugOf returns the string identifier of the code of c, see WithSlug.
+func SlugOf(c Coder) (string, bool) {
+	if v, ok := c.(interface{ Slug() string }); ok && v.Slug() != "" {
+		return v.Slug(), true
+	}
+	return "", false
+}
+
+// APIVersionsOf returns the API versions in which the code of c is
+// available, see WithAPIVersions. It defaults to 0, 0: every version.
+func APIVersionsOf(c Coder) (introduced, retired int, ok bool) {
+	if v, ok := c.(interface{ APIVersions() (int, int) }); ok {
+		introduced, retired = v.APIVersions()
+		return introduced, retired, introduced != 0 || retired != 0
+	}
+	return 0, 0, false
+}
+
+// FallbackOf returns the fallback code of c, see WithFallback. It defaults
+// to 0.
+func FallbackOf(c Coder) (int, bool) {
+	if v, ok := c.(interface{ Fallback() int }); ok && v.Fallback() != 0 {
+		return v.Fallback(), true
+	}
+	return 0, false
+}
+
+// RemediationOf returns the remediation steps of c. It defaults to nil.
+func RemediationOf(c Coder) ([]string, bool) {
+	if v, ok := c.(interface{ Remediation() []string }); ok {
+		if steps := v.Remediation(); len(steps) > 0 {
+			return steps, true
+		}
+	}
+	return nil, false
+}
+
+// ClassOf returns the class of the cause of the errors of c, see WithClass.
+// It defaults to "".
+func ClassOf(c Coder) (Class, bool) {
+	if v, ok := c.(interface{ Class() Class }); ok && v.Class() != "" {
+		return v.Class(), true
+	}
+	return "", false
+}
+
+// coderBlame returns the party blamed for the errors of c. It defaults to
+// "", BlameOf derives the blame of errors from their HTTP status instead.
+func coderBlame(c Coder) (Blame, bool) {
+	if v, ok := c.(interface{ Blame() Blame }); ok && v.Blame() != "" {
+		return v.Blame(), true
+	}
+	return "", false
+}
+
+// ConditionOf returns the failure condition c stands for. It defaults to
+// "".
+func ConditionOf(c Coder) (Condition, bool) {
+	if v, ok := c.(interface{ Condition() Condition }); ok && v.Condition() != "" {
+		return v.Condition(), true
+	}
+	return "", false
+}
+
+// MessagesOf returns the messages of c in other languages, by lowercase
+// language tag, see WithMessages. It defaults to nil.
+func MessagesOf(c Coder) (map[string]string, bool) {
+	if v, ok := c.(interface{ Messages() map[string]string }); ok {
+		if messages := v.Messages(); len(messages) > 0 {
+			return messages, true
+		}
+	}
+	return nil, false
+}
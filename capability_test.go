@@ -0,0 +1,72 @@
+package errors
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCapabilityProbes(t *testing.T) {
+	bare := defaultCoder{905001, 503, "unavailable", ""}
+	rich := NewCoder(905002, 400, "invalid", "",
+		WithSeverity(SeverityWarn), WithDomain("users"), WithRetryable(false),
+		WithRetryAfter(time.Second), WithAPIVersions(2, 0), WithFallback(905001),
+		WithRemediation("fix it"), WithCondition(ConditionFeatureDisabled))
+	unset := NewCoder(905003, 502, "bad gateway", "")
+
+	if s, ok := SeverityOf(bare); s != SeverityError || ok {
+		t.Errorf("SeverityOf(bare): got (%v, %v), want (error, false)", s, ok)
+	}
+	if s, ok := SeverityOf(rich); s != SeverityWarn || !ok {
+		t.Errorf("SeverityOf(rich): got (%v, %v), want (warn, true)", s, ok)
+	}
+	if s, ok := SeverityOf(unset); s != SeverityError || ok {
+		t.Errorf("SeverityOf(unset): got (%v, %v), want (error, false)", s, ok)
+	}
+
+	if r, ok := RetryableOf(bare); !r || ok {
+		t.Errorf("RetryableOf(bare): got (%v, %v), want (true, false)", r, ok)
+	}
+	if r, ok := RetryableOf(unset); r || !ok {
+		t.Errorf("RetryableOf(unset): got (%v, %v), want (false, true)", r, ok)
+	}
+
+	if d, ok := DomainOf(rich); d != "users" || !ok {
+		t.Errorf("DomainOf(rich): got (%q, %v)", d, ok)
+	}
+	if d, ok := RetryAfterOf(rich); d != time.Second || !ok {
+		t.Errorf("RetryAfterOf(rich): got (%v, %v)", d, ok)
+	}
+	if i, r, ok := APIVersionsOf(rich); i != 2 || r != 0 || !ok {
+		t.Errorf("APIVersionsOf(rich): got (%d, %d, %v)", i, r, ok)
+	}
+	if _, _, ok := APIVersionsOf(unset); ok {
+		t.Errorf("APIVersionsOf(unset): got true, want false")
+	}
+	if f, ok := FallbackOf(rich); f != 905001 || !ok {
+		t.Errorf("FallbackOf(rich): got (%d, %v)", f, ok)
+	}
+	if s, ok := RemediationOf(rich); len(s) != 1 || !ok {
+		t.Errorf("RemediationOf(rich): got (%v, %v)", s, ok)
+	}
+	if c, ok := ConditionOf(rich); c != ConditionFeatureDisabled || !ok {
+		t.Errorf("ConditionOf(rich): got (%q, %v)", c, ok)
+	}
+
+	for _, c := range []Coder{bare, unset} {
+		if _, ok := DomainOf(c); ok {
+			t.Errorf("DomainOf(%d): got true, want false", c.Code())
+		}
+		if _, ok := RetryAfterOf(c); ok {
+			t.Errorf("RetryAfterOf(%d): got true, want false", c.Code())
+		}
+		if _, ok := FallbackOf(c); ok {
+			t.Errorf("FallbackOf(%d): got true, want false", c.Code())
+		}
+		if _, ok := RemediationOf(c); ok {
+			t.Errorf("RemediationOf(%d): got true, want false", c.Code())
+		}
+		if _, ok := ConditionOf(c); ok {
+			t.Errorf("ConditionOf(%d): got true, want false", c.Code())
+		}
+	}
+}
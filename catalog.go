@@ -0,0 +1,280 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// CoderInfo is a read-only view of everything known about a code: the
+// Coder methods, the optional metadata a Coder may expose, and where it was
+// registered. It is also the form of the coders in exported catalogs.
+type CoderInfo struct {
+	Code        int       `json:"code"`
+	Slug        string    `json:"slug,omitempty"`
+	HTTPStatus  int       `json:"http_status"`
+	Message     string    `json:"message"`
+	Reference   string    `json:"reference,omitempty"`
+	Severity    Severity  `json:"severity,omitempty"`
+	Domain      string    `json:"domain,omitempty"`
+	Retryable   bool      `json:"retryable,omitempty"`
+	RetryAfter  string    `json:"retry_after,omitempty"`
+	DedupWindow string    `json:"dedup_window,omitempty"`
+	Owner       string    `json:"owner,omitempty"`
+	Introduced  int       `json:"introduced,omitempty"`
+	Retired     int       `json:"retired,omitempty"`
+	Fallback    int       `json:"fallback,omitempty"`
+	Remediation []string  `json:"remediation,omitempty"`
+	Condition   Condition `json:"condition,omitempty"`
+	Blame       Blame     `json:"blame,omitempty"`
+	Class       Class     `json:"class,omitempty"`
+	Category    Category  `json:"category,omitempty"`
+
+	// Deprecated reports whether the code is deprecated in favor of
+	// Replacement, see RegisterAlias.
+	Deprecated  bool `json:"deprecated,omitempty"`
+	Replacement int  `json:"replacement,omitempty"`
+
+	// Registered reports whether the code is registered.
+	Registered bool `json:"-"`
+
+	// Origin is the file:line of the call which registered the code.
+	Origin string `json:"origin,omitempty"`
+
+	// Translations are the translated messages by language, see
+	// WithMessages and RegisterTranslation.
+	Translations map[string]string `json:"translations,omitempty"`
+}
+
+// newCoderInfo returns the metadata exposed by coder.
+func newCoderInfo(coder Coder) CoderInfo {
+	e := CoderInfo{
+		Code:       coder.Code(),
+		HTTPStatus: coder.HTTPStatus(),
+		Message:    coder.String(),
+		Reference:  coder.Reference(),
+	}
+	if severity, ok := SeverityOf(coder); ok {
+		e.Severity = severity
+	}
+	e.Domain, _ = DomainOf(coder)
+	if retryable, ok := RetryableOf(coder); ok {
+		e.Retryable = retryable
+	}
+	if d, ok := RetryAfterOf(coder); ok {
+		e.RetryAfter = d.String()
+	}
+	if d, ok := DedupWindowOf(coder); ok {
+		e.DedupWindow = d.String()
+	}
+	e.Owner, _ = OwnerOf(coder)
+	e.Slug, _ = SlugOf(coder)
+	e.Introduced, e.Retired, _ = APIVersionsOf(coder)
+	e.Fallback, _ = FallbackOf(coder)
+	e.Remediation, _ = RemediationOf(coder)
+	e.Condition, _ = ConditionOf(coder)
+	e.Blame, _ = coderBlame(coder)
+	e.Class, _ = ClassOf(coder)
+	e.Category, _ = coderCategory(coder)
+	return e
+}
+
+// coder returns the Coder described by the info, with the extra options.
+func (e CoderInfo) coder(extra ...CoderOption) (Coder, error) {
+	opts := []CoderOption{
+		WithSeverity(e.Severity),
+		WithDomain(e.Domain),
+		WithRetryable(e.Retryable),
+		WithOwner(e.Owner),
+		WithSlug(e.Slug),
+		WithAPIVersions(e.Introduced, e.Retired),
+		WithFallback(e.Fallback),
+		WithRemediation(e.Remediation...),
+		WithCondition(e.Condition),
+		WithBlame(e.Blame),
+		WithClass(e.Class),
+		WithCategory(e.Category),
+	}
+	if e.RetryAfter != "" {
+		d, err := time.ParseDuration(e.RetryAfter)
+		if err != nil {
+			return nil, Wrapf(err, "code %d: invalid retry_after", e.Code)
+		}
+		opts = append(opts, WithRetryAfter(d))
+	}
+	if e.DedupWindow != "" {
+		d, err := time.ParseDuration(e.DedupWindow)
+		if err != nil {
+			return nil, Wrapf(err, "code %d: invalid dedup_window", e.Code)
+		}
+		opts = append(opts, WithDedupWindow(d))
+	}
+	opts = append(opts, extra...)
+	return NewCoder(e.Code, e.HTTPStatus, e.Message, e.Reference, opts...), nil
+}
+
+// registeredCoders returns the registered coders sorted by code, leaving
+// out the unknown coder.
+func registeredCoders() []Coder {
+	ensureCatalogs()
+	codeMux.RLock()
+	all := codes.list()
+	codeMux.RUnlock()
+
+	ret := make([]Coder, 0, len(all))
+	for _, coder := range all {
+		if coder.Code() != unknownCoder.Code() {
+			ret = append(ret, coder)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Code() < ret[j].Code() })
+	return ret
+}
+
+// ExportCatalog writes the registered coders to w as a JSON document, which
+// ImportCatalog reads back, e.g. to compare the catalogs of two releases
+// with DiffCatalogs.
+func ExportCatalog(w io.Writer) error {
+	coders := registeredCoders()
+	entries := make([]CoderInfo, 0, len(coders))
+	for _, coder := range coders {
+		info, _ := DescribeCode(coder.Code())
+		entries = append(entries, info)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+// ImportCatalog reads the coders of a catalog written by ExportCatalog.
+// The coders are not registered.
+func ImportCatalog(r io.Reader) ([]Coder, error) {
+	var entries []CoderInfo
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, Wrap(err, "decode catalog")
+	}
+
+	coders := make([]Coder, 0, len(entries))
+	for _, e := range entries {
+		coder, err := e.coder()
+		if err != nil {
+			return nil, err
+		}
+		coders = append(coders, coder)
+	}
+	return coders, nil
+}
+
+// CatalogDiff lists the differences between two catalogs, sorted by code.
+type CatalogDiff struct {
+	// Added are the coders of the new catalog missing from the old one.
+	Added []Coder
+
+	// Removed are the coders of the old catalog missing from the new one.
+	Removed []Coder
+
+	// Changed are the coders present in both catalogs with different
+	// metadata.
+	Changed []CoderChange
+}
+
+// CoderChange is a coder whose metadata differs between two catalogs.
+type CoderChange struct {
+	Old, New Coder
+}
+
+// Empty reports whether the catalogs are identical.
+func (d CatalogDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffCatalogs compares the old catalog a with the new catalog b.
+// Coders are matched by code and compared on all the metadata they expose,
+// see CoderInfo, except where they were registered.
+func DiffCatalogs(a, b []Coder) CatalogDiff {
+	byCode := func(coders []Coder) map[int]Coder {
+		m := make(map[int]Coder, len(coders))
+		for _, coder := range coders {
+			m[coder.Code()] = coder
+		}
+		return m
+	}
+	old, cur := byCode(a), byCode(b)
+
+	var diff CatalogDiff
+	for code, n := range cur {
+		o, ok := old[code]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, n)
+		case !reflect.DeepEqual(newCoderInfo(o), newCoderInfo(n)):
+			diff.Changed = append(diff.Changed, CoderChange{Old: o, New: n})
+		}
+	}
+	for code, o := range old {
+		if _, ok := cur[code]; !ok {
+			diff.Removed = append(diff.Removed, o)
+		}
+	}
+
+	sortCoders := func(coders []Coder) {
+		sort.Slice(coders, func(i, j int) bool { return coders[i].Code() < coders[j].Code() })
+	}
+	sortCoders(diff.Added)
+	sortCoders(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].New.Code() < diff.Changed[j].New.Code() })
+	return diff
+}
+
+// DescribeCode returns everything known about the given code, so that
+// tools do not need to probe the optional interfaces of its Coder.
+// A deprecated code is described by the Coder of its replacement, like
+// GetCoder resolves it, see RegisterAlias.
+// It returns false if the code is not registered.
+func DescribeCode(code int) (CoderInfo, bool) {
+	ensureCatalogs()
+	coder, ok := lookupCoder(code)
+	if !ok {
+		return CoderInfo{Code: code}, false
+	}
+	codeMux.RLock()
+	origin := origins[coder.Code()]
+	codeMux.RUnlock()
+
+	info := newCoderInfo(coder)
+	info.Code = code
+	info.Replacement, info.Deprecated = Alias(code)
+	info.Registered = true
+	info.Translations = translationsOf(coder.Code())
+	if messages, ok := MessagesOf(coder); ok {
+		if info.Translations == nil {
+			info.Translations = map[string]string{}
+		}
+		for lang, msg := range messages {
+			info.Translations[lang] = msg
+		}
+	}
+	if origin != 0 {
+		f := Frame(origin)
+		info.Origin = fmt.Sprintf("%s:%d", f.file(), f.line())
+	}
+	return info, true
+}
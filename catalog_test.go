@@ -0,0 +1,99 @@
+package errors
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCatalogExportImport(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	Register(NewCoder(902601, 404, "not found", "https://example.com/902601"))
+	Register(NewCoder(902602, 503, "unavailable", "",
+		WithSeverity(SeverityError), WithRetryable(true), WithRetryAfter(3*time.Second), WithRemediation("wait"), WithOwner("payments@example.com")))
+
+	var buf bytes.Buffer
+	if err := ExportCatalog(&buf); err != nil {
+		t.Fatal(err)
+	}
+	coders, err := ImportCatalog(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := DiffCatalogs(registeredCoders(), coders); !diff.Empty() {
+		t.Errorf("DiffCatalogs(registered, imported): got %+v, want no difference", diff)
+	}
+
+	if _, err := ImportCatalog(strings.NewReader(`[{"code": 1, "retry_after": "soon"}]`)); err == nil {
+		t.Errorf("ImportCatalog(invalid retry_after): got nil error")
+	}
+}
+
+func TestDiffCatalogs(t *testing.T) {
+	a := []Coder{
+		NewCoder(902611, 400, "kept", ""),
+		NewCoder(902612, 400, "removed", ""),
+		NewCoder(902613, 400, "changed", ""),
+	}
+	b := []Coder{
+		NewCoder(902611, 400, "kept", ""),
+		NewCoder(902613, 409, "changed", ""),
+		NewCoder(902614, 400, "added", ""),
+	}
+
+	diff := DiffCatalogs(a, b)
+	codes := func(coders []Coder) []int {
+		var ret []int
+		for _, c := range coders {
+			ret = append(ret, c.Code())
+		}
+		return ret
+	}
+	if got := codes(diff.Added); !reflect.DeepEqual(got, []int{902614}) {
+		t.Errorf("Added: got %v", got)
+	}
+	if got := codes(diff.Removed); !reflect.DeepEqual(got, []int{902612}) {
+		t.Errorf("Removed: got %v", got)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Old.HTTPStatus() != 400 || diff.Changed[0].New.HTTPStatus() != 409 {
+		t.Errorf("Changed: got %+v", diff.Changed)
+	}
+}
+
+func TestDescribeCode(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(902621, 503, "unavailable", "https://example.com/902621",
+		WithDomain("billing"), WithRetryable(true), WithRemediation("retry later")))
+
+	info, ok := DescribeCode(902621)
+	if !ok || !info.Registered {
+		t.Fatalf("DescribeCode(902621): got (%+v, %v), want a registered code", info, ok)
+	}
+	want := CoderInfo{
+		Code:        902621,
+		HTTPStatus:  503,
+		Message:     "unavailable",
+		Reference:   "https://example.com/902621",
+		Domain:      "billing",
+		Retryable:   true,
+		Remediation: []string{"retry later"},
+		Registered:  true,
+		Origin:      info.Origin,
+	}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("DescribeCode(902621): got %+v, want %+v", info, want)
+	}
+	if !strings.Contains(info.Origin, "catalog_test.go:") {
+		t.Errorf("DescribeCode(902621).Origin: got %q, want the MustRegister call site", info.Origin)
+	}
+
+	if _, ok := DescribeCode(902622); ok {
+		t.Errorf("DescribeCode(unregistered): got true, want false")
+	}
+}
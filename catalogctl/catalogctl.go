@@ -0,0 +1,213 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package catalogctl provides commands managing the error catalog of a
+// service, to embed in an existing admin CLI. The commands work on the
+// coders registered in the running binary or on a catalog file written by
+// errors.ExportCatalog.
+//
+// The commands do not depend on a CLI framework. With cobra, each of them
+// maps to a command as follows:
+//
+//	for _, c := range catalogctl.Commands() {
+//		c := c
+//		root.AddCommand(&cobra.Command{
+//			Use:   c.Usage,
+//			Short: c.Short,
+//			RunE: func(cmd *cobra.Command, args []string) error {
+//				return c.Run(cmd.OutOrStdout(), args)
+//			},
+//		})
+//	}
+package catalogctl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"text/tabwriter"
+
+	"github.com/rtmzk/errors"
+)
+
+// Registered is the catalog source naming the coders registered in the
+// running binary. Any other source is the path of a catalog file, or "-"
+// for the standard input.
+const Registered = "registered"
+
+// Command is a catalog management command.
+type Command struct {
+	// Name is the name of the command and Usage its usage line.
+	Name  string
+	Usage string
+
+	// Short is a one line description of the command.
+	Short string
+
+	// Run runs the command with the given arguments, writing its output
+	// to w.
+	Run func(w io.Writer, args []string) error
+}
+
+// Commands returns the list, validate, export and diff commands.
+func Commands() []Command {
+	return []Command{
+		{
+			Name:  "list",
+			Usage: "list [source]",
+			Short: "List the codes of a catalog",
+			Run:   runList,
+		},
+		{
+			Name:  "validate",
+			Usage: "validate [source]",
+			Short: "Check a catalog for invalid statuses, messages and fallbacks",
+			Run:   runValidate,
+		},
+		{
+			Name:  "export",
+			Usage: "export",
+			Short: "Export the registered codes as a catalog file",
+			Run:   runExport,
+		},
+		{
+			Name:  "diff",
+			Usage: "diff old [new]",
+			Short: "Compare two catalogs",
+			Run:   runDiff,
+		},
+	}
+}
+
+// Load returns the coders of the given catalog source, see Registered.
+func Load(source string) ([]errors.Coder, error) {
+	switch source {
+	case "", Registered:
+		var buf bytes.Buffer
+		if err := errors.ExportCatalog(&buf); err != nil {
+			return nil, err
+		}
+		return errors.ImportCatalog(&buf)
+	case "-":
+		return errors.ImportCatalog(os.Stdin)
+	}
+
+	f, err := os.Open(source)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return errors.ImportCatalog(f)
+}
+
+// Validate returns the problems of a catalog: reserved codes, duplicated
+// codes, invalid HTTP statuses, empty messages and fallbacks to codes
+// missing from the catalog.
+func Validate(coders []errors.Coder) []string {
+	var problems []string
+	seen := make(map[int]bool, len(coders))
+	for _, c := range coders {
+		if seen[c.Code()] {
+			problems = append(problems, fmt.Sprintf("code %d: duplicated", c.Code()))
+		}
+		seen[c.Code()] = true
+	}
+	for _, c := range coders {
+		code := c.Code()
+		if code == 0 {
+			problems = append(problems, "code 0: reserved")
+		}
+		if http.StatusText(c.HTTPStatus()) == "" {
+			problems = append(problems, fmt.Sprintf("code %d: invalid HTTP status %d", code, c.HTTPStatus()))
+		}
+		if c.String() == "" {
+			problems = append(problems, fmt.Sprintf("code %d: empty message", code))
+		}
+		if fallback, ok := errors.FallbackOf(c); ok && !seen[fallback] {
+			problems = append(problems, fmt.Sprintf("code %d: fallback %d is not in the catalog", code, fallback))
+		}
+	}
+	return problems
+}
+
+func source(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+	return Registered
+}
+
+func runList(w io.Writer, args []string) error {
+	coders, err := Load(source(args, 0))
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CODE\tHTTP\tMESSAGE")
+	for _, c := range coders {
+		fmt.Fprintf(tw, "%d\t%d\t%s\n", c.Code(), c.HTTPStatus(), c.String())
+	}
+	return tw.Flush()
+}
+
+func runValidate(w io.Writer, args []string) error {
+	coders, err := Load(source(args, 0))
+	if err != nil {
+		return err
+	}
+	problems := Validate(coders)
+	for _, p := range problems {
+		fmt.Fprintln(w, p)
+	}
+	if len(problems) > 0 {
+		return errors.Errorf("%d problems found", len(problems))
+	}
+	fmt.Fprintf(w, "%d codes, no problem found\n", len(coders))
+	return nil
+}
+
+func runExport(w io.Writer, _ []string) error {
+	return errors.ExportCatalog(w)
+}
+
+func runDiff(w io.Writer, args []string) error {
+	if len(args) == 0 {
+		return errors.New("diff: missing the old catalog")
+	}
+	old, err := Load(args[0])
+	if err != nil {
+		return err
+	}
+	cur, err := Load(source(args, 1))
+	if err != nil {
+		return err
+	}
+
+	diff := errors.DiffCatalogs(old, cur)
+	for _, c := range diff.Added {
+		fmt.Fprintf(w, "+ %d %d %s\n", c.Code(), c.HTTPStatus(), c.String())
+	}
+	for _, c := range diff.Removed {
+		fmt.Fprintf(w, "- %d %d %s\n", c.Code(), c.HTTPStatus(), c.String())
+	}
+	for _, c := range diff.Changed {
+		fmt.Fprintf(w, "~ %d %d %s -> %d %s\n", c.New.Code(), c.Old.HTTPStatus(), c.Old.String(), c.New.HTTPStatus(), c.New.String())
+	}
+	if diff.Empty() {
+		fmt.Fprintln(w, "no difference")
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package catalogctl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rtmzk/errors"
+)
+
+func init() {
+	errors.Register(errors.NewCoder(905101, 404, "not found", ""))
+	errors.Register(errors.NewCoder(905102, 409, "conflict", "", errors.WithFallback(905101)))
+}
+
+func run(t *testing.T, name string, args ...string) (string, error) {
+	t.Helper()
+	for _, c := range Commands() {
+		if c.Name == name {
+			var buf bytes.Buffer
+			err := c.Run(&buf, args)
+			return buf.String(), err
+		}
+	}
+	t.Fatalf("no command %q", name)
+	return "", nil
+}
+
+func TestCommands(t *testing.T) {
+	out, err := run(t, "list")
+	if err != nil || !strings.Contains(out, "905101") || !strings.Contains(out, "conflict") {
+		t.Errorf("list: got (%q, %v), want the registered codes", out, err)
+	}
+
+	if out, err := run(t, "validate"); err != nil {
+		t.Errorf("validate: got (%q, %v), want no problem", out, err)
+	}
+
+	out, err = run(t, "export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := filepath.Join(t.TempDir(), "old.json")
+	if err := os.WriteFile(old, []byte(out), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if out, err := run(t, "diff", old); err != nil || out != "no difference\n" {
+		t.Errorf("diff: got (%q, %v), want no difference", out, err)
+	}
+
+	errors.Register(errors.NewCoder(905103, 0, "", ""))
+	out, err = run(t, "diff", old, Registered)
+	if err != nil || !strings.Contains(out, "+ 905103 500") {
+		t.Errorf("diff: got (%q, %v), want 905103 added", out, err)
+	}
+
+	out, err = run(t, "validate")
+	if err == nil || !strings.Contains(out, "code 905103: empty message") {
+		t.Errorf("validate: got (%q, %v), want the empty message reported", out, err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	coders := []errors.Coder{
+		errors.NewCoder(905111, 999, "bad status", ""),
+		errors.NewCoder(905112, 400, "dangling", "", errors.WithFallback(905119)),
+		errors.NewCoder(905112, 400, "duplicated", ""),
+	}
+	want := []string{
+		"code 905112: duplicated",
+		"code 905111: invalid HTTP status 999",
+		"code 905112: fallback 905119 is not in the catalog",
+	}
+	got := Validate(coders)
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Validate(): got %q, want %q", got, want)
+	}
+}
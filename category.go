@@ -0,0 +1,86 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import "context"
+
+// Category tells who an error is attributed to by availability metrics:
+// the errors of the server and of its dependencies count against the SLOs
+// of a service, those of its clients and the canceled requests do not.
+type Category string
+
+// Error categories.
+const (
+	// CategoryClient marks errors caused by a client, such as invalid
+	// requests.
+	CategoryClient Category = "client"
+
+	// CategoryServer marks errors caused by the service itself.
+	CategoryServer Category = "server"
+
+	// CategoryDependency marks errors caused by a dependency of the
+	// service, such as a database or a third-party API.
+	CategoryDependency Category = "dependency"
+
+	// CategoryCanceled marks requests canceled by the client before they
+	// completed.
+	CategoryCanceled Category = "canceled"
+)
+
+// AffectsAvailability reports whether the errors of the category count
+// against the availability of the service, i.e. whether it is
+// CategoryServer or CategoryDependency.
+func (c Category) AffectsAvailability() bool {
+	return c == CategoryServer || c == CategoryDependency
+}
+
+// WithCategory sets the category of the coder's errors.
+func WithCategory(category Category) CoderOption {
+	return func(c *coder) { c.category = category }
+}
+
+// CategoryOf returns the category of err: the category of its Coder, see
+// WithCategory. Otherwise canceled contexts, client aborts, see
+// IsClientAbort, and errors served with StatusClientClosedRequest are
+// categorized as CategoryCanceled, and the other errors
+// after their blame, see BlameOf: the user blames the client, the upstream
+// a dependency and the system the server. It returns "" if err is nil.
+func CategoryOf(err error) Category {
+	if err == nil {
+		return ""
+	}
+	if category, ok := coderCategory(ParseCoder(err)); ok {
+		return category
+	}
+	if Is(err, context.Canceled) || IsClientAbort(err) || ParseCoder(err).HTTPStatus() == StatusClientClosedRequest {
+		return CategoryCanceled
+	}
+	switch BlameOf(err) {
+	case BlameUser:
+		return CategoryClient
+	case BlameUpstream:
+		return CategoryDependency
+	}
+	return CategoryServer
+}
+
+// coderCategory returns the category of the errors of c. It defaults to
+// "", CategoryOf derives the category of errors from their chain instead.
+func coderCategory(c Coder) (Category, bool) {
+	if v, ok := c.(interface{ Category() Category }); ok && v.Category() != "" {
+		return v.Category(), true
+	}
+	return "", false
+}
@@ -0,0 +1,79 @@
+package errors
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"syscall"
+	"testing"
+)
+
+func TestCategoryOf(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(912301, http.StatusBadRequest, "Bad request", ""))
+	MustRegister(NewCoder(912302, http.StatusInternalServerError, "Internal", ""))
+	MustRegister(NewCoder(912303, http.StatusGatewayTimeout, "Upstream timeout", ""))
+	MustRegister(NewCoder(912304, http.StatusServiceUnavailable, "Database down", "", WithCategory(CategoryDependency)))
+	MustRegister(NewCoder(912305, http.StatusInternalServerError, "Quota", "", WithBlame(BlameUser)))
+
+	tests := []struct {
+		err  error
+		want Category
+	}{
+		{nil, ""},
+		{WithCode(912301, "bad"), CategoryClient},
+		{WithCode(912302, "bug"), CategoryServer},
+		{WithCode(912303, "slow"), CategoryDependency},
+		{WithCode(912304, "down"), CategoryDependency},
+		{WithCode(912305, "quota"), CategoryClient},
+		{Blamed(WithCode(912302, "bug"), BlameUpstream), CategoryDependency},
+		{Wrapc(context.Canceled, 912302, "canceled"), CategoryCanceled},
+		{Wrap(syscall.EPIPE, "write"), CategoryCanceled},
+		{io.EOF, CategoryServer},
+	}
+
+	for _, tt := range tests {
+		if got := CategoryOf(tt.err); got != tt.want {
+			t.Errorf("CategoryOf(%v): got %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestCategoryAvailability(t *testing.T) {
+	tests := []struct {
+		category Category
+		want     bool
+	}{
+		{CategoryClient, false},
+		{CategoryServer, true},
+		{CategoryDependency, true},
+		{CategoryCanceled, false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.category.AffectsAvailability(); got != tt.want {
+			t.Errorf("%q.AffectsAvailability(): got %v, want %v", tt.category, got, tt.want)
+		}
+	}
+}
+
+func TestCategoryCatalog(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(912310, http.StatusServiceUnavailable, "Down", "", WithCategory(CategoryDependency)))
+	info, _ := DescribeCode(912310)
+	if info.Category != CategoryDependency {
+		t.Errorf("DescribeCode(): got category %q, want %q", info.Category, CategoryDependency)
+	}
+	coder, err := info.coder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := coderCategory(coder); got != CategoryDependency {
+		t.Errorf("round trip: got category %q, want %q", got, CategoryDependency)
+	}
+}
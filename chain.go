@@ -0,0 +1,40 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+// Chain returns every layer of err, from err itself to its root cause, see
+// Cause. It suits debug tooling displaying each wrapping layer with its own
+// message and code, rather than the top-most one only.
+// Chain returns nil if err is nil.
+func Chain(err error) []error {
+	if err == nil {
+		return nil
+	}
+	return list(err)
+}
+
+// AllCodes returns the codes of every coded layer of err, outermost first,
+// including those of the members of the multi-errors of its chain. A code
+// is listed once per layer carrying it.
+func AllCodes(err error) []int {
+	var codes []int
+	walk(err, func(e error) bool {
+		if c, ok := e.(CodeError); ok {
+			codes = append(codes, c.Code())
+		}
+		return false
+	})
+	return codes
+}
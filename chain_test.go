@@ -0,0 +1,57 @@
+package errors
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+func init() {
+	Register(NewCoder(909101, 500, "storage failed", ""))
+	Register(NewCoder(909102, 503, "service unavailable", ""))
+}
+
+func TestChain(t *testing.T) {
+	root := io.EOF
+	wrapped := Wrap(root, "read")
+	coded := Wrapc(wrapped, 909101, "load")
+
+	tests := []struct {
+		err  error
+		want []error
+	}{
+		{nil, nil},
+		{root, []error{root}},
+		{coded, []error{coded, wrapped, wrapped.(*withStack).error, root}},
+	}
+
+	for _, tt := range tests {
+		got := Chain(tt.err)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Chain(%v): got %v, want %v", tt.err, got, tt.want)
+		}
+		if len(got) > 0 && got[len(got)-1] != Cause(tt.err) {
+			t.Errorf("Chain(%v): got root %v, want Cause %v", tt.err, got[len(got)-1], Cause(tt.err))
+		}
+	}
+}
+
+func TestAllCodes(t *testing.T) {
+	inner := WithCode(909101, "disk full")
+	tests := []struct {
+		err  error
+		want []int
+	}{
+		{nil, nil},
+		{io.EOF, nil},
+		{inner, []int{909101}},
+		{Wrapc(Wrap(inner, "save"), 909102, "serve"), []int{909102, 909101}},
+		{Join(inner, io.EOF, WithCode(909102, "down")), []int{909101, 909102}},
+	}
+
+	for _, tt := range tests {
+		if got := AllCodes(tt.err); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("AllCodes(%v): got %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
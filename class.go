@@ -0,0 +1,85 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+// Class is the class of the cause of an error, a low-cardinality dimension
+// independent of the codes of the business, e.g. for SRE dashboards.
+// The set of classes is fixed.
+type Class string
+
+// Cause classes.
+const (
+	ClassTimeout        Class = "timeout"
+	ClassConnectivity   Class = "connectivity"
+	ClassValidation     Class = "validation"
+	ClassAuthz          Class = "authz"
+	ClassConflict       Class = "conflict"
+	ClassCapacity       Class = "capacity"
+	ClassDataCorruption Class = "data_corruption"
+	ClassBug            Class = "bug"
+)
+
+// WithClass sets the class of the cause of the coder's errors.
+func WithClass(class Class) CoderOption {
+	return func(c *coder) { c.class = class }
+}
+
+// CauseClass returns the class of the cause of err: the class of its Coder,
+// see WithClass. Otherwise the class is derived from the chain, timeouts
+// and network failures being classified as such, and then from the HTTP
+// status of the Coder. It returns "" if err is nil or if no class applies.
+func CauseClass(err error) Class {
+	if err == nil {
+		return ""
+	}
+	coder := ParseCoder(err)
+	if class, ok := ClassOf(coder); ok {
+		return class
+	}
+
+	var netErr net.Error
+	switch {
+	case Is(err, context.DeadlineExceeded):
+		return ClassTimeout
+	case As(err, &netErr) && netErr.Timeout():
+		return ClassTimeout
+	case netErr != nil:
+		return ClassConnectivity
+	}
+
+	switch coder.HTTPStatus() {
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return ClassTimeout
+	case http.StatusBadGateway:
+		return ClassConnectivity
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return ClassValidation
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ClassAuthz
+	case http.StatusConflict, http.StatusPreconditionFailed:
+		return ClassConflict
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return ClassCapacity
+	case http.StatusInternalServerError:
+		return ClassBug
+	}
+	return ""
+}
@@ -0,0 +1,42 @@
+package errors
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestCauseClass(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(906901, 400, "invalid", ""))
+	MustRegister(NewCoder(906902, 500, "checksum mismatch", "", WithClass(ClassDataCorruption)))
+	MustRegister(NewCoder(906903, 503, "overloaded", ""))
+	MustRegister(NewCoder(906904, 404, "not found", ""))
+
+	tests := []struct {
+		err  error
+		want Class
+	}{
+		{nil, ""},
+		{WithCode(906901, "bad name"), ClassValidation},
+		{Wrap(WithCode(906902, "bad block"), "read"), ClassDataCorruption},
+		{WithCode(906903, "queue full"), ClassCapacity},
+		{WithCode(906904, "no user"), ""},
+		{Wrap(context.DeadlineExceeded, "query"), ClassTimeout},
+		{Wrapc(&net.OpError{Op: "dial", Err: io.EOF}, 906903, "dial"), ClassConnectivity},
+		{io.EOF, ClassBug},
+	}
+
+	for i, tt := range tests {
+		if got := CauseClass(tt.err); got != tt.want {
+			t.Errorf("test %d: got %q, want %q", i+1, got, tt.want)
+		}
+	}
+
+	if info, _ := DescribeCode(906902); info.Class != ClassDataCorruption {
+		t.Errorf("DescribeCode(906902).Class: got %q, want %q", info.Class, ClassDataCorruption)
+	}
+}
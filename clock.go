@@ -0,0 +1,115 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"crypto/rand"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Clock provides the current time to the features of this package which
+// timestamp errors. A fixed Clock can be installed with SetClock to make
+// tests deterministic.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to the Clock interface.
+type ClockFunc func() time.Time
+
+// Now implements Clock.
+func (f ClockFunc) Now() time.Time { return f() }
+
+// clock holds the clockValue of the Clock used by the package. It is
+// replaced atomically, so that SetClock can be called while errors are
+// created.
+var clock atomic.Value
+
+// clockValue wraps a Clock, since atomic.Value requires the values it
+// stores to have the same concrete type.
+type clockValue struct{ Clock }
+
+func init() { clock.Store(clockValue{ClockFunc(time.Now)}) }
+
+// SetClock replaces the Clock used by the package.
+// A nil Clock restores the system clock.
+func SetClock(c Clock) {
+	if c == nil {
+		c = ClockFunc(time.Now)
+	}
+	clock.Store(clockValue{c})
+}
+
+func clockNow() Clock { return clock.Load().(clockValue).Clock }
+
+// now returns the current time of the package Clock.
+func now() time.Time { return clockNow().Now() }
+
+// Now returns the current time of the Clock set with SetClock, for the
+// integrations timestamping errors, so that a fixed Clock makes them
+// deterministic too.
+func Now() time.Time { return now() }
+
+// entropy is the source of randomness used by the package, read with
+// entropyMu held since seeded readers are seldom safe for concurrent use.
+var (
+	entropy   io.Reader = rand.Reader
+	entropyMu sync.Mutex
+)
+
+// SetEntropy replaces the source of randomness used by the package, which
+// draws the occurrence IDs of the errors from it, see ErrorID. A seeded
+// reader makes them reproducible in tests. A nil reader restores
+// crypto/rand.
+func SetEntropy(r io.Reader) {
+	if r == nil {
+		r = rand.Reader
+	}
+	entropyMu.Lock()
+	entropy = r
+	entropyMu.Unlock()
+}
+
+func entropyNow() io.Reader {
+	entropyMu.Lock()
+	defer entropyMu.Unlock()
+	return entropy
+}
+
+// Entropy returns a reader of the source of randomness set with
+// SetEntropy, for the integrations drawing random numbers, so that a seeded
+// reader makes them reproducible too. The reader is safe for concurrent use
+// and reads the source current at the time of each Read.
+func Entropy() io.Reader { return lockedEntropy{} }
+
+// lockedEntropy reads the entropy source with entropyMu held.
+type lockedEntropy struct{}
+
+func (lockedEntropy) Read(b []byte) (int, error) {
+	entropyMu.Lock()
+	defer entropyMu.Unlock()
+	return entropy.Read(b)
+}
+
+// readEntropy fills b from the entropy source, reporting whether it could.
+func readEntropy(b []byte) bool {
+	entropyMu.Lock()
+	defer entropyMu.Unlock()
+	_, err := io.ReadFull(entropy, b)
+	return err == nil
+}
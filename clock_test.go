@@ -0,0 +1,76 @@
+package errors
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetClock(t *testing.T) {
+	restore := NewConfig().Apply()
+	defer restore()
+
+	fixed := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	SetClock(ClockFunc(func() time.Time { return fixed }))
+	if got := now(); !got.Equal(fixed) {
+		t.Errorf("now(): got %v, want %v", got, fixed)
+	}
+
+	var buf bytes.Buffer
+	WriteNDJSON(&buf, New("boom"))
+	if want := `"time":"2024-05-01T12:00:00Z"`; !strings.Contains(buf.String(), want) {
+		t.Errorf("WriteNDJSON: got %s, want it to contain %s", buf.String(), want)
+	}
+
+	if got := Now(); !got.Equal(fixed) {
+		t.Errorf("Now(): got %v, want %v", got, fixed)
+	}
+
+	SetClock(nil)
+	if got := now(); got.Equal(fixed) {
+		t.Errorf("SetClock(nil): got the fixed clock, want the system clock")
+	}
+}
+
+func TestSetEntropy(t *testing.T) {
+	restore := NewConfig(UseEntropy(strings.NewReader("seed"))).Apply()
+	fixed := entropy
+	restore()
+
+	if fixed == entropy {
+		t.Errorf("restore: got the test entropy, want the previous one")
+	}
+	SetEntropy(strings.NewReader("seed"))
+	var b [4]byte
+	if _, err := io.ReadFull(Entropy(), b[:]); err != nil || string(b[:]) != "seed" {
+		t.Errorf("Entropy(): read %q, %v, want the seed", b[:], err)
+	}
+
+	SetEntropy(nil)
+	if entropy != rand.Reader {
+		t.Errorf("SetEntropy(nil): got %v, want crypto/rand.Reader", entropy)
+	}
+}
+
+func TestErrorID(t *testing.T) {
+	restore := NewConfig(UseEntropy(strings.NewReader("\x01\x02\x03\x04\x05\x06\x07\x08"))).Apply()
+	defer restore()
+
+	coded := WithCode(902201, "invalid")
+	err := Wrap(coded, "handle")
+	if got := ErrorID(err); got != "0102030405060708" {
+		t.Errorf("ErrorID(): got %q, want the ID drawn from the entropy", got)
+	}
+	if got := ErrorID(err); got != "0102030405060708" {
+		t.Errorf("ErrorID(): got %q on the second call, want the same ID", got)
+	}
+	if got := ErrorID(Renew(coded)); got == "" || got == "0102030405060708" {
+		t.Errorf("ErrorID(Renew()): got %q, want a new ID", got)
+	}
+	if got := ErrorID(New("plain")); got != "" {
+		t.Errorf("ErrorID(uncoded): got %q, want none", got)
+	}
+}
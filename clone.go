@@ -0,0 +1,57 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+// Clone returns a copy of err whose layers created by this package, down
+// to the first layer of another type, hold their own fields, details and
+// template parameters. The stacks, which are never modified, are shared.
+//
+// The errors of this package are immutable: the functions deriving an
+// error from another, such as WithFields, WithDetails, WithHelp and
+// WithMessage, return a new value and never modify the error given, so
+// that sentinel coded errors can be cached and annotated per request
+// concurrently. Clone is meant for code handing an error to a consumer
+// which may not be trusted with a shared value.
+// The clone is a distinct error: it matches err with IsCode, not with the
+// standard errors.Is. If err is nil, Clone returns nil.
+func Clone(err error) error {
+	switch e := err.(type) {
+	case *fundamental:
+		cp := *e
+		return &cp
+	case *withCode:
+		cp := *e
+		cp.cause = Clone(e.cause)
+		cp.fields = mergeFields(nil, e.fields)
+		cp.details = append([]Detail(nil), e.details...)
+		if e.params != nil {
+			cp.params = Params(mergeFields(map[string]interface{}{}, e.params))
+		}
+		return &cp
+	case *withFields:
+		return &withFields{error: Clone(e.error), fields: mergeFields(nil, e.fields)}
+	case *withDetails:
+		return &withDetails{error: Clone(e.error), details: append([]Detail(nil), e.details...)}
+	case *withMessage:
+		cp := *e
+		cp.cause = Clone(e.cause)
+		return &cp
+	case *withStack:
+		return &withStack{Clone(e.error), e.stack, e.pc}
+	case *withPublicMessage:
+		return &withPublicMessage{error: Clone(e.error), msg: e.msg}
+	}
+	return err
+}
@@ -0,0 +1,78 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func init() {
+	Register(NewCoder(913701, http.StatusNotFound, "user not found", ""))
+}
+
+func TestClone(t *testing.T) {
+	sentinel := WithDetails(WithFields(WithCode(913701, "no user"), "table", "users"), BlobRef{Ref: "s3://x"})
+	tests := []error{
+		sentinel,
+		Wrap(WithFields(io.EOF, "path", "/tmp"), "read"),
+		WithMessage(New("boom"), "ctx"),
+		io.EOF,
+	}
+
+	for i, err := range tests {
+		cp := Clone(err)
+		if got, want := fmt.Sprintf("%+v", cp), fmt.Sprintf("%+v", err); got != want {
+			t.Errorf("test %d: %%+v: got %q, want %q", i+1, got, want)
+		}
+		if !reflect.DeepEqual(Fields(cp), Fields(err)) || !reflect.DeepEqual(Details(cp), Details(err)) {
+			t.Errorf("test %d: got fields %v and details %v, want %v and %v", i+1, Fields(cp), Details(cp), Fields(err), Details(err))
+		}
+	}
+
+	cp := Clone(sentinel).(*withCode)
+	orig := sentinel.(*withCode)
+	if cp == orig || reflect.ValueOf(cp.fields).Pointer() == reflect.ValueOf(orig.fields).Pointer() {
+		t.Errorf("Clone(): got shared fields, want a copy")
+	}
+	if !IsCode(cp, 913701) {
+		t.Errorf("IsCode(Clone()): got false, want true")
+	}
+	if Clone(nil) != nil {
+		t.Errorf("Clone(nil): got non-nil, want nil")
+	}
+}
+
+// TestSharedSentinel annotates a shared coded error concurrently, which is
+// race free since the errors are never modified; run with -race.
+func TestSharedSentinel(t *testing.T) {
+	sentinel := WithFields(WithCode(913701, "no user"), "table", "users")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				err := WithFields(sentinel, "request", i)
+				err = WithDetails(err, BlobRef{Ref: "s3://x"})
+				err = WithMessage(Clone(err), "handle")
+				_ = fmt.Sprintf("%+v %#-v", err, sentinel)
+				if got := Fields(err)["request"]; got != i {
+					t.Errorf("goroutine %d: request field: got %v, want %d", i, got, i)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got, want := Fields(sentinel), map[string]interface{}{"table": "users"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sentinel fields: got %v, want %v", got, want)
+	}
+	if len(Details(sentinel)) != 0 {
+		t.Errorf("sentinel details: got %v, want none", Details(sentinel))
+	}
+}
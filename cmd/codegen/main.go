@@ -0,0 +1,301 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command codegen generates the registration of the error codes of a
+// package from annotated constants, together with a markdown reference
+// table, so that the codes, their registration and their documentation
+// are maintained in a single place.
+//
+// Each constant annotated with a comment of the form
+//
+//	// <code>: <message>, <http status>[, <reference>]
+//
+// is registered, e.g.
+//
+//	const (
+//		// 100001: User not found, 404, https://example.com/docs/100001
+//		ErrUserNotFound = iota + 100001
+//
+//		// 100002: User already exists, 409
+//		ErrUserExists
+//	)
+//
+// The code of the comment must match the value of the constant. The
+// generated file registers the coders with errors.MustRegisterTable when
+// the package is initialized. Usage with go generate:
+//
+//	//go:generate go run github.com/rtmzk/errors/cmd/codegen -doc ../docs/codes.md
+//
+// Flags:
+//
+//	-dir     directory of the package, "." by default
+//	-output  generated Go file, "zz_generated.codes.go" in the directory by default
+//	-doc     generated markdown table, none by default
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultOutput is the name of the generated Go file.
+const defaultOutput = "zz_generated.codes.go"
+
+// generatedHeader marks the generated files, which are skipped when parsing.
+const generatedHeader = "// Code generated by codegen; DO NOT EDIT."
+
+// annotation matches the comments of the annotated constants. The message
+// may hold commas, so the status and the reference are matched last.
+var annotation = regexp.MustCompile(`^(\d+):\s*(.*?),\s*(\d{3})(?:,\s*(\S+))?\s*$`)
+
+// codeSpec is an annotated constant.
+type codeSpec struct {
+	Name       string
+	Code       int
+	HTTPStatus int
+	Message    string
+	Reference  string
+}
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the package")
+	output := flag.String("output", "", "generated Go file")
+	doc := flag.String("doc", "", "generated markdown table")
+	flag.Parse()
+
+	if err := run(*dir, *output, *doc); err != nil {
+		fmt.Fprintln(os.Stderr, "codegen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, output, doc string) error {
+	pkg, specs, err := parseDir(dir)
+	if err != nil {
+		return err
+	}
+	if len(specs) == 0 {
+		return fmt.Errorf("no annotated constant in %s", dir)
+	}
+
+	src, err := generateGo(pkg, specs)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		output = filepath.Join(dir, defaultOutput)
+	}
+	if err := os.WriteFile(output, src, 0o644); err != nil {
+		return err
+	}
+	if doc != "" {
+		return os.WriteFile(doc, generateMarkdown(specs), 0o644)
+	}
+	return nil
+}
+
+// parseDir returns the name of the package in dir and its annotated
+// constants, sorted by code.
+func parseDir(dir string) (string, []codeSpec, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var pkg *ast.Package
+	for _, p := range pkgs {
+		if pkg != nil {
+			return "", nil, fmt.Errorf("several packages in %s", dir)
+		}
+		pkg = p
+	}
+	if pkg == nil {
+		return "", nil, fmt.Errorf("no package in %s", dir)
+	}
+
+	var files []*ast.File
+	for _, f := range pkg.Files {
+		if !isGenerated(f) {
+			files = append(files, f)
+		}
+	}
+
+	// The values of the constants are computed by the type checker, which
+	// handles iota. Errors, such as unresolved imports, are ignored since
+	// only the constants matter.
+	info := &types.Info{Defs: map[*ast.Ident]types.Object{}}
+	conf := types.Config{Importer: importer.Default(), Error: func(error) {}}
+	_, _ = conf.Check(pkg.Name, fset, files, info)
+
+	var specs []codeSpec
+	var problems []string
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, s := range gen.Specs {
+				vs := s.(*ast.ValueSpec)
+				comment := vs.Doc
+				if comment == nil && len(gen.Specs) == 1 {
+					comment = gen.Doc
+				}
+				if comment == nil {
+					comment = vs.Comment
+				}
+				spec, ok, err := parseAnnotation(comment)
+				if err != nil {
+					problems = append(problems, fmt.Sprintf("%s: %v", fset.Position(vs.Pos()), err))
+					continue
+				}
+				if !ok {
+					continue
+				}
+				if len(vs.Names) != 1 {
+					problems = append(problems, fmt.Sprintf("%s: annotated constants must be declared one per line", fset.Position(vs.Pos())))
+					continue
+				}
+				spec.Name = vs.Names[0].Name
+				if err := checkValue(info.Defs[vs.Names[0]], spec.Code); err != nil {
+					problems = append(problems, fmt.Sprintf("%s: %s: %v", fset.Position(vs.Pos()), spec.Name, err))
+					continue
+				}
+				specs = append(specs, spec)
+			}
+		}
+	}
+
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Code < specs[j].Code })
+	for i := 1; i < len(specs); i++ {
+		if specs[i].Code == specs[i-1].Code {
+			problems = append(problems, fmt.Sprintf("code %d is used by %s and %s", specs[i].Code, specs[i-1].Name, specs[i].Name))
+		}
+	}
+	if len(problems) > 0 {
+		return "", nil, fmt.Errorf("%d problems:\n%s", len(problems), strings.Join(problems, "\n"))
+	}
+	return pkg.Name, specs, nil
+}
+
+// isGenerated reports whether f was generated, such as by codegen itself.
+func isGenerated(f *ast.File) bool {
+	for _, c := range f.Comments {
+		if c.Pos() >= f.Package {
+			break
+		}
+		for _, l := range c.List {
+			if strings.HasPrefix(l.Text, "// Code generated ") && strings.HasSuffix(l.Text, " DO NOT EDIT.") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseAnnotation parses the annotation of a constant, the last line of its
+// comment. It returns false if the comment is not an annotation.
+func parseAnnotation(comment *ast.CommentGroup) (codeSpec, bool, error) {
+	if comment == nil {
+		return codeSpec{}, false, nil
+	}
+	lines := strings.Split(strings.TrimSpace(comment.Text()), "\n")
+	m := annotation.FindStringSubmatch(strings.TrimSpace(lines[len(lines)-1]))
+	if m == nil {
+		return codeSpec{}, false, nil
+	}
+
+	code, err := strconv.Atoi(m[1])
+	if err != nil {
+		return codeSpec{}, false, fmt.Errorf("invalid code %q", m[1])
+	}
+	status, _ := strconv.Atoi(m[3])
+	switch {
+	case code == 0:
+		return codeSpec{}, false, fmt.Errorf("code 0 is reserved")
+	case status < 100 || status > 599:
+		return codeSpec{}, false, fmt.Errorf("code %d: invalid HTTP status %d", code, status)
+	case m[2] == "":
+		return codeSpec{}, false, fmt.Errorf("code %d: empty message", code)
+	}
+	return codeSpec{Code: code, HTTPStatus: status, Message: m[2], Reference: m[4]}, true, nil
+}
+
+// checkValue checks that the constant obj has the value code.
+func checkValue(obj types.Object, code int) error {
+	c, ok := obj.(*types.Const)
+	if !ok || c.Val().Kind() == constant.Unknown {
+		return fmt.Errorf("cannot compute the value of the constant")
+	}
+	v, ok := constant.Int64Val(constant.ToInt(c.Val()))
+	if !ok || v != int64(code) {
+		return fmt.Errorf("value %s does not match the code %d of the comment", c.Val(), code)
+	}
+	return nil
+}
+
+// generateGo returns the Go file registering specs in package pkg.
+func generateGo(pkg string, specs []codeSpec) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n\npackage %s\n\n", generatedHeader, pkg)
+	fmt.Fprintf(&buf, "import \"github.com/rtmzk/errors\"\n\n")
+	fmt.Fprintf(&buf, "func init() {\n\terrors.MustRegisterTable([]errors.CoderSpec{\n")
+	for _, s := range specs {
+		fmt.Fprintf(&buf, "\t\t{Code: %s, HTTPStatus: %d, Message: %q", s.Name, s.HTTPStatus, s.Message)
+		if s.Reference != "" {
+			fmt.Fprintf(&buf, ", Reference: %q", s.Reference)
+		}
+		fmt.Fprintf(&buf, "},\n")
+	}
+	fmt.Fprintf(&buf, "\t})\n}\n")
+	return format.Source(buf.Bytes())
+}
+
+// generateMarkdown returns the markdown reference table of specs.
+func generateMarkdown(specs []codeSpec) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<!-- %s -->\n\n", strings.TrimPrefix(generatedHeader, "// "))
+	fmt.Fprintf(&buf, "| Identifier | Code | HTTP status | Message | Reference |\n")
+	fmt.Fprintf(&buf, "| --- | --- | --- | --- | --- |\n")
+	for _, s := range specs {
+		ref := s.Reference
+		if ref != "" {
+			ref = "[" + ref + "](" + ref + ")"
+		}
+		fmt.Fprintf(&buf, "| %s | %d | %d | %s | %s |\n", s.Name, s.Code, s.HTTPStatus, escapeCell(s.Message), ref)
+	}
+	return buf.Bytes()
+}
+
+// escapeCell escapes the pipes of a markdown table cell.
+func escapeCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const source = `package code
+
+import "net/http"
+
+const (
+	// ErrUserNotFound is returned when the user does not exist.
+	// 100001: User not found, 404, https://example.com/docs/100001
+	ErrUserNotFound = iota + 100001
+
+	// 100002: User already exists, please pick another name, 409
+	ErrUserExists
+
+	ErrUnannotated
+)
+
+// 100010: Bad | request, 400
+const ErrBadRequest = 100010
+
+const ErrTrailing = 100011 // 100011: Trailing comment, 500
+
+var _ = http.StatusOK
+`
+
+func writePackage(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestParseDir(t *testing.T) {
+	dir := writePackage(t, map[string]string{
+		"code.go":      source,
+		defaultOutput:  generatedHeader + "\n\npackage code\n\n// 100099: Stale, 500\nconst ErrStale = 100099\n",
+		"code_test.go": "package code\n\n// 100098: Test, 500\nconst ErrTest = 100098\n",
+	})
+
+	pkg, specs, err := parseDir(dir)
+	if err != nil {
+		t.Fatalf("parseDir: got error %v", err)
+	}
+	if pkg != "code" {
+		t.Errorf("package: got %q, want %q", pkg, "code")
+	}
+	want := []codeSpec{
+		{"ErrUserNotFound", 100001, 404, "User not found", "https://example.com/docs/100001"},
+		{"ErrUserExists", 100002, 409, "User already exists, please pick another name", ""},
+		{"ErrBadRequest", 100010, 400, "Bad | request", ""},
+		{"ErrTrailing", 100011, 500, "Trailing comment", ""},
+	}
+	if !reflect.DeepEqual(specs, want) {
+		t.Errorf("specs: got %+v, want %+v", specs, want)
+	}
+}
+
+func TestParseDirProblems(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"mismatch", "package code\n\n// 100001: Wrong, 400\nconst ErrWrong = 100002\n", "does not match the code 100001"},
+		{"status", "package code\n\n// 100001: Teapot, 999\nconst ErrTeapot = 100001\n", "invalid HTTP status 999"},
+		{"zero", "package code\n\n// 0: Zero, 400\nconst ErrZero = 0\n", "code 0 is reserved"},
+		{"duplicate", "package code\n\nconst (\n\t// 100001: A, 400\n\tErrA = 100001\n\t// 100001: B, 400\n\tErrB = 100001\n)\n", "code 100001 is used by"},
+	}
+
+	for _, tt := range tests {
+		_, _, err := parseDir(writePackage(t, map[string]string{"code.go": tt.src}))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got error %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := writePackage(t, map[string]string{"code.go": source})
+	doc := filepath.Join(dir, "codes.md")
+	if err := run(dir, "", doc); err != nil {
+		t.Fatalf("run: got error %v", err)
+	}
+
+	src, err := os.ReadFile(filepath.Join(dir, defaultOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		generatedHeader,
+		"errors.MustRegisterTable([]errors.CoderSpec{",
+		`{Code: ErrUserNotFound, HTTPStatus: 404, Message: "User not found", Reference: "https://example.com/docs/100001"},`,
+		`{Code: ErrTrailing, HTTPStatus: 500, Message: "Trailing comment"},`,
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generated Go: got\n%s\nwant it to contain %q", src, want)
+		}
+	}
+
+	md, err := os.ReadFile(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"| Identifier | Code | HTTP status | Message | Reference |",
+		"| ErrUserNotFound | 100001 | 404 | User not found | [https://example.com/docs/100001](https://example.com/docs/100001) |",
+		`| ErrBadRequest | 100010 | 400 | Bad \| request |  |`,
+	} {
+		if !strings.Contains(string(md), want) {
+			t.Errorf("markdown: got\n%s\nwant it to contain %q", md, want)
+		}
+	}
+
+	// The generated file is skipped when generating again.
+	if err := run(dir, "", ""); err != nil {
+		t.Errorf("second run: got error %v", err)
+	}
+}
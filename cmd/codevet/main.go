@@ -0,0 +1,138 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command codevet reports the misuses of the error codes found by the
+// analyzer package: the coded errors created with literal codes which are
+// never registered, and the codes registered more than once. All the
+// packages given are checked together, so that the codes registered by a
+// package are known to the others. Usage in CI:
+//
+//	go run github.com/rtmzk/errors/cmd/codevet ./...
+//
+// The arguments are directories, or directory trees when suffixed with
+// "/...", "." by default. The directories named testdata or vendor, and
+// those starting with "." or "_", are skipped in trees. codevet exits with
+// status 1 if a problem is found.
+//
+// codevet is also a vet tool running analyzer.Analyzer, which checks the
+// packages one by one against the codes registered by their dependencies:
+//
+//	go vet -vettool=$(which codevet) ./...
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/analysis/unitchecker"
+
+	"github.com/rtmzk/errors/analyzer"
+)
+
+func main() {
+	args := os.Args[1:]
+	if vetTool(args) {
+		unitchecker.Main(analyzer.Analyzer)
+	}
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+
+	n, err := run(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "codevet:", err)
+		os.Exit(1)
+	}
+	if n > 0 {
+		os.Exit(1)
+	}
+}
+
+// vetTool reports whether codevet is run by go vet -vettool, which asks
+// for its version and flags, and then passes it the configuration file of
+// each package.
+func vetTool(args []string) bool {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-V") || arg == "-flags" || strings.HasSuffix(arg, ".cfg") {
+			return true
+		}
+	}
+	return false
+}
+
+// run checks the packages matched by args, prints the problems found and
+// returns their number.
+func run(args []string) (int, error) {
+	dirs, err := expand(args)
+	if err != nil {
+		return 0, err
+	}
+	diags, err := analyzer.Check(dirs...)
+	if err != nil {
+		return 0, err
+	}
+	for _, d := range diags {
+		fmt.Fprintln(os.Stderr, d)
+	}
+	return len(diags), nil
+}
+
+// expand returns the directories holding Go files matched by args.
+func expand(args []string) ([]string, error) {
+	var dirs []string
+	for _, arg := range args {
+		root, ok := strings.CutSuffix(arg, "/...")
+		if !ok {
+			dirs = append(dirs, arg)
+			continue
+		}
+		if root == "" {
+			root = "."
+		}
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() {
+				return nil
+			}
+			name := d.Name()
+			if path != root && (name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			if hasGoFiles(path) {
+				dirs = append(dirs, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dirs, nil
+}
+
+// hasGoFiles reports whether dir holds Go files other than tests.
+func hasGoFiles(dir string) bool {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, m := range matches {
+		if !strings.HasSuffix(m, "_test.go") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	root := t.TempDir()
+	files := []string{
+		"a/a.go",
+		"a/b/b.go",
+		"a/tests/x_test.go",
+		"a/testdata/t.go",
+		"a/vendor/v.go",
+		"a/.hidden/h.go",
+		"a/_skip/s.go",
+	}
+	for _, f := range files {
+		path := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("package x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := expand([]string{filepath.Join(root, "a") + "/...", "single"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(root, "a"), filepath.Join(root, "a/b"), "single"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expand(): got %v, want %v", got, want)
+	}
+}
+
+func TestVetTool(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"-V=full"}, true},
+		{[]string{"-flags"}, true},
+		{[]string{"/tmp/go-build/vet.cfg"}, true},
+		{[]string{"./..."}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := vetTool(tt.args); got != tt.want {
+			t.Errorf("vetTool(%q): got %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command errctl inspects and lints the error codes of a program outside of
+// it, from a manifest of its registered codes, as written by
+// errors.ExportCatalog or the export command of the catalogctl package, or
+// from a document of codes read by errors.LoadCodes. Usage in CI:
+//
+//	errctl list codes.json
+//	errctl lint codes.yaml
+//	errctl diff v1.4.0.json v1.5.0.json
+//
+// The commands are:
+//
+//	list  print the code, HTTP status, slug and message of every code
+//	lint  report the invalid codes, the codes sharing an HTTP status and
+//	      a message, and the codes without a reference
+//	diff  print the codes added, removed, changed and renumbered between
+//	      two releases
+//
+// The sources are files, in JSON or, when suffixed with ".yaml" or ".yml",
+// in YAML, or "-" for the standard input. errctl exits with status 1 if
+// lint finds a problem, or if diff finds a code removed or renumbered.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rtmzk/errors"
+	"github.com/rtmzk/errors/catalogctl"
+	"github.com/rtmzk/errors/yamlcodes"
+)
+
+const usage = `usage: errctl list source
+       errctl lint source
+       errctl diff old new`
+
+func main() {
+	n, err := run(os.Stdout, os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "errctl:", err)
+		os.Exit(2)
+	}
+	if n > 0 {
+		os.Exit(1)
+	}
+}
+
+// run runs the command of args, writing its output to w, and returns the
+// number of problems found.
+func run(w io.Writer, args []string) (int, error) {
+	if len(args) == 0 {
+		return 0, errors.New(usage)
+	}
+	cmd, args := args[0], args[1:]
+	want := 1
+	if cmd == "diff" {
+		want = 2
+	}
+	if len(args) != want {
+		return 0, errors.New(usage)
+	}
+
+	catalogs := make([][]errors.Coder, len(args))
+	for i, source := range args {
+		coders, err := load(source)
+		if err != nil {
+			return 0, errors.Wrapf(err, "load %s", source)
+		}
+		catalogs[i] = coders
+	}
+
+	switch cmd {
+	case "list":
+		return 0, list(w, catalogs[0])
+	case "lint":
+		problems := lint(catalogs[0])
+		for _, p := range problems {
+			fmt.Fprintln(w, p)
+		}
+		return len(problems), nil
+	case "diff":
+		return diff(w, catalogs[0], catalogs[1]), nil
+	}
+	return 0, errors.Errorf("unknown command %q\n%s", cmd, usage)
+}
+
+// load returns the coders of a source, sorted by code.
+func load(source string) ([]errors.Coder, error) {
+	var r io.Reader = os.Stdin
+	if source != "-" {
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	switch strings.ToLower(filepath.Ext(source)) {
+	case ".yaml", ".yml":
+		var err error
+		if r, err = yamlcodes.ToJSON(r); err != nil {
+			return nil, err
+		}
+	}
+
+	coders, err := errors.ImportCatalog(r)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(coders, func(i, j int) bool { return coders[i].Code() < coders[j].Code() })
+	return coders, nil
+}
+
+func list(w io.Writer, coders []errors.Coder) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CODE\tHTTP\tSLUG\tMESSAGE")
+	for _, c := range coders {
+		slug, _ := errors.SlugOf(c)
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s\n", c.Code(), c.HTTPStatus(), slug, c.String())
+	}
+	return tw.Flush()
+}
+
+// lint returns the problems of a catalog: those reported by
+// catalogctl.Validate, the codes which clients cannot tell apart since
+// they share an HTTP status and a message, and the codes without a
+// reference.
+func lint(coders []errors.Coder) []string {
+	problems := catalogctl.Validate(coders)
+
+	type mapping struct {
+		status  int
+		message string
+	}
+	shared := map[mapping][]int{}
+	var mappings []mapping
+	for _, c := range coders {
+		m := mapping{c.HTTPStatus(), c.String()}
+		if len(shared[m]) == 0 {
+			mappings = append(mappings, m)
+		}
+		shared[m] = append(shared[m], c.Code())
+	}
+	for _, m := range mappings {
+		if codes := shared[m]; len(codes) > 1 {
+			problems = append(problems, fmt.Sprintf("codes %s: same HTTP status %d and message %q", joinCodes(codes), m.status, m.message))
+		}
+	}
+
+	for _, c := range coders {
+		if c.Reference() == "" {
+			problems = append(problems, fmt.Sprintf("code %d: missing reference", c.Code()))
+		}
+	}
+	return problems
+}
+
+// diff prints the differences between the catalogs of two releases and
+// returns the number of codes removed or renumbered. A removed code is
+// renumbered when an added code has its slug or, for codes without a
+// slug, its HTTP status and message.
+func diff(w io.Writer, old, cur []errors.Coder) int {
+	d := errors.DiffCatalogs(old, cur)
+
+	renumbered := map[int]errors.Coder{}
+	taken := map[int]bool{}
+	for _, o := range d.Removed {
+		for _, n := range d.Added {
+			if !taken[n.Code()] && sameCoder(o, n) {
+				renumbered[o.Code()] = n
+				taken[n.Code()] = true
+				break
+			}
+		}
+	}
+
+	for _, c := range d.Added {
+		if !taken[c.Code()] {
+			fmt.Fprintf(w, "+ %d %d %s\n", c.Code(), c.HTTPStatus(), c.String())
+		}
+	}
+	for _, c := range d.Removed {
+		if _, ok := renumbered[c.Code()]; !ok {
+			fmt.Fprintf(w, "- %d %d %s\n", c.Code(), c.HTTPStatus(), c.String())
+		}
+	}
+	for _, c := range d.Changed {
+		fmt.Fprintf(w, "~ %d %d %s -> %d %s\n", c.New.Code(), c.Old.HTTPStatus(), c.Old.String(), c.New.HTTPStatus(), c.New.String())
+	}
+	for _, o := range d.Removed {
+		if n, ok := renumbered[o.Code()]; ok {
+			fmt.Fprintf(w, "! %d -> %d renumbered %s\n", o.Code(), n.Code(), o.String())
+		}
+	}
+	if d.Empty() {
+		fmt.Fprintln(w, "no difference")
+	}
+	return len(d.Removed)
+}
+
+// sameCoder reports whether the coders a and b describe the same error.
+func sameCoder(a, b errors.Coder) bool {
+	slugA, _ := errors.SlugOf(a)
+	slugB, _ := errors.SlugOf(b)
+	if slugA != "" || slugB != "" {
+		return slugA == slugB
+	}
+	return a.HTTPStatus() == b.HTTPStatus() && a.String() == b.String()
+}
+
+func joinCodes(codes []int) string {
+	s := make([]string, len(codes))
+	for i, code := range codes {
+		s[i] = fmt.Sprint(code)
+	}
+	return strings.Join(s, ", ")
+}
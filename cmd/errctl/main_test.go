@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const v1 = `[
+  {"code": 914001, "slug": "user_not_found", "http_status": 404, "message": "User not found", "reference": "https://example.com/914001"},
+  {"code": 914002, "http_status": 409, "message": "Conflict", "reference": "https://example.com/914002"},
+  {"code": 914003, "http_status": 500, "message": "Internal error", "reference": "https://example.com/914003"}
+]`
+
+const v2 = `
+- code: 914004
+  http_status: 409
+  message: Conflict
+  reference: https://example.com/914004
+- code: 914005
+  http_status: 404
+  message: User not found
+- code: 914006
+  slug: user_not_found
+  http_status: 404
+  message: User not found
+  reference: https://example.com/914006
+`
+
+func write(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun(t *testing.T) {
+	old := write(t, "v1.json", v1)
+	cur := write(t, "v2.yaml", v2)
+
+	tests := []struct {
+		args     []string
+		problems int
+		want     []string
+	}{
+		{
+			args: []string{"list", old},
+			want: []string{"914001  404   user_not_found  User not found", "914003  500"},
+		},
+		{
+			args: []string{"lint", old},
+			want: nil,
+		},
+		{
+			args:     []string{"lint", cur},
+			problems: 2,
+			want: []string{
+				`codes 914005, 914006: same HTTP status 404 and message "User not found"`,
+				"code 914005: missing reference",
+			},
+		},
+		{
+			args:     []string{"diff", old, cur},
+			problems: 3,
+			want: []string{
+				"+ 914005 404 User not found",
+				"- 914003 500 Internal error",
+				"! 914001 -> 914006 renumbered User not found",
+				"! 914002 -> 914004 renumbered Conflict",
+			},
+		},
+		{
+			args: []string{"diff", old, old},
+			want: []string{"no difference"},
+		},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		n, err := run(&buf, tt.args)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.args[0], err)
+			continue
+		}
+		if n != tt.problems {
+			t.Errorf("%s: got %d problems, want %d\n%s", tt.args[0], n, tt.problems, buf.String())
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("%s: got\n%s\nwant %q", tt.args[0], buf.String(), want)
+			}
+		}
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	tests := [][]string{
+		nil,
+		{"lint"},
+		{"diff", "a.json"},
+		{"bogus", "a.json"},
+		{"list", filepath.Join(t.TempDir(), "missing.json")},
+	}
+	for _, args := range tests {
+		if _, err := run(&bytes.Buffer{}, args); err == nil {
+			t.Errorf("run(%q): got no error, want one", args)
+		}
+	}
+}
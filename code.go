@@ -17,11 +17,14 @@ package errors
 import (
 	"fmt"
 	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
 	"sync"
 )
 
 var (
-	unknownCoder defaultCoder = defaultCoder{1, http.StatusInternalServerError, "An internal server error occurred", "https://github.com/rtmzk/errors/README.md"}
+	unknownCoder Coder = defaultCoder{1, http.StatusInternalServerError, "An internal server error occurred", ""}
 )
 
 // Coder defines an interface for an error code detail information.
@@ -77,74 +80,387 @@ func (d defaultCoder) Code() int {
 	return d.C
 }
 
-// codes contains a map of error codes to metadata.
-var codes = map[int]Coder{}
-var codeMux = &sync.Mutex{}
+// codes contains the registered error codes and their metadata.
+var codes RegistryBackend = mapBackend{}
+
+// origins contains the call sites which registered the codes.
+var origins = map[int]uintptr{}
+
+var codeMux = &sync.RWMutex{}
+
+// Registration failures, matched by RegistrationError with Is.
+var (
+	ErrCodeReserved  = New("code is reserved")
+	ErrCodeExists    = New("code already exist")
+	ErrCodeOwned     = New("code belongs to the range of another owner")
+	ErrInvalidStatus = New("invalid HTTP status")
+	ErrEmptyMessage  = New("empty message")
+	ErrNoOwner       = New("no owner")
+	ErrFrozen        = New("registry is frozen")
+	ErrSlugExists    = New("slug already exist")
+)
+
+// RegistrationError is the error returned by RegisterE.
+type RegistrationError struct {
+	// Code is the code which could not be registered.
+	Code int
+
+	// Err is the failure, one of ErrCodeReserved, ErrCodeExists,
+	// ErrCodeOwned, ErrInvalidStatus, ErrEmptyMessage, ErrNoOwner,
+	// ErrFrozen and ErrSlugExists.
+	Err error
+
+	// Detail optionally describes the failure further.
+	Detail string
+}
+
+func (e *RegistrationError) Error() string {
+	if e.Detail != "" {
+		return fmt.Sprintf("code %d: %v: %s", e.Code, e.Err, e.Detail)
+	}
+	return fmt.Sprintf("code %d: %v", e.Code, e.Err)
+}
+
+// Unwrap returns the failure, so that errors.Is(err, ErrCodeExists) holds.
+func (e *RegistrationError) Unwrap() error { return e.Err }
+
+// panicMessage returns the message which Register, or MustRegister if
+// unique is true, panics with for e. The messages predating
+// RegistrationError are kept for the programs matching them.
+func (e *RegistrationError) panicMessage(unique bool) string {
+	switch {
+	case e.Err == ErrCodeReserved && e.Code == 0 && unique:
+		return "code '0' is reserved by 'github.com/rtmzk/errors' as ErrUnknown error code"
+	case e.Err == ErrCodeReserved && e.Code == 0:
+		return "code `0` is reserved by `github.com/rtmzk/errors` as unknownCode error code"
+	case e.Err == ErrCodeExists:
+		return fmt.Sprintf("code: %d already exist", e.Code)
+	case e.Err == ErrCodeOwned:
+		return fmt.Sprintf("code %d is %s", e.Code, e.Detail)
+	}
+	return e.Error()
+}
 
 // Register a user define error code.
 // It will override the exist code.
+// It panics if the code is reserved by another owner, see RegisterRange.
 func Register(coder Coder) {
-	if coder.Code() == 0 {
-		panic("code `0` is reserved by `github.com/rtmzk/errors` as unknownCode error code")
+	if err := registerAt(coder, callerPC(), false, false); err != nil {
+		panic(err.panicMessage(false))
 	}
+}
+
+// MustRegister register a user define error code.
+// It will panic when the same Code already exist, or when the code is
+// reserved by another owner, see RegisterRange.
+func MustRegister(coder Coder) {
+	if err := registerAt(coder, callerPC(), true, false); err != nil {
+		panic(err.panicMessage(true))
+	}
+}
+
+// RegisterE registers coder like MustRegister, but returns a
+// *RegistrationError instead of panicking, so that libraries can register
+// their codes without risking to take the process down. It also rejects
+// coders whose HTTP status is outside of 100-599 or whose message is empty.
+func RegisterE(coder Coder) error {
+	if err := registerAt(coder, callerPC(), true, true); err != nil {
+		return err
+	}
+	return nil
+}
+
+// registerAt registers coder on behalf of the function at pc. If unique is
+// true, a code which already exist is rejected, and if validate is true,
+// so is a coder with an invalid HTTP status or an empty message.
+func registerAt(coder Coder, pc uintptr, unique, validate bool) *RegistrationError {
+	if err := checkCoder(coder, validate); err != nil {
+		return err
+	}
+	code := coder.Code()
 
 	codeMux.Lock()
 	defer codeMux.Unlock()
 
-	codes[coder.Code()] = coder
+	if Frozen() {
+		return &RegistrationError{Code: code, Err: ErrFrozen}
+	}
+	if unique {
+		if _, ok := codes.get(code); ok {
+			return &RegistrationError{Code: code, Err: ErrCodeExists}
+		}
+		if slug, ok := SlugOf(coder); ok {
+			if owner, ok := slugOwner(slug); ok && owner != code {
+				return &RegistrationError{Code: code, Err: ErrSlugExists, Detail: fmt.Sprintf("%s, used by %d", slug, owner)}
+			}
+		}
+	}
+	if err := checkRange(code, pc); err != nil {
+		return err
+	}
+
+	setCoder(coder)
+	origins[code] = pc
+	return nil
 }
 
-// MustRegister register a user define error code.
-// It will panic when the same Code already exist.
-func MustRegister(coder Coder) {
-	if coder.Code() == 0 {
-		panic("code '0' is reserved by 'github.com/rtmzk/errors' as ErrUnknown error code")
+// checkCoder rejects the coders which cannot be registered: those of the
+// reserved code, those without an owner in strict mode, and if validate is
+// true, those with an invalid HTTP status or an empty message.
+func checkCoder(coder Coder, validate bool) *RegistrationError {
+	code := coder.Code()
+	if code == 0 {
+		return &RegistrationError{Code: code, Err: ErrCodeReserved, Detail: "used for the unknown code"}
 	}
+	if validate {
+		if status := coder.HTTPStatus(); status < 100 || status > 599 {
+			return &RegistrationError{Code: code, Err: ErrInvalidStatus, Detail: strconv.Itoa(status)}
+		}
+		if coder.String() == "" {
+			return &RegistrationError{Code: code, Err: ErrEmptyMessage}
+		}
+	}
+	return checkOwner(coder)
+}
+
+// ListCodes returns the registered coders sorted by code, leaving out the
+// unknown coder, e.g. to generate the documentation of an API.
+func ListCodes() []Coder {
+	return registeredCoders()
+}
+
+// GetCoder returns the Coder registered for code.
+func GetCoder(code int) (Coder, bool) {
+	ensureCatalogs()
+	return lookupCoder(code)
+}
+
+// IsRegistered reports whether a Coder is registered for code.
+func IsRegistered(code int) bool {
+	_, ok := GetCoder(code)
+	return ok
+}
+
+// RequireRegistered returns an error listing the given codes which are not
+// registered. Feature packages usually register their coders in the init
+// function of a codes subpackage, which binaries import for its side
+// effects:
+//
+//	import _ "example.com/billing/codes"
+//
+// Calling RequireRegistered at startup with the codes a binary relies on
+// catches a missing blank import before the first error is produced with
+// the unknown coder.
+func RequireRegistered(codes ...int) error {
+	var missing []int
+	for _, code := range codes {
+		if !IsRegistered(code) {
+			missing = append(missing, code)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return Errorf("codes not registered, is the package registering them imported? %v", missing)
+}
+
+// CoderSpec describes a Coder of a generated code table, see
+// MustRegisterTable.
+type CoderSpec struct {
+	Code       int
+	HTTPStatus int
+	Message    string
+	Reference  string
+	Options    []CoderOption
+}
+
+// coder returns the Coder described by the spec.
+func (s CoderSpec) coder() Coder {
+	return NewCoder(s.Code, s.HTTPStatus, s.Message, s.Reference, s.Options...)
+}
+
+// MustRegisterTable registers the coders of a generated table, built with
+// NewCoder. Unlike calling MustRegister for each of them, the registry is
+// locked once, and the whole table is validated first: it panics listing
+// all the reserved, invalid and duplicated codes, whether duplicated within
+// the table or with the registered codes, the duplicated slugs, and in
+// strict mode the codes without an owner, and registers none of them.
+func MustRegisterTable(specs []CoderSpec) {
+	pc := callerPC()
 
 	codeMux.Lock()
 	defer codeMux.Unlock()
 
-	if _, ok := codes[coder.Code()]; ok {
-		panic(fmt.Sprintf("code: %d already exist", coder.Code()))
+	if Frozen() {
+		panic("invalid code table: " + ErrFrozen.Error())
 	}
 
-	codes[coder.Code()] = coder
+	var problems []string
+	seen := make(map[int]bool, len(specs))
+	seenSlugs := map[string]int{}
+	for i, spec := range specs {
+		slug, _ := SlugOf(spec.coder())
+		owner, slugTaken := slugOwner(slug)
+		if prev, ok := seenSlugs[slug]; ok {
+			owner, slugTaken = prev, true
+		}
+		switch {
+		case spec.Code == 0:
+			problems = append(problems, fmt.Sprintf("#%d: code 0 is reserved", i))
+		case spec.HTTPStatus != 0 && (spec.HTTPStatus < 100 || spec.HTTPStatus > 599):
+			problems = append(problems, fmt.Sprintf("#%d: code %d has an invalid HTTP status %d", i, spec.Code, spec.HTTPStatus))
+		case checkOwner(spec.coder()) != nil:
+			problems = append(problems, fmt.Sprintf("#%d: code %d has no owner", i, spec.Code))
+		case seen[spec.Code]:
+			problems = append(problems, fmt.Sprintf("#%d: code %d is duplicated in the table", i, spec.Code))
+		case slugTaken && owner != spec.Code:
+			problems = append(problems, fmt.Sprintf("#%d: code %d has the slug %s of code %d", i, spec.Code, slug, owner))
+		default:
+			if _, ok := codes.get(spec.Code); ok {
+				problems = append(problems, fmt.Sprintf("#%d: code %d already exist", i, spec.Code))
+			} else if err := checkRange(spec.Code, pc); err != nil {
+				problems = append(problems, fmt.Sprintf("#%d: %s", i, err.panicMessage(true)))
+			}
+		}
+		seen[spec.Code] = true
+		if slug != "" {
+			seenSlugs[slug] = spec.Code
+		}
+	}
+	if len(problems) > 0 {
+		panic(fmt.Sprintf("invalid code table: %d problems:\n%s", len(problems), strings.Join(problems, "\n")))
+	}
+
+	for _, spec := range specs {
+		setCoder(spec.coder())
+		origins[spec.Code] = pc
+	}
+}
+
+// CodeError is implemented by errors which carry an error code.
+// Besides the errors created by WithCode and Wrapc, any user defined error
+// type exposing a Code method is honored by ParseCoder and IsCode.
+// The code can be extracted with the standard errors.As:
+//
+//	var coded errors.CodeError
+//	if stderrors.As(err, &coded) {
+//		metrics.Inc(coded.Code())
+//	}
+//
+// or with CodeOf.
+type CodeError interface {
+	error
+	Code() int
 }
 
 // ParseCoder parse any error into *withCode.
 // nil error will return nil direct.
 // None withStack error will be parsed as ErrUnknown.
+//
+// The chain of err is walked, through Unwrap and Cause, down to the
+// outermost error implementing CodeError, so that coded errors wrapped by
+// fmt.Errorf("%w") or by third-party wrappers keep their code.
+// That error is resolved through the registered code. A chain without a
+// code holding a multi-error, such as an Aggregate, resolves to the
+// dominant Coder of its members, see SetDominantCoder.
+// If the code is not registered here, the Coder reported by the error's
+// Coder method is used, and failing that the error itself if it implements
+// Coder. This keeps errors created by another copy of this package, such as
+// a vendored fork, meaningful. A coded error resolving to none of them is
+// skipped for the next coded error of the chain, or for the previous one
+// when the deepest code is searched, see SetCoderSearch. A chain whose
+// codes resolve to none of them resolves to the Coder mapped from its code
+// by SetUnknownCodeFunc, if any, or else to the unknown coder, see
+// SetUnknownCoder.
 func ParseCoder(err error) Coder {
 	if err == nil {
 		return nil
 	}
-
-	if v, ok := err.(*withCode); ok {
-		if coder, ok := codes[v.code]; ok {
-			return coder
+	ensureCatalogs()
+
+	errs := list(err)
+	if coderSearchNow() == SearchDeepest {
+		for i := len(errs) - 1; i >= 0; i-- {
+			if coder, ok := resolveCoder(errs[i]); ok {
+				return coder
+			}
+		}
+	} else {
+		for _, e := range errs {
+			if coder, ok := resolveCoder(e); ok {
+				return coder
+			}
 		}
 	}
 
-	return unknownCoder
+	if coder, ok := unregisteredCoder(errs); ok {
+		return coder
+	}
+	if coder, ok := dominantCoder(err); ok {
+		return coder
+	}
+	return fallbackCoder(err)
 }
 
-// IsCode reports whether any error in err's chain contains the given error code.
-func IsCode(err error, code int) bool {
-	if v, ok := err.(*withCode); ok {
-		if v.code == code {
-			return true
-		}
+// resolveCoder returns the Coder of e if it is a coded error: the Coder
+// registered for its code, or else the Coder reported by its Coder method,
+// or else e itself if it implements Coder. The code of an error created by
+// a private Registry is resolved in that registry only.
+func resolveCoder(e error) (Coder, bool) {
+	v, ok := e.(CodeError)
+	if !ok {
+		return nil, false
+	}
+	if w, ok := e.(*withCode); ok && w.registry != nil {
+		return w.registryCoder()
+	}
+	if coder, ok := lookupCoder(v.Code()); ok {
+		warnAlias(v.Code())
+		return coder, true
+	}
+	if coder, ok := coderOf(e); ok {
+		return coder, true
+	}
+	if coder, ok := e.(Coder); ok {
+		return coder, true
+	}
+	return nil, false
+}
 
-		if v.cause != nil {
-			return IsCode(v.cause, code)
-		}
+// coderOf returns the Coder reported by the Coder method of err.
+// The method is looked up by name, since the Coder return type of another
+// copy of this package is a distinct type that no interface here matches.
+func coderOf(err error) (Coder, bool) {
+	if v, ok := err.(interface{ Coder() Coder }); ok {
+		coder := v.Coder()
+		return coder, coder != nil && coder.Code() != unknownCoder.Code()
+	}
 
-		return false
+	m := reflect.ValueOf(err).MethodByName("Coder")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil, false
+	}
+	out := m.Call(nil)[0]
+	if out.Kind() == reflect.Interface && out.IsNil() {
+		return nil, false
 	}
+	coder, ok := out.Interface().(Coder)
+	return coder, ok && coder.Code() != unknownCoder.Code()
+}
 
-	return false
+// IsCode reports whether any error in err's chain contains the given error code.
+// The chain is walked through Unwrap and Cause, whatever the wrapper types,
+// and into the members of Aggregates and other multi-errors, such as those
+// of errors.Join and of fmt.Errorf with several %w verbs, at any depth.
+// A deprecated code and its replacement match each other, see RegisterAlias.
+func IsCode(err error, code int) bool {
+	code = canonicalCode(code)
+	return walk(err, func(e error) bool {
+		v, ok := e.(CodeError)
+		return ok && canonicalCode(v.Code()) == code
+	})
 }
 
 func init() {
-	codes[unknownCoder.Code()] = unknownCoder
+	codes.set(unknownCoder)
 }
@@ -0,0 +1,264 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type userCodeError struct {
+	code int
+}
+
+func (e *userCodeError) Error() string { return "user defined" }
+func (e *userCodeError) Code() int     { return e.code }
+
+type userCoderError struct {
+	userCodeError
+}
+
+func (e *userCoderError) HTTPStatus() int   { return 418 }
+func (e *userCoderError) String() string    { return "teapot" }
+func (e *userCoderError) Reference() string { return "" }
+
+func TestParseCoder(t *testing.T) {
+	registered := defaultCoder{C: 900401, HTTP: 404, Ext: "not found"}
+	Register(registered)
+
+	tests := []struct {
+		err  error
+		want Coder
+	}{
+		{nil, nil},
+		{io.EOF, unknownCoder},
+		{WithCode(900401, "missing"), registered},
+		{WithCode(900499, "unregistered"), unknownCoder},
+		{&userCodeError{code: 900401}, registered},
+		{&userCodeError{code: 900499}, unknownCoder},
+		{&userCoderError{userCodeError{code: 900499}}, &userCoderError{userCodeError{code: 900499}}},
+	}
+
+	for i, tt := range tests {
+		got := ParseCoder(tt.err)
+		if got == nil || tt.want == nil {
+			if got != tt.want {
+				t.Errorf("test %d: ParseCoder(%v): got %v, want %v", i+1, tt.err, got, tt.want)
+			}
+			continue
+		}
+		if got.Code() != tt.want.Code() || got.HTTPStatus() != tt.want.HTTPStatus() {
+			t.Errorf("test %d: ParseCoder(%v): got (%d, %d), want (%d, %d)", i+1, tt.err,
+				got.Code(), got.HTTPStatus(), tt.want.Code(), tt.want.HTTPStatus())
+		}
+	}
+}
+
+func TestIsCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+		want bool
+	}{
+		{nil, 900402, false},
+		{io.EOF, 900402, false},
+		{WithCode(900402, "top"), 900402, true},
+		{Wrapc(WithCode(900402, "inner"), 900403, "outer"), 900402, true},
+		{Wrapc(WithCode(900402, "inner"), 900403, "outer"), 900404, false},
+		{&userCodeError{code: 900402}, 900402, true},
+	}
+
+	for i, tt := range tests {
+		if got := IsCode(tt.err, tt.code); got != tt.want {
+			t.Errorf("test %d: IsCode(%v, %d): got %v, want %v", i+1, tt.err, tt.code, got, tt.want)
+		}
+	}
+}
+
+// foreignCoder and foreignCodeError mimic the types of another copy of this
+// package: the Coder method returns a type this package knows nothing about.
+type foreignCoder interface {
+	HTTPStatus() int
+	String() string
+	Reference() string
+	Code() int
+}
+
+type foreignCodeError struct {
+	code  int
+	cause error
+}
+
+func (e *foreignCodeError) Error() string { return "foreign" }
+func (e *foreignCodeError) Code() int     { return e.code }
+func (e *foreignCodeError) Unwrap() error { return e.cause }
+func (e *foreignCodeError) Coder() foreignCoder {
+	return defaultCoder{C: e.code, HTTP: 409, Ext: "foreign conflict"}
+}
+
+func TestParseCoderForeign(t *testing.T) {
+	err := Wrapc(&foreignCodeError{code: 900501}, 900502, "wrapped")
+	if !IsCode(err, 900501) {
+		t.Errorf("IsCode(%v, 900501): got false, want true", err)
+	}
+
+	coder := ParseCoder(&foreignCodeError{code: 900501})
+	if coder.Code() != 900501 || coder.HTTPStatus() != 409 {
+		t.Errorf("ParseCoder(foreign): got (%d, %d), want (%d, %d)", coder.Code(), coder.HTTPStatus(), 900501, 409)
+	}
+}
+
+func TestNewCoder(t *testing.T) {
+	c := NewCoder(900901, 0, "unavailable", "https://example.com/900901",
+		WithSeverity(SeverityError), WithDomain("billing"), WithRetryable(true))
+
+	if c.Code() != 900901 || c.HTTPStatus() != 500 || c.String() != "unavailable" || c.Reference() != "https://example.com/900901" {
+		t.Errorf("NewCoder: got (%d, %d, %q, %q)", c.Code(), c.HTTPStatus(), c.String(), c.Reference())
+	}
+	meta := c.(interface {
+		Severity() Severity
+		Domain() string
+		Retryable() bool
+	})
+	if meta.Severity() != SeverityError || meta.Domain() != "billing" || !meta.Retryable() {
+		t.Errorf("NewCoder options: got (%v, %q, %v)", meta.Severity(), meta.Domain(), meta.Retryable())
+	}
+}
+
+func TestRemediation(t *testing.T) {
+	Register(NewCoder(902501, 503, "disk full", "", WithRemediation("free some space", "restart the service")))
+
+	want := []string{"free some space", "restart the service"}
+	if got := Remediation(WithCode(902501, "write failed")); !reflect.DeepEqual(got, want) {
+		t.Errorf("Remediation(): got %v, want %v", got, want)
+	}
+	if got := Remediation(io.EOF); got != nil {
+		t.Errorf("Remediation(io.EOF): got %v, want nil", got)
+	}
+}
+
+func TestMustRegisterTable(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(904001, 400, "existing", ""))
+
+	table := []CoderSpec{
+		{Code: 904002, HTTPStatus: 404, Message: "not found"},
+		{Code: 904003, Message: "internal", Options: []CoderOption{WithDomain("billing")}},
+	}
+	MustRegisterTable(table)
+
+	if got := ParseCoder(WithCode(904002, "")).HTTPStatus(); got != 404 {
+		t.Errorf("MustRegisterTable: got status %d for 904002, want 404", got)
+	}
+	if info, _ := DescribeCode(904003); info.HTTPStatus != 500 || info.Domain != "billing" || info.Origin == "" {
+		t.Errorf("MustRegisterTable: got %+v for 904003", info)
+	}
+
+	invalid := []CoderSpec{
+		{Code: 904004},
+		{Code: 0},
+		{Code: 904001},
+		{Code: 904004},
+		{Code: 904005, HTTPStatus: 1000},
+	}
+	msg := func() (msg string) {
+		defer func() { msg = fmt.Sprint(recover()) }()
+		MustRegisterTable(invalid)
+		return ""
+	}()
+	for _, want := range []string{
+		"4 problems",
+		"#1: code 0 is reserved",
+		"#2: code 904001 already exist",
+		"#3: code 904004 is duplicated in the table",
+		"#4: code 904005 has an invalid HTTP status 1000",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("MustRegisterTable(invalid): got panic %q, want it to contain %q", msg, want)
+		}
+	}
+	if _, ok := DescribeCode(904004); ok {
+		t.Errorf("MustRegisterTable(invalid): got 904004 registered, want no code of the table registered")
+	}
+}
+
+func TestRequireRegistered(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(904601, 400, "registered", ""))
+
+	if err := RequireRegistered(904601); err != nil {
+		t.Errorf("RequireRegistered(904601): got %v, want nil", err)
+	}
+	err := RequireRegistered(904601, 904602, 904603)
+	if err == nil || !strings.HasSuffix(err.Error(), "[904602 904603]") {
+		t.Errorf("RequireRegistered(904601, 904602, 904603): got %v, want the missing codes", err)
+	}
+}
+
+func TestCodeThroughChains(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(905201, 404, "not found", ""))
+	MustRegister(NewCoder(905202, 409, "conflict", ""))
+
+	inner := WithCode(905201, "missing")
+	tests := []struct {
+		err  error
+		code int
+		is   []int
+	}{
+		{fmt.Errorf("lookup: %w", inner), 905201, []int{905201}},
+		{Wrap(fmt.Errorf("lookup: %w", inner), "handler"), 905201, []int{905201}},
+		{WithStack(Wrapc(fmt.Errorf("lookup: %w", inner), 905202, "save")), 905202, []int{905202, 905201}},
+		{fmt.Errorf("lookup: %w", io.EOF), 1, nil},
+		{Wrap(fmt.Errorf("batch: %w, %w", io.EOF, stderrors.Join(io.ErrClosedPipe, fmt.Errorf("item: %w", inner))), "flush"), 905201, []int{905201}},
+		{stderrors.Join(io.EOF, stderrors.Join(io.ErrUnexpectedEOF, Wrapc(inner, 905202, "save"))), 905202, []int{905202, 905201}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseCoder(tt.err).Code(); got != tt.code {
+			t.Errorf("ParseCoder(%v): got %d, want %d", tt.err, got, tt.code)
+		}
+		for _, code := range tt.is {
+			if !IsCode(tt.err, code) {
+				t.Errorf("IsCode(%v, %d): got false, want true", tt.err, code)
+			}
+		}
+		if IsCode(tt.err, 905299) {
+			t.Errorf("IsCode(%v, 905299): got true, want false", tt.err)
+		}
+	}
+}
+
+func TestRegistryIntrospection(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(907102, 404, "not found", ""))
+	MustRegister(NewCoder(907101, 400, "invalid", ""))
+
+	var got []int
+	for _, coder := range ListCodes() {
+		got = append(got, coder.Code())
+	}
+	if want := []int{907101, 907102}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListCodes(): got %v, want %v", got, want)
+	}
+
+	if coder, ok := GetCoder(907102); !ok || coder.String() != "not found" {
+		t.Errorf("GetCoder(907102): got %v, %v, want the registered coder", coder, ok)
+	}
+	if _, ok := GetCoder(907103); ok {
+		t.Errorf("GetCoder(907103): got true, want false")
+	}
+	if !IsRegistered(907101) || IsRegistered(907103) {
+		t.Errorf("IsRegistered(): got %v and %v, want true and false", IsRegistered(907101), IsRegistered(907103))
+	}
+}
@@ -0,0 +1,60 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func init() {
+	Register(NewCoder(909301, 404, "user not found", ""))
+	Register(NewCoder(909302, 500, "lookup failed", ""))
+	Register(NewCoder(909303, 429, "quota at 100% of the limit", ""))
+}
+
+func TestCodeFormatVerbs(t *testing.T) {
+	err := WithCode(909301, "user %d missing", 7)
+	unregistered := WithCode(909399, "no such code")
+
+	tests := []struct {
+		err    error
+		format string
+		want   string
+	}{
+		{err, "%s", "user not found"},
+		{err, "%v", "909301: user not found"},
+		{err, "%q", `"909301: user not found"`},
+		{unregistered, "%v", fmt.Sprintf("%d: %s", unknownCoder.Code(), unknownCoder.String())},
+		{WithCode(909303, "quota exceeded"), "%s", "quota at 100% of the limit"},
+	}
+
+	for _, tt := range tests {
+		if got := fmt.Sprintf(tt.format, tt.err); got != tt.want {
+			t.Errorf("Sprintf(%q): got %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestCodeFormatTrace(t *testing.T) {
+	err := Wrapc(WithCode(909301, "user 7 missing"), 909302, "load profile")
+	got := fmt.Sprintf("%+v", err)
+
+	for _, want := range []string{
+		"load profile - #1",
+		"(909302) lookup failed",
+		"user 7 missing - #0",
+		"(909301) user not found",
+		"TestCodeFormatTrace\n\t",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%%+v: got %q, want it to contain %q", got, want)
+		}
+	}
+
+	restore := NewConfig(CaptureStacks(false)).Apply()
+	defer restore()
+	if got := fmt.Sprintf("%+v", Wrapc(io.EOF, 909302, "read")); strings.Contains(got, "\n") {
+		t.Errorf("%%+v without stacks: got %q, want a single line per layer", got)
+	}
+}
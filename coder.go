@@ -0,0 +1,300 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Severity is the importance of an error.
+type Severity int
+
+// Severity levels, from the least to the most important.
+const (
+	SeverityDebug Severity = iota + 1
+	SeverityInfo
+	SeverityWarn
+	SeverityError
+	SeverityFatal
+)
+
+// String returns the name of the severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityDebug:
+		return "debug"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarn:
+		return "warn"
+	case SeverityError:
+		return "error"
+	case SeverityFatal:
+		return "fatal"
+	}
+	return "unspecified"
+}
+
+// Condition is a well-known failure condition a code stands for, which
+// callers can test for without knowing the code, see IsUnimplemented.
+type Condition string
+
+// Known conditions.
+const (
+	// ConditionUnimplemented marks code paths which are not implemented,
+	// usually mapped to http.StatusNotImplemented.
+	ConditionUnimplemented Condition = "unimplemented"
+
+	// ConditionFeatureDisabled marks features disabled by a feature flag,
+	// usually mapped to http.StatusForbidden.
+	ConditionFeatureDisabled Condition = "feature_disabled"
+
+	// ConditionInvariantViolated marks violations of invariants, conditions
+	// which should never happen, usually mapped to
+	// http.StatusInternalServerError.
+	ConditionInvariantViolated Condition = "invariant_violated"
+
+	// ConditionPanicked marks the errors recovered from panics, see
+	// FromPanic.
+	ConditionPanicked Condition = "panicked"
+)
+
+// CoderOption configures the optional metadata of a Coder built by NewCoder.
+type CoderOption func(*coder)
+
+// WithSeverity sets the severity of the coder.
+func WithSeverity(severity Severity) CoderOption {
+	return func(c *coder) { c.severity = severity }
+}
+
+// WithDomain sets the domain of the coder, e.g. the owning service.
+func WithDomain(domain string) CoderOption {
+	return func(c *coder) { c.domain = domain }
+}
+
+// WithRetryable marks the errors of the coder as retryable.
+func WithRetryable(retryable bool) CoderOption {
+	return func(c *coder) { c.retryable = retryable }
+}
+
+// WithRetryAfter sets how long clients should wait before retrying the
+// errors of the coder.
+func WithRetryAfter(d time.Duration) CoderOption {
+	return func(c *coder) { c.retryAfter = d }
+}
+
+// WithDedupWindow marks the errors of the coder as duplicates of the
+// identical errors reported to the same client less than d ago, which a
+// Deduplicator suppresses.
+func WithDedupWindow(d time.Duration) CoderOption {
+	return func(c *coder) { c.dedupWindow = d }
+}
+
+// WithSlug sets the string identifier of the code, such as
+// "user.not_found", exposed in the JSON encodings alongside the number,
+// which client developers find easier to handle. The registered coders can
+// be looked up by slug, see CoderBySlug and IsSlug.
+func WithSlug(slug string) CoderOption {
+	return func(c *coder) { c.slug = slug }
+}
+
+// WithOwner sets the owner responsible for the code, a team slug or an
+// email address. Owners are required at registration in strict mode, see
+// SetStrictMode.
+func WithOwner(owner string) CoderOption {
+	return func(c *coder) { c.owner = owner }
+}
+
+// WithAPIVersions sets the API versions in which the code is available:
+// from introduced up to, but excluding, retired. A zero bound is open.
+func WithAPIVersions(introduced, retired int) CoderOption {
+	return func(c *coder) { c.introduced, c.retired = introduced, retired }
+}
+
+// WithFallback sets the code to report instead in the API versions where
+// the code is not available, see CoderForVersion.
+func WithFallback(code int) CoderOption {
+	return func(c *coder) { c.fallback = code }
+}
+
+// WithRemediation sets the ordered steps users or operators should follow to
+// fix the errors of the coder.
+func WithRemediation(steps ...string) CoderOption {
+	return func(c *coder) { c.remediation = append([]string(nil), steps...) }
+}
+
+// WithCondition sets the failure condition the code stands for.
+func WithCondition(condition Condition) CoderOption {
+	return func(c *coder) { c.condition = condition }
+}
+
+// WithBlame sets the party blamed for the errors of the coder, see BlameOf.
+func WithBlame(b Blame) CoderOption {
+	return func(c *coder) { c.blame = b }
+}
+
+// WithMessages sets the messages of the coder in other languages, by BCP 47
+// tag such as "fr" or "pt-BR", see StringL.
+func WithMessages(messages map[string]string) CoderOption {
+	return func(c *coder) {
+		c.messages = make(map[string]string, len(messages))
+		for lang, msg := range messages {
+			c.messages[strings.ToLower(lang)] = msg
+		}
+	}
+}
+
+// NewCoder returns an immutable Coder.
+// A zero httpStatus maps to http.StatusInternalServerError.
+func NewCoder(code, httpStatus int, msg, ref string, opts ...CoderOption) Coder {
+	c := coder{
+		code:       code,
+		httpStatus: httpStatus,
+		msg:        msg,
+		ref:        ref,
+	}
+	if c.httpStatus == 0 {
+		c.httpStatus = http.StatusInternalServerError
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// coder is the Coder returned by NewCoder. Its fields are only set at
+// construction time.
+type coder struct {
+	code        int
+	httpStatus  int
+	msg         string
+	ref         string
+	severity    Severity
+	domain      string
+	retryable   bool
+	retryAfter  time.Duration
+	dedupWindow time.Duration
+	owner       string
+	slug        string
+	introduced  int
+	retired     int
+	fallback    int
+	remediation []string
+	condition   Condition
+	blame       Blame
+	class       Class
+	category    Category
+	messages    map[string]string
+}
+
+// HTTPStatus should be used for the associated error code.
+func (c coder) HTTPStatus() int { return c.httpStatus }
+
+// String returns external (user) facing error text.
+func (c coder) String() string { return c.msg }
+
+// StringL returns the external (user) facing error text in the given
+// language. For lang, then for the fallback language, the messages set by
+// WithMessages are looked up first, then the translations registered with
+// RegisterTranslation, see Message. The text returned by String is used
+// when none matches.
+func (c coder) StringL(lang string) string {
+	for _, l := range []string{lang, fallbackLanguage} {
+		if msg, ok := lookupLanguage(c.messages, l); ok {
+			return msg
+		}
+		if msg, ok := translation(c.code, l); ok {
+			return msg
+		}
+	}
+	return c.msg
+}
+
+// Messages returns the messages of the coder in other languages.
+func (c coder) Messages() map[string]string {
+	if len(c.messages) == 0 {
+		return nil
+	}
+	ret := make(map[string]string, len(c.messages))
+	for lang, msg := range c.messages {
+		ret[lang] = msg
+	}
+	return ret
+}
+
+// Reference returns the detail documents for user, or the reference given
+// by the reference template if none was set, see SetReferenceTemplate.
+func (c coder) Reference() string {
+	if c.ref == "" {
+		return expandReference(c.code, c.slug)
+	}
+	return c.ref
+}
+
+// Code returns the code of the coder.
+func (c coder) Code() int { return c.code }
+
+// Severity returns the severity of the coder's errors.
+func (c coder) Severity() Severity { return c.severity }
+
+// Domain returns the domain of the coder.
+func (c coder) Domain() string { return c.domain }
+
+// Retryable reports whether the coder's errors are retryable.
+func (c coder) Retryable() bool { return c.retryable }
+
+// RetryAfter returns how long clients should wait before retrying.
+func (c coder) RetryAfter() time.Duration { return c.retryAfter }
+
+// DedupWindow returns the window within which identical errors reported to
+// the same client are duplicates.
+func (c coder) DedupWindow() time.Duration { return c.dedupWindow }
+
+// Owner returns the owner responsible for the code, see WithOwner.
+func (c coder) Owner() string { return c.owner }
+
+// Slug returns the string identifier of the code, see WithSlug.
+func (c coder) Slug() string { return c.slug }
+
+// APIVersions returns the API versions in which the code is available.
+func (c coder) APIVersions() (introduced, retired int) { return c.introduced, c.retired }
+
+// Fallback returns the code reported in the API versions where the code is
+// not available, or 0.
+func (c coder) Fallback() int { return c.fallback }
+
+// Remediation returns the steps to follow to fix the coder's errors.
+func (c coder) Remediation() []string { return append([]string(nil), c.remediation...) }
+
+// Condition returns the failure condition the code stands for.
+func (c coder) Condition() Condition { return c.condition }
+
+// Blame returns the party blamed for the coder's errors.
+func (c coder) Blame() Blame { return c.blame }
+
+// Class returns the class of the cause of the coder's errors.
+func (c coder) Class() Class { return c.class }
+
+// Category returns the category of the coder's errors.
+func (c coder) Category() Category { return c.category }
+
+// Remediation returns the remediation steps of the Coder of err, see
+// WithRemediation, or nil if it has none.
+func Remediation(err error) []string {
+	steps, _ := RemediationOf(ParseCoder(err))
+	return steps
+}
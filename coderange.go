@@ -0,0 +1,92 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// codeRange is a block of codes reserved by RegisterRange.
+type codeRange struct {
+	min, max int
+	owner    string
+}
+
+// ranges contains the reserved code blocks, guarded by codeMux.
+var ranges []codeRange
+
+// RegisterRange reserves the codes from min to max included for owner, the
+// import path of a package or of a module. The codes of the block can then
+// only be registered by the packages of owner, that is owner itself and
+// the packages below it, so that teams sharing a repository do not collide
+// on code numbers:
+//
+//	func init() {
+//		errors.RegisterRange(120000, 120999, "example.com/platform/billing")
+//	}
+//
+// The registration functions panic when a code lands in the block of
+// another owner. RegisterRange panics if min is greater than max or if the
+// block overlaps the block of another owner.
+func RegisterRange(min, max int, owner string) {
+	if min > max {
+		panic(fmt.Sprintf("invalid code range [%d, %d]", min, max))
+	}
+
+	codeMux.Lock()
+	defer codeMux.Unlock()
+
+	for _, r := range ranges {
+		if r.owner != owner && min <= r.max && r.min <= max {
+			panic(fmt.Sprintf("code range [%d, %d] of %s overlaps range [%d, %d] of %s",
+				min, max, owner, r.min, r.max, r.owner))
+		}
+	}
+	ranges = append(ranges, codeRange{min: min, max: max, owner: owner})
+}
+
+// RangeOwner returns the owner of the block reserving code, see
+// RegisterRange.
+func RangeOwner(code int) (string, bool) {
+	codeMux.RLock()
+	defer codeMux.RUnlock()
+
+	return rangeOwner(code)
+}
+
+func rangeOwner(code int) (string, bool) {
+	for _, r := range ranges {
+		if r.min <= code && code <= r.max {
+			return r.owner, true
+		}
+	}
+	return "", false
+}
+
+// checkRange returns an error if code belongs to the block of an owner
+// other than the package of the function at pc. The caller must hold
+// codeMux.
+func checkRange(code int, pc uintptr) *RegistrationError {
+	owner, ok := rangeOwner(code)
+	if !ok || pc == 0 {
+		return nil
+	}
+	pkg := funcPackage(Frame(pc).name())
+	if pkg == owner || strings.HasPrefix(pkg, owner+"/") {
+		return nil
+	}
+	return &RegistrationError{Code: code, Err: ErrCodeOwned, Detail: fmt.Sprintf("reserved by %s, not %s", owner, pkg)}
+}
@@ -0,0 +1,59 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRegisterRange(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	RegisterRange(907400, 907449, "github.com/rtmzk/errors")
+	RegisterRange(907450, 907499, "example.com/other")
+
+	if owner, ok := RangeOwner(907460); !ok || owner != "example.com/other" {
+		t.Errorf("RangeOwner(907460): got %q, %v, want %q", owner, ok, "example.com/other")
+	}
+	if _, ok := RangeOwner(907500); ok {
+		t.Errorf("RangeOwner(907500): got true, want false")
+	}
+
+	tests := []struct {
+		name     string
+		register func()
+		panics   string
+	}{
+		{"own range", func() { MustRegister(NewCoder(907401, 400, "own", "")) }, ""},
+		{"no range", func() { Register(NewCoder(907501, 400, "free", "")) }, ""},
+		{"other range", func() { MustRegister(NewCoder(907451, 400, "other", "")) }, "reserved by example.com/other"},
+		{"other range, Register", func() { Register(NewCoder(907452, 400, "other", "")) }, "reserved by example.com/other"},
+		{"other range, table", func() {
+			MustRegisterTable([]CoderSpec{{Code: 907402, Message: "own"}, {Code: 907453, Message: "other"}})
+		}, "#1: code 907453 is reserved by example.com/other"},
+		{"overlap", func() { RegisterRange(907440, 907460, "example.com/third") }, "overlaps range [907400, 907449]"},
+		{"same owner", func() { RegisterRange(907440, 907445, "github.com/rtmzk/errors") }, ""},
+		{"inverted", func() { RegisterRange(2, 1, "example.com/third") }, "invalid code range"},
+	}
+
+	for _, tt := range tests {
+		got := panicMessage(tt.register)
+		if tt.panics == "" && got != "" || !strings.Contains(got, tt.panics) {
+			t.Errorf("%s: got panic %q, want %q", tt.name, got, tt.panics)
+		}
+	}
+	if IsRegistered(907402) {
+		t.Errorf("MustRegisterTable: registered 907402 of a rejected table")
+	}
+}
+
+func panicMessage(f func()) (msg string) {
+	defer func() {
+		if r := recover(); r != nil {
+			msg = fmt.Sprint(r)
+		}
+	}()
+	f()
+	return ""
+}
@@ -0,0 +1,160 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import "net/http"
+
+// CoderBuilder builds a Coder step by step, as an alternative to the
+// positional arguments of NewCoder:
+//
+//	var ErrUserNotFound = errors.BuildCoder(100201).
+//		HTTP(http.StatusNotFound).
+//		Message("user not found").
+//		Ref("https://example.com/errors/100201").
+//		MustRegister()
+//
+// A CoderBuilder is not safe for concurrent use.
+type CoderBuilder struct {
+	c coder
+}
+
+// BuildCoder returns a CoderBuilder of a Coder with the given code.
+func BuildCoder(code int) *CoderBuilder {
+	return &CoderBuilder{c: coder{code: code}}
+}
+
+// HTTP sets the HTTP status of the Coder, http.StatusInternalServerError
+// if unset.
+func (b *CoderBuilder) HTTP(status int) *CoderBuilder {
+	b.c.httpStatus = status
+	return b
+}
+
+// Message sets the external message of the Coder.
+func (b *CoderBuilder) Message(msg string) *CoderBuilder {
+	b.c.msg = msg
+	return b
+}
+
+// Ref sets the reference document of the Coder.
+func (b *CoderBuilder) Ref(ref string) *CoderBuilder {
+	b.c.ref = ref
+	return b
+}
+
+// With applies the options to the Coder, such as WithSeverity.
+func (b *CoderBuilder) With(opts ...CoderOption) *CoderBuilder {
+	for _, opt := range opts {
+		opt(&b.c)
+	}
+	return b
+}
+
+// Coder returns the Coder built so far.
+func (b *CoderBuilder) Coder() Coder {
+	c := b.c
+	if c.httpStatus == 0 {
+		c.httpStatus = http.StatusInternalServerError
+	}
+	return c
+}
+
+// Register registers the Coder like Register and returns it.
+func (b *CoderBuilder) Register() Coder {
+	coder := b.Coder()
+	if err := registerAt(coder, callerPC(), false, false); err != nil {
+		panic(err.panicMessage(false))
+	}
+	return coder
+}
+
+// MustRegister registers the Coder like MustRegister and returns it.
+func (b *CoderBuilder) MustRegister() Coder {
+	coder := b.Coder()
+	if err := registerAt(coder, callerPC(), true, false); err != nil {
+		panic(err.panicMessage(true))
+	}
+	return coder
+}
+
+// RegisterE registers the Coder like RegisterE and returns it.
+func (b *CoderBuilder) RegisterE() (Coder, error) {
+	coder := b.Coder()
+	if err := registerAt(coder, callerPC(), true, true); err != nil {
+		return nil, err
+	}
+	return coder, nil
+}
+
+// WithCoderCode sets the code of the Coder, see Derive.
+func WithCoderCode(code int) CoderOption {
+	return func(c *coder) { c.code = code }
+}
+
+// WithHTTPStatus sets the HTTP status of the Coder, see Derive.
+func WithHTTPStatus(status int) CoderOption {
+	return func(c *coder) { c.httpStatus = status }
+}
+
+// WithExternalMessage sets the external message of the Coder, see Derive.
+func WithExternalMessage(msg string) CoderOption {
+	return func(c *coder) { c.msg = msg }
+}
+
+// WithReference sets the reference document of the Coder, see Derive.
+func WithReference(ref string) CoderOption {
+	return func(c *coder) { c.ref = ref }
+}
+
+// Derive returns a Coder with the code, HTTP status, message, reference and
+// optional metadata of base, changed by the overrides, so that families of
+// related codes share their definition:
+//
+//	base := errors.NewCoder(100300, http.StatusBadRequest, "invalid request", "",
+//		errors.WithOwner("team-api"), errors.WithCategory(errors.CategoryClient))
+//	errors.MustRegister(errors.Derive(base, errors.WithCoderCode(100301),
+//		errors.WithExternalMessage("invalid email address")))
+//
+// The metadata of a base Coder not built by NewCoder are read through its
+// optional methods, see CoderInfo.
+func Derive(base Coder, overrides ...CoderOption) Coder {
+	c, ok := base.(coder)
+	if !ok {
+		info := newCoderInfo(base)
+		var opts []CoderOption
+		if m, ok := base.(interface{ Messages() map[string]string }); ok {
+			opts = append(opts, WithMessages(m.Messages()))
+		}
+		// The durations of info are formatted by newCoderInfo, so that
+		// they always parse.
+		derived, _ := info.coder(opts...)
+		c = derived.(coder)
+	}
+	c.remediation = append([]string(nil), c.remediation...)
+	if c.messages != nil {
+		messages := make(map[string]string, len(c.messages))
+		for lang, msg := range c.messages {
+			messages[lang] = msg
+		}
+		c.messages = messages
+	}
+	for _, opt := range overrides {
+		opt(&c)
+	}
+	if c.httpStatus == 0 {
+		c.httpStatus = http.StatusInternalServerError
+	}
+	return c
+}
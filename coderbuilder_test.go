@@ -0,0 +1,60 @@
+package errors
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestCoderBuilder(t *testing.T) {
+	c := BuildCoder(912801).
+		HTTP(http.StatusNotFound).
+		Message("user not found").
+		Ref("https://example.com/912801").
+		With(WithSlug("builder.user_not_found"), WithOwner("team-a")).
+		MustRegister()
+
+	want := NewCoder(912801, http.StatusNotFound, "user not found", "https://example.com/912801",
+		WithSlug("builder.user_not_found"), WithOwner("team-a"))
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("MustRegister(): got %#v, want %#v", c, want)
+	}
+	if got, ok := lookupCoder(912801); !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("registered coder: got %#v, want %#v", got, want)
+	}
+
+	if got := BuildCoder(912802).Message("boom").Coder().HTTPStatus(); got != http.StatusInternalServerError {
+		t.Errorf("default HTTP status: got %d, want %d", got, http.StatusInternalServerError)
+	}
+	if _, err := BuildCoder(912803).HTTP(http.StatusOK * 10).Message("x").RegisterE(); err == nil {
+		t.Errorf("RegisterE() with an invalid status: got nil, want an error")
+	}
+}
+
+func TestDerive(t *testing.T) {
+	base := NewCoder(912810, http.StatusBadRequest, "invalid request", "https://example.com/912810",
+		WithOwner("team-a"), WithCategory(CategoryClient), WithMessages(map[string]string{"fr": "requête invalide"}))
+	tests := []struct {
+		base      Coder
+		overrides []CoderOption
+		want      Coder
+	}{
+		{base, []CoderOption{WithCoderCode(912811), WithExternalMessage("invalid email")},
+			NewCoder(912811, http.StatusBadRequest, "invalid email", "https://example.com/912810",
+				WithOwner("team-a"), WithCategory(CategoryClient), WithMessages(map[string]string{"fr": "requête invalide"}))},
+		{base, []CoderOption{WithCoderCode(912812), WithHTTPStatus(http.StatusConflict), WithReference(""), WithOwner("team-b")},
+			NewCoder(912812, http.StatusConflict, "invalid request", "",
+				WithOwner("team-b"), WithCategory(CategoryClient), WithMessages(map[string]string{"fr": "requête invalide"}))},
+		{defaultCoder{C: 912813, HTTP: 404, Ext: "missing"}, []CoderOption{WithCoderCode(912814)},
+			NewCoder(912814, http.StatusNotFound, "missing", "")},
+	}
+
+	for i, tt := range tests {
+		if got := Derive(tt.base, tt.overrides...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("test %d: got %#v, want %#v", i+1, got, tt.want)
+		}
+	}
+	if base.Code() != 912810 || base.HTTPStatus() != http.StatusBadRequest {
+		t.Errorf("Derive() changed the base: got %#v", base)
+	}
+}
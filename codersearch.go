@@ -0,0 +1,62 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import "sync/atomic"
+
+// CoderSearch selects the coded error of a chain whose Coder ParseCoder
+// returns, see SetCoderSearch.
+type CoderSearch int32
+
+const (
+	// SearchOutermost resolves the outermost coded error of the chain
+	// whose code resolves, the default: the code set last wins.
+	SearchOutermost CoderSearch = iota
+
+	// SearchDeepest resolves the innermost coded error of the chain whose
+	// code resolves: the code of the root cause wins.
+	SearchDeepest
+)
+
+// coderSearch is the CoderSearch of ParseCoder.
+var coderSearch int32
+
+// SetCoderSearch sets which coded error of a chain ParseCoder resolves,
+// SearchOutermost by default.
+func SetCoderSearch(search CoderSearch) {
+	atomic.StoreInt32(&coderSearch, int32(search))
+}
+
+func coderSearchNow() CoderSearch {
+	return CoderSearch(atomic.LoadInt32(&coderSearch))
+}
+
+// ParseCoderAll returns the Coders of every coded error of err's chain,
+// outermost first, descending into the members of multi-errors like
+// IsCode. Each code is reported once. ParseCoderAll returns nil if the
+// chain carries no code which resolves, see ParseCoder.
+func ParseCoderAll(err error) []Coder {
+	ensureCatalogs()
+	var ret []Coder
+	seen := map[int]bool{}
+	walk(err, func(e error) bool {
+		if coder, ok := resolveCoder(e); ok && !seen[coder.Code()] {
+			seen[coder.Code()] = true
+			ret = append(ret, coder)
+		}
+		return false
+	})
+	return ret
+}
@@ -0,0 +1,63 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func init() {
+	Register(NewCoder(913601, http.StatusNotFound, "row not found", ""))
+	Register(NewCoder(913602, http.StatusBadGateway, "store failed", ""))
+}
+
+func TestCoderSearch(t *testing.T) {
+	inner := WithCode(913601, "no row")
+	outer := Wrapc(fmt.Errorf("query: %w", inner), 913602, "load")
+	unregistered := Wrapc(Wrap(inner, "read"), 913699, "unregistered")
+
+	tests := []struct {
+		search CoderSearch
+		err    error
+		want   int
+	}{
+		{SearchOutermost, Wrap(inner, "read"), 913601},
+		{SearchOutermost, outer, 913602},
+		{SearchOutermost, unregistered, 913601},
+		{SearchDeepest, outer, 913601},
+		{SearchDeepest, Wrap(WithCode(913699, "unregistered"), "read"), unknownCoder.Code()},
+		{SearchDeepest, io.EOF, unknownCoder.Code()},
+	}
+
+	for i, tt := range tests {
+		restore := NewConfig(UseCoderSearch(tt.search)).Apply()
+		got := ParseCoder(tt.err).Code()
+		restore()
+		if got != tt.want {
+			t.Errorf("test %d: ParseCoder(): got %d, want %d", i+1, got, tt.want)
+		}
+	}
+}
+
+func TestParseCoderAll(t *testing.T) {
+	inner := WithCode(913601, "no row")
+	tests := []struct {
+		err  error
+		want []int
+	}{
+		{Wrapc(Wrap(inner, "read"), 913602, "load"), []int{913602, 913601}},
+		{Join(Wrapc(inner, 913602, "a"), WithCode(913602, "b"), WithCode(913699, "c")), []int{913602, 913601}},
+		{io.EOF, nil},
+	}
+
+	for i, tt := range tests {
+		var got []int
+		for _, coder := range ParseCoderAll(tt.err) {
+			got = append(got, coder.Code())
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("test %d: ParseCoderAll(): got %v, want %v", i+1, got, tt.want)
+		}
+	}
+}
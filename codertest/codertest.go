@@ -0,0 +1,167 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package codertest provides a conformance suite for the implementations
+// of errors.Coder, so that library authors defining their own coder types
+// can check that they behave like the coders of the errors package:
+//
+//	func TestCoder(t *testing.T) {
+//		codertest.Run(t, myCoder{code: 120001})
+//	}
+package codertest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/rtmzk/errors"
+)
+
+// check is a single conformance check, returning the violation found, or
+// nil.
+type check struct {
+	name string
+	fn   func(coder errors.Coder) error
+}
+
+// checks are the conformance checks run by Run, in order.
+var checks = []check{
+	{"Code", checkCode},
+	{"HTTPStatus", checkHTTPStatus},
+	{"String", checkString},
+	{"Stable", checkStable},
+	{"Concurrent", checkConcurrent},
+	{"Messages", checkMessages},
+}
+
+// Run checks that coder conforms to the expectations of the errors
+// package, each check in its own subtest:
+//
+//   - Code: the code is not 0, which is reserved for the unknown code
+//   - HTTPStatus: the HTTP status is in [100, 599]
+//   - String: the external message is not empty
+//   - Stable: every method returns the same value on every call
+//   - Concurrent: the methods are safe to call concurrently, which the
+//     race detector verifies
+//   - Messages: the localized messages, if any, are not empty
+func Run(t *testing.T, coder errors.Coder) {
+	t.Helper()
+	if coder == nil {
+		t.Fatal("coder is nil")
+	}
+	for _, c := range checks {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			if err := c.fn(coder); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func checkCode(coder errors.Coder) error {
+	if coder.Code() == 0 {
+		return fmt.Errorf("Code: got 0, which is reserved for the unknown code")
+	}
+	return nil
+}
+
+func checkHTTPStatus(coder errors.Coder) error {
+	if status := coder.HTTPStatus(); status < 100 || status > 599 {
+		return fmt.Errorf("HTTPStatus: got %d, want a status in [100, 599]", status)
+	}
+	return nil
+}
+
+func checkString(coder errors.Coder) error {
+	if coder.String() == "" {
+		return fmt.Errorf("String: got an empty message")
+	}
+	return nil
+}
+
+// snapshot holds the values returned by the methods of a Coder.
+type snapshot struct {
+	code       int
+	httpStatus int
+	str        string
+	ref        string
+}
+
+func snapshotOf(coder errors.Coder) snapshot {
+	return snapshot{
+		code:       coder.Code(),
+		httpStatus: coder.HTTPStatus(),
+		str:        coder.String(),
+		ref:        coder.Reference(),
+	}
+}
+
+func (s snapshot) diff(other snapshot) error {
+	switch {
+	case s.code != other.code:
+		return fmt.Errorf("Code: got %d, then %d", s.code, other.code)
+	case s.httpStatus != other.httpStatus:
+		return fmt.Errorf("HTTPStatus: got %d, then %d", s.httpStatus, other.httpStatus)
+	case s.str != other.str:
+		return fmt.Errorf("String: got %q, then %q", s.str, other.str)
+	case s.ref != other.ref:
+		return fmt.Errorf("Reference: got %q, then %q", s.ref, other.ref)
+	}
+	return nil
+}
+
+func checkStable(coder errors.Coder) error {
+	first := snapshotOf(coder)
+	for i := 0; i < 3; i++ {
+		if err := first.diff(snapshotOf(coder)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// concurrency is the number of goroutines of checkConcurrent.
+const concurrency = 8
+
+func checkConcurrent(coder errors.Coder) error {
+	first := snapshotOf(coder)
+	errs := make([]error, concurrency)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = first.diff(snapshotOf(coder))
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkMessages(coder errors.Coder) error {
+	msgs, _ := errors.MessagesOf(coder)
+	for lang, msg := range msgs {
+		if msg == "" {
+			return fmt.Errorf("Messages: got an empty message for language %q", lang)
+		}
+	}
+	return nil
+}
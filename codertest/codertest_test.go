@@ -0,0 +1,72 @@
+package codertest
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rtmzk/errors"
+)
+
+// unstableCoder returns a different message on every call.
+type unstableCoder struct {
+	calls *int64
+}
+
+func (c unstableCoder) Code() int         { return 909001 }
+func (c unstableCoder) HTTPStatus() int   { return 500 }
+func (c unstableCoder) Reference() string { return "" }
+func (c unstableCoder) String() string {
+	if atomic.AddInt64(c.calls, 1)%2 == 0 {
+		return "even"
+	}
+	return "odd"
+}
+
+// rawCoder returns the values it is built with.
+type rawCoder struct {
+	code, status int
+	msg          string
+}
+
+func (c rawCoder) Code() int         { return c.code }
+func (c rawCoder) HTTPStatus() int   { return c.status }
+func (c rawCoder) String() string    { return c.msg }
+func (c rawCoder) Reference() string { return "" }
+
+func TestRun(t *testing.T) {
+	Run(t, errors.NewCoder(909002, 404, "not found", "https://example.com/909002",
+		errors.WithMessages(map[string]string{"fr": "introuvable"})))
+	Run(t, rawCoder{code: 909003, status: 499, msg: "client closed request"})
+}
+
+func TestChecks(t *testing.T) {
+	tests := []struct {
+		coder errors.Coder
+		want  map[string]string
+	}{
+		{rawCoder{909004, 400, "bad request"}, nil},
+		{rawCoder{0, 400, "bad request"}, map[string]string{"Code": "reserved"}},
+		{rawCoder{909005, 0, "bad request"}, map[string]string{"HTTPStatus": "got 0"}},
+		{rawCoder{909006, 600, "bad request"}, map[string]string{"HTTPStatus": "got 600"}},
+		{rawCoder{909007, 400, ""}, map[string]string{"String": "empty"}},
+		{unstableCoder{calls: new(int64)}, map[string]string{"Stable": "String"}},
+		{
+			errors.NewCoder(909008, 400, "bad request", "", errors.WithMessages(map[string]string{"fr": ""})),
+			map[string]string{"Messages": `"fr"`},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, c := range checks {
+			err := c.fn(tt.coder)
+			want, failing := tt.want[c.name]
+			switch {
+			case !failing && err != nil && c.name != "Concurrent":
+				t.Errorf("%s(%v): got %v, want nil", c.name, tt.coder, err)
+			case failing && (err == nil || !strings.Contains(err.Error(), want)):
+				t.Errorf("%s(%v): got %v, want an error containing %q", c.name, tt.coder, err, want)
+			}
+		}
+	}
+}
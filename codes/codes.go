@@ -0,0 +1,93 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package codes registers a standard set of coders for the common failures
+// of services, so that small services can use coded errors without
+// defining codes of their own:
+//
+//	import "github.com/rtmzk/errors/codes"
+//
+//	return errors.WithCode(codes.NotFound, "user %s not found", id)
+//
+// The codes are stable, and reserved for this package in the block
+// 100000-100999, see errors.RegisterRange. Their last three digits are the
+// HTTP status they map to.
+package codes
+
+import "github.com/rtmzk/errors"
+
+// Owner is the owner of the code block of this package.
+const Owner = "github.com/rtmzk/errors/codes"
+
+// The code block reserved for this package.
+const (
+	MinCode = 100000
+	MaxCode = 100999
+)
+
+// Standard codes.
+const (
+	// BadRequest is the code of malformed or invalid requests.
+	BadRequest = 100400
+
+	// Unauthorized is the code of requests lacking valid credentials.
+	Unauthorized = 100401
+
+	// Forbidden is the code of requests the caller is not allowed to make.
+	Forbidden = 100403
+
+	// NotFound is the code of requests for missing resources.
+	NotFound = 100404
+
+	// Conflict is the code of requests conflicting with the current state
+	// of a resource.
+	Conflict = 100409
+
+	// TooManyRequests is the code of requests rejected by rate limits.
+	TooManyRequests = 100429
+
+	// Internal is the code of unexpected server failures.
+	Internal = 100500
+
+	// BadGateway is the code of failures of an upstream server, such as
+	// refused connections or invalid responses.
+	BadGateway = 100502
+
+	// Timeout is the code of operations which did not complete in time.
+	Timeout = 100504
+)
+
+func init() {
+	errors.RegisterRange(MinCode, MaxCode, Owner)
+	errors.MustRegisterTable([]errors.CoderSpec{
+		{Code: BadRequest, HTTPStatus: 400, Message: "The request is invalid",
+			Options: []errors.CoderOption{errors.WithClass(errors.ClassValidation), errors.WithBlame(errors.BlameUser)}},
+		{Code: Unauthorized, HTTPStatus: 401, Message: "Authentication is required",
+			Options: []errors.CoderOption{errors.WithClass(errors.ClassAuthz), errors.WithBlame(errors.BlameUser)}},
+		{Code: Forbidden, HTTPStatus: 403, Message: "The request is not allowed",
+			Options: []errors.CoderOption{errors.WithClass(errors.ClassAuthz), errors.WithBlame(errors.BlameUser)}},
+		{Code: NotFound, HTTPStatus: 404, Message: "The resource was not found",
+			Options: []errors.CoderOption{errors.WithBlame(errors.BlameUser)}},
+		{Code: Conflict, HTTPStatus: 409, Message: "The request conflicts with the state of the resource",
+			Options: []errors.CoderOption{errors.WithClass(errors.ClassConflict), errors.WithBlame(errors.BlameUser)}},
+		{Code: TooManyRequests, HTTPStatus: 429, Message: "Too many requests",
+			Options: []errors.CoderOption{errors.WithClass(errors.ClassCapacity), errors.WithRetryable(true)}},
+		{Code: Internal, HTTPStatus: 500, Message: "An internal server error occurred",
+			Options: []errors.CoderOption{errors.WithSeverity(errors.SeverityError), errors.WithBlame(errors.BlameSystem)}},
+		{Code: BadGateway, HTTPStatus: 502, Message: "The upstream server failed",
+			Options: []errors.CoderOption{errors.WithClass(errors.ClassConnectivity), errors.WithRetryable(true)}},
+		{Code: Timeout, HTTPStatus: 504, Message: "The operation timed out",
+			Options: []errors.CoderOption{errors.WithClass(errors.ClassTimeout), errors.WithRetryable(true)}},
+	})
+}
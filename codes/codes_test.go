@@ -0,0 +1,48 @@
+package codes
+
+import (
+	"testing"
+
+	"github.com/rtmzk/errors"
+)
+
+func TestCodes(t *testing.T) {
+	tests := []struct {
+		code      int
+		status    int
+		retryable bool
+	}{
+		{BadRequest, 400, false},
+		{Unauthorized, 401, false},
+		{Forbidden, 403, false},
+		{NotFound, 404, false},
+		{Conflict, 409, false},
+		{TooManyRequests, 429, true},
+		{Internal, 500, false},
+		{BadGateway, 502, true},
+		{Timeout, 504, true},
+	}
+
+	for _, tt := range tests {
+		if !errors.IsRegistered(tt.code) {
+			t.Errorf("IsRegistered(%d): got false, want true", tt.code)
+			continue
+		}
+		coder := errors.ParseCoder(errors.WithCode(tt.code, "failed"))
+		if got := coder.HTTPStatus(); got != tt.status {
+			t.Errorf("HTTPStatus(%d): got %d, want %d", tt.code, got, tt.status)
+		}
+		if got, want := tt.code%1000, tt.status; got != want {
+			t.Errorf("code %d: got status digits %d, want %d", tt.code, got, want)
+		}
+		if coder.String() == "" {
+			t.Errorf("String(%d): got empty message", tt.code)
+		}
+		if got, _ := errors.RetryableOf(coder); got != tt.retryable {
+			t.Errorf("RetryableOf(%d): got %v, want %v", tt.code, got, tt.retryable)
+		}
+		if owner, _ := errors.RangeOwner(tt.code); owner != Owner {
+			t.Errorf("RangeOwner(%d): got %q, want %q", tt.code, owner, Owner)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import "net/http"
+
+// Combine returns an Aggregate holding the non-nil errs, like Join, for
+// batch APIs summarizing the errors of their items in one response. The
+// members of the Aggregates among errs are flattened into it, and
+// ParseCoder resolves it to the Coder of the member with the most severe
+// HTTP status, see DominantHTTPStatus, whatever the dominant Coder set
+// with SetDominantCoder.
+// If all errs are nil, Combine returns nil.
+func Combine(errs ...error) error {
+	var flat []error
+	for _, err := range errs {
+		if agg, ok := err.(Aggregate); ok {
+			if f := Flatten(agg); f != nil {
+				flat = append(flat, f.Errors()...)
+			}
+			continue
+		}
+		if err != nil {
+			flat = append(flat, err)
+		}
+	}
+	if len(flat) == 0 {
+		return nil
+	}
+	return combined{aggregate(flat)}
+}
+
+// combined is the Aggregate returned by Combine.
+type combined struct {
+	aggregate
+}
+
+// DominantHTTPStatus selects the Coder of the member with the most severe
+// HTTP status: a server error over a client error over any other status,
+// and the first of them on ties.
+func DominantHTTPStatus(coders []Coder) Coder {
+	dominant := coders[0]
+	max := statusRank(dominant.HTTPStatus())
+	for _, coder := range coders[1:] {
+		if rank := statusRank(coder.HTTPStatus()); rank > max {
+			dominant, max = coder, rank
+		}
+	}
+	return dominant
+}
+
+// statusRank orders the HTTP statuses by severity for DominantHTTPStatus.
+func statusRank(status int) int {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return 2
+	case status >= http.StatusBadRequest:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,49 @@
+package errors
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+func init() {
+	Register(NewCoder(913001, http.StatusBadRequest, "invalid item", ""))
+	Register(NewCoder(913002, http.StatusNotFound, "missing item", ""))
+	Register(NewCoder(913003, http.StatusServiceUnavailable, "store unavailable", ""))
+	Register(NewCoder(913004, http.StatusOK, "partial", ""))
+}
+
+func TestCombine(t *testing.T) {
+	invalid := WithCode(913001, "bad id")
+	missing := WithCode(913002, "no item 2")
+	down := WithCode(913003, "store down")
+
+	tests := []struct {
+		err     error
+		code    int
+		members int
+	}{
+		{Combine(invalid, nil, missing), 913001, 2},
+		{Combine(io.EOF, missing, Combine(invalid, down)), 913003, 4},
+		{Combine(WithCode(913004, "partial"), Join(missing, nil)), 913002, 2},
+		{Combine(io.EOF, nil), unknownCoder.Code(), 1},
+	}
+
+	for i, tt := range tests {
+		if got := ParseCoder(tt.err).Code(); got != tt.code {
+			t.Errorf("test %d: ParseCoder(): got %d, want %d", i+1, got, tt.code)
+		}
+		if got := len(tt.err.(Aggregate).Errors()); got != tt.members {
+			t.Errorf("test %d: members: got %d, want %d", i+1, got, tt.members)
+		}
+	}
+
+	restore := NewConfig(UseDominantCoder(DominantFirst)).Apply()
+	defer restore()
+	if got := ParseCoder(Wrap(Combine(missing, down), "batch")).Code(); got != 913003 {
+		t.Errorf("ParseCoder(wrapped): got %d, want %d", got, 913003)
+	}
+	if got := Combine(nil, nil); got != nil {
+		t.Errorf("Combine(nil, nil): got %v, want nil", got)
+	}
+}
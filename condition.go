@@ -0,0 +1,82 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// FieldFeature is the field naming the unimplemented or disabled feature.
+const FieldFeature = "feature"
+
+// NewUnimplementedCoder returns a Coder for code paths which are not
+// implemented yet, mapped to http.StatusNotImplemented.
+func NewUnimplementedCoder(code int, msg, ref string, opts ...CoderOption) Coder {
+	opts = append(opts, WithCondition(ConditionUnimplemented))
+	return NewCoder(code, http.StatusNotImplemented, msg, ref, opts...)
+}
+
+// NewFeatureDisabledCoder returns a Coder for features disabled by a
+// feature flag, mapped to http.StatusForbidden.
+func NewFeatureDisabledCoder(code int, msg, ref string, opts ...CoderOption) Coder {
+	opts = append(opts, WithCondition(ConditionFeatureDisabled))
+	return NewCoder(code, http.StatusForbidden, msg, ref, opts...)
+}
+
+// Unimplemented returns an error with the given code, usually registered
+// with NewUnimplementedCoder, reporting that feature is not implemented.
+// The feature is recorded in the FieldFeature field.
+func Unimplemented(code int, feature string) error {
+	st := codeCallers(code)
+	return newConditionError(code, feature+" is not implemented", feature, st, stackPC(st))
+}
+
+// FeatureDisabled returns an error with the given code, usually registered
+// with NewFeatureDisabledCoder, reporting that feature is disabled.
+// The feature is recorded in the FieldFeature field.
+func FeatureDisabled(code int, feature string) error {
+	st := codeCallers(code)
+	return newConditionError(code, feature+" is disabled", feature, st, stackPC(st))
+}
+
+func newConditionError(code int, msg, feature string, st *stack, pc uintptr) error {
+	fields := map[string]interface{}{FieldFeature: feature}
+	return created(newCoded(code, fmt.Errorf("%s", msg), nil, fields, st, pc))
+}
+
+// IsUnimplemented reports whether a coded layer of err's chain has a Coder
+// standing for ConditionUnimplemented.
+func IsUnimplemented(err error) bool {
+	return hasCondition(err, ConditionUnimplemented)
+}
+
+// IsFeatureDisabled reports whether a coded layer of err's chain has a
+// Coder standing for ConditionFeatureDisabled.
+func IsFeatureDisabled(err error) bool {
+	return hasCondition(err, ConditionFeatureDisabled)
+}
+
+func hasCondition(err error, condition Condition) bool {
+	for _, e := range list(err) {
+		if _, ok := e.(CodeError); !ok {
+			continue
+		}
+		if c, ok := ConditionOf(ParseCoder(e)); ok && c == condition {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package errors
+
+import "testing"
+
+func TestConditions(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewUnimplementedCoder(903901, "not implemented", ""))
+	MustRegister(NewFeatureDisabledCoder(903902, "feature disabled", ""))
+	MustRegister(NewCoder(903903, 500, "internal", ""))
+
+	tests := []struct {
+		err           error
+		status        int
+		unimplemented bool
+		disabled      bool
+	}{
+		{Unimplemented(903901, "export"), 501, true, false},
+		{FeatureDisabled(903902, "beta-search"), 403, false, true},
+		{Wrap(FeatureDisabled(903902, "beta-search"), "search"), 0, false, true},
+		{WithCode(903903, "boom"), 500, false, false},
+		{New("boom"), 500, false, false},
+	}
+
+	for _, tt := range tests {
+		if got := ParseCoder(tt.err).HTTPStatus(); tt.status != 0 && got != tt.status {
+			t.Errorf("%v: got status %d, want %d", tt.err, got, tt.status)
+		}
+		if got := IsUnimplemented(tt.err); got != tt.unimplemented {
+			t.Errorf("IsUnimplemented(%v): got %v, want %v", tt.err, got, tt.unimplemented)
+		}
+		if got := IsFeatureDisabled(tt.err); got != tt.disabled {
+			t.Errorf("IsFeatureDisabled(%v): got %v, want %v", tt.err, got, tt.disabled)
+		}
+	}
+
+	if got := Fields(FeatureDisabled(903902, "beta-search"))[FieldFeature]; got != "beta-search" {
+		t.Errorf("FeatureDisabled: got feature %v, want %q", got, "beta-search")
+	}
+}
@@ -0,0 +1,473 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// Config holds the package wide settings, so that applications configure
+// the package in one place.
+//
+// A Config is built from the current settings with NewConfig, changed with
+// ConfigOptions and made effective with Apply:
+//
+//	restore := errors.NewConfig(errors.TrackUsage(false)).Apply()
+//	defer restore()
+type Config struct {
+	registry     RegistryBackend
+	unknownCoder Coder
+	unknownCode  func(code int) Coder
+	abortCoder   Coder
+	timeoutCoder Coder
+	trackUsage   bool
+	logLevels    map[int]slog.Level
+	stacker      Stacker
+	environment  map[string]interface{}
+	strictMode   StrictMode
+	expvar       bool
+	clock        Clock
+	entropy      io.Reader
+	maxFields    int
+	overflow     OverflowPolicy
+	fallbackLang string
+	refTemplate  string
+	pseudo       bool
+	stacksJSON   bool
+	uncodedHook  func(UncodedEvent)
+	dominant     DominantFunc
+	warning      string
+	stacks       bool
+	stackDepth   int
+	stackSkip    int
+	stackBudget  int
+	stackDedup   bool
+	stackPolicy  func(code int) bool
+	stackSample  float64
+	ranges       []codeRange
+	aliases      map[int]int
+	coderSearch  CoderSearch
+	deprecation  bool
+	redaction    bool
+	debugRing    bool
+	reporters    []namedReporter
+	stackFilters []StackFilter
+	errorHook    func(code int, err error)
+	extractors   []contextExtractor
+	renderHook   func(RenderFailure)
+	enrichers    []Enricher
+	frozen       bool
+	audit        bool
+	health       time.Duration
+	sealed       bool
+}
+
+// ConfigOption changes a setting of a Config.
+type ConfigOption func(*Config)
+
+// UseRegistry sets the registry backend holding the registered coders.
+// Unlike SetRegistryBackend, the coders registered so far are not copied
+// into it, which allows tests to work on an isolated registry.
+func UseRegistry(backend RegistryBackend) ConfigOption {
+	return func(c *Config) { c.registry = backend }
+}
+
+// UseUnknownCoder sets the Coder of the errors without a registered code.
+func UseUnknownCoder(coder Coder) ConfigOption {
+	return func(c *Config) { c.unknownCoder = coder }
+}
+
+// UseUnknownCodeFunc sets the function mapping the unregistered codes to a
+// Coder, like SetUnknownCodeFunc.
+func UseUnknownCodeFunc(fn func(code int) Coder) ConfigOption {
+	return func(c *Config) { c.unknownCode = fn }
+}
+
+// UseClientAbortCoder sets the Coder of the client aborts and canceled
+// contexts without a registered code, see IsClientAbort and
+// FromContextErr.
+func UseClientAbortCoder(coder Coder) ConfigOption {
+	return func(c *Config) { c.abortCoder = coder }
+}
+
+// UseTimeoutCoder sets the Coder of the context deadlines without a
+// registered code, see FromContextErr.
+func UseTimeoutCoder(coder Coder) ConfigOption {
+	return func(c *Config) { c.timeoutCoder = coder }
+}
+
+// TrackUsage enables or disables the usage counters of CodeUsage.
+func TrackUsage(enabled bool) ConfigOption {
+	return func(c *Config) { c.trackUsage = enabled }
+}
+
+// OverrideLogLevel overrides the log level of the given code, like
+// SetLogLevel.
+func OverrideLogLevel(code int, level slog.Level) ConfigOption {
+	return func(c *Config) { c.logLevels[code] = level }
+}
+
+// UseStacker sets the Stacker recording the stacks of new errors, like
+// SetStacker.
+func UseStacker(s Stacker) ConfigOption {
+	return func(c *Config) { c.stacker = s }
+}
+
+// StampEnvironment sets the environment fields stamped on new errors, like
+// SetEnvironment.
+func StampEnvironment(fields map[string]interface{}) ConfigOption {
+	return func(c *Config) { c.environment = fields }
+}
+
+// UseStrictMode sets what happens on unregistered codes, like
+// SetStrictMode.
+func UseStrictMode(mode StrictMode) ConfigOption {
+	return func(c *Config) { c.strictMode = mode }
+}
+
+// PublishExpvar enables or disables the publication of the usage counters
+// through expvar, like SetExpvar.
+func PublishExpvar(enabled bool) ConfigOption {
+	return func(c *Config) { c.expvar = enabled }
+}
+
+// UseClock sets the Clock of the package, like SetClock.
+func UseClock(c Clock) ConfigOption {
+	return func(cfg *Config) { cfg.clock = c }
+}
+
+// UseEntropy sets the source of randomness of the package, like
+// SetEntropy.
+func UseEntropy(r io.Reader) ConfigOption {
+	return func(c *Config) { c.entropy = r }
+}
+
+// LimitFields bounds the number of fields of a layer, like SetMaxFields.
+func LimitFields(max int, policy OverflowPolicy) ConfigOption {
+	return func(c *Config) { c.maxFields, c.overflow = max, policy }
+}
+
+// UseFallbackLanguage sets the language of the messages which are not
+// translated in the requested one, like SetFallbackLanguage.
+func UseFallbackLanguage(lang string) ConfigOption {
+	return func(c *Config) { c.fallbackLang = lang }
+}
+
+// UseCoderSearch sets which coded error of a chain ParseCoder resolves,
+// like SetCoderSearch.
+func UseCoderSearch(search CoderSearch) ConfigOption {
+	return func(c *Config) { c.coderSearch = search }
+}
+
+// WarnDeprecatedCodes enables or disables the warnings logged when errors
+// with a deprecated code are resolved, like SetDeprecationWarnings.
+func WarnDeprecatedCodes(enabled bool) ConfigOption {
+	return func(c *Config) { c.deprecation = enabled }
+}
+
+// UseReferenceTemplate sets the template of the references of the coders
+// without one, like SetReferenceTemplate.
+func UseReferenceTemplate(tmpl string) ConfigOption {
+	return func(c *Config) { c.refTemplate = tmpl }
+}
+
+// PseudoLocalize enables or disables the pseudo-localization of messages,
+// like SetPseudoLocalization.
+func PseudoLocalize(enabled bool) ConfigOption {
+	return func(c *Config) { c.pseudo = enabled }
+}
+
+// MarshalStacks enables or disables the stack trace in the JSON encoding of
+// coded errors, like SetMarshalStacks.
+func MarshalStacks(enabled bool) ConfigOption {
+	return func(c *Config) { c.stacksJSON = enabled }
+}
+
+// OnUncoded sets the function called for every error served with the
+// unknown coder, like SetUncodedHook.
+func OnUncoded(fn func(UncodedEvent)) ConfigOption {
+	return func(c *Config) { c.uncodedHook = fn }
+}
+
+// OnError sets the function called with every coded error created, like
+// SetErrorHook.
+func OnError(fn func(code int, err error)) ConfigOption {
+	return func(c *Config) { c.errorHook = fn }
+}
+
+// OnRenderFailure sets the function called every time rendering an error
+// panics, like SetRenderFailureHook.
+func OnRenderFailure(fn func(RenderFailure)) ConfigOption {
+	return func(c *Config) { c.renderHook = fn }
+}
+
+// AuditConversions enables or disables the recording of the call sites
+// converting uncoded errors, like SetConversionAudit.
+func AuditConversions(enabled bool) ConfigOption {
+	return func(c *Config) { c.audit = enabled }
+}
+
+// EnrichWith adds an enricher run when errors are served, like
+// AddEnricher.
+func EnrichWith(e Enricher) ConfigOption {
+	return func(c *Config) {
+		if e == nil {
+			return
+		}
+		c.enrichers = append(c.enrichers[:len(c.enrichers):len(c.enrichers)], e)
+	}
+}
+
+// ExtractContext adds an extractor of the fields attached to the errors
+// created by NewWithContext and WrapC, like AddContextExtractor.
+func ExtractContext(field string, x ContextExtractor) ConfigOption {
+	return func(c *Config) {
+		if x == nil {
+			return
+		}
+		c.extractors = append(c.extractors[:len(c.extractors):len(c.extractors)], contextExtractor{field: field, extract: x})
+	}
+}
+
+// UseDominantCoder sets the function selecting the Coder of multi-errors,
+// like SetDominantCoder.
+func UseDominantCoder(fn DominantFunc) ConfigOption {
+	return func(c *Config) { c.dominant = fn }
+}
+
+// UseWarningHeader sets the name of the header carrying warnings, like
+// SetWarningHeader.
+func UseWarningHeader(name string) ConfigOption {
+	return func(c *Config) { c.warning = name }
+}
+
+// CaptureStacks enables or disables the capture of the stacks of new
+// errors, like SetCaptureStacks.
+func CaptureStacks(enabled bool) ConfigOption {
+	return func(c *Config) { c.stacks = enabled }
+}
+
+// LimitStacks sets the maximum depth of the stacks of new errors and the
+// number of frames skipped, like SetStackDepth and SetStackSkip.
+func LimitStacks(depth, skip int) ConfigOption {
+	return func(c *Config) { c.stackDepth, c.stackSkip = depth, skip }
+}
+
+// SealInternalDetails guarantees that the internal parts of errors are
+// never served, like SetSealInternal.
+func SealInternalDetails(enabled bool) ConfigOption {
+	return func(c *Config) { c.sealed = enabled }
+}
+
+// UseHealthTimeout sets the timeout of the checks of Healthy, like
+// SetHealthTimeout.
+func UseHealthTimeout(d time.Duration) ConfigOption {
+	return func(c *Config) { c.health = d }
+}
+
+// BudgetStacks sets the number of full stacks recorded per second, like
+// SetStackBudget.
+func BudgetStacks(perSecond int) ConfigOption {
+	return func(c *Config) { c.stackBudget = perSecond }
+}
+
+// UseStackPolicy sets the function selecting the codes whose errors record
+// a stack, like SetStackPolicyFunc.
+func UseStackPolicy(fn func(code int) bool) ConfigOption {
+	return func(c *Config) { c.stackPolicy = fn }
+}
+
+// SampleStacks sets the fraction of the coded errors of each code which
+// record a stack, like SetStackSampling.
+func SampleStacks(rate float64) ConfigOption {
+	return func(c *Config) { c.stackSample = rate }
+}
+
+// DedupStacks enables or disables the deduplication of the stacks of
+// wrapped errors, like SetStackDedup.
+func DedupStacks(enabled bool) ConfigOption {
+	return func(c *Config) { c.stackDedup = enabled }
+}
+
+// RedactSecrets enables or disables the redaction of the secret arguments,
+// like SetRedaction.
+func RedactSecrets(enabled bool) ConfigOption {
+	return func(c *Config) { c.redaction = enabled }
+}
+
+// RecordDebugRing enables or disables the debug ring buffer, like
+// SetDebugRing.
+func RecordDebugRing(enabled bool) ConfigOption {
+	return func(c *Config) { c.debugRing = enabled }
+}
+
+// FilterStacks sets the filters stripping frames from the printed stack
+// traces, replacing those added with AddStackFilter.
+func FilterStacks(filters ...StackFilter) ConfigOption {
+	return func(c *Config) { c.stackFilters = filters }
+}
+
+// NewConfig returns a Config holding the current settings changed by opts.
+func NewConfig(opts ...ConfigOption) *Config {
+	c := currentConfig()
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Apply makes c the current settings of the package. It returns a function
+// restoring the settings which were current before. The registry backend is
+// restored by reference: the codes registered in it meanwhile stay
+// registered, so tests registering codes apply UseRegistry(MapBackend())
+// to discard them with the restore.
+// Apply is meant to be called at program start, or around tests: each
+// setting is replaced safely, but errors created concurrently may observe a
+// mix of the previous and the new settings.
+func (c *Config) Apply() (restore func()) {
+	prev := currentConfig()
+	c.apply()
+	return prev.apply
+}
+
+func currentConfig() *Config {
+	c := &Config{
+		registry:     codes,
+		unknownCoder: unknownCoder,
+		unknownCode:  unknownCodeNow(),
+		abortCoder:   clientAbortCoder,
+		timeoutCoder: timeoutCoder,
+		trackUsage:   trackUsageNow(),
+		logLevels:    map[int]slog.Level{},
+		stacker:      stacker,
+		environment:  environment,
+		strictMode:   strictMode,
+		expvar:       expvarEnabledNow(),
+		clock:        clockNow(),
+		entropy:      entropyNow(),
+		maxFields:    fieldLimitNow().max,
+		overflow:     fieldLimitNow().policy,
+		fallbackLang: fallbackLanguage,
+		refTemplate:  referenceTemplateNow(),
+		pseudo:       pseudoLocalize,
+		stacksJSON:   marshalStacks,
+		uncodedHook:  uncodedHookNow(),
+		dominant:     dominant,
+		warning:      warningHeader,
+		stacks:       captureStacksNow(),
+		stackDepth:   stackDepth,
+		stackSkip:    stackSkip,
+		stackBudget:  stackBudgetNow(),
+		stackDedup:   stackDedupNow(),
+		stackPolicy:  stackPolicyNow(),
+		stackSample:  stackSamplingNow(),
+		ranges:       ranges,
+		aliases:      aliasesNow(),
+		coderSearch:  coderSearchNow(),
+		deprecation:  deprecationWarningsNow(),
+		redaction:    redactionNow(),
+		debugRing:    debugRingNow(),
+		reporters:    reportersNow(),
+		stackFilters: stackFiltersNow(),
+		errorHook:    errorHookNow(),
+		extractors:   contextExtractorsNow(),
+		renderHook:   renderHookNow(),
+		enrichers:    enrichersNow(),
+		frozen:       Frozen(),
+		audit:        conversionAuditNow(),
+		health:       healthTimeoutNow(),
+		sealed:       sealInternalNow(),
+	}
+	logLevels.Range(func(k, v interface{}) bool {
+		c.logLevels[k.(int)] = v.(slog.Level)
+		return true
+	})
+	return c
+}
+
+func (c *Config) apply() {
+	codeMux.Lock()
+	var freeze int32
+	if c.frozen {
+		freeze = 1
+	}
+	atomic.StoreInt32(&frozen, freeze)
+	codes = c.registry
+	ranges = c.ranges
+	clientAbortCoder = c.abortCoder
+	timeoutCoder = c.timeoutCoder
+	installUnknownCoder(unknownCoder, c.unknownCoder)
+	reindexSlugs()
+	codeMux.Unlock()
+
+	setTrackUsage(c.trackUsage)
+	SetUnknownCodeFunc(c.unknownCode)
+	SetStacker(c.stacker)
+	SetEnvironment(c.environment)
+	SetStrictMode(c.strictMode)
+	SetExpvar(c.expvar)
+	SetClock(c.clock)
+	SetEntropy(c.entropy)
+	SetMaxFields(c.maxFields, c.overflow)
+	aliasMux.Lock()
+	aliases.Store(c.aliases)
+	aliasMux.Unlock()
+	SetDeprecationWarnings(c.deprecation)
+	SetCoderSearch(c.coderSearch)
+	SetFallbackLanguage(c.fallbackLang)
+	SetReferenceTemplate(c.refTemplate)
+	SetPseudoLocalization(c.pseudo)
+	SetMarshalStacks(c.stacksJSON)
+	SetUncodedHook(c.uncodedHook)
+	SetDominantCoder(c.dominant)
+	SetWarningHeader(c.warning)
+	SetCaptureStacks(c.stacks)
+	SetStackDepth(c.stackDepth)
+	SetStackSkip(c.stackSkip)
+	SetStackBudget(c.stackBudget)
+	SetStackDedup(c.stackDedup)
+	SetStackPolicyFunc(c.stackPolicy)
+	SetStackSampling(c.stackSample)
+	SetRedaction(c.redaction)
+	SetDebugRing(c.debugRing)
+	reportersMux.Lock()
+	reporters.Store(c.reporters)
+	reportersMux.Unlock()
+	stackFiltersMux.Lock()
+	stackFilters.Store(c.stackFilters)
+	stackFiltersMux.Unlock()
+	SetErrorHook(c.errorHook)
+	contextExtractorsMux.Lock()
+	contextExtractors.Store(c.extractors)
+	contextExtractorsMux.Unlock()
+	SetRenderFailureHook(c.renderHook)
+	enrichersMux.Lock()
+	enrichers.Store(c.enrichers)
+	enrichersMux.Unlock()
+	SetConversionAudit(c.audit)
+	SetHealthTimeout(c.health)
+	SetSealInternal(c.sealed)
+
+	logLevels.Range(func(k, _ interface{}) bool {
+		logLevels.Delete(k)
+		return true
+	})
+	for code, level := range c.logLevels {
+		logLevels.Store(code, level)
+	}
+}
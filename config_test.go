@@ -0,0 +1,70 @@
+package errors
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestConfigApply(t *testing.T) {
+	Register(defaultCoder{C: 901401, HTTP: 404})
+
+	unknown := NewCoder(901499, 500, "something went wrong", "")
+	restore := NewConfig(
+		UseRegistry(MapBackend()),
+		UseUnknownCoder(unknown),
+		TrackUsage(false),
+		OverrideLogLevel(901402, slog.LevelWarn),
+	).Apply()
+
+	if got := ParseCoder(WithCode(901401, "isolated")).Code(); got != 901499 {
+		t.Errorf("ParseCoder with an isolated registry: got %d, want %d", got, 901499)
+	}
+	if got := ParseCoder(New("uncoded")).String(); got != "something went wrong" {
+		t.Errorf("ParseCoder with a custom unknown coder: got %q", got)
+	}
+	Register(defaultCoder{C: 901402, HTTP: 500})
+	if got := LogLevel(WithCode(901402, "overridden")); got != slog.LevelWarn {
+		t.Errorf("LogLevel with an override: got %v, want %v", got, slog.LevelWarn)
+	}
+	if got := CodeUsage(901402); got != 0 {
+		t.Errorf("CodeUsage with usage tracking disabled: got %d, want 0", got)
+	}
+
+	restore()
+
+	if got := ParseCoder(WithCode(901401, "restored")).Code(); got != 901401 {
+		t.Errorf("ParseCoder after restore: got %d, want %d", got, 901401)
+	}
+	if got := ParseCoder(WithCode(901402, "not registered")).Code(); got != 1 {
+		t.Errorf("ParseCoder after restore: got %d, want %d", got, 1)
+	}
+	if got := LogLevel(WithCode(901401, "restored")); got != slog.LevelInfo {
+		t.Errorf("LogLevel after restore: got %v, want %v", got, slog.LevelInfo)
+	}
+}
+
+func TestConfigApplyConcurrent(t *testing.T) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_ = WithCode(901401, "concurrent")
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		NewConfig(TrackUsage(i%2 == 0)).Apply()()
+	}
+	<-done
+}
+
+func TestConfigApplyKeepsRegistrations(t *testing.T) {
+	outer := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer outer()
+
+	restore := NewConfig().Apply()
+	Register(defaultCoder{C: 901403, HTTP: 409})
+	restore()
+	if !IsRegistered(901403) {
+		t.Errorf("IsRegistered(901403) after restore: got false, want the registry contents kept")
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package connecterr carries the errors of github.com/rtmzk/errors over
+// connect-go, in all its protocols including the web one. It is kept apart
+// from the errors package so that the latter does not depend on connect.
+package connecterr
+
+import (
+	"connectrpc.com/connect"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+
+	"github.com/rtmzk/errors"
+	"github.com/rtmzk/errors/grpcerr"
+)
+
+// Domain is the domain of the ErrorInfo details describing coded errors,
+// shared with the grpcerr package.
+const Domain = grpcerr.Domain
+
+// Metadata keys of the ErrorInfo details describing coded errors.
+const (
+	MetadataReference  = grpcerr.MetadataReference
+	MetadataHTTPStatus = grpcerr.MetadataHTTPStatus
+	MetadataDomain     = grpcerr.MetadataDomain
+)
+
+// NewError converts err into a connect error. The connect code is derived
+// from the HTTP status of the Coder of err, and the code, reference and
+// domain of the Coder travel in an ErrorInfo detail whose Reason is the
+// code, so that FromError restores them on the client. The field
+// violations of err are attached as a BadRequest detail.
+// If err is nil, NewError returns nil.
+func NewError(err error) *connect.Error {
+	if err == nil {
+		return nil
+	}
+
+	coder := errors.ServedCoder(err)
+	msg := coder.String()
+	if msg == "" {
+		msg = err.Error()
+	}
+	cerr := connect.NewError(Code(coder.HTTPStatus()), errors.New(msg))
+	if d, derr := connect.NewErrorDetail(grpcerr.ErrorInfo(coder)); derr == nil {
+		cerr.AddDetail(d)
+	}
+	if violations := errors.FieldViolations(err); len(violations) > 0 {
+		pb := grpcerr.BadRequestToProto(errors.BadRequest{FieldViolations: violations})
+		if d, derr := connect.NewErrorDetail(pb); derr == nil {
+			cerr.AddDetail(d)
+		}
+	}
+	return cerr
+}
+
+// FromError converts a connect error received by a client back into a coded
+// error, using the ErrorInfo detail attached by NewError. Errors which are
+// not connect errors or which carry no such detail are returned unchanged.
+func FromError(err error) error {
+	cerr, ok := asConnectError(err)
+	if !ok {
+		return err
+	}
+
+	code := 0
+	var violations []errors.FieldViolation
+	for _, d := range cerr.Details() {
+		v, verr := d.Value()
+		if verr != nil {
+			continue
+		}
+		switch pb := v.(type) {
+		case *errdetails.ErrorInfo:
+			if n, ok := grpcerr.CodeFromErrorInfo(pb); ok {
+				code = n
+			}
+		case *errdetails.BadRequest:
+			violations = append(violations, grpcerr.BadRequestFromProto(pb).FieldViolations...)
+		}
+	}
+	if code == 0 {
+		return err
+	}
+
+	coded := errors.WithCode(code, "%s", cerr.Message())
+	if len(violations) > 0 {
+		coded = errors.WithDetails(coded, errors.BadRequest{FieldViolations: violations})
+	}
+	return coded
+}
+
+func asConnectError(err error) (*connect.Error, bool) {
+	var cerr *connect.Error
+	if !errors.As(err, &cerr) {
+		return nil, false
+	}
+	return cerr, true
+}
+
+// Code returns the connect code matching an HTTP status, following the
+// mapping of the gRPC HTTP gateway. Connect codes share the values of the
+// gRPC ones, see grpcerr.Code.
+func Code(httpStatus int) connect.Code {
+	return connect.Code(grpcerr.Code(httpStatus))
+}
@@ -0,0 +1,65 @@
+package connecterr
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"connectrpc.com/connect"
+
+	"github.com/rtmzk/errors"
+)
+
+func init() {
+	errors.Register(errors.NewCoder(904301, 400, "invalid user", "https://example.com/904301", errors.WithDomain("users")))
+	errors.Register(errors.NewCoder(904302, 503, "try again", ""))
+}
+
+func TestRoundTrip(t *testing.T) {
+	violations := []errors.FieldViolation{{Field: "email", Description: "malformed"}}
+	err := errors.WithDetails(errors.WithCode(904301, "bad email %q", "x"),
+		errors.BadRequest{FieldViolations: violations})
+
+	cerr := NewError(err)
+	if cerr.Code() != connect.CodeInvalidArgument || cerr.Message() != "invalid user" {
+		t.Errorf("NewError(): got (%v, %q), want (%v, %q)", cerr.Code(), cerr.Message(), connect.CodeInvalidArgument, "invalid user")
+	}
+
+	got := FromError(cerr)
+	if !errors.IsCode(got, 904301) {
+		t.Errorf("FromError(): got code %d, want 904301", errors.ParseCoder(got).Code())
+	}
+	if v := errors.FieldViolations(got); !reflect.DeepEqual(v, violations) {
+		t.Errorf("FromError(): got field violations %v, want %v", v, violations)
+	}
+}
+
+func TestFromErrorPassthrough(t *testing.T) {
+	plain := connect.NewError(connect.CodeUnavailable, io.EOF)
+	tests := []error{nil, io.EOF, plain}
+	for _, err := range tests {
+		if got := FromError(err); got != err {
+			t.Errorf("FromError(%v): got %v, want it unchanged", err, got)
+		}
+	}
+	if NewError(nil) != nil {
+		t.Errorf("NewError(nil): got non-nil, want nil")
+	}
+}
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		status int
+		want   connect.Code
+	}{
+		{400, connect.CodeInvalidArgument},
+		{404, connect.CodeNotFound},
+		{503, connect.CodeUnavailable},
+		{418, connect.CodeUnknown},
+	}
+	for _, tt := range tests {
+		if got := Code(tt.status); got != tt.want {
+			t.Errorf("Code(%d): got %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"context"
+	"errors"
+)
+
+type defaultCodeKey struct{}
+
+// WithDefaultCode returns a copy of ctx carrying the code Ensure assigns to
+// uncoded errors, so that the errors produced by deep library layers get a
+// meaningful code at the request boundary instead of the unknown one.
+func WithDefaultCode(ctx context.Context, code int) context.Context {
+	return context.WithValue(ctx, defaultCodeKey{}, code)
+}
+
+// DefaultCode returns the code set on ctx by WithDefaultCode.
+func DefaultCode(ctx context.Context) (int, bool) {
+	code, ok := ctx.Value(defaultCodeKey{}).(int)
+	return code, ok
+}
+
+// Ensure returns err with the default code of ctx if err's chain carries no
+// code. The message of err is kept. Coded errors, nil errors and errors
+// returned without a default code on ctx are returned unchanged. The
+// conversions are recorded by the conversion audit, see SetConversionAudit.
+func Ensure(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := TopCode(err); ok {
+		return err
+	}
+	code, ok := DefaultCode(ctx)
+	if !ok {
+		return err
+	}
+
+	st := codeCallers(code)
+	pc := stackPC(st)
+	recordConversion(pc, code, err)
+	return created(newCoded(code, errors.New(err.Error()), err, pathFields(err), st, pc))
+}
@@ -0,0 +1,34 @@
+package errors
+
+import (
+	"context"
+	"io"
+	"testing"
+)
+
+func TestEnsure(t *testing.T) {
+	ctx := WithDefaultCode(context.Background(), 902301)
+
+	if got := Ensure(ctx, nil); got != nil {
+		t.Errorf("Ensure(nil): got %v, want nil", got)
+	}
+
+	coded := WithCode(902302, "coded")
+	if got := Ensure(ctx, coded); got != coded {
+		t.Errorf("Ensure(coded): got %v, want it unchanged", got)
+	}
+	if got := Ensure(context.Background(), io.EOF); got != io.EOF {
+		t.Errorf("Ensure without a default code: got %v, want it unchanged", got)
+	}
+
+	got := Ensure(ctx, Wrap(io.EOF, "read"))
+	if !IsCode(got, 902301) {
+		t.Errorf("Ensure(uncoded): got %v, want code 902301", got)
+	}
+	if got.Error() != "read: EOF" {
+		t.Errorf("Ensure(uncoded).Error(): got %q, want %q", got.Error(), "read: EOF")
+	}
+	if !Is(got, io.EOF) {
+		t.Errorf("Ensure(uncoded) lost its cause")
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// timeoutCoder is the Coder of the context deadlines carrying no registered
+// code. Its code 3 is not reserved: the errors of FromContextErr carry the
+// Coder itself, which tells them apart from those of a registered code 3.
+var timeoutCoder = NewCoder(3, http.StatusGatewayTimeout, "The request timed out", "", WithClass(ClassTimeout))
+
+// FromContextErr returns err, typically the error of a done context, coded
+// after the context error of its chain: the timeout coder, of status 504,
+// for context.DeadlineExceeded and the client abort coder, of status 499,
+// for context.Canceled, see UseTimeoutCoder and UseClientAbortCoder. The
+// message of the coded error is the message of the Coder and err is kept as
+// its cause, so that Is still matches the context error:
+//
+//	if err := ctx.Err(); err != nil {
+//		return errors.FromContextErr(err)
+//	}
+//
+// The errors already carrying a code and the errors holding no context
+// error are returned unchanged. If err is nil, FromContextErr returns nil.
+// ParseCoder resolves the uncoded context errors to the same coders.
+func FromContextErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := TopCode(err); ok {
+		return err
+	}
+	coder, ok := contextCoder(err)
+	if !ok {
+		return err
+	}
+
+	var st *stack
+	if !hasStack(err) {
+		st = codeCallers(coder.Code())
+	}
+	w := newCoded(coder.Code(), fmt.Errorf("%s", coder.String()), err, pathFields(err), st, stackPC(st))
+	w.remote = coder
+	return created(w)
+}
+
+// contextCoder returns the Coder of the context error of err's chain.
+func contextCoder(err error) (Coder, bool) {
+	switch {
+	case Is(err, context.DeadlineExceeded):
+		return timeoutCoder, true
+	case Is(err, context.Canceled):
+		return clientAbortCoder, true
+	}
+	return nil, false
+}
+
+// IsTimeout reports whether err's chain holds a timeout: a context deadline
+// exceeded, an error whose Timeout method reports one, such as the timeouts
+// of net.Error and os.ErrDeadlineExceeded, or an error coded by
+// FromContextErr for a deadline.
+func IsTimeout(err error) bool {
+	if err == nil {
+		return false
+	}
+	var timeout interface{ Timeout() bool }
+	return Is(err, context.DeadlineExceeded) || (As(err, &timeout) && timeout.Timeout()) ||
+		walk(err, func(e error) bool {
+			w, ok := e.(*withCode)
+			return ok && w.remote != nil && sameCoder(w.remote, timeoutCoder)
+		})
+}
+
+// IsCanceled reports whether err's chain holds a canceled context error.
+func IsCanceled(err error) bool {
+	return err != nil && Is(err, context.Canceled)
+}
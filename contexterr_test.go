@@ -0,0 +1,130 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func init() {
+	Register(NewCoder(914201, http.StatusServiceUnavailable, "store unavailable", ""))
+}
+
+func TestFromContextErr(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		code   int
+		status int
+		is     error
+	}{
+		{"deadline", context.DeadlineExceeded, 3, http.StatusGatewayTimeout, context.DeadlineExceeded},
+		{"canceled", context.Canceled, 2, StatusClientClosedRequest, context.Canceled},
+		{"wrapped", fmt.Errorf("query: %w", context.DeadlineExceeded), 3, http.StatusGatewayTimeout, context.DeadlineExceeded},
+		{"coded", Wrapc(context.DeadlineExceeded, 914201, "query"), 914201, http.StatusServiceUnavailable, context.DeadlineExceeded},
+		{"other", io.EOF, 1, http.StatusInternalServerError, io.EOF},
+	}
+	for _, tt := range tests {
+		err := FromContextErr(tt.err)
+		coder := ParseCoder(err)
+		if coder.Code() != tt.code || coder.HTTPStatus() != tt.status {
+			t.Errorf("%s: got %d %d, want %d %d", tt.name, coder.Code(), coder.HTTPStatus(), tt.code, tt.status)
+		}
+		if !Is(err, tt.is) {
+			t.Errorf("%s: Is(%v): got false, want true", tt.name, tt.is)
+		}
+	}
+
+	err := FromContextErr(context.DeadlineExceeded)
+	if code, ok := TopCode(err); !ok || code != 3 {
+		t.Errorf("TopCode(): got (%d, %v), want (3, true)", code, ok)
+	}
+	if got := err.Error(); got != "The request timed out" {
+		t.Errorf("Error(): got %q, want the message of the timeout coder", got)
+	}
+	if stackOf(err) == nil {
+		t.Errorf("FromContextErr(): got no stack, want one")
+	}
+	if FromContextErr(nil) != nil {
+		t.Errorf("FromContextErr(nil): got an error, want nil")
+	}
+}
+
+func TestContextErrorClassification(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"deadline", Wrap(context.DeadlineExceeded, "query"), http.StatusGatewayTimeout},
+		{"canceled", Wrap(ctx.Err(), "query"), StatusClientClosedRequest},
+	}
+	for _, tt := range tests {
+		if got := ParseCoder(tt.err).HTTPStatus(); got != tt.want {
+			t.Errorf("%s: ParseCoder(): got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUseTimeoutCoder(t *testing.T) {
+	custom := NewCoder(914202, http.StatusRequestTimeout, "Too slow", "")
+	restore := NewConfig(UseTimeoutCoder(custom)).Apply()
+	defer restore()
+
+	if got := ParseCoder(FromContextErr(context.DeadlineExceeded)).Code(); got != 914202 {
+		t.Errorf("ParseCoder(): got %d, want the custom timeout coder", got)
+	}
+}
+
+type timeoutError struct{ timeout bool }
+
+func (e timeoutError) Error() string { return "i/o" }
+func (e timeoutError) Timeout() bool { return e.timeout }
+
+func TestIsTimeout(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"deadline", context.DeadlineExceeded, true},
+		{"wrapped deadline", Wrap(context.DeadlineExceeded, "query"), true},
+		{"coded deadline", FromContextErr(context.DeadlineExceeded), true},
+		{"os deadline", fmt.Errorf("read: %w", os.ErrDeadlineExceeded), true},
+		{"net timeout", Wrap(timeoutError{true}, "dial"), true},
+		{"net failure", Wrap(timeoutError{false}, "dial"), false},
+		{"joined", Join(io.EOF, context.DeadlineExceeded), true},
+		{"canceled", context.Canceled, false},
+		{"code 3", WithCode(3, "quota exceeded"), false},
+	}
+	for _, tt := range tests {
+		if got := IsTimeout(tt.err); got != tt.want {
+			t.Errorf("%s: IsTimeout(): got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsCanceled(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"canceled", context.Canceled, true},
+		{"coded", FromContextErr(Wrap(context.Canceled, "query")), true},
+		{"deadline", context.DeadlineExceeded, false},
+	}
+	for _, tt := range tests {
+		if got := IsCanceled(tt.err); got != tt.want {
+			t.Errorf("%s: IsCanceled(): got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
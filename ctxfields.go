@@ -0,0 +1,125 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// ContextExtractor returns the value of a field taken from a context, such
+// as a request ID, and whether the context holds one.
+type ContextExtractor func(ctx context.Context) (interface{}, bool)
+
+type contextExtractor struct {
+	field   string
+	extract ContextExtractor
+}
+
+var (
+	contextExtractorsMux sync.Mutex
+
+	// contextExtractors holds the []contextExtractor of NewWithContext and
+	// WrapC. It is replaced on every change, so that the constructors read
+	// it without locking.
+	contextExtractors atomic.Value
+)
+
+// AddContextExtractor registers an extractor whose value is attached under
+// field to the errors created by NewWithContext and WrapC, so that errors
+// are correlated with their request without plumbing at every call site:
+//
+//	errors.AddContextExtractor("request_id", errors.ContextValue(requestIDKey{}))
+//	errors.AddContextExtractor("trace_id", func(ctx context.Context) (interface{}, bool) {
+//		sc := trace.SpanContextFromContext(ctx)
+//		return sc.TraceID().String(), sc.HasTraceID()
+//	})
+//
+// The extractors run in the order they were added; the value of a later
+// extractor wins when two of them share a field.
+func AddContextExtractor(field string, x ContextExtractor) {
+	if x == nil {
+		return
+	}
+	contextExtractorsMux.Lock()
+	defer contextExtractorsMux.Unlock()
+
+	prev := contextExtractorsNow()
+	updated := make([]contextExtractor, 0, len(prev)+1)
+	updated = append(updated, prev...)
+	updated = append(updated, contextExtractor{field: field, extract: x})
+	contextExtractors.Store(updated)
+}
+
+// ClearContextExtractors removes the extractors added with
+// AddContextExtractor.
+func ClearContextExtractors() {
+	contextExtractorsMux.Lock()
+	defer contextExtractorsMux.Unlock()
+	contextExtractors.Store([]contextExtractor(nil))
+}
+
+func contextExtractorsNow() []contextExtractor {
+	xs, _ := contextExtractors.Load().([]contextExtractor)
+	return xs
+}
+
+// ContextValue returns an extractor of the value stored on contexts under
+// key by context.WithValue. Nil values are skipped.
+func ContextValue(key interface{}) ContextExtractor {
+	return func(ctx context.Context) (interface{}, bool) {
+		v := ctx.Value(key)
+		return v, v != nil
+	}
+}
+
+// ContextFields returns the fields extracted from ctx by the registered
+// extractors, or nil if there are none.
+func ContextFields(ctx context.Context) map[string]interface{} {
+	if ctx == nil {
+		return nil
+	}
+	var ret map[string]interface{}
+	for _, x := range contextExtractorsNow() {
+		if v, ok := x.extract(ctx); ok {
+			if ret == nil {
+				ret = map[string]interface{}{}
+			}
+			ret[x.field] = v
+		}
+	}
+	return ret
+}
+
+// NewWithContext is like WithCode, with the fields extracted from ctx by
+// the registered extractors attached, see AddContextExtractor.
+func NewWithContext(ctx context.Context, code int, format string, args ...interface{}) error {
+	fields := limitFields(nil, ContextFields(ctx))
+	st := codeCallers(code)
+	return created(newCoded(code, errorf(format, args...), nil, fields, st, stackPC(st)))
+}
+
+// WrapC is like Wrapc, with the fields extracted from ctx by the registered
+// extractors attached, see AddContextExtractor.
+// If err is nil, WrapC returns nil.
+func WrapC(ctx context.Context, err error, code int, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	fields := limitFields(pathFields(err), ContextFields(ctx))
+	st := codeCallers(code)
+	return created(newCoded(code, errorf(format, args...), err, fields, st, stackPC(st)))
+}
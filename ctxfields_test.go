@@ -0,0 +1,78 @@
+package errors
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func init() {
+	Register(NewCoder(910301, 500, "storage failed", ""))
+}
+
+type requestIDKey struct{}
+
+type tenantKey struct{}
+
+func TestContextFields(t *testing.T) {
+	restore := NewConfig(
+		ExtractContext("request_id", ContextValue(requestIDKey{})),
+		ExtractContext("tenant", ContextValue(tenantKey{})),
+	).Apply()
+	defer restore()
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-1")
+	ctx = context.WithValue(ctx, tenantKey{}, "acme")
+
+	pathErr := &os.PathError{Op: "open", Path: "/etc/app.conf", Err: os.ErrNotExist}
+	tests := []struct {
+		name string
+		err  error
+		want map[string]interface{}
+	}{{
+		name: "new",
+		err:  NewWithContext(ctx, 910301, "load config"),
+		want: map[string]interface{}{"request_id": "req-1", "tenant": "acme"},
+	}, {
+		name: "wrap",
+		err:  WrapC(ctx, pathErr, 910301, "load config"),
+		want: map[string]interface{}{"request_id": "req-1", "tenant": "acme", "op": "open", "path": "/etc/app.conf"},
+	}, {
+		name: "missing values",
+		err:  NewWithContext(context.WithValue(context.Background(), tenantKey{}, "acme"), 910301, "load config"),
+		want: map[string]interface{}{"tenant": "acme"},
+	}, {
+		name: "no values",
+		err:  NewWithContext(context.Background(), 910301, "load config"),
+		want: nil,
+	}}
+
+	for _, tt := range tests {
+		if got := Fields(tt.err); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+		if got := tt.err.(*withCode).code; got != 910301 {
+			t.Errorf("%s: code: got %d, want %d", tt.name, got, 910301)
+		}
+	}
+
+	if got := WrapC(ctx, nil, 910301, "load config"); got != nil {
+		t.Errorf("WrapC(nil): got %v, want nil", got)
+	}
+}
+
+func TestClearContextExtractors(t *testing.T) {
+	restore := NewConfig().Apply()
+	defer restore()
+
+	AddContextExtractor("request_id", ContextValue(requestIDKey{}))
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-1")
+	if got := ContextFields(ctx); !reflect.DeepEqual(got, map[string]interface{}{"request_id": "req-1"}) {
+		t.Errorf("ContextFields: got %v, want request_id", got)
+	}
+	ClearContextExtractors()
+	if got := ContextFields(ctx); got != nil {
+		t.Errorf("ContextFields after clear: got %v, want nil", got)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DebugRingSize is the number of errors kept by the debug ring buffer.
+const DebugRingSize = 256
+
+// DebugEntry is an error recorded by the debug ring buffer.
+type DebugEntry struct {
+	// Time is when the error was served.
+	Time time.Time `json:"time"`
+
+	// Code is the code the error was served with.
+	Code int `json:"code"`
+
+	// Message is the message of the error.
+	Message string `json:"message"`
+
+	// Err is the error served.
+	Err error `json:"-"`
+}
+
+var (
+	debugRingEnabled int32
+	debugRingMux     sync.Mutex
+	debugRing        [DebugRingSize]DebugEntry
+	debugRingNext    int
+	debugRingLen     int
+)
+
+// SetDebugRing enables or disables the debug ring buffer, which records the
+// last DebugRingSize errors served to clients, see ServedCoder and
+// DebugErrors. It is disabled by default, and meant to be enabled while
+// debugging an incident, see SetVerbosity. The recorded errors are kept
+// when it is disabled.
+func SetDebugRing(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&debugRingEnabled, v)
+}
+
+func debugRingNow() bool {
+	return atomic.LoadInt32(&debugRingEnabled) == 1
+}
+
+// recordDebug records err served with coder in the debug ring buffer, if
+// it is enabled.
+func recordDebug(err error, coder Coder) {
+	if !debugRingNow() {
+		return
+	}
+	entry := DebugEntry{Time: now(), Code: coder.Code(), Message: err.Error(), Err: err}
+
+	debugRingMux.Lock()
+	defer debugRingMux.Unlock()
+	debugRing[debugRingNext] = entry
+	debugRingNext = (debugRingNext + 1) % DebugRingSize
+	if debugRingLen < DebugRingSize {
+		debugRingLen++
+	}
+}
+
+// DebugErrors returns the errors recorded by the debug ring buffer, oldest
+// first.
+func DebugErrors() []DebugEntry {
+	debugRingMux.Lock()
+	defer debugRingMux.Unlock()
+
+	ret := make([]DebugEntry, 0, debugRingLen)
+	start := (debugRingNext - debugRingLen + DebugRingSize) % DebugRingSize
+	for i := 0; i < debugRingLen; i++ {
+		ret = append(ret, debugRing[(start+i)%DebugRingSize])
+	}
+	return ret
+}
+
+// ResetDebugErrors removes the errors recorded by the debug ring buffer.
+func ResetDebugErrors() {
+	debugRingMux.Lock()
+	defer debugRingMux.Unlock()
+	debugRing = [DebugRingSize]DebugEntry{}
+	debugRingNext, debugRingLen = 0, 0
+}
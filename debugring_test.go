@@ -0,0 +1,48 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestDebugRing(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend()), RecordDebugRing(false)).Apply()
+	defer restore()
+	defer ResetDebugErrors()
+
+	MustRegister(NewCoder(907701, 503, "unavailable", ""))
+	ResetDebugErrors()
+
+	ServedCoder(WithCode(907701, "before"))
+	if got := len(DebugErrors()); got != 0 {
+		t.Errorf("disabled ring: got %d entries, want 0", got)
+	}
+
+	SetDebugRing(true)
+	ServedCoder(nil)
+	ServedCoder(WithCode(907701, "first"))
+	ServedCoder(io.EOF)
+	got := DebugErrors()
+	if len(got) != 2 {
+		t.Fatalf("DebugErrors(): got %d entries, want 2", len(got))
+	}
+	if got[0].Code != 907701 || got[0].Message != "first" {
+		t.Errorf("DebugErrors()[0]: got %d %q, want 907701 %q", got[0].Code, got[0].Message, "first")
+	}
+	if got[1].Code != unknownCoder.Code() || got[1].Err != io.EOF {
+		t.Errorf("DebugErrors()[1]: got %d %v, want %d EOF", got[1].Code, got[1].Err, unknownCoder.Code())
+	}
+
+	for i := 0; i < DebugRingSize+3; i++ {
+		ServedCoder(WithCode(907701, fmt.Sprint(i)))
+	}
+	got = DebugErrors()
+	if len(got) != DebugRingSize {
+		t.Fatalf("full ring: got %d entries, want %d", len(got), DebugRingSize)
+	}
+	if got[0].Message != "3" || got[len(got)-1].Message != fmt.Sprint(DebugRingSize+2) {
+		t.Errorf("full ring: got oldest %q and newest %q, want %q and %q",
+			got[0].Message, got[len(got)-1].Message, "3", fmt.Sprint(DebugRingSize+2))
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DedupStore records the errors reported to clients for a Deduplicator. A
+// shared store, e.g. backed by Redis, suppresses the duplicates across the
+// instances of a service.
+type DedupStore interface {
+	// Seen records key and reports whether it was already recorded less
+	// than window ago.
+	Seen(key string, window time.Duration) bool
+}
+
+// NewMemoryDedupStore returns a DedupStore keeping the keys in memory,
+// timed with the package Clock, see SetClock. Expired keys are dropped as
+// new ones are recorded.
+func NewMemoryDedupStore() DedupStore {
+	return &memoryDedupStore{seen: map[string]time.Time{}}
+}
+
+// dedupPruneEvery is the number of keys recorded between two prunings of a
+// memory store.
+const dedupPruneEvery = 1024
+
+type memoryDedupStore struct {
+	mu      sync.Mutex
+	seen    map[string]time.Time // key -> expiry
+	records int
+}
+
+func (s *memoryDedupStore) Seen(key string, window time.Duration) bool {
+	t := now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if expiry, ok := s.seen[key]; ok && t.Before(expiry) {
+		return true
+	}
+	s.seen[key] = t.Add(window)
+	if s.records++; s.records%dedupPruneEvery == 0 {
+		for k, expiry := range s.seen {
+			if !t.Before(expiry) {
+				delete(s.seen, k)
+			}
+		}
+	}
+	return false
+}
+
+// Deduplicator suppresses the duplicate errors reported to a client, such
+// as webhook callbacks, for the codes marked with WithDedupWindow, so that
+// a failing client is not hammered with identical errors.
+type Deduplicator struct {
+	store DedupStore
+}
+
+// NewDeduplicator returns a Deduplicator recording the errors in store. A
+// nil store stands for NewMemoryDedupStore.
+func NewDeduplicator(store DedupStore) *Deduplicator {
+	if store == nil {
+		store = NewMemoryDedupStore()
+	}
+	return &Deduplicator{store: store}
+}
+
+// Suppress reports whether err duplicates an identical error reported to
+// client within the dedup window of its Coder, in which case it should not
+// be reported again. Errors are identical when they have the same
+// fingerprint, which does not depend on the arguments of their messages,
+// see Bucket. Errors whose Coder has no dedup window are never suppressed.
+func (d *Deduplicator) Suppress(client string, err error) bool {
+	if err == nil {
+		return false
+	}
+	window, ok := DedupWindowOf(ParseCoder(err))
+	if !ok {
+		return false
+	}
+	key := client + "\x00" + strconv.FormatUint(fingerprint(err), 16)
+	return d.store.Seen(key, window)
+}
@@ -0,0 +1,86 @@
+package errors
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func init() {
+	Register(NewCoder(909801, 502, "callback failed", "", WithDedupWindow(time.Minute)))
+	Register(NewCoder(909802, 500, "callback crashed", ""))
+}
+
+func TestDeduplicator(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	current := t0
+	restore := NewConfig(UseClock(ClockFunc(func() time.Time { return current }))).Apply()
+	defer restore()
+
+	d := NewDeduplicator(nil)
+	tests := []struct {
+		after  time.Duration
+		client string
+		err    error
+		want   bool
+	}{
+		{0, "acme", WithCode(909801, "POST %s: 502", "/hook/1"), false},
+		{time.Second, "acme", WithCode(909801, "POST %s: 502", "/hook/2"), true},
+		{time.Second, "globex", WithCode(909801, "POST %s: 502", "/hook/1"), false},
+		{2 * time.Second, "acme", WithCode(909802, "crashed"), false},
+		{3 * time.Second, "acme", WithCode(909802, "crashed"), false},
+		{59 * time.Second, "acme", WithCode(909801, "POST %s: 502", "/hook/1"), true},
+		{time.Minute, "acme", WithCode(909801, "POST %s: 502", "/hook/1"), false},
+		{time.Minute + time.Second, "acme", nil, false},
+	}
+
+	for i, tt := range tests {
+		current = t0.Add(tt.after)
+		if got := d.Suppress(tt.client, tt.err); got != tt.want {
+			t.Errorf("test %d: Suppress(%q, %v): got %v, want %v", i+1, tt.client, tt.err, got, tt.want)
+		}
+	}
+}
+
+// countingStore is a DedupStore counting its calls.
+type countingStore struct{ calls int }
+
+func (s *countingStore) Seen(string, time.Duration) bool {
+	s.calls++
+	return s.calls > 1
+}
+
+func TestDeduplicatorStore(t *testing.T) {
+	store := &countingStore{}
+	d := NewDeduplicator(store)
+	d.Suppress("acme", WithCode(909802, "crashed"))
+	if store.calls != 0 {
+		t.Errorf("code without window: got %d calls of the store, want 0", store.calls)
+	}
+	if d.Suppress("acme", WithCode(909801, "failed")) || !d.Suppress("acme", WithCode(909801, "failed")) {
+		t.Errorf("Suppress: got the store ignored")
+	}
+}
+
+func TestDedupWindowCatalog(t *testing.T) {
+	info, ok := DescribeCode(909801)
+	if !ok || info.DedupWindow != "1m0s" {
+		t.Fatalf("DescribeCode(909801).DedupWindow: got %q, want %q", info.DedupWindow, "1m0s")
+	}
+	var buf bytes.Buffer
+	if err := ExportCatalog(&buf); err != nil {
+		t.Fatal(err)
+	}
+	coders, err := ImportCatalog(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, coder := range coders {
+		if coder.Code() != 909801 {
+			continue
+		}
+		if d, _ := DedupWindowOf(coder); d != time.Minute {
+			t.Errorf("imported dedup window: got %v, want %v", d, time.Minute)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Description is a compact structured summary of an error, meant to be
+// attached to support tickets or fed to tools which cannot make sense of a
+// full %+v dump.
+type Description struct {
+	// Code and HTTPStatus are those of the Coder parsed from the error.
+	Code       int `json:"code"`
+	HTTPStatus int `json:"http_status"`
+
+	// Message is the human facing message of the Coder, or the error
+	// message if the Coder has none.
+	Message string `json:"message"`
+
+	// CauseType and Cause are the type and message of the innermost error
+	// of the chain, the layer which most probably caused the failure.
+	CauseType string `json:"cause_type"`
+	Cause     string `json:"cause"`
+
+	// Origin is the topmost application frame of the innermost stack of
+	// the chain, skipping the frames of the Go runtime and standard
+	// library. It is empty if the chain has no stack.
+	Origin string `json:"origin,omitempty"`
+}
+
+// String returns the description on a single line.
+func (d Description) String() string {
+	s := fmt.Sprintf("%d (%d): %s; cause: %s: %s", d.Code, d.HTTPStatus, d.Message, d.CauseType, d.Cause)
+	if d.Origin != "" {
+		s += "; at " + d.Origin
+	}
+	return s
+}
+
+// Describe returns a compact structured summary of err.
+// It returns the zero Description if err is nil.
+func Describe(err error) Description {
+	if err == nil {
+		return Description{}
+	}
+
+	coder := ParseCoder(err)
+	d := Description{
+		Code:       coder.Code(),
+		HTTPStatus: coder.HTTPStatus(),
+		Message:    coder.String(),
+	}
+	if d.Message == "" {
+		d.Message = err.Error()
+	}
+
+	errs := list(err)
+	root := errs[len(errs)-1]
+	d.CauseType = fmt.Sprintf("%T", root)
+	d.Cause = root.Error()
+
+	for i := len(errs) - 1; i >= 0; i-- {
+		s := stackOf(errs[i])
+		if s == nil {
+			continue
+		}
+		for _, pc := range *s {
+			f := Frame(pc)
+			if isAppFrame(f.name()) {
+				d.Origin = fmt.Sprintf("%s:%d (%s)", f.file(), f.line(), f.name())
+				break
+			}
+		}
+		break
+	}
+
+	return d
+}
+
+// stackOf returns the stack recorded by a layer of this package, or nil.
+func stackOf(err error) *stack {
+	switch e := err.(type) {
+	case *fundamental:
+		return e.stack
+	case *withStack:
+		return e.stack
+	case *withCode:
+		return e.stack
+	}
+	return nil
+}
+
+// chainMessages returns the message of every layer of err's chain,
+// outermost first.
+func chainMessages(err error) []string {
+	var ret []string
+	for _, e := range list(err) {
+		ret = append(ret, buildFormatInfo(e).err)
+	}
+	return ret
+}
+
+// stackLines returns the frames of the innermost stack trace of err's
+// chain, formatted as "file:line (function)".
+func stackLines(err error) []string {
+	errs := list(err)
+	for i := len(errs) - 1; i >= 0; i-- {
+		s := stackOf(errs[i])
+		if s == nil {
+			continue
+		}
+		pcs := s.frames()
+		ret := make([]string, 0, len(pcs))
+		for _, pc := range pcs {
+			f := Frame(pc)
+			ret = append(ret, fmt.Sprintf("%s:%d (%s)", f.file(), f.line(), f.name()))
+		}
+		return ret
+	}
+	return nil
+}
+
+// isAppFrame reports whether the function belongs to the application rather
+// than to the Go runtime or standard library, whose import paths have no
+// dot in their first element.
+func isAppFrame(fn string) bool {
+	if fn == "" || fn == "unknown" {
+		return false
+	}
+	if strings.HasPrefix(fn, "main.") {
+		return true
+	}
+	first := fn
+	if i := strings.Index(first, "/"); i >= 0 {
+		first = first[:i]
+	} else if i := strings.Index(first, "."); i >= 0 {
+		return false
+	}
+	return strings.Contains(first, ".")
+}
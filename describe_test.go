@@ -0,0 +1,52 @@
+package errors
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	Register(defaultCoder{C: 901801, HTTP: 409, Ext: "conflict"})
+
+	err := Wrapc(Wrap(io.EOF, "read"), 901801, "update failed")
+	d := Describe(err)
+	if d.Code != 901801 || d.HTTPStatus != 409 || d.Message != "conflict" {
+		t.Errorf("Describe(): got %+v", d)
+	}
+	if d.CauseType != "*errors.errorString" || d.Cause != "EOF" {
+		t.Errorf("Describe() cause: got (%q, %q)", d.CauseType, d.Cause)
+	}
+	if !strings.Contains(d.Origin, "describe_test.go:12 (github.com/rtmzk/errors.TestDescribe)") {
+		t.Errorf("Describe() origin: got %q", d.Origin)
+	}
+
+	d = Describe(io.EOF)
+	if d.Code != 1 || d.Message != "An internal server error occurred" || d.Origin != "" {
+		t.Errorf("Describe(io.EOF): got %+v", d)
+	}
+	if got := Describe(nil); got != (Description{}) {
+		t.Errorf("Describe(nil): got %+v", got)
+	}
+}
+
+func TestIsAppFrame(t *testing.T) {
+	tests := []struct {
+		fn   string
+		want bool
+	}{
+		{"runtime.goexit", false},
+		{"net/http.(*conn).serve", false},
+		{"testing.tRunner", false},
+		{"main.main", true},
+		{"github.com/rtmzk/errors.TestDescribe", true},
+		{"example.com/app/handler.(*Server).Get", true},
+		{"unknown", false},
+	}
+
+	for _, tt := range tests {
+		if got := isAppFrame(tt.fn); got != tt.want {
+			t.Errorf("isAppFrame(%q): got %v, want %v", tt.fn, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Detail is a machine-readable payload attached to an error, such as Help.
+// Details are rendered in the JSON output of coded errors, as an object
+// holding the JSON encoding of the detail and its type under "@type".
+type Detail interface {
+	// DetailType returns the name identifying the kind of detail.
+	DetailType() string
+}
+
+// WithDetails attaches details to err.
+// If err is a coded error, a copy of it holding the details is returned,
+// so that coded errors shared between callers are never modified.
+// Otherwise err is wrapped.
+// If err is nil, WithDetails returns nil.
+func WithDetails(err error, details ...Detail) error {
+	if err == nil {
+		return nil
+	}
+	if len(details) == 0 {
+		return err
+	}
+
+	if w, ok := err.(*withCode); ok {
+		cp := *w
+		cp.details = make([]Detail, 0, len(w.details)+len(details))
+		cp.details = append(cp.details, w.details...)
+		cp.details = append(cp.details, details...)
+		return &cp
+	}
+
+	return &withDetails{
+		error:   err,
+		details: details,
+	}
+}
+
+// Details returns all details attached to err's chain, outermost first.
+func Details(err error) []Detail {
+	var ret []Detail
+	for _, e := range list(err) {
+		switch e := e.(type) {
+		case *withCode:
+			ret = append(ret, e.details...)
+		case *withDetails:
+			ret = append(ret, e.details...)
+		}
+	}
+	return ret
+}
+
+type withDetails struct {
+	error
+	details []Detail
+}
+
+func (w *withDetails) Cause() error { return w.error }
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (w *withDetails) Unwrap() error { return w.error }
+
+func (w *withDetails) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "%+v", w.Cause())
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, w.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", w.Error())
+	}
+}
+
+// renderDetails converts details into their JSON representation.
+func renderDetails(details []Detail) []map[string]interface{} {
+	ret := make([]map[string]interface{}, 0, len(details))
+	for _, d := range details {
+		data := map[string]interface{}{}
+		if byts, err := json.Marshal(d); err == nil {
+			_ = json.Unmarshal(byts, &data)
+		}
+		data["@type"] = d.DetailType()
+		ret = append(ret, data)
+	}
+	return ret
+}
+
+// HelpLink describes a document helping to understand or fix an error.
+type HelpLink struct {
+	Description string `json:"description"`
+	URL         string `json:"url"`
+}
+
+// Help is a Detail pointing to documents about an error, like the Help
+// detail of gRPC. It complements the single reference of a Coder when a
+// failure needs more than one document.
+type Help struct {
+	Links []HelpLink `json:"links"`
+}
+
+// DetailType implements Detail.
+func (Help) DetailType() string { return "help" }
+
+// WithHelp attaches help links to err.
+// If err is nil, WithHelp returns nil.
+func WithHelp(err error, links ...HelpLink) error {
+	if len(links) == 0 {
+		return err
+	}
+	return WithDetails(err, Help{Links: links})
+}
+
+// HelpLinks returns all help links attached to err's chain.
+func HelpLinks(err error) []HelpLink {
+	var ret []HelpLink
+	for _, d := range Details(err) {
+		if h, ok := d.(Help); ok {
+			ret = append(ret, h.Links...)
+		}
+	}
+	return ret
+}
+
+// QuotaFailure is a Detail describing a violated quota or rate limit,
+// mirroring the QuotaFailure detail of google.rpc, so that rate limiting
+// responses carry machine-readable limits.
+type QuotaFailure struct {
+	// Subject is the entity on which the quota was checked,
+	// e.g. "project:123" or "user:alice".
+	Subject string `json:"subject"`
+
+	// Limit is the maximum allowed by the quota.
+	Limit int64 `json:"limit"`
+
+	// Current is the usage which caused the violation.
+	Current int64 `json:"current"`
+
+	// Description optionally explains the violated quota.
+	Description string `json:"description,omitempty"`
+}
+
+// DetailType implements Detail.
+func (QuotaFailure) DetailType() string { return "quota_failure" }
+
+// QuotaFailures returns all quota failures attached to err's chain.
+func QuotaFailures(err error) []QuotaFailure {
+	var ret []QuotaFailure
+	for _, d := range Details(err) {
+		if q, ok := d.(QuotaFailure); ok {
+			ret = append(ret, q)
+		}
+	}
+	return ret
+}
+
+// BadRequest is a Detail listing the invalid fields of a request. It maps
+// 1:1 to the BadRequest detail of google.rpc, see the grpcerr package for
+// the conversions.
+type BadRequest struct {
+	FieldViolations []FieldViolation `json:"field_violations"`
+}
+
+// FieldViolation describes a single invalid request field.
+type FieldViolation struct {
+	// Field is the path to the invalid field, e.g. "user.emails[0]".
+	Field string `json:"field"`
+
+	// Description explains why the field is invalid.
+	Description string `json:"description"`
+
+	// Reason is an optional machine-readable identifier of the violation,
+	// e.g. "INVALID_EMAIL_ADDRESS".
+	Reason string `json:"reason,omitempty"`
+
+	// LocalizedMessage is an optional user facing message.
+	LocalizedMessage *LocalizedMessage `json:"localized_message,omitempty"`
+
+	// Label is the display name of the field in the language of a
+	// response, set when the response is rendered, see RegisterFieldLabel.
+	Label string `json:"label,omitempty"`
+}
+
+// LocalizedMessage is a message in a given locale, such as "en-US".
+type LocalizedMessage struct {
+	Locale  string `json:"locale"`
+	Message string `json:"message"`
+}
+
+// DetailType implements Detail.
+func (BadRequest) DetailType() string { return "bad_request" }
+
+// FieldViolations returns all field violations attached to err's chain.
+func FieldViolations(err error) []FieldViolation {
+	var ret []FieldViolation
+	for _, d := range Details(err) {
+		if b, ok := d.(BadRequest); ok {
+			ret = append(ret, b.FieldViolations...)
+		}
+	}
+	return ret
+}
+
+// BlobRef is a Detail referencing data stored outside of the error, such as
+// the object-store key of a multi-megabyte upstream response body. It keeps
+// errors small in memory and in logs, while the data stays retrievable.
+type BlobRef struct {
+	Ref string `json:"ref"`
+}
+
+// DetailType implements Detail.
+func (BlobRef) DetailType() string { return "blob_ref" }
+
+// WithBlobRef attaches the reference of externally stored data to err.
+// The reference is rendered by the detailed and JSON formats of coded
+// errors. If err is nil, WithBlobRef returns nil.
+func WithBlobRef(err error, ref string) error {
+	return WithDetails(err, BlobRef{Ref: ref})
+}
+
+// BlobRefs returns all blob references attached to err's chain.
+func BlobRefs(err error) []string {
+	var ret []string
+	for _, d := range Details(err) {
+		if b, ok := d.(BlobRef); ok {
+			ret = append(ret, b.Ref)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,105 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithHelp(t *testing.T) {
+	links := []HelpLink{
+		{Description: "quota guide", URL: "https://example.com/quota"},
+		{Description: "support", URL: "https://example.com/support"},
+	}
+
+	base := WithCode(900701, "over quota")
+	err := WithHelp(base, links...)
+	if got := HelpLinks(err); !reflect.DeepEqual(got, links) {
+		t.Errorf("HelpLinks(): got %v, want %v", got, links)
+	}
+	if got := HelpLinks(base); len(got) != 0 {
+		t.Errorf("HelpLinks(base): got %v, the shared coded error must not be modified", got)
+	}
+	if !IsCode(err, 900701) {
+		t.Errorf("IsCode(%v, 900701): got false, want true", err)
+	}
+
+	wrapped := WithHelp(io.EOF, links[0])
+	if got := HelpLinks(wrapped); !reflect.DeepEqual(got, links[:1]) {
+		t.Errorf("HelpLinks(wrapped): got %v, want %v", got, links[:1])
+	}
+	if got := Cause(wrapped); got != io.EOF {
+		t.Errorf("Cause(wrapped): got %v, want %v", got, io.EOF)
+	}
+
+	if WithHelp(nil, links...) != nil {
+		t.Errorf("WithHelp(nil): got non-nil, want nil")
+	}
+}
+
+func TestDetailsJSON(t *testing.T) {
+	err := WithHelp(WithCode(900702, "over quota"), HelpLink{Description: "guide", URL: "https://example.com"})
+
+	var got []map[string]interface{}
+	if e := json.Unmarshal([]byte(fmt.Sprintf("%#v", err)), &got); e != nil {
+		t.Fatalf("%%#v is not valid JSON: %v", e)
+	}
+	want := []interface{}{map[string]interface{}{
+		"@type": "help",
+		"links": []interface{}{map[string]interface{}{"description": "guide", "url": "https://example.com"}},
+	}}
+	if len(got) != 1 || !reflect.DeepEqual(got[0]["details"], want) {
+		t.Errorf("%%#v details: got %v, want %v", got, want)
+	}
+}
+
+func TestQuotaFailure(t *testing.T) {
+	q := QuotaFailure{Subject: "user:alice", Limit: 100, Current: 101}
+	err := WithDetails(WithCode(900801, "rate limited"), q)
+
+	if got := QuotaFailures(err); !reflect.DeepEqual(got, []QuotaFailure{q}) {
+		t.Errorf("QuotaFailures(): got %v, want %v", got, []QuotaFailure{q})
+	}
+
+	var got []map[string]interface{}
+	if e := json.Unmarshal([]byte(fmt.Sprintf("%#v", err)), &got); e != nil {
+		t.Fatalf("%%#v is not valid JSON: %v", e)
+	}
+	want := []interface{}{map[string]interface{}{
+		"@type":   "quota_failure",
+		"subject": "user:alice",
+		"limit":   float64(100),
+		"current": float64(101),
+	}}
+	if len(got) != 1 || !reflect.DeepEqual(got[0]["details"], want) {
+		t.Errorf("%%#v details: got %v, want %v", got, want)
+	}
+}
+
+func TestWithBlobRef(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(903601, 502, "upstream failed", ""))
+
+	if got := WithBlobRef(nil, "s3://bucket/key"); got != nil {
+		t.Errorf("WithBlobRef(nil): got %v, want nil", got)
+	}
+
+	err := WithBlobRef(WithCode(903601, "upstream answered 500"), "s3://bucket/resp-1")
+	err = WithBlobRef(Wrap(err, "call upstream"), "s3://bucket/req-1")
+	if got, want := BlobRefs(err), []string{"s3://bucket/req-1", "s3://bucket/resp-1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("BlobRefs(): got %v, want %v", got, want)
+	}
+
+	coded := WithBlobRef(WithCode(903601, "upstream answered 500"), "s3://bucket/resp-1")
+	if got := fmt.Sprintf("%-v", coded); !strings.HasSuffix(got, " [blob s3://bucket/resp-1]") {
+		t.Errorf("%%-v: got %q, want the blob reference", got)
+	}
+	if got := fmt.Sprintf("%#v", coded); !strings.Contains(got, `{"@type":"blob_ref","ref":"s3://bucket/resp-1"}`) {
+		t.Errorf("%%#v: got %q, want the blob reference", got)
+	}
+}
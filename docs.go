@@ -0,0 +1,131 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ExportMarkdown writes the registered coders to w as a markdown table,
+// sorted by code, so that the authoritative list of error codes is
+// generated from the registry rather than maintained by hand.
+func ExportMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("| Code | HTTP status | Message | Severity | Retryable | Remediation | Reference | Owner |\n")
+	b.WriteString("| --- | --- | --- | --- | --- | --- | --- | --- |\n")
+	for _, coder := range registeredCoders() {
+		info, _ := DescribeCode(coder.Code())
+		severity, _ := SeverityOf(coder)
+		retryable, _ := RetryableOf(coder)
+
+		steps := make([]string, len(info.Remediation))
+		for i, step := range info.Remediation {
+			steps[i] = fmt.Sprintf("%d. %s", i+1, markdownCell(step))
+		}
+		ref := info.Reference
+		if ref != "" {
+			ref = "[" + markdownCell(ref) + "](" + ref + ")"
+		}
+		retry := "no"
+		if retryable {
+			retry = "yes"
+		}
+
+		fmt.Fprintf(&b, "| %d | %d %s | %s | %s | %s | %s | %s | %s |\n",
+			info.Code, info.HTTPStatus, http.StatusText(info.HTTPStatus), markdownCell(info.Message),
+			severity, retry, strings.Join(steps, "<br>"), ref, markdownCell(info.Owner))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownCell escapes s for a markdown table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+// OpenAPIResponse is a response object of an OpenAPI 3 document.
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIMediaType is a media type object of an OpenAPI 3 document.
+type OpenAPIMediaType struct {
+	Schema   map[string]interface{}    `json:"schema"`
+	Examples map[string]OpenAPIExample `json:"examples,omitempty"`
+}
+
+// OpenAPIExample is an example object of an OpenAPI 3 document.
+type OpenAPIExample struct {
+	Summary string      `json:"summary,omitempty"`
+	Value   interface{} `json:"value"`
+}
+
+// openAPIErrorSchema is the schema of the bodies written by WriteError.
+var openAPIErrorSchema = map[string]interface{}{
+	"type":     "object",
+	"required": []string{"message"},
+	"properties": map[string]interface{}{
+		"code":      map[string]interface{}{"type": "integer", "description": "Error code"},
+		"message":   map[string]interface{}{"type": "string", "description": "Human facing message"},
+		"reference": map[string]interface{}{"type": "string", "description": "Documentation of the error"},
+	},
+}
+
+// ExportOpenAPIResponses returns reusable OpenAPI 3 response components
+// describing the bodies written by WriteError for the registered coders,
+// to be set under components.responses. There is one response per HTTP
+// status, named "Error" followed by the status, e.g. "Error404", with an
+// example per code, named after the code:
+//
+//	responses:
+//	  "404":
+//	    $ref: "#/components/responses/Error404"
+func ExportOpenAPIResponses() map[string]OpenAPIResponse {
+	ret := map[string]OpenAPIResponse{}
+	for _, coder := range registeredCoders() {
+		status := coder.HTTPStatus()
+		name := "Error" + strconv.Itoa(status)
+		resp, ok := ret[name]
+		if !ok {
+			resp = OpenAPIResponse{
+				Description: http.StatusText(status),
+				Content: map[string]OpenAPIMediaType{
+					"application/json": {Schema: openAPIErrorSchema, Examples: map[string]OpenAPIExample{}},
+				},
+			}
+			if resp.Description == "" {
+				resp.Description = "Status " + strconv.Itoa(status)
+			}
+			ret[name] = resp
+		}
+
+		value := map[string]interface{}{"code": coder.Code(), "message": coder.String()}
+		if ref := coder.Reference(); ref != "" {
+			value["reference"] = ref
+		}
+		resp.Content["application/json"].Examples[strconv.Itoa(coder.Code())] = OpenAPIExample{
+			Summary: coder.String(),
+			Value:   value,
+		}
+	}
+	return ret
+}
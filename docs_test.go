@@ -0,0 +1,65 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExportMarkdown(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(908702, 503, "Service | unavailable", "https://example.com/908702",
+		WithRetryable(true), WithRemediation("Wait", "Retry"), WithOwner("team-edge")))
+	MustRegister(NewCoder(908701, 404, "Not found", ""))
+
+	var buf bytes.Buffer
+	if err := ExportMarkdown(&buf); err != nil {
+		t.Fatalf("ExportMarkdown: got error %v", err)
+	}
+	want := "| Code | HTTP status | Message | Severity | Retryable | Remediation | Reference | Owner |\n" +
+		"| --- | --- | --- | --- | --- | --- | --- | --- |\n" +
+		"| 908701 | 404 Not Found | Not found | info | no |  |  |  |\n" +
+		"| 908702 | 503 Service Unavailable | Service \\| unavailable | error | yes | 1. Wait<br>2. Retry | [https://example.com/908702](https://example.com/908702) | team-edge |\n"
+	if got := buf.String(); got != want {
+		t.Errorf("ExportMarkdown: got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestExportOpenAPIResponses(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(908711, 404, "User not found", "https://example.com/908711"))
+	MustRegister(NewCoder(908712, 404, "Order not found", ""))
+	MustRegister(NewCoder(908713, 409, "Conflict", ""))
+
+	responses := ExportOpenAPIResponses()
+	if len(responses) != 2 {
+		t.Fatalf("ExportOpenAPIResponses: got %d responses, want 2", len(responses))
+	}
+	notFound, ok := responses["Error404"]
+	if !ok || notFound.Description != "Not Found" {
+		t.Fatalf("Error404: got %+v, want the Not Found response", notFound)
+	}
+	examples := notFound.Content["application/json"].Examples
+	if len(examples) != 2 || examples["908712"].Summary != "Order not found" {
+		t.Errorf("Error404 examples: got %+v, want 908711 and 908712", examples)
+	}
+
+	byts, err := json.Marshal(responses)
+	if err != nil {
+		t.Fatalf("json.Marshal: got error %v", err)
+	}
+	for _, want := range []string{
+		`"Error409":{"description":"Conflict"`,
+		`"908711":{"summary":"User not found","value":{"code":908711,"message":"User not found","reference":"https://example.com/908711"}}`,
+		`"required":["message"]`,
+	} {
+		if !strings.Contains(string(byts), want) {
+			t.Errorf("JSON: got %s, want it to contain %s", byts, want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// Enricher adds information to an error when it is served to a client,
+// rather than when it is created. It suits expensive lookups, such as
+// translating an entity ID into a name for the message, which are then only
+// made for the errors which actually reach a client.
+type Enricher func(ctx context.Context, b *ErrorBuilder)
+
+var (
+	enrichersMux sync.Mutex
+
+	// enrichers holds the []Enricher run by Enrich. It is replaced on every
+	// change, so that serving errors reads it without locking.
+	enrichers atomic.Value
+)
+
+// AddEnricher adds an enricher run by Enrich, and so by WriteError and
+// ServeProblem, with the context of the request:
+//
+//	errors.AddEnricher(func(ctx context.Context, b *errors.ErrorBuilder) {
+//		if b.Code() == code.ErrProjectArchived {
+//			id := errors.Fields(b.Err())["project_id"]
+//			b.Message("Project %s is archived", projects.Name(ctx, id))
+//		}
+//	})
+//
+// The enrichers run in the order they were added, on the goroutine serving
+// the error, and share a single ErrorBuilder: the fields and details they
+// add accumulate, and the last message set wins.
+// They must be safe for concurrent use.
+func AddEnricher(e Enricher) {
+	if e == nil {
+		return
+	}
+	enrichersMux.Lock()
+	defer enrichersMux.Unlock()
+
+	prev := enrichersNow()
+	updated := make([]Enricher, 0, len(prev)+1)
+	updated = append(updated, prev...)
+	updated = append(updated, e)
+	enrichers.Store(updated)
+}
+
+// ClearEnrichers removes the enrichers added with AddEnricher.
+func ClearEnrichers() {
+	enrichersMux.Lock()
+	defer enrichersMux.Unlock()
+	enrichers.Store([]Enricher(nil))
+}
+
+func enrichersNow() []Enricher {
+	es, _ := enrichers.Load().([]Enricher)
+	return es
+}
+
+// ErrorBuilder collects the information added to an error by the
+// enrichers, see AddEnricher.
+type ErrorBuilder struct {
+	err     error
+	message string
+	kv      []interface{}
+	details []Detail
+}
+
+// Err returns the error being enriched.
+func (b *ErrorBuilder) Err() error { return b.err }
+
+// Code returns the code of the Coder of the error being enriched.
+func (b *ErrorBuilder) Code() int { return ParseCoder(b.err).Code() }
+
+// Message replaces the message served to the client, see Message.
+func (b *ErrorBuilder) Message(format string, args ...interface{}) {
+	b.message = fmt.Sprintf(format, args...)
+}
+
+// Fields attaches fields to the error, like WithFields.
+func (b *ErrorBuilder) Fields(kv ...interface{}) {
+	b.kv = append(b.kv, kv...)
+}
+
+// Details attaches details to the error, like WithDetails.
+func (b *ErrorBuilder) Details(details ...Detail) {
+	b.details = append(b.details, details...)
+}
+
+// Enrich returns err with the information added by the enrichers run with
+// ctx, see AddEnricher. It is called by WriteError and ServeProblem, and
+// lets other transports serve enriched errors too. Enrich returns err
+// unchanged if it is nil or if no enricher is set.
+func Enrich(ctx context.Context, err error) error {
+	es := enrichersNow()
+	if err == nil || len(es) == 0 {
+		return err
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	b := &ErrorBuilder{err: err}
+	for _, e := range es {
+		e(ctx, b)
+	}
+
+	err = WithDetails(WithFields(err, b.kv...), b.details...)
+	if b.message != "" {
+		err = &withPublicMessage{error: err, msg: b.message}
+	}
+	return err
+}
+
+// withPublicMessage overrides the message served to clients, leaving the
+// message of the error unchanged.
+type withPublicMessage struct {
+	error
+	msg string
+}
+
+func (w *withPublicMessage) Cause() error { return w.error }
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (w *withPublicMessage) Unwrap() error { return w.error }
+
+func (w *withPublicMessage) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "%+v", w.Cause())
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, w.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", w.Error())
+	}
+}
+
+// publicMessage returns the message set by an enricher on err's chain.
+func publicMessage(err error) (string, bool) {
+	for _, e := range list(err) {
+		if w, ok := e.(*withPublicMessage); ok {
+			return w.msg, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,74 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func init() {
+	Register(NewCoder(910701, 409, "Project is archived", ""))
+	Register(NewCoder(910702, 500, "Internal error", ""))
+}
+
+type projectNamesKey struct{}
+
+func TestEnrich(t *testing.T) {
+	calls := 0
+	restore := NewConfig(EnrichWith(func(ctx context.Context, b *ErrorBuilder) {
+		calls++
+		if b.Code() != 910701 {
+			return
+		}
+		names, _ := ctx.Value(projectNamesKey{}).(map[interface{}]string)
+		b.Message("Project %s is archived", names[Fields(b.Err())["project_id"]])
+		b.Fields("owner", "alice")
+		b.Details(Help{Links: []HelpLink{{Description: "Unarchive", URL: "https://example.com/unarchive"}}})
+	})).Apply()
+	defer restore()
+
+	err := WithFields(WithCode(910701, "update project"), "project_id", 42)
+	if calls != 0 {
+		t.Errorf("enricher calls at creation: got %d, want 0", calls)
+	}
+
+	ctx := context.WithValue(context.Background(), projectNamesKey{}, map[interface{}]string{42: "Apollo"})
+	r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(WithProfile(ctx, ProfileInternal))
+	rec := httptest.NewRecorder()
+	WriteError(rec, r, err)
+	if calls != 1 {
+		t.Errorf("enricher calls: got %d, want 1", calls)
+	}
+
+	var body responseBody
+	if jerr := json.Unmarshal(rec.Body.Bytes(), &body); jerr != nil {
+		t.Fatal(jerr)
+	}
+	if body.Message != "Project Apollo is archived" {
+		t.Errorf("message: got %q, want %q", body.Message, "Project Apollo is archived")
+	}
+	if want := map[string]interface{}{"project_id": float64(42), "owner": "alice"}; !reflect.DeepEqual(body.Fields, want) {
+		t.Errorf("fields: got %v, want %v", body.Fields, want)
+	}
+	if got := err.Error(); got != "update project" {
+		t.Errorf("Error() of the served error: got %q, want %q", got, "update project")
+	}
+
+	rec = httptest.NewRecorder()
+	ServeProblem(rec, r, err)
+	if got := rec.Body.String(); !strings.Contains(got, `"title":"Project Apollo is archived"`) || !strings.Contains(got, "unarchive") {
+		t.Errorf("ServeProblem: got %s, want the enriched title and details", got)
+	}
+
+	enriched := Enrich(ctx, WithCode(910702, "boom"))
+	if got := Message(enriched, ""); got != "Internal error" {
+		t.Errorf("Message(not enriched): got %q, want %q", got, "Internal error")
+	}
+	if got := Enrich(ctx, nil); got != nil {
+		t.Errorf("Enrich(nil): got %v, want nil", got)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"os"
+	"runtime/debug"
+)
+
+// Keys of the fields returned by BuildEnvironment.
+const (
+	FieldBuildVersion = "build_version"
+	FieldGitSHA       = "git_sha"
+	FieldHostname     = "hostname"
+)
+
+// environment holds the fields stamped on the errors created by New, Errorf,
+// WithCode and Wrapc. It is never modified once set, so that errors share
+// it instead of copying it.
+var environment map[string]interface{}
+
+// SetEnvironment sets the fields stamped on every error created from now
+// on, such as the fields returned by BuildEnvironment, so that error reports
+// identify the exact build and host which produced them.
+// The fields are reported by Fields and in the JSON output, with a lower
+// precedence than the fields of the error itself.
+// A nil or empty map stops the stamping, which is the default.
+func SetEnvironment(fields map[string]interface{}) {
+	if len(fields) == 0 {
+		environment = nil
+		return
+	}
+	env := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		env[k] = v
+	}
+	environment = env
+}
+
+// BuildEnvironment returns the version of the main module and the git
+// revision it was built from, as recorded by the Go toolchain, and the
+// hostname. Unknown values are left out.
+func BuildEnvironment() map[string]interface{} {
+	env := map[string]interface{}{}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if v := info.Main.Version; v != "" && v != "(devel)" {
+			env[FieldBuildVersion] = v
+		}
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" && s.Value != "" {
+				env[FieldGitSHA] = s.Value
+			}
+		}
+	}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		env[FieldHostname] = hostname
+	}
+	return env
+}
@@ -0,0 +1,43 @@
+package errors
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestSetEnvironment(t *testing.T) {
+	defer SetEnvironment(nil)
+
+	before := WithCode(902101, "before")
+	SetEnvironment(map[string]interface{}{FieldBuildVersion: "v1.2.3", "region": "eu"})
+	created := New("created")
+	coded := Wrapc(created, 902101, "coded")
+
+	if got := Fields(before); got != nil {
+		t.Errorf("Fields(before): got %v, want nil", got)
+	}
+	want := map[string]interface{}{FieldBuildVersion: "v1.2.3", "region": "eu"}
+	if got := Fields(created); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields(created): got %v, want %v", got, want)
+	}
+	if got := Fields(coded); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields(coded): got %v, want %v", got, want)
+	}
+
+	_, openErr := os.Open("/does/not/exist")
+	want = map[string]interface{}{FieldBuildVersion: "v1.2.3", "region": "eu", "op": "open", "path": "/does/not/exist"}
+	if got := Fields(Wrapc(openErr, 902101, "open")); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields(open): got %v, want %v", got, want)
+	}
+}
+
+func TestBuildEnvironment(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Skip(err)
+	}
+	if got := BuildEnvironment()[FieldHostname]; got != hostname {
+		t.Errorf("BuildEnvironment()[%q]: got %v, want %q", FieldHostname, got, hostname)
+	}
+}
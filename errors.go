@@ -90,19 +90,59 @@
 // considered a part of its stable public interface.
 //
 // See the documentation for Frame.Format for more details.
+//
+// # Reusing error values
+//
+// Errors are immutable once created: the functions attaching fields,
+// details or messages never modify the error they are given, they return
+// a copy or a wrapper. A base error shared by the iterations of a loop, or
+// declared as a package variable, can be wrapped any number of times
+// without accumulating the fields or breadcrumbs of earlier iterations.
+// Renew returns a copy of such an error holding the stack of its call site,
+// so that each occurrence reports where it happened.
+//
+// # Integrations
+//
+// This package only depends on the standard library. The integrations with
+// transports and observability systems live in subpackages, which bring
+// their own dependencies only to the programs importing them:
+//
+//	grpcerr     gRPC statuses and error details
+//	grpcmw      gRPC server interceptors
+//	connecterr  Connect errors
+//	otelerr     OpenTelemetry log records
+//	sentryerr   Sentry exception interface
+//	metrics     Prometheus counters
+//	syncerr     golang.org/x/sync primitives
+//	httpmw      net/http middlewares
+//	jobresult   job-queue results
+//	catalogctl  catalog management commands
+//	codes       a standard set of codes
+//	yamlcodes   YAML documents for LoadCodes
+//	tomlcodes   TOML documents for LoadCodes
+//	errorspb    protocol buffer encoding
+//	logadapter  zap and logrus fields
+//
+// The integrations observing the served errors are wired in through the
+// Reporter interface, see AddReporter, so that this package calls into
+// them without importing them.
 package errors
 
 import (
 	"fmt"
 	"io"
+	"sync/atomic"
 )
 
 // New returns an error with the supplied message.
 // New also records the stack trace at the point it was called.
 func New(message string) error {
+	st := callers()
 	return &fundamental{
 		msg:   message,
-		stack: callers(),
+		env:   environment,
+		stack: st,
+		pc:    stackPC(st),
 	}
 }
 
@@ -110,16 +150,23 @@ func New(message string) error {
 // as a value that satisfies error.
 // Errorf also records the stack trace at the point it was called.
 func Errorf(format string, args ...interface{}) error {
+	st := callers()
 	return &fundamental{
-		msg:   fmt.Sprintf(format, args...),
-		stack: callers(),
+		msg:   sprintf(format, args...),
+		env:   environment,
+		stack: st,
+		pc:    stackPC(st),
 	}
 }
 
 // fundamental is an error that has a message and a stack, but no caller.
 type fundamental struct {
 	msg string
+	env map[string]interface{}
 	*stack
+	// pc is the call site of the constructor, recorded for Breadcrumbs
+	// whether or not the stack is.
+	pc uintptr
 }
 
 func (f *fundamental) Error() string { return f.msg }
@@ -146,15 +193,22 @@ func WithStack(err error) error {
 	if err == nil {
 		return nil
 	}
+	if hasStack(err) {
+		return err
+	}
+	st := callers()
 	return &withStack{
 		err,
-		callers(),
+		st,
+		stackPC(st),
 	}
 }
 
 type withStack struct {
 	error
 	*stack
+	// pc is the call site of the constructor, see fundamental.
+	pc uintptr
 }
 
 func (w *withStack) Cause() error { return w.error }
@@ -167,7 +221,9 @@ func (w *withStack) Format(s fmt.State, verb rune) {
 	case 'v':
 		if s.Flag('+') {
 			fmt.Fprintf(s, "%+v", w.Cause())
-			w.stack.Format(s, verb)
+			if !hasStack(w.error) {
+				w.stack.Format(s, verb)
+			}
 			return
 		}
 		fallthrough
@@ -185,14 +241,15 @@ func Wrap(err error, message string) error {
 	if err == nil {
 		return nil
 	}
-	err = &withMessage{
-		cause: err,
-		msg:   message,
-	}
-	return &withStack{
-		err,
-		callers(),
+	if hasStack(err) {
+		return &withMessage{
+			cause: err,
+			msg:   message,
+			pc:    callerPC(),
+		}
 	}
+	st := callers()
+	return wrap(err, message, st, stackPC(st))
 }
 
 // Wrapf returns an error annotating err with a stack trace
@@ -202,14 +259,15 @@ func Wrapf(err error, format string, args ...interface{}) error {
 	if err == nil {
 		return nil
 	}
-	err = &withMessage{
-		cause: err,
-		msg:   fmt.Sprintf(format, args...),
-	}
-	return &withStack{
-		err,
-		callers(),
+	if hasStack(err) {
+		return &withMessage{
+			cause: err,
+			msg:   sprintf(format, args...),
+			pc:    callerPC(),
+		}
 	}
+	st := callers()
+	return wrap(err, sprintf(format, args...), st, stackPC(st))
 }
 
 // WithMessage annotates err with a new message.
@@ -221,6 +279,7 @@ func WithMessage(err error, message string) error {
 	return &withMessage{
 		cause: err,
 		msg:   message,
+		pc:    callerPC(),
 	}
 }
 
@@ -232,13 +291,17 @@ func WithMessagef(err error, format string, args ...interface{}) error {
 	}
 	return &withMessage{
 		cause: err,
-		msg:   fmt.Sprintf(format, args...),
+		msg:   sprintf(format, args...),
+		pc:    callerPC(),
 	}
 }
 
 type withMessage struct {
 	cause error
 	msg   string
+	// pc is the call site of WithMessage, or 0 when the message is part of
+	// a Wrap, whose withStack records the call site.
+	pc uintptr
 }
 
 func (w *withMessage) Error() string { return w.msg + ": " + w.cause.Error() }
@@ -269,48 +332,92 @@ func (w *withMessage) Format(s fmt.State, verb rune) {
 //	       Cause() error
 //	}
 //
-// If the error does not implement Cause, the original error will
+// Wrappers implementing only the Go 1.13 Unwrap method, such as those
+// of fmt.Errorf, are traversed as well.
+//
+// If the error does not implement Cause nor Unwrap, the original error will
 // be returned. If the error is nil, nil will be returned without further
 // investigation.
 func Cause(err error) error {
 	type causer interface {
 		Cause() error
 	}
+	type wrapper interface {
+		Unwrap() error
+	}
 
 	for err != nil {
-		cause, ok := err.(causer)
-		if !ok {
-			break
+		switch w := err.(type) {
+		case causer:
+			err = w.Cause()
+		case wrapper:
+			next := w.Unwrap()
+			if next == nil {
+				return err
+			}
+			err = next
+		default:
+			return err
 		}
-		err = cause.Cause()
 	}
 	return err
 }
 
 type withCode struct {
-	err   error
-	code  int
-	cause error
+	err     error
+	code    int
+	cause   error
+	details []Detail
+	fields  map[string]interface{}
+	env     map[string]interface{}
+
+	// params are the parameters of the message template, see NewT.
+	params Params
+
+	// remote is the Coder of an error decoded by FromJSON or created by
+	// a Registry, used when its code is not registered locally.
+	remote Coder
+
+	// registry is the private Registry which created the error, nil for
+	// the package registry. Its code is resolved in registry only.
+	registry *Registry
+
+	// id holds the occurrence ID once drawn, see ErrorID.
+	id atomic.Value // string
 	*stack
+	// pc is the call site of the constructor, see fundamental.
+	pc uintptr
 }
 
 func WithCode(code int, format string, args ...interface{}) error {
-	return &withCode{
-		err:   fmt.Errorf(format, args...),
-		code:  code,
-		stack: callers(),
-	}
+	st := codeCallers(code)
+	return created(newCoded(code, errorf(format, args...), nil, nil, st, stackPC(st)))
 }
 
 func Wrapc(err error, code int, format string, args ...interface{}) error {
 	if err == nil {
 		return nil
 	}
+	var st *stack
+	if !hasStack(err) {
+		st = codeCallers(code)
+	}
+	return created(newCoded(code, errorf(format, args...), err, pathFields(err), st, stackPC(st)))
+}
+
+// newCoded returns the coded error with the given code and message msg,
+// wrapping cause, holding fields and the stack st, created at pc. Every
+// coded constructor builds its error with newCoded, sets any other field
+// of it, then hands it to created.
+func newCoded(code int, msg, cause error, fields map[string]interface{}, st *stack, pc uintptr) *withCode {
 	return &withCode{
-		err:   fmt.Errorf(format, args...),
-		code:  code,
-		cause: err,
-		stack: callers(),
+		err:    msg,
+		code:   code,
+		cause:  cause,
+		fields: fields,
+		env:    environment,
+		stack:  st,
+		pc:     pc,
 	}
 }
 
@@ -319,6 +426,34 @@ func (w *withCode) Error() string {
 	return fmt.Sprintf("%v", w.err)
 }
 
+// Code returns the error code of the withCode error.
+func (w *withCode) Code() int { return w.code }
+
+// Coder returns the registered Coder of the withCode error.
+func (w *withCode) Coder() Coder {
+	ensureCatalogs()
+	if w.registry != nil {
+		if coder, ok := w.registryCoder(); ok {
+			return coder
+		}
+	} else {
+		if coder, ok := lookupCoder(w.code); ok {
+			return coder
+		}
+		if w.remote != nil {
+			return w.remote
+		}
+	}
+	if coder, ok := unregisteredCoder([]error{w}); ok {
+		return coder
+	}
+	return unknownCoder
+}
+
+// StackTrace returns the stack recorded when the coded error was created,
+// or nil if stacks were not captured, see SetCaptureStacks.
+func (w *withCode) StackTrace() StackTrace { return w.stack.StackTrace() }
+
 // Cause return the cause of the withCode error.
 func (w *withCode) Cause() error { return w.cause }
 
@@ -0,0 +1,156 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package errorspb encodes coded errors as protocol buffers, see
+// errors.proto, so that they can be embedded in gRPC error details, in
+// dead-letter payloads and in the envelopes exchanged between services.
+//
+//	msg := errorspb.ToProto(err)
+//	...
+//	err = errorspb.FromProto(msg)
+//
+// The decoded error has the code, message and fields of the encoded one,
+// and its Coder reports the encoded HTTP status, slug and reference when
+// the code is not registered locally. The encoded stack trace is attached
+// as a RemoteStack detail.
+package errorspb
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/rtmzk/errors"
+)
+
+// RemoteStack is a Detail holding the stack trace of an error decoded by
+// FromProto, as printed by the process which encoded it.
+type RemoteStack struct {
+	Frames []string `json:"frames"`
+}
+
+// DetailType implements errors.Detail.
+func (RemoteStack) DetailType() string { return "remote_stack" }
+
+// ToProto returns the protocol buffer encoding of err: the code, slug,
+// message, HTTP status and reference of its Coder, its fields and its
+// innermost stack trace. An error decoded by FromProto is encoded with the
+// stack trace it was decoded with.
+// If err is nil, ToProto returns nil.
+func ToProto(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	coder := errors.ParseCoder(err)
+	msg := &Error{
+		Code:       int64(coder.Code()),
+		Message:    coder.String(),
+		HttpStatus: int32(coder.HTTPStatus()),
+		Reference:  coder.Reference(),
+		Stack:      stackLines(err),
+	}
+	msg.Slug, _ = errors.SlugOf(coder)
+	if msg.Message == "" {
+		msg.Message = err.Error()
+	}
+	for _, f := range errors.SortedFields(err) {
+		if msg.Fields == nil {
+			msg.Fields = map[string]*structpb.Value{}
+		}
+		msg.Fields[f.Key] = toValue(f.Value)
+	}
+	return msg
+}
+
+// FromProto decodes an error encoded by ToProto.
+// If msg is nil, FromProto returns nil.
+func FromProto(msg *Error) error {
+	if msg == nil {
+		return nil
+	}
+
+	byts, encErr := json.Marshal(map[string]interface{}{
+		"code":        msg.GetCode(),
+		"slug":        msg.GetSlug(),
+		"message":     msg.GetMessage(),
+		"http_status": msg.GetHttpStatus(),
+		"reference":   msg.GetReference(),
+	})
+	if encErr != nil {
+		return errors.Wrap(encErr, "decode coded error")
+	}
+	err, decErr := errors.FromJSON(byts)
+	if decErr != nil {
+		return decErr
+	}
+
+	if len(msg.GetFields()) > 0 {
+		kv := make([]interface{}, 0, 2*len(msg.GetFields()))
+		for k, v := range msg.GetFields() {
+			kv = append(kv, k, v.AsInterface())
+		}
+		err = errors.WithFields(err, kv...)
+	}
+	if len(msg.GetStack()) > 0 {
+		err = errors.WithDetails(err, RemoteStack{Frames: msg.GetStack()})
+	}
+	return err
+}
+
+// stackLines returns the frames of the innermost stack trace of err's
+// chain, or else the frames of its remote stack.
+func stackLines(err error) []string {
+	var st errors.StackTrace
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if t, ok := e.(interface{ StackTrace() errors.StackTrace }); ok {
+			if s := t.StackTrace(); len(s) > 0 {
+				st = s
+			}
+		}
+	}
+	if st == nil {
+		for _, d := range errors.Details(err) {
+			if r, ok := d.(RemoteStack); ok {
+				return r.Frames
+			}
+		}
+		return nil
+	}
+
+	lines := make([]string, 0, len(st))
+	for _, f := range st {
+		lines = append(lines, fmt.Sprintf("%+v", f))
+	}
+	return lines
+}
+
+// toValue converts a field value into a protocol buffer value. Values
+// which are not JSON-like are converted through their JSON encoding, or
+// else formatted with %v.
+func toValue(v interface{}) *structpb.Value {
+	if pv, err := structpb.NewValue(v); err == nil {
+		return pv
+	}
+	if byts, err := json.Marshal(v); err == nil {
+		var generic interface{}
+		if json.Unmarshal(byts, &generic) == nil {
+			if pv, err := structpb.NewValue(generic); err == nil {
+				return pv
+			}
+		}
+	}
+	return structpb.NewStringValue(fmt.Sprintf("%v", v))
+}
@@ -0,0 +1,98 @@
+package errorspb
+
+import (
+	stderrors "errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/rtmzk/errors"
+)
+
+func init() {
+	errors.Register(errors.NewCoder(912401, http.StatusNotFound, "user not found", "https://example.com/912401", errors.WithSlug("user_not_found")))
+}
+
+func TestProtoRoundTrip(t *testing.T) {
+	tests := []struct {
+		err     error
+		code    int
+		message string
+		fields  map[string]interface{}
+	}{
+		{errors.WithCode(912401, "no user 42"), 912401, "user not found", nil},
+		{errors.WithFields(errors.Wrapc(stderrors.New("boom"), 912401, "lookup"), "user", "42", "attempt", 3),
+			912401, "user not found", map[string]interface{}{"user": "42", "attempt": float64(3)}},
+		{stderrors.New("plain"), 1, errors.ParseCoder(stderrors.New("plain")).String(), nil},
+	}
+
+	for i, tt := range tests {
+		msg := ToProto(tt.err)
+		if int(msg.GetCode()) != tt.code {
+			t.Errorf("test %d: code: got %v, want %v", i+1, msg.GetCode(), tt.code)
+		}
+		if msg.GetMessage() != tt.message {
+			t.Errorf("test %d: message: got %q, want %q", i+1, msg.GetMessage(), tt.message)
+		}
+
+		byts, err := proto.Marshal(msg)
+		if err != nil {
+			t.Fatalf("test %d: marshal: %v", i+1, err)
+		}
+		var decoded Error
+		if err := proto.Unmarshal(byts, &decoded); err != nil {
+			t.Fatalf("test %d: unmarshal: %v", i+1, err)
+		}
+
+		back := FromProto(&decoded)
+		if got := errors.ParseCoder(back).Code(); got != tt.code {
+			t.Errorf("test %d: FromProto code: got %v, want %v", i+1, got, tt.code)
+		}
+		if got := errors.Fields(back); !reflect.DeepEqual(got, tt.fields) {
+			t.Errorf("test %d: fields: got %v, want %v", i+1, got, tt.fields)
+		}
+		if again := ToProto(back); !reflect.DeepEqual(again.GetStack(), msg.GetStack()) {
+			t.Errorf("test %d: stack: got %v, want %v", i+1, again.GetStack(), msg.GetStack())
+		}
+	}
+}
+
+func TestToProtoFields(t *testing.T) {
+	msg := ToProto(errors.WithCode(912401, "not found"))
+	if msg.GetSlug() != "user_not_found" {
+		t.Errorf("slug: got %q, want %q", msg.GetSlug(), "user_not_found")
+	}
+	if msg.GetHttpStatus() != http.StatusNotFound {
+		t.Errorf("http status: got %v, want %v", msg.GetHttpStatus(), http.StatusNotFound)
+	}
+	if msg.GetReference() != "https://example.com/912401" {
+		t.Errorf("reference: got %q, want %q", msg.GetReference(), "https://example.com/912401")
+	}
+	if len(msg.GetStack()) == 0 || !strings.Contains(msg.GetStack()[0], "TestToProtoFields") {
+		t.Errorf("stack: got %v, want TestToProtoFields on top", msg.GetStack())
+	}
+}
+
+func TestFromProtoRemoteCoder(t *testing.T) {
+	err := FromProto(&Error{Code: 912499, Slug: "remote", Message: "remote failure", HttpStatus: 503, Stack: []string{"main.serve"}})
+	coder := errors.ParseCoder(err)
+	if coder.Code() != 912499 || coder.HTTPStatus() != 503 || coder.String() != "remote failure" {
+		t.Errorf("coder: got %d %d %q, want 912499 503 %q", coder.Code(), coder.HTTPStatus(), coder.String(), "remote failure")
+	}
+	want := []errors.Detail{RemoteStack{Frames: []string{"main.serve"}}}
+	if got := errors.Details(err); !reflect.DeepEqual(got, want) {
+		t.Errorf("details: got %v, want %v", got, want)
+	}
+}
+
+func TestProtoNil(t *testing.T) {
+	if msg := ToProto(nil); msg != nil {
+		t.Errorf("ToProto(nil): got %v, want nil", msg)
+	}
+	if err := FromProto(nil); err != nil {
+		t.Errorf("FromProto(nil): got %v, want nil", err)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errtest
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/rtmzk/errors"
+)
+
+// UpdateGoldenEnv is the environment variable which, when set to a non
+// empty value, makes AssertGolden write the golden files instead of
+// comparing them.
+const UpdateGoldenEnv = "ERRTEST_UPDATE_GOLDEN"
+
+// AssertCode checks that a layer of err's chain carries code, see
+// errors.IsCode.
+func AssertCode(t testing.TB, err error, code int) {
+	t.Helper()
+	if !errors.IsCode(err, code) {
+		t.Errorf("error %v: got code %s, want %d", err, codeOf(err), code)
+	}
+}
+
+// AssertCoderRegistered checks that a Coder is registered for code.
+func AssertCoderRegistered(t testing.TB, code int) {
+	t.Helper()
+	if !errors.IsRegistered(code) {
+		t.Errorf("code %d: got no registered coder, want one", code)
+	}
+}
+
+// RequireChainContains checks that target is in err's chain, see
+// errors.Is, and stops the test otherwise.
+func RequireChainContains(t testing.TB, err, target error) {
+	t.Helper()
+	if !errors.Is(err, target) {
+		t.Fatalf("error %v: got chain %s, want it to contain %v", err, chainOf(err), target)
+	}
+}
+
+// AssertFormat checks the %+v output of err against want, after both are
+// normalized with NormalizeFormat.
+func AssertFormat(t testing.TB, err error, want string) {
+	t.Helper()
+	got := NormalizeFormat(fmt.Sprintf("%+v", err))
+	if want = NormalizeFormat(want); got != want {
+		t.Errorf("%%+v: got\n%s\nwant\n%s", got, want)
+	}
+}
+
+// AssertGolden checks the %+v output of err against the content of the
+// golden file, like AssertFormat. The file is written instead when the
+// UpdateGoldenEnv environment variable is set, e.g.
+//
+//	ERRTEST_UPDATE_GOLDEN=1 go test ./...
+func AssertGolden(t testing.TB, err error, file string) {
+	t.Helper()
+	got := NormalizeFormat(fmt.Sprintf("%+v", err))
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.WriteFile(file, []byte(got), 0o644); err != nil {
+			t.Fatalf("golden file %s: %v", file, err)
+		}
+		return
+	}
+	want, rerr := os.ReadFile(file)
+	if rerr != nil {
+		t.Fatalf("golden file %s: %v, set %s to create it", file, rerr, UpdateGoldenEnv)
+		return
+	}
+	if string(want) != got {
+		t.Errorf("%%+v: got\n%s\nwant, from %s,\n%s", got, file, want)
+	}
+}
+
+// sourcePath matches the file:line references of the stack traces.
+var sourcePath = regexp.MustCompile(`[^\s\[(]*/([^/\s]+\.(?:go|s)):\d+`)
+
+// NormalizeFormat returns the %+v output s of an error without what
+// changes between machines and edits: the file:line references are
+// reduced to the file name followed by ":_", and the frames of the runtime
+// and testing packages, which depend on the Go version and architecture,
+// are removed.
+func NormalizeFormat(s string) string {
+	lines := strings.Split(s, "\n")
+	ret := make([]string, 0, len(lines))
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if i+1 < len(lines) && strings.HasPrefix(lines[i+1], "\t") &&
+			(strings.HasPrefix(line, "runtime.") || strings.HasPrefix(line, "testing.")) {
+			i++
+			continue
+		}
+		ret = append(ret, sourcePath.ReplaceAllString(line, "$1:_"))
+	}
+	return strings.Join(ret, "\n")
+}
+
+// codeOf describes the code of err.
+func codeOf(err error) string {
+	if code, ok := errors.CodeOf(err); ok {
+		return fmt.Sprint(code)
+	}
+	return "none"
+}
+
+// chainOf describes the layers of err's chain.
+func chainOf(err error) string {
+	var layers []string
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		layers = append(layers, fmt.Sprintf("%T(%q)", e, e.Error()))
+	}
+	return "[" + strings.Join(layers, " -> ") + "]"
+}
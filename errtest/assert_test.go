@@ -0,0 +1,86 @@
+package errtest
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rtmzk/errors"
+)
+
+func TestAssertions(t *testing.T) {
+	err := errors.Wrap(errors.Wrapc(io.EOF, 909501, "read user"), "load profile")
+
+	AssertCode(t, err, 909501)
+	AssertCoderRegistered(t, 909501)
+	RequireChainContains(t, err, io.EOF)
+
+	rt := &recordingT{}
+	AssertCode(rt, err, 909502)
+	AssertCode(rt, io.EOF, 909501)
+	AssertCoderRegistered(rt, 909599)
+	RequireChainContains(rt, err, io.ErrUnexpectedEOF)
+
+	want := []string{
+		"got code 909501, want 909502",
+		"got code none, want 909501",
+		"code 909599: got no registered coder",
+		"want it to contain unexpected EOF",
+	}
+	if len(rt.failures) != len(want) {
+		t.Fatalf("failures: got %q, want %d failures", rt.failures, len(want))
+	}
+	for i, w := range want {
+		if !strings.Contains(rt.failures[i], w) {
+			t.Errorf("failure %d: got %q, want it to contain %q", i, rt.failures[i], w)
+		}
+	}
+}
+
+func TestNormalizeFormat(t *testing.T) {
+	in := "read - #1 [/home/ci/src/user/load.go:42 (user.Load)] (1) internal; EOF - #0 EOF\n" +
+		"user.Load\n" +
+		"\t/home/ci/src/user/load.go:42\n" +
+		"testing.tRunner\n" +
+		"\t/usr/local/go/src/testing/testing.go:1792\n" +
+		"runtime.goexit\n" +
+		"\t/usr/local/go/src/runtime/asm_amd64.s:1700"
+	want := "read - #1 [load.go:_ (user.Load)] (1) internal; EOF - #0 EOF\n" +
+		"user.Load\n" +
+		"\tload.go:_"
+
+	if got := NormalizeFormat(in); got != want {
+		t.Errorf("NormalizeFormat(): got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func loadProfile() error {
+	return errors.Wrap(errors.Wrapc(io.EOF, 909501, "read user"), "load profile")
+}
+
+func TestAssertGolden(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "error.golden")
+
+	t.Setenv(UpdateGoldenEnv, "1")
+	AssertGolden(t, loadProfile(), file)
+	t.Setenv(UpdateGoldenEnv, "")
+
+	golden, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(golden), "errtest.loadProfile\n\tassert_test.go:_") {
+		t.Errorf("golden file: got\n%s\nwant the normalized frame of loadProfile", golden)
+	}
+	AssertGolden(t, loadProfile(), file)
+	AssertFormat(t, loadProfile(), string(golden))
+
+	rt := &recordingT{}
+	AssertGolden(rt, errors.WithCode(909501, "other"), file)
+	AssertGolden(rt, loadProfile(), filepath.Join(t.TempDir(), "missing.golden"))
+	if len(rt.failures) != 2 {
+		t.Errorf("failures: got %q, want 2 failures", rt.failures)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package errtest helps writing contract tests over the error surface of an
+// HTTP service: it records the response written for an error and asserts
+// its status, headers and JSON envelope in a few lines:
+//
+//	func TestNotFound(t *testing.T) {
+//		errtest.Record(t, nil, errors.WithCode(codes.NotFound, "user 7")).
+//			ExpectStatus(http.StatusNotFound).
+//			ExpectHeader("Content-Type", "application/json; charset=utf-8").
+//			ExpectEnvelope(errtest.Envelope{
+//				Code:    codes.NotFound,
+//				Message: "The resource was not found",
+//			})
+//	}
+//
+// It also asserts the codes and chains of errors, see AssertCode and
+// RequireChainContains, and compares their %+v output with golden files
+// whose stack traces are normalized, see AssertGolden.
+package errtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/rtmzk/errors"
+)
+
+// Responder writes the response of an error, like errors.WriteError.
+type Responder func(w http.ResponseWriter, r *http.Request, err error)
+
+// Envelope is the JSON body written by errors.WriteError. The parts which
+// are not exposed by the profile of the request are empty.
+type Envelope struct {
+	Code      int                    `json:"code,omitempty"`
+	Message   string                 `json:"message"`
+	Reference string                 `json:"reference,omitempty"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+	Chain     []string               `json:"chain,omitempty"`
+	Stack     []string               `json:"stack,omitempty"`
+}
+
+// ResponseRecorder is the response recorded for an error by Record. Its
+// Expect methods report the mismatches on the test and return the recorder,
+// so that they can be chained.
+type ResponseRecorder struct {
+	*httptest.ResponseRecorder
+	t testing.TB
+}
+
+// Record records the response written by errors.WriteError for err to r.
+// A nil r stands for a GET request of "/".
+func Record(t testing.TB, r *http.Request, err error) *ResponseRecorder {
+	t.Helper()
+	return RecordWith(t, errors.WriteError, r, err)
+}
+
+// RecordWith records the response written by respond for err to r.
+// A nil r stands for a GET request of "/".
+func RecordWith(t testing.TB, respond Responder, r *http.Request, err error) *ResponseRecorder {
+	t.Helper()
+	if r == nil {
+		r = httptest.NewRequest(http.MethodGet, "/", nil)
+	}
+	rec := httptest.NewRecorder()
+	respond(rec, r, err)
+	return &ResponseRecorder{ResponseRecorder: rec, t: t}
+}
+
+// ExpectStatus checks the status of the response.
+func (rr *ResponseRecorder) ExpectStatus(status int) *ResponseRecorder {
+	rr.t.Helper()
+	if rr.Code != status {
+		rr.t.Errorf("status: got %d, want %d", rr.Code, status)
+	}
+	return rr
+}
+
+// ExpectHeader checks the value of the header key of the response. An
+// empty value checks that the header is not set.
+func (rr *ResponseRecorder) ExpectHeader(key, value string) *ResponseRecorder {
+	rr.t.Helper()
+	if got := rr.Header().Get(key); got != value {
+		rr.t.Errorf("header %s: got %q, want %q", key, got, value)
+	}
+	return rr
+}
+
+// Envelope returns the JSON envelope of the response. The test fails if
+// the body is not a JSON envelope.
+func (rr *ResponseRecorder) Envelope() Envelope {
+	rr.t.Helper()
+	var env Envelope
+	if err := json.Unmarshal(rr.Body.Bytes(), &env); err != nil {
+		rr.t.Fatalf("body: got %q, want a JSON envelope: %v", rr.Body.String(), err)
+	}
+	return env
+}
+
+// ExpectEnvelope checks the JSON envelope of the response. The fields of
+// want are compared after a JSON round trip, so that numbers can be given
+// as ints although they are decoded as float64.
+func (rr *ResponseRecorder) ExpectEnvelope(want Envelope) *ResponseRecorder {
+	rr.t.Helper()
+	got := rr.Envelope()
+	want = normalize(rr.t, want)
+	if !reflect.DeepEqual(got, want) {
+		rr.t.Errorf("envelope: got %+v, want %+v", got, want)
+	}
+	return rr
+}
+
+// ExpectEmptyBody checks that the response has no body, as for HEAD
+// requests and statuses forbidding one.
+func (rr *ResponseRecorder) ExpectEmptyBody() *ResponseRecorder {
+	rr.t.Helper()
+	if rr.Body.Len() != 0 {
+		rr.t.Errorf("body: got %q, want none", rr.Body.String())
+	}
+	return rr
+}
+
+// normalize returns env after a JSON round trip.
+func normalize(t testing.TB, env Envelope) Envelope {
+	t.Helper()
+	byts, err := json.Marshal(env)
+	if err != nil {
+		t.Fatalf("envelope: %v", err)
+	}
+	var ret Envelope
+	if err := json.Unmarshal(byts, &ret); err != nil {
+		t.Fatalf("envelope: %v", err)
+	}
+	return ret
+}
@@ -0,0 +1,97 @@
+package errtest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rtmzk/errors"
+)
+
+func init() {
+	errors.Register(errors.NewCoder(909501, http.StatusNotFound, "user not found", "https://example.com/909501"))
+	errors.Register(errors.NewCoder(909502, http.StatusNotModified, "not modified", ""))
+}
+
+// recordingT records the failures instead of failing the test.
+type recordingT struct {
+	testing.TB
+	failures []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingT) Fatalf(format string, args ...interface{}) {
+	t.Errorf(format, args...)
+}
+
+func TestRecord(t *testing.T) {
+	err := errors.WithFields(errors.WithCode(909501, "user 7 missing"), "user_id", 7)
+
+	Record(t, nil, err).
+		ExpectStatus(http.StatusNotFound).
+		ExpectHeader(errors.HeaderErrorCode, "909501").
+		ExpectHeader("Content-Type", "application/json; charset=utf-8").
+		ExpectEnvelope(Envelope{
+			Code:      909501,
+			Message:   "user not found",
+			Reference: "https://example.com/909501",
+		})
+
+	r := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+	r = r.WithContext(errors.WithProfile(r.Context(), errors.ProfilePartner))
+	Record(t, r, err).ExpectEnvelope(Envelope{
+		Code:      909501,
+		Message:   "user not found",
+		Reference: "https://example.com/909501",
+		Fields:    map[string]interface{}{"user_id": 7},
+	})
+
+	Record(t, httptest.NewRequest(http.MethodHead, "/", nil), err).
+		ExpectStatus(http.StatusNotFound).
+		ExpectEmptyBody()
+	Record(t, nil, errors.WithCode(909502, "cached")).
+		ExpectStatus(http.StatusNotModified).
+		ExpectHeader("Content-Type", "").
+		ExpectEmptyBody()
+}
+
+func TestRecordFailures(t *testing.T) {
+	err := errors.WithCode(909501, "user 7 missing")
+	tests := []struct {
+		check func(rr *ResponseRecorder)
+		want  string
+	}{
+		{func(rr *ResponseRecorder) { rr.ExpectStatus(http.StatusOK) }, "status: got 404, want 200"},
+		{func(rr *ResponseRecorder) { rr.ExpectHeader(errors.HeaderErrorCode, "1") }, `got "909501", want "1"`},
+		{func(rr *ResponseRecorder) { rr.ExpectEnvelope(Envelope{Code: 909501}) }, "envelope: got"},
+		{func(rr *ResponseRecorder) { rr.ExpectEmptyBody() }, "want none"},
+	}
+
+	for _, tt := range tests {
+		rt := &recordingT{TB: t}
+		tt.check(Record(rt, nil, err))
+		if len(rt.failures) != 1 || !strings.Contains(rt.failures[0], tt.want) {
+			t.Errorf("failures: got %q, want one containing %q", rt.failures, tt.want)
+		}
+	}
+}
+
+func TestRecordWith(t *testing.T) {
+	respond := func(w http.ResponseWriter, _ *http.Request, err error) {
+		errors.WriteStatus(w, err)
+	}
+	rt := &recordingT{TB: t}
+	rr := RecordWith(rt, respond, nil, errors.WithCode(909501, "user 7 missing"))
+	rr.ExpectStatus(http.StatusNotFound).ExpectEmptyBody()
+	rr.Envelope()
+	if len(rt.failures) != 1 || !strings.Contains(rt.failures[0], "want a JSON envelope") {
+		t.Errorf("failures: got %q, want the body to be rejected", rt.failures)
+	}
+}
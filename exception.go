@@ -0,0 +1,119 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Exception is a normalized layer of an error chain, in the shape of the
+// exception interface of crash reporters such as Sentry, see ExceptionChain.
+type Exception struct {
+	// Type is the Go type of the layer, e.g. "*fs.PathError".
+	Type string `json:"type"`
+
+	// Message is the message of the layer.
+	Message string `json:"message"`
+
+	// Code is the code of the layer, if it is a coded error.
+	Code int `json:"code,omitempty"`
+
+	// Frames is the stack recorded by the layer, or by the wrappers
+	// annotating it, innermost first.
+	Frames []ExceptionFrame `json:"frames,omitempty"`
+}
+
+// ExceptionFrame is a frame of the stack of an Exception.
+type ExceptionFrame struct {
+	// Function is the fully qualified name of the function, e.g.
+	// "github.com/acme/app/store.(*DB).Load".
+	Function string `json:"function"`
+
+	// Package is the import path of the package of the function.
+	Package string `json:"package"`
+
+	// File and Line locate the call.
+	File string `json:"file"`
+	Line int    `json:"line"`
+
+	// InApp reports whether the function belongs to the application rather
+	// than to the Go runtime or standard library.
+	InApp bool `json:"in_app"`
+}
+
+// ExceptionChain returns the layers of err's chain as exceptions, innermost
+// first, like the exception values of a Sentry event. Layers which only
+// annotate the layer below, such as WithStack or WithFields, are folded
+// into it: their stack is used when the annotated layer has none.
+//
+// The frames are resolved from the recorded program counters, so that
+// inlined functions get a frame of their own, which parsing the %+v output
+// loses. The stack filters apply, see AddStackFilter.
+// ExceptionChain returns nil if err is nil.
+func ExceptionChain(err error) []Exception {
+	if err == nil {
+		return nil
+	}
+
+	errs := list(err)
+	var ret []Exception
+	for i := len(errs) - 1; i >= 0; i-- {
+		e := errs[i]
+		s := stackOf(e)
+		if i < len(errs)-1 && e.Error() == errs[i+1].Error() {
+			// An annotation of the layer below.
+			if last := &ret[len(ret)-1]; last.Frames == nil && s != nil {
+				last.Frames = exceptionFrames(s)
+			}
+			continue
+		}
+
+		x := Exception{
+			Type:    fmt.Sprintf("%T", e),
+			Message: buildFormatInfo(e).err,
+		}
+		if c, ok := e.(CodeError); ok {
+			x.Code = c.Code()
+		}
+		if s != nil {
+			x.Frames = exceptionFrames(s)
+		}
+		ret = append(ret, x)
+	}
+	return ret
+}
+
+// exceptionFrames resolves the frames of s, expanding inlined calls.
+func exceptionFrames(s *stack) []ExceptionFrame {
+	pcs := s.frames()
+	ret := make([]ExceptionFrame, 0, len(pcs))
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		if f.Function != "" {
+			ret = append(ret, ExceptionFrame{
+				Function: f.Function,
+				Package:  funcPackage(f.Function),
+				File:     f.File,
+				Line:     f.Line,
+				InApp:    isAppFrame(f.Function),
+			})
+		}
+		if !more {
+			return ret
+		}
+	}
+}
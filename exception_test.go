@@ -0,0 +1,48 @@
+package errors
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func init() {
+	Register(NewCoder(910601, 502, "upstream failed", ""))
+}
+
+// leafError is small enough to be inlined in its callers.
+func leafError() error { return WithStack(io.EOF) }
+
+func TestExceptionChain(t *testing.T) {
+	err := WithFields(Wrapc(Wrap(leafError(), "read body"), 910601, "fetch profile"), "user", 42)
+
+	got := ExceptionChain(err)
+	want := []Exception{
+		{Type: "*errors.errorString", Message: "EOF"},
+		{Type: "*errors.withMessage", Message: "read body: EOF"},
+		{Type: "*errors.withCode", Message: "fetch profile", Code: 910601},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ExceptionChain: got %d exceptions %+v, want %d", len(got), got, len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Type != w.Type || g.Message != w.Message || g.Code != w.Code {
+			t.Errorf("exception %d: got %s %q %d, want %s %q %d", i, g.Type, g.Message, g.Code, w.Type, w.Message, w.Code)
+		}
+		if len(g.Frames) == 0 {
+			t.Errorf("exception %d: got no frames", i)
+			continue
+		}
+		if f := g.Frames[0]; !strings.HasSuffix(f.File, "exception_test.go") || !f.InApp || f.Package != "github.com/rtmzk/errors" {
+			t.Errorf("exception %d: got innermost frame %+v, want one of exception_test.go", i, f)
+		}
+	}
+	if fn := got[0].Frames[0].Function; fn != "github.com/rtmzk/errors.leafError" {
+		t.Errorf("root frame: got %s, want %s", fn, "github.com/rtmzk/errors.leafError")
+	}
+
+	if got := ExceptionChain(nil); got != nil {
+		t.Errorf("ExceptionChain(nil): got %v, want nil", got)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"expvar"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// ExpvarName is the name of the expvar variable publishing the per-code
+// usage counters, served by the expvar handler under /debug/vars.
+const ExpvarName = "errors.codes"
+
+var (
+	expvarOnce    sync.Once
+	expvarEnabled int32
+)
+
+// SetExpvar enables or disables the publication of the per-code usage
+// counters, see CodeUsage, as the ExpvarName expvar variable. The variable
+// is published the first time this is enabled, and reads as null while
+// disabled, since expvar variables cannot be removed.
+func SetExpvar(enabled bool) {
+	if !enabled {
+		atomic.StoreInt32(&expvarEnabled, 0)
+		return
+	}
+	expvarOnce.Do(func() {
+		expvar.Publish(ExpvarName, expvar.Func(expvarCounts))
+	})
+	atomic.StoreInt32(&expvarEnabled, 1)
+}
+
+func expvarEnabledNow() bool {
+	return atomic.LoadInt32(&expvarEnabled) == 1
+}
+
+func expvarCounts() interface{} {
+	if !expvarEnabledNow() {
+		return nil
+	}
+	counts := map[string]uint64{}
+	for code, n := range UsageCounts() {
+		counts[strconv.Itoa(code)] = n
+	}
+	return counts
+}
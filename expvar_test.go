@@ -0,0 +1,30 @@
+package errors
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+)
+
+func TestSetExpvar(t *testing.T) {
+	defer SetExpvar(false)
+	SetExpvar(true)
+
+	_ = WithCode(902901, "counted")
+	v := expvar.Get(ExpvarName)
+	if v == nil {
+		t.Fatalf("expvar.Get(%q): got nil", ExpvarName)
+	}
+	var counts map[string]uint64
+	if err := json.Unmarshal([]byte(v.String()), &counts); err != nil {
+		t.Fatal(err)
+	}
+	if counts["902901"] == 0 {
+		t.Errorf("expvar %s: got %v, want a count for 902901", ExpvarName, counts)
+	}
+
+	SetExpvar(false)
+	if got := v.String(); got != "null" {
+		t.Errorf("expvar %s when disabled: got %s, want null", ExpvarName, got)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// An error carries two messages. The internal one, returned by Error and
+// detailed by %+v, is meant for developers: it holds the messages of the
+// wrapped causes, such as a database error, and their stacks. The external
+// one, returned by External, is meant for clients: it is the message of
+// the Coder of the error, which never depends on its causes.
+
+// External returns the message of err which is safe to show to clients:
+// the message of its Coder, or the message set by an enricher, see
+// Message. Unlike err.Error(), it never contains the messages of the
+// causes of err. External returns "" if err is nil.
+func External(err error) string {
+	return Message(err, "")
+}
+
+// Internal returns the developer message of err, with the messages, fields
+// and stacks of every layer of its chain, as printed by %+v.
+// Internal returns "" if err is nil.
+func Internal(err error) string {
+	if err == nil {
+		return ""
+	}
+	return fmt.Sprintf("%+v", err)
+}
+
+// sealInternal is 1 when the internal parts of errors are never served.
+var sealInternal int32
+
+// SetSealInternal guarantees, when enabled, that WriteError and
+// WriteResponse never serve the internal parts of errors, their fields,
+// chain and stack, whatever the profile selected, e.g. in production
+// builds where a misconfigured WithProfile must not leak database errors.
+func SetSealInternal(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&sealInternal, v)
+}
+
+func sealInternalNow() bool {
+	return atomic.LoadInt32(&sealInternal) == 1
+}
+
+// seal returns p without the parts exposing the internal message of the
+// error.
+func seal(p Profile) Profile {
+	p.Fields, p.Chain, p.Stack = false, false, false
+	return p
+}
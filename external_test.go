@@ -0,0 +1,71 @@
+package errors
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExternal(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(911901, 500, "Internal error", ""))
+	err := Wrapc(sql.ErrConnDone, 911901, "query users")
+
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{err, "Internal error"},
+		{Wrap(err, "list users"), "Internal error"},
+		{sql.ErrNoRows, unknownCoder.String()},
+	}
+
+	for _, tt := range tests {
+		if got := External(tt.err); got != tt.want {
+			t.Errorf("External(%v): got %q, want %q", tt.err, got, tt.want)
+		}
+	}
+
+	if got := Internal(err); !strings.Contains(got, sql.ErrConnDone.Error()) || !strings.Contains(got, "query users") {
+		t.Errorf("Internal(): got %q, want the cause and the message", got)
+	}
+	if got := Internal(nil); got != "" {
+		t.Errorf("Internal(nil): got %q, want %q", got, "")
+	}
+}
+
+func TestSealInternal(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(911902, 500, "Internal error", ""))
+	err := WithFields(Wrapc(sql.ErrConnDone, 911902, "query users"), "table", "users")
+	leaks := []string{`"fields"`, `"chain"`, `"stack"`, sql.ErrConnDone.Error()}
+
+	rec := httptest.NewRecorder()
+	WriteResponse(rec, err, IncludeInternal(true), SealInternal())
+	for _, leak := range leaks {
+		if strings.Contains(rec.Body.String(), leak) {
+			t.Errorf("WriteResponse(SealInternal()): got body %s, want it without %s", rec.Body.String(), leak)
+		}
+	}
+	if !strings.Contains(rec.Body.String(), `"code":911902`) {
+		t.Errorf("WriteResponse(SealInternal()): got body %s, want the code", rec.Body.String())
+	}
+
+	SetSealInternal(true)
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(WithProfile(r.Context(), ProfileInternal))
+	rec = httptest.NewRecorder()
+	WriteError(rec, r, err)
+	for _, leak := range leaks {
+		if strings.Contains(rec.Body.String(), leak) {
+			t.Errorf("WriteError() sealed: got body %s, want it without %s", rec.Body.String(), leak)
+		}
+	}
+}
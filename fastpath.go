@@ -0,0 +1,92 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strings"
+)
+
+// The constructors are on the hot path of the services returning errors to
+// their clients, so they avoid the allocations which do not show in their
+// results: the static messages are not formatted, Wrap and Wrapf allocate
+// their two layers at once, the stacks are recorded in a buffer on the
+// goroutine stack and copied into a single allocation of their size, see
+// captureStack, and the chain is searched without the allocations of As.
+
+// sprintf is fmt.Sprintf, which a format without arguments nor verbs
+// bypasses, since it is its own result.
+func sprintf(format string, args ...interface{}) string {
+	if len(args) == 0 && strings.IndexByte(format, '%') < 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// errorf is fmt.Errorf, which a format without arguments nor verbs
+// bypasses like for sprintf. The result is of the same type.
+func errorf(format string, args ...interface{}) error {
+	if len(args) == 0 && strings.IndexByte(format, '%') < 0 {
+		return stderrors.New(format)
+	}
+	return fmt.Errorf(format, args...)
+}
+
+// wrapped holds the two layers of the errors returned by Wrap and Wrapf,
+// allocated together.
+type wrapped struct {
+	withStack
+	msg withMessage
+}
+
+// wrap returns err annotated with msg and the stack st, called at pc, see
+// Wrap.
+func wrap(err error, msg string, st *stack, pc uintptr) error {
+	w := &wrapped{msg: withMessage{cause: err, msg: msg}}
+	w.withStack = withStack{&w.msg, st, pc}
+	return &w.withStack
+}
+
+// findAs returns the first error of err's chain matching T, in the order
+// of As, without allocating the target of As. The errors with an As method
+// are handed to As.
+func findAs[T error](err error) (T, bool) {
+	var zero T
+	for err != nil {
+		if v, ok := err.(T); ok {
+			return v, true
+		}
+		if _, ok := err.(interface{ As(interface{}) bool }); ok {
+			var v T
+			ok := As(err, &v)
+			return v, ok
+		}
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			err = u.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, e := range u.Unwrap() {
+				if v, ok := findAs[T](e); ok {
+					return v, true
+				}
+			}
+			return zero, false
+		default:
+			return zero, false
+		}
+	}
+	return zero, false
+}
@@ -0,0 +1,90 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"testing"
+)
+
+func init() {
+	Register(NewCoder(914302, http.StatusInternalServerError, "allocations", ""))
+}
+
+func TestConstructorAllocs(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func() error
+		max  float64
+	}{
+		{"New", func() error { return New("static") }, 2},
+		{"Wrap", func() error { return Wrap(io.EOF, "static") }, 2},
+		{"Wrapf", func() error { return Wrapf(io.EOF, "static") }, 2},
+		{"WithCode", func() error { return WithCode(914302, "static") }, 3},
+		{"Wrapc", func() error { return Wrapc(io.EOF, 914302, "static") }, 3},
+	}
+	for _, tt := range tests {
+		if got := testing.AllocsPerRun(100, func() { GlobalE = tt.fn() }); got > tt.max {
+			t.Errorf("%s: got %v allocations, want at most %v", tt.name, got, tt.max)
+		}
+	}
+}
+
+func TestStaticFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		args   []interface{}
+	}{
+		{"static", nil},
+		{"100%", nil},
+		{"100%%", nil},
+		{"%d items", []interface{}{3}},
+		{"wrapped: %w", []interface{}{io.EOF}},
+	}
+	for _, tt := range tests {
+		if got, want := sprintf(tt.format, tt.args...), fmt.Sprintf(tt.format, tt.args...); got != want {
+			t.Errorf("sprintf(%q): got %q, want %q", tt.format, got, want)
+		}
+		got, want := errorf(tt.format, tt.args...), fmt.Errorf(tt.format, tt.args...)
+		if got.Error() != want.Error() || fmt.Sprintf("%T", got) != fmt.Sprintf("%T", want) {
+			t.Errorf("errorf(%q): got %T %q, want %T %q", tt.format, got, got, want, want)
+		}
+	}
+}
+
+// asPathError converts itself into a *fs.PathError through As.
+type asPathError struct{}
+
+func (asPathError) Error() string { return "as" }
+
+func (asPathError) As(target interface{}) bool {
+	if p, ok := target.(**fs.PathError); ok {
+		*p = &fs.PathError{Op: "as", Path: "/as"}
+		return true
+	}
+	return false
+}
+
+func TestFindAs(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, ""},
+		{"direct", &fs.PathError{Op: "open", Path: "/a"}, "/a"},
+		{"wrapped", fmt.Errorf("load: %w", &fs.PathError{Op: "open", Path: "/b"}), "/b"},
+		{"joined", Join(io.EOF, Wrap(&fs.PathError{Op: "open", Path: "/c"}, "read")), "/c"},
+		{"As method", Wrap(asPathError{}, "read"), "/as"},
+		{"none", Wrap(io.EOF, "read"), ""},
+	}
+	for _, tt := range tests {
+		got, ok := findAs[*fs.PathError](tt.err)
+		var want *fs.PathError
+		wantOK := As(tt.err, &want)
+		if ok != wantOK || (ok && got.Path != want.Path) || (ok && got.Path != tt.want) {
+			t.Errorf("%s: findAs(): got (%v, %v), want (%v, %v)", tt.name, got, ok, want, wantOK)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import "strings"
+
+// fieldLabels contains the display labels of the request fields, by field
+// and language. It is guarded by codeMux.
+var fieldLabels = map[string]map[string]string{}
+
+// RegisterFieldLabel registers the display label of a request field in the
+// given language, a BCP 47 tag such as "fr" or "pt-BR". The label of a field
+// path with indices, such as "user.emails[0]", defaults to the label of the
+// path without them, "user.emails".
+//
+// The labels are set on the field violations, see BadRequest, when they are
+// rendered in a language, so that the same error produces user facing
+// messages in the language of each caller, see ServeProblem and
+// LocalizeViolations.
+func RegisterFieldLabel(field, lang, label string) {
+	codeMux.Lock()
+	defer codeMux.Unlock()
+
+	if fieldLabels[field] == nil {
+		fieldLabels[field] = map[string]string{}
+	}
+	fieldLabels[field][strings.ToLower(lang)] = label
+}
+
+// FieldLabel returns the display label of field in the given language, its
+// base language or the fallback language, like Message. It returns "" if no
+// label is registered.
+func FieldLabel(field, lang string) string {
+	codeMux.RLock()
+	defer codeMux.RUnlock()
+
+	for _, f := range []string{field, stripIndices(field)} {
+		for _, l := range []string{lang, fallbackLanguage} {
+			if label, ok := lookupLanguage(fieldLabels[f], l); ok {
+				return label
+			}
+		}
+	}
+	return ""
+}
+
+// stripIndices removes the indices of a field path, e.g. "a[0].b[1]"
+// becomes "a.b".
+func stripIndices(field string) string {
+	if !strings.Contains(field, "[") {
+		return field
+	}
+	var b strings.Builder
+	depth := 0
+	for _, r := range field {
+		switch {
+		case r == '[':
+			depth++
+		case r == ']' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// LocalizeViolations returns a copy of violations whose labels are set in
+// the given language, see RegisterFieldLabel. Violations whose field has no
+// label keep theirs.
+func LocalizeViolations(violations []FieldViolation, lang string) []FieldViolation {
+	if len(violations) == 0 {
+		return nil
+	}
+	ret := make([]FieldViolation, len(violations))
+	for i, v := range violations {
+		if label := FieldLabel(v.Field, lang); label != "" {
+			v.Label = label
+		}
+		ret[i] = v
+	}
+	return ret
+}
+
+// localizeDetails returns details whose BadRequest details are localized in
+// lang, see LocalizeViolations.
+func localizeDetails(details []Detail, lang string) []Detail {
+	ret := make([]Detail, len(details))
+	for i, d := range details {
+		if b, ok := d.(BadRequest); ok {
+			d = BadRequest{FieldViolations: LocalizeViolations(b.FieldViolations, lang)}
+		}
+		ret[i] = d
+	}
+	return ret
+}
@@ -0,0 +1,72 @@
+package errors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFieldLabel(t *testing.T) {
+	RegisterFieldLabel("label_test.email", "fr", "Adresse e-mail")
+	RegisterFieldLabel("label_test.email", "en", "Email address")
+	RegisterFieldLabel("label_test.phones", "pt-BR", "Telefones")
+
+	tests := []struct {
+		field string
+		lang  string
+		want  string
+	}{
+		{"label_test.email", "fr-CA", "Adresse e-mail"},
+		{"label_test.email", "de", "Email address"},
+		{"label_test.phones[2]", "pt-br", "Telefones"},
+		{"label_test.phones[2]", "fr", ""},
+		{"label_test.unknown", "fr", ""},
+	}
+	for _, tt := range tests {
+		if got := FieldLabel(tt.field, tt.lang); got != tt.want {
+			t.Errorf("FieldLabel(%q, %q): got %q, want %q", tt.field, tt.lang, got, tt.want)
+		}
+	}
+
+	violations := []FieldViolation{
+		{Field: "label_test.email", Description: "invalid"},
+		{Field: "label_test.unknown", Description: "missing", Label: "Unknown"},
+	}
+	want := []FieldViolation{
+		{Field: "label_test.email", Description: "invalid", Label: "Adresse e-mail"},
+		{Field: "label_test.unknown", Description: "missing", Label: "Unknown"},
+	}
+	if got := LocalizeViolations(violations, "fr"); !reflect.DeepEqual(got, want) {
+		t.Errorf("LocalizeViolations(fr): got %+v, want %+v", got, want)
+	}
+	if violations[0].Label != "" {
+		t.Errorf("LocalizeViolations: modified its argument")
+	}
+}
+
+func TestServeProblemFieldLabels(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(908301, 400, "invalid request", ""))
+	RegisterFieldLabel("label_test.name", "fr", "Nom")
+	RegisterFieldLabel("label_test.name", "en", "Name")
+
+	err := WithDetails(WithCode(908301, "validation failed"),
+		BadRequest{FieldViolations: []FieldViolation{{Field: "label_test.name", Description: "required"}}})
+
+	for lang, want := range map[string]string{"fr": `"label":"Nom"`, "en": `"label":"Name"`} {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.Header.Set("Accept-Language", lang)
+		rec := httptest.NewRecorder()
+		ServeProblem(rec, r, err)
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("ServeProblem(%s): got %s, want %s", lang, rec.Body.String(), want)
+		}
+	}
+	if got := FieldViolations(err)[0].Label; got != "" {
+		t.Errorf("FieldViolations after ServeProblem: got label %q, want none", got)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"sort"
+	"sync/atomic"
+)
+
+// FieldOverflow is the field holding the fields merged away by
+// OverflowMerge.
+const FieldOverflow = "overflow"
+
+// OverflowPolicy decides what happens to the fields attached beyond the
+// maximum set by SetMaxFields.
+type OverflowPolicy int
+
+// Overflow policies.
+const (
+	// OverflowDropNew ignores the new fields.
+	OverflowDropNew OverflowPolicy = iota
+
+	// OverflowDropByKey removes fields attached earlier to make room for
+	// the new ones, those whose keys sort first going first. The fields
+	// keep no insertion order, so these are not necessarily the oldest.
+	OverflowDropByKey
+
+	// OverflowMerge moves the new fields into the FieldOverflow field,
+	// which holds a map itself bounded by the maximum. The FieldOverflow
+	// field is not counted in the maximum.
+	OverflowMerge
+)
+
+// fieldLimit is the bound set by SetMaxFields.
+type fieldLimit struct {
+	max    int
+	policy OverflowPolicy
+}
+
+// fieldLimits holds the fieldLimit, loaded by every constructor attaching
+// fields.
+var fieldLimits atomic.Value // fieldLimit
+
+// SetMaxFields bounds the number of fields held by a single layer of an
+// error, which protects memory when middleware layers keep attaching
+// context to an error looping through a retry pipeline. The policy decides
+// what happens to the fields beyond the bound. A max of 0, the default,
+// disables the bound.
+func SetMaxFields(max int, policy OverflowPolicy) {
+	if max < 0 {
+		max = 0
+	}
+	fieldLimits.Store(fieldLimit{max: max, policy: policy})
+}
+
+func fieldLimitNow() fieldLimit {
+	l, _ := fieldLimits.Load().(fieldLimit)
+	return l
+}
+
+// limitFields returns a copy of old holding the added fields, within the
+// bound set by SetMaxFields. Existing keys are always overwritten.
+func limitFields(old, added map[string]interface{}) map[string]interface{} {
+	ret := mergeFields(nil, old)
+	limit := fieldLimitNow()
+	maxFields := limit.max
+	if maxFields == 0 || len(ret)+len(added) <= maxFields {
+		return mergeFields(ret, added)
+	}
+
+	keys := make([]string, 0, len(added))
+	for k := range added {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var dropped []string
+	for k := range ret {
+		if _, ok := added[k]; !ok && k != FieldOverflow {
+			dropped = append(dropped, k)
+		}
+	}
+	sort.Strings(dropped)
+
+	if ret == nil {
+		ret = make(map[string]interface{}, maxFields)
+	}
+	for _, k := range keys {
+		if _, ok := ret[k]; ok || len(ret) < maxFields {
+			ret[k] = added[k]
+			continue
+		}
+		switch limit.policy {
+		case OverflowDropByKey:
+			if len(dropped) > 0 {
+				delete(ret, dropped[0])
+				dropped = dropped[1:]
+				ret[k] = added[k]
+			}
+		case OverflowMerge:
+			overflow, _ := ret[FieldOverflow].(map[string]interface{})
+			if overflow == nil {
+				overflow = map[string]interface{}{}
+			} else {
+				overflow = mergeFields(nil, overflow)
+			}
+			if _, ok := overflow[k]; ok || len(overflow) < maxFields {
+				overflow[k] = added[k]
+			}
+			ret[FieldOverflow] = overflow
+		}
+	}
+	return ret
+}
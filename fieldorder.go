@@ -0,0 +1,109 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"sort"
+)
+
+// Field is a structured field of an error, see Fields.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// FieldList is an ordered list of fields. Unlike a map, it keeps the order
+// of its fields when rendered as JSON or logged, so it can be attached as
+// the value of a field where the order of the fields matters:
+//
+//	err = errors.WithFields(err, "request", errors.FieldList{
+//		{Key: "method", Value: r.Method},
+//		{Key: "path", Value: r.URL.Path},
+//	})
+//
+// Given to WithFields in place of a key, its fields are added to the fields
+// of the error one by one, which are not ordered.
+type FieldList []Field
+
+// MarshalJSON implements json.Marshaler. The fields are encoded as a JSON
+// object whose keys are in the order of the list.
+func (l FieldList) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range l {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// LogValue implements slog.LogValuer. The fields are logged as a group, in
+// the order of the list.
+func (l FieldList) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, len(l))
+	for _, f := range l {
+		attrs = append(attrs, slog.Any(f.Key, f.Value))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// Map returns the fields of l as a map. When several fields have the same
+// key, the last one wins.
+func (l FieldList) Map() map[string]interface{} {
+	if len(l) == 0 {
+		return nil
+	}
+	m := make(map[string]interface{}, len(l))
+	for _, f := range l {
+		m[f.Key] = f.Value
+	}
+	return m
+}
+
+// SortedFields returns the fields of err's chain, see Fields, sorted by
+// key, so that they can be iterated in a deterministic order.
+// SortedFields returns nil if the chain holds no field.
+func SortedFields(err error) FieldList {
+	return sortFields(Fields(err))
+}
+
+// sortFields returns fields as a list sorted by key.
+func sortFields(fields map[string]interface{}) FieldList {
+	if len(fields) == 0 {
+		return nil
+	}
+	ret := make(FieldList, 0, len(fields))
+	for k, v := range fields {
+		ret = append(ret, Field{Key: k, Value: v})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Key < ret[j].Key })
+	return ret
+}
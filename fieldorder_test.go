@@ -0,0 +1,69 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSortedFields(t *testing.T) {
+	err := WithFields(io.EOF, "zeta", 1, "alpha", 2, "mid", 3)
+	err = WithFields(err, Field{Key: "beta", Value: 4}, FieldList{{Key: "omega", Value: 5}, {Key: "alpha", Value: 6}})
+
+	want := FieldList{{"alpha", 6}, {"beta", 4}, {"mid", 3}, {"omega", 5}, {"zeta", 1}}
+	for i := 0; i < 10; i++ {
+		if got := SortedFields(err); !reflect.DeepEqual(got, want) {
+			t.Fatalf("SortedFields(): got %v, want %v", got, want)
+		}
+	}
+	if got := SortedFields(io.EOF); got != nil {
+		t.Errorf("SortedFields(EOF): got %v, want nil", got)
+	}
+	if got := want.Map(); !reflect.DeepEqual(got, Fields(err)) {
+		t.Errorf("Map(): got %v, want %v", got, Fields(err))
+	}
+}
+
+func TestFieldListJSON(t *testing.T) {
+	tests := []struct {
+		list FieldList
+		want string
+	}{
+		{nil, `{}`},
+		{FieldList{{"z", 1}, {"a", "x"}}, `{"z":1,"a":"x"}`},
+		{FieldList{{"nested", FieldList{{"b", true}, {"a", nil}}}}, `{"nested":{"b":true,"a":null}}`},
+	}
+
+	for _, tt := range tests {
+		byts, err := json.Marshal(tt.list)
+		if err != nil || string(byts) != tt.want {
+			t.Errorf("json.Marshal(%v): got %s, %v, want %s", tt.list, byts, err, tt.want)
+		}
+	}
+
+	byts, _ := json.Marshal(map[string]interface{}{"list": FieldList{{"b", fmt.Errorf("x")}}})
+	if !strings.Contains(string(byts), `"b":{}`) {
+		t.Errorf("json.Marshal(nested): got %s, want the list encoded as an object", byts)
+	}
+}
+
+func TestFieldListLogValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	logger.Info("msg", "req", FieldList{{"z", 1}, {"a", 2}})
+	if got, want := buf.String(), "level=INFO msg=msg req.z=1 req.a=2\n"; got != want {
+		t.Errorf("slog: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+)
+
+// WithFields attaches structured fields to err, see Fields. The fields are
+// given as alternating keys and values, like the arguments of slog:
+//
+//	err = errors.WithFields(err, "user_id", id, "resource", name)
+//
+// A map[string]interface{}, Field or FieldList argument adds all its
+// entries instead, in order for the latter two. A key
+// which is not a string, or which lacks a value, is stored under
+// BadFieldKey.
+// If err is a coded error or already holds fields attached by WithFields, a
+// copy of it holding the merged fields is returned, so that errors looping
+// through a pipeline do not grow their chain. Otherwise err is wrapped.
+// The number of fields of a layer is bounded by SetMaxFields.
+//
+// The fields are copied on write: neither err nor the maps given are ever
+// modified, so that WithFields is safe to call concurrently on an error
+// shared across goroutines, such as the result of a singleflight call,
+// while other goroutines format it.
+// If err is nil, WithFields returns nil.
+func WithFields(err error, kv ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	fields := kvFields(kv)
+	if len(fields) == 0 {
+		return err
+	}
+
+	switch w := err.(type) {
+	case *withCode:
+		cp := *w
+		cp.fields = limitFields(w.fields, fields)
+		return &cp
+	case *withFields:
+		return &withFields{
+			error:  w.error,
+			fields: limitFields(w.fields, fields),
+		}
+	}
+
+	return &withFields{
+		error:  err,
+		fields: limitFields(nil, fields),
+	}
+}
+
+// BadFieldKey is the key of the values given to WithFields without a valid
+// key, like the !BADKEY of slog.
+const BadFieldKey = "!BADKEY"
+
+// kvFields converts the alternating keys and values given to WithFields
+// into a map.
+func kvFields(kv []interface{}) map[string]interface{} {
+	if len(kv) == 0 {
+		return nil
+	}
+	fields := make(map[string]interface{}, (len(kv)+1)/2)
+	for i := 0; i < len(kv); i++ {
+		switch k := kv[i].(type) {
+		case map[string]interface{}:
+			for key, v := range k {
+				fields[key] = v
+			}
+		case Field:
+			fields[k.Key] = k.Value
+		case FieldList:
+			for _, f := range k {
+				fields[f.Key] = f.Value
+			}
+		case string:
+			if i+1 == len(kv) {
+				fields[BadFieldKey] = k
+				continue
+			}
+			fields[k] = kv[i+1]
+			i++
+		default:
+			fields[BadFieldKey] = k
+		}
+	}
+	return fields
+}
+
+type withFields struct {
+	error
+	fields map[string]interface{}
+}
+
+func (w *withFields) Cause() error { return w.error }
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (w *withFields) Unwrap() error { return w.error }
+
+func (w *withFields) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "%+v", w.Cause())
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, w.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", w.Error())
+	}
+}
+
+// Fields returns the structured fields of err's chain. When several layers
+// hold the same key, the outermost one wins. The fields are rendered in the
+// order of their keys by every format of this package, and SortedFields
+// returns them in that order.
+// Fields returns nil if the chain holds no field.
+//
+// The environment fields stamped on the errors created while an environment
+// is set, see SetEnvironment, have the lowest precedence.
+func Fields(err error) map[string]interface{} {
+	var ret map[string]interface{}
+	errs := list(err)
+	for i := len(errs) - 1; i >= 0; i-- {
+		var env, fields map[string]interface{}
+		switch e := errs[i].(type) {
+		case *fundamental:
+			env = e.env
+		case *withCode:
+			env, fields = e.env, e.fields
+		case *withAttempt:
+			fields = e.fields()
+		case *withFields:
+			fields = e.fields
+		}
+		ret = mergeFields(ret, env, fields)
+	}
+	return ret
+}
+
+// mergeFields copies the fields of each map into dst, in order, allocating
+// dst if needed. It returns dst, which stays nil if there is nothing to copy.
+func mergeFields(dst map[string]interface{}, maps ...map[string]interface{}) map[string]interface{} {
+	for _, m := range maps {
+		if len(m) == 0 {
+			continue
+		}
+		if dst == nil {
+			dst = make(map[string]interface{}, len(m))
+		}
+		for k, v := range m {
+			dst[k] = v
+		}
+	}
+	return dst
+}
+
+// pathFields lifts the operation and paths of a *fs.PathError or
+// *os.LinkError of err's chain into fields, which keeps file related errors
+// machine-readable without parsing their message.
+func pathFields(err error) map[string]interface{} {
+	if pathErr, ok := findAs[*fs.PathError](err); ok {
+		return map[string]interface{}{
+			"op":   pathErr.Op,
+			"path": pathErr.Path,
+		}
+	}
+
+	if linkErr, ok := findAs[*os.LinkError](err); ok {
+		return map[string]interface{}{
+			"op":       linkErr.Op,
+			"old_path": linkErr.Old,
+			"new_path": linkErr.New,
+		}
+	}
+
+	return nil
+}
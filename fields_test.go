@@ -0,0 +1,159 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestPathFields(t *testing.T) {
+	_, openErr := os.Open("/does/not/exist")
+	linkErr := os.Symlink("/does/not/exist", "/does/not/exist/either")
+
+	tests := []struct {
+		err  error
+		want map[string]interface{}
+	}{
+		{WithCode(901701, "no fields"), nil},
+		{Wrapc(io.EOF, 901701, "no fields"), nil},
+		{Wrapc(openErr, 901701, "open config"), map[string]interface{}{"op": "open", "path": "/does/not/exist"}},
+		{Wrapc(Wrap(openErr, "load"), 901701, "open config"), map[string]interface{}{"op": "open", "path": "/does/not/exist"}},
+		{Wrapc(linkErr, 901701, "link"), map[string]interface{}{"op": "symlink", "old_path": "/does/not/exist", "new_path": "/does/not/exist/either"}},
+	}
+
+	for i, tt := range tests {
+		if got := Fields(tt.err); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("test %d: Fields(%v): got %v, want %v", i+1, tt.err, got, tt.want)
+		}
+	}
+
+	var got []map[string]interface{}
+	if e := json.Unmarshal([]byte(fmt.Sprintf("%#v", tests[2].err)), &got); e != nil {
+		t.Fatalf("%%#v is not valid JSON: %v", e)
+	}
+	if len(got) != 1 || !reflect.DeepEqual(got[0]["fields"], tests[2].want) {
+		t.Errorf("%%#v fields: got %v, want %v", got, tests[2].want)
+	}
+}
+
+func TestWithFields(t *testing.T) {
+	if got := WithFields(nil, map[string]interface{}{"k": 1}); got != nil {
+		t.Errorf("WithFields(nil): got %v, want nil", got)
+	}
+
+	base := WithCode(901701, "coded")
+	err := WithFields(WithFields(base, map[string]interface{}{"a": 1}), map[string]interface{}{"b": 2})
+	if got, want := Fields(err), map[string]interface{}{"a": 1, "b": 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields(): got %v, want %v", got, want)
+	}
+	if got := Fields(base); got != nil {
+		t.Errorf("Fields(base): got %v, the shared coded error must not be modified", got)
+	}
+	if got := len(list(err)); got != 1 {
+		t.Errorf("WithFields(coded): got %d layers, want 1", got)
+	}
+
+	plain := WithFields(WithFields(io.EOF, map[string]interface{}{"a": 1}), map[string]interface{}{"a": 3})
+	if got, want := Fields(plain), map[string]interface{}{"a": 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields(plain): got %v, want %v", got, want)
+	}
+	if got := len(list(plain)); got != 2 {
+		t.Errorf("WithFields(plain): got %d layers, want 2", got)
+	}
+}
+
+func TestWithFieldsKV(t *testing.T) {
+	tests := []struct {
+		kv   []interface{}
+		want map[string]interface{}
+	}{
+		{nil, nil},
+		{[]interface{}{"user_id", 42, "request_id", "r-1"}, map[string]interface{}{"user_id": 42, "request_id": "r-1"}},
+		{[]interface{}{"a", 1, map[string]interface{}{"b": 2}}, map[string]interface{}{"a": 1, "b": 2}},
+		{[]interface{}{"a", 1, "dangling"}, map[string]interface{}{"a": 1, BadFieldKey: "dangling"}},
+		{[]interface{}{7, "a", 1}, map[string]interface{}{BadFieldKey: 7, "a": 1}},
+	}
+
+	for i, tt := range tests {
+		err := Wrap(WithFields(WithCode(901702, "coded"), tt.kv...), "wrapped")
+		if got := Fields(err); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("test %d: got %v, want %v", i+1, got, tt.want)
+		}
+	}
+}
+
+func TestMaxFields(t *testing.T) {
+	old := map[string]interface{}{"a": 1, "b": 2}
+	added := map[string]interface{}{"b": 20, "c": 3, "d": 4}
+
+	tests := []struct {
+		policy OverflowPolicy
+		want   map[string]interface{}
+	}{
+		{OverflowDropNew, map[string]interface{}{"a": 1, "b": 20, "c": 3}},
+		{OverflowDropByKey, map[string]interface{}{"b": 20, "c": 3, "d": 4}},
+		{OverflowMerge, map[string]interface{}{"a": 1, "b": 20, "c": 3,
+			FieldOverflow: map[string]interface{}{"d": 4}}},
+	}
+
+	for _, tt := range tests {
+		restore := NewConfig(LimitFields(3, tt.policy)).Apply()
+		got := Fields(WithFields(WithFields(io.EOF, old), added))
+		restore()
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("policy %d: got %v, want %v", tt.policy, got, tt.want)
+		}
+	}
+}
+
+func init() {
+	Register(NewCoder(909401, 500, "shared result", ""))
+}
+
+func TestWithFieldsConcurrent(t *testing.T) {
+	base := map[string]interface{}{"shared": true}
+	tests := []error{
+		WithFields(io.EOF, base),
+		WithFields(WithCode(909401, "shared result"), base),
+		WithCode(909401, "shared result"),
+	}
+
+	for _, shared := range tests {
+		var wg sync.WaitGroup
+		results := make([]error, 8)
+		for i := range results {
+			wg.Add(2)
+			go func(i int) {
+				defer wg.Done()
+				results[i] = WithFields(shared, "worker", i, "key"+strconv.Itoa(i), i)
+			}(i)
+			go func() {
+				defer wg.Done()
+				_ = fmt.Sprintf("%+v %#-v", shared, shared)
+				_, _ = json.Marshal(Fields(shared))
+			}()
+		}
+		wg.Wait()
+
+		for i, err := range results {
+			fields := Fields(err)
+			if fields["worker"] != i || fields["key"+strconv.Itoa(i)] != i {
+				t.Errorf("worker %d: got fields %v, want its own fields", i, fields)
+			}
+			if _, ok := fields["key"+strconv.Itoa((i+1)%len(results))]; ok {
+				t.Errorf("worker %d: got the fields of another worker: %v", i, fields)
+			}
+		}
+		if _, ok := Fields(shared)["worker"]; ok {
+			t.Errorf("shared error: got fields %v, want them unchanged", Fields(shared))
+		}
+	}
+	if len(base) != 1 {
+		t.Errorf("argument map: got %v, want it unchanged", base)
+	}
+}
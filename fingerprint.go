@@ -0,0 +1,142 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+)
+
+// fingerprint returns a hash of the error which is stable across
+// processes and deployments of the same code base.
+//
+// The codes of every coded layer of the chain and the type of the root
+// cause feed the hash. Messages are only used when the chain does not
+// carry any code, since coded messages are usually formatted with request
+// specific arguments. Stack frames are never used, so the fingerprint
+// does not change when unrelated code moves around.
+func fingerprint(err error) uint64 {
+	return fingerprintWith(err, fingerprintOptions{codes: true, rootType: true})
+}
+
+// fingerprintOptions select the components of a fingerprint.
+type fingerprintOptions struct {
+	codes    bool
+	rootType bool
+	message  bool
+	frames   int
+}
+
+// FingerprintOption selects a component feeding the hash of Fingerprint.
+type FingerprintOption func(*fingerprintOptions)
+
+// FingerprintCodes includes the codes of the chain, the default.
+func FingerprintCodes(include bool) FingerprintOption {
+	return func(o *fingerprintOptions) { o.codes = include }
+}
+
+// FingerprintRootType includes the type of the root cause, the default.
+func FingerprintRootType(include bool) FingerprintOption {
+	return func(o *fingerprintOptions) { o.rootType = include }
+}
+
+// FingerprintMessage includes the message of the root cause even when the
+// chain carries a code. By default it is only included when the codes are
+// not, or when the chain does not carry any.
+func FingerprintMessage(include bool) FingerprintOption {
+	return func(o *fingerprintOptions) { o.message = include }
+}
+
+// FingerprintFrames includes the functions of the n top frames of the
+// innermost stack of the chain, 3 by default. A count lower than 1
+// excludes the stack.
+func FingerprintFrames(n int) FingerprintOption {
+	return func(o *fingerprintOptions) { o.frames = n }
+}
+
+// Fingerprint returns a hash of err, as 16 hexadecimal digits, which is
+// the same for the errors created at the same place for the same reason,
+// in any process, so that log pipelines and alerting can group them.
+// By default the codes of the chain, the type of its root cause and the
+// functions of the top 3 frames of its stack feed the hash, and the
+// message of the root cause does when the chain carries no code; opts
+// select other components. Only function names are used for the frames,
+// so the fingerprint does not change when lines move within a function,
+// and a stack stored as a summary only feeds its top frame, see
+// SetStackBudget.
+// With FingerprintFrames(0), it is the fingerprint of the NDJSON records
+// and of the uncoded error events.
+func Fingerprint(err error, opts ...FingerprintOption) string {
+	o := fingerprintOptions{codes: true, rootType: true, frames: 3}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return fmt.Sprintf("%016x", fingerprintWith(err, o))
+}
+
+// fingerprintWith returns the hash of the components of err selected by o.
+func fingerprintWith(err error, o fingerprintOptions) uint64 {
+	h := fnv.New64a()
+	if err == nil {
+		return h.Sum64()
+	}
+
+	errs := list(err)
+	coded := false
+	if o.codes {
+		for _, e := range errs {
+			if w, ok := e.(CodeError); ok {
+				coded = true
+				h.Write([]byte(strconv.Itoa(w.Code())))
+				h.Write([]byte{';'})
+			}
+		}
+	}
+
+	root := errs[len(errs)-1]
+	if o.rootType {
+		h.Write([]byte(fmt.Sprintf("%T", root)))
+	}
+	if o.message || !coded {
+		h.Write([]byte{';'})
+		h.Write([]byte(root.Error()))
+	}
+
+	if s := innermostStack(errs); o.frames > 0 && s != nil {
+		pcs := s.frames()
+		if len(pcs) > o.frames {
+			pcs = pcs[:o.frames]
+		}
+		for _, pc := range pcs {
+			h.Write([]byte{';'})
+			h.Write([]byte(Frame(pc).name()))
+		}
+	}
+
+	return h.Sum64()
+}
+
+// Bucket deterministically assigns err to one of n buckets, numbered from
+// 0 to n-1. The same kind of error always lands in the same bucket, in any
+// process, which allows error distributions of two deployments to be
+// compared without exporting the error messages themselves.
+// Bucket returns 0 if err is nil or n is less than 1.
+func Bucket(err error, n int) int {
+	if err == nil || n < 1 {
+		return 0
+	}
+	return int(fingerprint(err) % uint64(n))
+}
@@ -0,0 +1,74 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestBucket(t *testing.T) {
+	tests := []struct {
+		a, b error
+		same bool
+	}{
+		{WithCode(900201, "user %d not found", 1), WithCode(900201, "user %d not found", 2), true},
+		{WithCode(900201, "not found"), WithCode(900202, "not found"), false},
+		{Wrap(io.EOF, "read failed"), Wrap(io.EOF, "read failed"), true},
+		{New("whoops"), New("oh noes"), false},
+	}
+
+	for i, tt := range tests {
+		if got := fingerprint(tt.a) == fingerprint(tt.b); got != tt.same {
+			t.Errorf("test %d: fingerprint(%v) == fingerprint(%v): got %v, want %v", i+1, tt.a, tt.b, got, tt.same)
+		}
+		for _, n := range []int{1, 7, 1024} {
+			if got := Bucket(tt.a, n); got < 0 || got >= n {
+				t.Errorf("test %d: Bucket(%v, %d): got %d, want a value in [0, %d)", i+1, tt.a, n, got, n)
+			}
+		}
+	}
+
+	if got := Bucket(nil, 16); got != 0 {
+		t.Errorf("Bucket(nil, 16): got %d, want 0", got)
+	}
+	if got := Bucket(io.EOF, 0); got != 0 {
+		t.Errorf("Bucket(io.EOF, 0): got %d, want 0", got)
+	}
+}
+
+func fingerprintSiteA() error { return WithCode(911701, "user %d not found", 1) }
+func fingerprintSiteB() error { return WithCode(911701, "user %d not found", 2) }
+
+func TestFingerprintOptions(t *testing.T) {
+	a1, a2, b := fingerprintSiteA(), fingerprintSiteA(), fingerprintSiteB()
+
+	tests := []struct {
+		name string
+		a, b error
+		opts []FingerprintOption
+		same bool
+	}{
+		{"same site", a1, a2, nil, true},
+		{"other site", a1, b, nil, false},
+		{"other site without frames", a1, b, []FingerprintOption{FingerprintFrames(0)}, true},
+		{"other site with messages", a1, b, []FingerprintOption{FingerprintFrames(0), FingerprintMessage(true)}, false},
+		{"other code", WithCode(911701, "x"), WithCode(911702, "x"), []FingerprintOption{FingerprintFrames(0)}, false},
+		{"codes excluded", WithCode(911701, "x"), WithCode(911702, "x"), []FingerprintOption{FingerprintFrames(0), FingerprintCodes(false)}, true},
+		{"uncoded messages", Wrap(io.EOF, "x"), Wrap(io.ErrUnexpectedEOF, "x"), []FingerprintOption{FingerprintFrames(0)}, false},
+		{"root types excluded", Wrapc(io.EOF, 911701, "x"), Wrapc(New("other"), 911701, "x"), []FingerprintOption{FingerprintFrames(0), FingerprintRootType(false)}, true},
+	}
+
+	for _, tt := range tests {
+		fa, fb := Fingerprint(tt.a, tt.opts...), Fingerprint(tt.b, tt.opts...)
+		if len(fa) != 16 {
+			t.Errorf("%s: Fingerprint(): got %q, want 16 hexadecimal digits", tt.name, fa)
+		}
+		if got := fa == fb; got != tt.same {
+			t.Errorf("%s: Fingerprint(%v) == Fingerprint(%v): got %v, want %v", tt.name, tt.a, tt.b, got, tt.same)
+		}
+	}
+
+	if got, want := Fingerprint(a1, FingerprintFrames(0)), fmt.Sprintf("%016x", fingerprint(a1)); got != want {
+		t.Errorf("Fingerprint(FingerprintFrames(0)): got %s, want %s", got, want)
+	}
+}
@@ -18,6 +18,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
 )
 
@@ -26,6 +28,8 @@ type formatInfo struct {
 	code    int
 	message string
 	err     string
+	details []Detail
+	fields  map[string]interface{}
 	stack   *stack
 }
 
@@ -34,16 +38,26 @@ type formatInfo struct {
 // Verbs:
 //     %s  - Returns the user-safe error string mapped to the error code or
 //       ┊   the error message if none is specified.
-//     %v      Alias for %s
+//     %v      Returns the code and the user-safe error string, as
+//       ┊   "code: message"
+//     %q      Returns %v as a double-quoted string, safe to embed in a
+//       ┊   single line of machine-readable output
 //
 // Flags:
 //      #      JSON formatted output, useful for logging
 //      -      Output caller details, useful for troubleshooting
-//      +      Output full error stack details, useful for debugging
+//      +      Output full error stack details, useful for debugging: every
+//       ┊     layer of the chain with its code, message and caller,
+//       ┊     followed by the stack trace of the innermost layer
 
 func (w *withCode) Format(state fmt.State, verb rune) {
 	switch verb {
 	case 'v':
+		if !state.Flag('#') && !state.Flag('-') && !state.Flag('+') {
+			io.WriteString(state, w.codeMessage())
+			return
+		}
+
 		str := bytes.NewBuffer([]byte{})
 		jsonData := []map[string]interface{}{}
 
@@ -85,14 +99,36 @@ func (w *withCode) Format(state fmt.State, verb rune) {
 			byts, _ = json.Marshal(jsonData)
 
 			str.Write(byts)
+		} else if flagTrace {
+			fmt.Fprintf(str, "%+v", innermostStack(errs))
 		}
 
 		fmt.Fprintf(state, "%s", strings.Trim(str.String(), "\r\n\t"))
+	case 'q':
+		fmt.Fprintf(state, "%q", w.codeMessage())
 	default:
 		finfo := buildFormatInfo(w)
 		// Externally-safe error message
-		fmt.Fprintf(state, finfo.message)
+		io.WriteString(state, finfo.message)
+	}
+}
+
+// codeMessage returns the code and the user-safe error string of w, as
+// printed by %v.
+func (w *withCode) codeMessage() string {
+	finfo := buildFormatInfo(w)
+	return strconv.Itoa(finfo.code) + ": " + finfo.message
+}
+
+// innermostStack returns the stack of the innermost layer of errs which
+// recorded one, or nil.
+func innermostStack(errs []error) *stack {
+	for i := len(errs) - 1; i >= 0; i-- {
+		if s := stackOf(errs[i]); s != nil {
+			return s
+		}
 	}
+	return nil
 }
 
 func format(k int, jsonData []map[string]interface{}, str *bytes.Buffer, finfo *formatInfo,
@@ -107,7 +143,7 @@ func format(k int, jsonData []map[string]interface{}, str *bytes.Buffer, finfo *
 			}
 
 			caller := fmt.Sprintf("#%d", k)
-			if finfo.stack != nil {
+			if finfo.stack != nil && len(*finfo.stack) > 0 {
 				f := Frame((*finfo.stack)[0])
 				caller = fmt.Sprintf("%s %s:%d (%s)",
 					caller,
@@ -120,10 +156,16 @@ func format(k int, jsonData []map[string]interface{}, str *bytes.Buffer, finfo *
 		} else {
 			data["error"] = finfo.message
 		}
+		if len(finfo.fields) > 0 {
+			data["fields"] = finfo.fields
+		}
+		if len(finfo.details) > 0 {
+			data["details"] = renderDetails(finfo.details)
+		}
 		jsonData = append(jsonData, data)
 	} else {
 		if flagDetail || flagTrace {
-			if finfo.stack != nil {
+			if finfo.stack != nil && len(*finfo.stack) > 0 {
 				f := Frame((*finfo.stack)[0])
 				fmt.Fprintf(str, "%s%s - #%d [%s:%d (%s)] (%d) %s",
 					sep,
@@ -138,6 +180,11 @@ func format(k int, jsonData []map[string]interface{}, str *bytes.Buffer, finfo *
 			} else {
 				fmt.Fprintf(str, "%s%s - #%d %s", sep, finfo.err, k, finfo.message)
 			}
+			for _, d := range finfo.details {
+				if b, ok := d.(BlobRef); ok {
+					fmt.Fprintf(str, " [blob %s]", b.Ref)
+				}
+			}
 
 		} else {
 			fmt.Fprintf(str, finfo.message)
@@ -148,15 +195,18 @@ func format(k int, jsonData []map[string]interface{}, str *bytes.Buffer, finfo *
 }
 
 // list will convert the error stack into a simple array.
+// Layers are unwrapped with Unwrap, or with Cause for the wrappers of
+// libraries predating Go 1.13 error chains.
 func list(e error) []error {
 	ret := []error{}
 
 	if e != nil {
-		if w, ok := e.(interface{ Unwrap() error }); ok {
-			ret = append(ret, e)
+		ret = append(ret, e)
+		switch w := e.(type) {
+		case interface{ Unwrap() error }:
 			ret = append(ret, list(w.Unwrap())...)
-		} else {
-			ret = append(ret, e)
+		case interface{ Cause() error }:
+			ret = append(ret, list(w.Cause())...)
 		}
 	}
 
@@ -182,10 +232,7 @@ func buildFormatInfo(e error) *formatInfo {
 			stack:   err.stack,
 		}
 	case *withCode:
-		coder, ok := codes[err.code]
-		if !ok {
-			coder = unknownCoder
-		}
+		coder := err.Coder()
 
 		extMsg := coder.String()
 		if extMsg == "" {
@@ -196,8 +243,44 @@ func buildFormatInfo(e error) *formatInfo {
 			code:    coder.Code(),
 			message: extMsg,
 			err:     err.err.Error(),
+			details: err.details,
+			fields:  mergeFields(nil, err.env, err.fields),
 			stack:   err.stack,
 		}
+	case *withDetails:
+		finfo = &formatInfo{
+			code:    unknownCoder.Code(),
+			message: err.Error(),
+			err:     err.Error(),
+			details: err.details,
+		}
+	case *withFields:
+		finfo = &formatInfo{
+			code:    unknownCoder.Code(),
+			message: err.Error(),
+			err:     err.Error(),
+			fields:  err.fields,
+		}
+	case *withAttempt:
+		finfo = &formatInfo{
+			code:    unknownCoder.Code(),
+			message: err.Error(),
+			err:     err.Error(),
+			fields:  err.fields(),
+		}
+	case CodeError:
+		coder := ParseCoder(err)
+
+		extMsg := coder.String()
+		if extMsg == "" {
+			extMsg = err.Error()
+		}
+
+		finfo = &formatInfo{
+			code:    coder.Code(),
+			message: extMsg,
+			err:     err.Error(),
+		}
 	default:
 		finfo = &formatInfo{
 			code:    unknownCoder.Code(),
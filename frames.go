@@ -0,0 +1,68 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// StackFrame is a frame of a stack trace resolved into its function, file
+// and line, for tools consuming stacks without parsing their formatted
+// output, see Frames.
+type StackFrame struct {
+	// Function is the fully qualified name of the function, such as
+	// "github.com/rtmzk/errors.New", or "unknown".
+	Function string
+
+	// File is the full path of the source file, or "unknown".
+	File string
+
+	// Line is the line number in File, or 0 if unknown.
+	Line int
+
+	// PC is the program counter of the call instruction of the frame.
+	PC uintptr
+}
+
+// MarshalJSON encodes the frame as an object holding the function, file,
+// line and the program counter as a hexadecimal string, as in pprof:
+//
+//	{"function":"main.main","file":"/src/main.go","line":12,"pc":"0x4a1f3c"}
+func (f StackFrame) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Function string `json:"function"`
+		File     string `json:"file"`
+		Line     int    `json:"line"`
+		PC       string `json:"pc"`
+	}{f.Function, f.File, f.Line, "0x" + strconv.FormatUint(uint64(f.PC), 16)})
+}
+
+// Frames returns the frames of the innermost stack of err's chain,
+// innermost first, as printed by %+v once the stack filters are applied,
+// see AddStackFilter. It returns nil if the chain holds no stack.
+func Frames(err error) []StackFrame {
+	s := innermostStack(list(err))
+	if s == nil {
+		return nil
+	}
+	pcs := s.frames()
+	ret := make([]StackFrame, len(pcs))
+	for i, pc := range pcs {
+		f := Frame(pc)
+		ret[i] = StackFrame{Function: f.name(), File: f.file(), Line: f.line(), PC: f.pc()}
+	}
+	return ret
+}
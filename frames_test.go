@@ -0,0 +1,42 @@
+package errors
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestFrames(t *testing.T) {
+	_, _, line, _ := runtime.Caller(0)
+	err := Wrap(New("boom"), "read")
+
+	frames := Frames(err)
+	if len(frames) == 0 {
+		t.Fatalf("Frames(): got no frame, want the stack of New")
+	}
+	top := frames[0]
+	if !strings.HasSuffix(top.Function, ".TestFrames") || filepath.Base(top.File) != "frames_test.go" || top.Line != line+1 {
+		t.Errorf("Frames()[0]: got %s %s:%d, want TestFrames frames_test.go:%d", top.Function, top.File, top.Line, line+1)
+	}
+	if fn := runtime.FuncForPC(top.PC); fn == nil || fn.Name() != top.Function {
+		t.Errorf("Frames()[0].PC: got %#x, want a program counter of %s", top.PC, top.Function)
+	}
+
+	if got := Frames(io.EOF); got != nil {
+		t.Errorf("Frames(io.EOF): got %v, want nil", got)
+	}
+}
+
+func TestStackFrameMarshalJSON(t *testing.T) {
+	byts, err := json.Marshal(StackFrame{Function: "main.main", File: "/src/main.go", Line: 12, PC: 0x4a1f3c})
+	if err != nil {
+		t.Fatalf("MarshalJSON(): %v", err)
+	}
+	want := `{"function":"main.main","file":"/src/main.go","line":12,"pc":"0x4a1f3c"}`
+	if string(byts) != want {
+		t.Errorf("MarshalJSON(): got %s, want %s", byts, want)
+	}
+}
@@ -176,3 +176,48 @@ func TestUnwrap(t *testing.T) {
 		})
 	}
 }
+
+// legacyWrapper is a wrapper of a library predating Go 1.13, implementing
+// only Cause.
+type legacyWrapper struct{ cause error }
+
+func (w legacyWrapper) Error() string { return "legacy: " + w.cause.Error() }
+func (w legacyWrapper) Cause() error  { return w.cause }
+
+// modernWrapper is a wrapper implementing only Unwrap.
+type modernWrapper struct{ err error }
+
+func (w modernWrapper) Error() string { return "modern: " + w.err.Error() }
+func (w modernWrapper) Unwrap() error { return w.err }
+
+func TestMixedChains(t *testing.T) {
+	root := stderrors.New("root")
+	coded := WithCode(1, "coded")
+
+	tests := []struct {
+		name  string
+		err   error
+		cause error
+	}{
+		{"fmt over ours", fmt.Errorf("ctx: %w", Wrap(root, "wrapped")), root},
+		{"legacy over ours", legacyWrapper{WithMessage(root, "msg")}, root},
+		{"ours over modern", Wrap(modernWrapper{root}, "wrapped"), root},
+		{"ours over legacy over fmt", WithStack(legacyWrapper{fmt.Errorf("ctx: %w", root)}), root},
+		{"coalesced", Coalesce(NewAggregate([]error{root, root})).Errors()[0], root},
+		{"nil unwrap", modernWrapper{nil}, modernWrapper{nil}},
+	}
+
+	for _, tt := range tests {
+		if got := Cause(tt.err); got != tt.cause {
+			t.Errorf("%s: Cause(): got %v, want %v", tt.name, got, tt.cause)
+		}
+	}
+
+	chain := legacyWrapper{fmt.Errorf("ctx: %w", coded)}
+	if code, ok := TopCode(chain); !ok || code != 1 {
+		t.Errorf("TopCode(legacy over fmt over coded): got (%d, %v), want (1, true)", code, ok)
+	}
+	if got := len(list(chain)); got != 3 {
+		t.Errorf("list(legacy over fmt over coded): got %d layers, want 3", got)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"io"
+)
+
+// Go runs fn in a new goroutine and returns a channel receiving its error,
+// then closed. A non-nil error is annotated with the call site of Go, which
+// is reported by Breadcrumbs, so that errors crossing goroutines show where
+// the work was launched from.
+func Go(fn func() error) <-chan error {
+	pc := callerPC()
+	ch := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		ch <- withHandoff(fn(), pc, "goroutine started")
+	}()
+	return ch
+}
+
+// Send sends err on ch, annotated with the call site of Send, which is
+// reported by Breadcrumbs when the error is received by another goroutine.
+// A nil error is sent as is.
+func Send(ch chan<- error, err error) {
+	ch <- withHandoff(err, callerPC(), "error sent")
+}
+
+func withHandoff(err error, pc uintptr, msg string) error {
+	if err == nil {
+		return nil
+	}
+	return &handoff{
+		error: err,
+		pc:    pc,
+		msg:   msg,
+	}
+}
+
+// handoff records where an error crossed goroutines.
+type handoff struct {
+	error
+	pc  uintptr
+	msg string
+}
+
+func (h *handoff) Cause() error { return h.error }
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (h *handoff) Unwrap() error { return h.error }
+
+func (h *handoff) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "%+v", h.Cause())
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, h.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", h.Error())
+	}
+}
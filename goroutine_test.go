@@ -0,0 +1,43 @@
+package errors
+
+import (
+	"io"
+	"runtime"
+	"testing"
+)
+
+func TestGo(t *testing.T) {
+	_, _, line, _ := runtime.Caller(0)
+	ch := Go(func() error { return Wrap(io.EOF, "read") })
+	err := <-ch
+	if Cause(err) != io.EOF || err.Error() != "read: EOF" {
+		t.Fatalf("Go(): got %v, want read: EOF", err)
+	}
+	crumbs := Breadcrumbs(err)
+	if len(crumbs) == 0 || crumbs[0].Line != line+1 || crumbs[0].Message != "goroutine started" {
+		t.Errorf("Breadcrumbs()[0]: got %v, want the Go call site at line %d", crumbs, line+1)
+	}
+	if _, ok := <-ch; ok {
+		t.Errorf("Go(): got an open channel, want it closed after the error")
+	}
+
+	if err := <-Go(func() error { return nil }); err != nil {
+		t.Errorf("Go(nil): got %v, want nil", err)
+	}
+}
+
+func TestSend(t *testing.T) {
+	ch := make(chan error, 2)
+	_, _, line, _ := runtime.Caller(0)
+	Send(ch, io.EOF)
+	Send(ch, nil)
+
+	err := <-ch
+	crumbs := Breadcrumbs(err)
+	if !Is(err, io.EOF) || len(crumbs) != 1 || crumbs[0].Line != line+1 {
+		t.Errorf("Send(): got %v with breadcrumbs %v, want EOF sent at line %d", err, crumbs, line+1)
+	}
+	if err := <-ch; err != nil {
+		t.Errorf("Send(nil): got %v, want nil", err)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package grpcerr converts the errors of github.com/rtmzk/errors to and
+// from their gRPC representations. It is kept apart from the errors package
+// so that the latter does not depend on gRPC.
+package grpcerr
+
+import (
+	"github.com/rtmzk/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// BadRequestToProto converts b into the equivalent google.rpc.BadRequest.
+func BadRequestToProto(b errors.BadRequest) *errdetails.BadRequest {
+	pb := &errdetails.BadRequest{}
+	for _, v := range b.FieldViolations {
+		fv := &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+			Reason:      v.Reason,
+		}
+		if v.LocalizedMessage != nil {
+			fv.LocalizedMessage = &errdetails.LocalizedMessage{
+				Locale:  v.LocalizedMessage.Locale,
+				Message: v.LocalizedMessage.Message,
+			}
+		}
+		pb.FieldViolations = append(pb.FieldViolations, fv)
+	}
+	return pb
+}
+
+// BadRequestFromProto converts a google.rpc.BadRequest into the equivalent
+// errors.BadRequest. A nil pb converts to an empty BadRequest.
+func BadRequestFromProto(pb *errdetails.BadRequest) errors.BadRequest {
+	b := errors.BadRequest{}
+	for _, fv := range pb.GetFieldViolations() {
+		v := errors.FieldViolation{
+			Field:       fv.GetField(),
+			Description: fv.GetDescription(),
+			Reason:      fv.GetReason(),
+		}
+		if lm := fv.GetLocalizedMessage(); lm != nil {
+			v.LocalizedMessage = &errors.LocalizedMessage{
+				Locale:  lm.GetLocale(),
+				Message: lm.GetMessage(),
+			}
+		}
+		b.FieldViolations = append(b.FieldViolations, v)
+	}
+	return b
+}
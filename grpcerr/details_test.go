@@ -0,0 +1,25 @@
+package grpcerr
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rtmzk/errors"
+)
+
+func TestBadRequestRoundTrip(t *testing.T) {
+	tests := []errors.BadRequest{
+		{},
+		{FieldViolations: []errors.FieldViolation{
+			{Field: "email", Description: "must be a valid address", Reason: "INVALID_EMAIL"},
+			{Field: "name", Description: "is required", LocalizedMessage: &errors.LocalizedMessage{Locale: "fr", Message: "est requis"}},
+		}},
+	}
+
+	for i, tt := range tests {
+		got := BadRequestFromProto(BadRequestToProto(tt))
+		if !reflect.DeepEqual(got, tt) {
+			t.Errorf("test %d: round trip: got %+v, want %+v", i+1, got, tt)
+		}
+	}
+}
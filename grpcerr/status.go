@@ -0,0 +1,170 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcerr
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	"github.com/rtmzk/errors"
+)
+
+// Domain is the domain of the ErrorInfo details describing coded errors.
+const Domain = "errors.rtmzk.github.com"
+
+// Metadata keys of the ErrorInfo details describing coded errors.
+const (
+	MetadataReference  = "reference"
+	MetadataHTTPStatus = "http_status"
+	MetadataDomain     = "domain"
+)
+
+// ToGRPCStatus converts err into a gRPC status. The gRPC code is derived
+// from the HTTP status of the Coder of err, and the code, reference and
+// domain of the Coder travel in an ErrorInfo detail whose Reason is the
+// code, so that FromGRPCStatus restores them. The field violations of err
+// are attached as a BadRequest detail.
+// If err is nil, ToGRPCStatus returns nil, which is the OK status.
+func ToGRPCStatus(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+	return toGRPCStatus(err, errors.ServedCoder(err))
+}
+
+// ToGRPCStatusContext converts err into a gRPC status like ToGRPCStatus,
+// with the Coder available in the API version of ctx, see
+// errors.WithAPIVersion and errors.CoderForVersion.
+func ToGRPCStatusContext(ctx context.Context, err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+	return toGRPCStatus(err, errors.ServedCoderContext(ctx, err))
+}
+
+// toGRPCStatus converts err, of the given Coder, into a gRPC status.
+func toGRPCStatus(err error, coder errors.Coder) *status.Status {
+	msg := coder.String()
+	if msg == "" {
+		msg = err.Error()
+	}
+
+	st := status.New(Code(coder.HTTPStatus()), msg)
+	details := []protoadapt.MessageV1{ErrorInfo(coder)}
+	if violations := errors.FieldViolations(err); len(violations) > 0 {
+		details = append(details, BadRequestToProto(errors.BadRequest{FieldViolations: violations}))
+	}
+	if withDetails, derr := st.WithDetails(details...); derr == nil {
+		st = withDetails
+	}
+	return st
+}
+
+// FromGRPCStatus converts a gRPC status back into a coded error, using the
+// ErrorInfo detail attached by ToGRPCStatus. A status without such detail
+// is returned as its plain error, and the OK status as nil.
+func FromGRPCStatus(st *status.Status) error {
+	if st.Code() == codes.OK {
+		return nil
+	}
+
+	code := 0
+	var violations []errors.FieldViolation
+	for _, d := range st.Details() {
+		switch pb := d.(type) {
+		case *errdetails.ErrorInfo:
+			if n, ok := CodeFromErrorInfo(pb); ok {
+				code = n
+			}
+		case *errdetails.BadRequest:
+			violations = append(violations, BadRequestFromProto(pb).FieldViolations...)
+		}
+	}
+	if code == 0 {
+		return st.Err()
+	}
+
+	err := errors.WithCode(code, "%s", st.Message())
+	if len(violations) > 0 {
+		err = errors.WithDetails(err, errors.BadRequest{FieldViolations: violations})
+	}
+	return err
+}
+
+// ErrorInfo returns the ErrorInfo detail describing coder.
+func ErrorInfo(coder errors.Coder) *errdetails.ErrorInfo {
+	info := &errdetails.ErrorInfo{
+		Reason: strconv.Itoa(coder.Code()),
+		Domain: Domain,
+		Metadata: map[string]string{
+			MetadataHTTPStatus: strconv.Itoa(coder.HTTPStatus()),
+		},
+	}
+	if ref := coder.Reference(); ref != "" {
+		info.Metadata[MetadataReference] = ref
+	}
+	if domain, ok := errors.DomainOf(coder); ok {
+		info.Metadata[MetadataDomain] = domain
+	}
+	return info
+}
+
+// CodeFromErrorInfo returns the code described by an ErrorInfo detail built
+// by ErrorInfo. It returns false for the details of other domains.
+func CodeFromErrorInfo(info *errdetails.ErrorInfo) (int, bool) {
+	if info.GetDomain() != Domain {
+		return 0, false
+	}
+	code, err := strconv.Atoi(info.GetReason())
+	return code, err == nil && code != 0
+}
+
+// Code returns the gRPC code matching an HTTP status, following the mapping
+// of the gRPC HTTP gateway.
+func Code(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case 499:
+		return codes.Canceled
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusInternalServerError:
+		return codes.Internal
+	}
+	return codes.Unknown
+}
@@ -0,0 +1,107 @@
+package grpcerr
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/rtmzk/errors"
+)
+
+func init() {
+	errors.Register(errors.NewCoder(905601, http.StatusNotFound, "user not found", "https://example.com/905601"))
+	errors.Register(errors.NewCoder(905602, http.StatusBadRequest, "invalid request", ""))
+	errors.Register(errors.NewCoder(905603, http.StatusUnprocessableEntity, "invalid email", "",
+		errors.WithAPIVersions(2, 0), errors.WithFallback(905602)))
+}
+
+func TestGRPCStatusRoundTrip(t *testing.T) {
+	violations := []errors.FieldViolation{{Field: "email", Description: "is required"}}
+	tests := []struct {
+		err        error
+		code       codes.Code
+		message    string
+		wantCode   int
+		violations []errors.FieldViolation
+	}{
+		{errors.WithCode(905601, "no user 42"), codes.NotFound, "user not found", 905601, nil},
+		{errors.Wrapc(stderrors.New("boom"), 905601, "lookup"), codes.NotFound, "user not found", 905601, nil},
+		{errors.WithDetails(errors.WithCode(905602, "bad"), errors.BadRequest{FieldViolations: violations}),
+			codes.InvalidArgument, "invalid request", 905602, violations},
+	}
+
+	for i, tt := range tests {
+		st := ToGRPCStatus(tt.err)
+		if st.Code() != tt.code {
+			t.Errorf("test %d: code: got %v, want %v", i+1, st.Code(), tt.code)
+		}
+		if st.Message() != tt.message {
+			t.Errorf("test %d: message: got %q, want %q", i+1, st.Message(), tt.message)
+		}
+
+		back := FromGRPCStatus(st)
+		if !errors.IsCode(back, tt.wantCode) {
+			t.Errorf("test %d: FromGRPCStatus: got %v, want code %d", i+1, back, tt.wantCode)
+		}
+		if got := errors.FieldViolations(back); !reflect.DeepEqual(got, tt.violations) {
+			t.Errorf("test %d: violations: got %+v, want %+v", i+1, got, tt.violations)
+		}
+	}
+}
+
+func TestToGRPCStatusContext(t *testing.T) {
+	err := errors.WithCode(905603, "bad email")
+	ctx := errors.WithAPIVersion(context.Background(), 1)
+	if st := ToGRPCStatusContext(ctx, err); st.Message() != "invalid request" {
+		t.Errorf("version 1: got %q, want the fallback message", st.Message())
+	}
+	if st := ToGRPCStatusContext(context.Background(), err); st.Message() != "invalid email" {
+		t.Errorf("no version: got %q, want the coder message", st.Message())
+	}
+}
+
+func TestGRPCStatusNil(t *testing.T) {
+	if st := ToGRPCStatus(nil); st.Code() != codes.OK {
+		t.Errorf("ToGRPCStatus(nil): got %v, want OK", st.Code())
+	}
+	if err := FromGRPCStatus(nil); err != nil {
+		t.Errorf("FromGRPCStatus(nil): got %v, want nil", err)
+	}
+	if err := FromGRPCStatus(status.New(codes.OK, "")); err != nil {
+		t.Errorf("FromGRPCStatus(OK): got %v, want nil", err)
+	}
+}
+
+func TestFromGRPCStatusForeign(t *testing.T) {
+	st := status.New(codes.Unavailable, "try later")
+	err := FromGRPCStatus(st)
+	if got := status.Code(err); got != codes.Unavailable {
+		t.Errorf("code: got %v, want %v", got, codes.Unavailable)
+	}
+	if _, ok := err.(errors.CodeError); ok {
+		t.Errorf("foreign status: got coded error %v", err)
+	}
+}
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		status int
+		want   codes.Code
+	}{
+		{http.StatusBadRequest, codes.InvalidArgument},
+		{http.StatusNotFound, codes.NotFound},
+		{http.StatusServiceUnavailable, codes.Unavailable},
+		{http.StatusTeapot, codes.Unknown},
+	}
+
+	for _, tt := range tests {
+		if got := Code(tt.status); got != tt.want {
+			t.Errorf("Code(%d): got %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
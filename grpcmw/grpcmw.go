@@ -0,0 +1,127 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package grpcmw provides gRPC server interceptors built around the coded
+// errors of github.com/rtmzk/errors: the errors returned by the handlers
+// are converted into gRPC statuses with grpcerr.ToGRPCStatus, and panics
+// are recovered into errors with errors.PanicCode:
+//
+//	srv := grpc.NewServer(
+//		grpc.ChainUnaryInterceptor(grpcmw.UnaryServerInterceptor(grpcmw.WithLogger(logger))),
+//		grpc.ChainStreamInterceptor(grpcmw.StreamServerInterceptor(grpcmw.WithLogger(logger))),
+//	)
+package grpcmw
+
+import (
+	"context"
+	"log/slog"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/rtmzk/errors"
+	"github.com/rtmzk/errors/grpcerr"
+)
+
+// options are the settings of the interceptors.
+type options struct {
+	logger  *slog.Logger
+	onError func(ctx context.Context, method string, err error)
+}
+
+// Option changes a setting of the interceptors.
+type Option func(*options)
+
+// WithLogger logs the failed calls on l, at the level of errors.LogLevel,
+// with the attributes of errors.SlogAttrs, stack trace included.
+func WithLogger(l *slog.Logger) Option {
+	return func(o *options) { o.logger = l }
+}
+
+// OnError sets a function called with every failed call, before its error
+// is converted into a status, e.g. to count the failures per code.
+func OnError(fn func(ctx context.Context, method string, err error)) Option {
+	return func(o *options) { o.onError = fn }
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// UnaryServerInterceptor returns an interceptor converting the errors of
+// unary handlers into gRPC statuses and recovering their panics.
+func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	o := newOptions(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if perr := errors.FromPanic(recover()); perr != nil {
+				resp, err = nil, o.handle(ctx, info.FullMethod, perr)
+			}
+		}()
+
+		resp, err = handler(ctx, req)
+		if err != nil {
+			return resp, o.handle(ctx, info.FullMethod, err)
+		}
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor returns an interceptor converting the errors of
+// stream handlers into gRPC statuses and recovering their panics.
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	o := newOptions(opts)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		ctx := ss.Context()
+		defer func() {
+			if perr := errors.FromPanic(recover()); perr != nil {
+				err = o.handle(ctx, info.FullMethod, perr)
+			}
+		}()
+
+		if err = handler(srv, ss); err != nil {
+			return o.handle(ctx, info.FullMethod, err)
+		}
+		return nil
+	}
+}
+
+// handle reports the error of a call to method and returns the status error
+// standing for it. Uncoded errors which already carry a gRPC status, and
+// uncoded context errors, keep their own status.
+func (o *options) handle(ctx context.Context, method string, err error) error {
+	if o.onError != nil {
+		o.onError(ctx, method, err)
+	}
+	if o.logger != nil {
+		o.logger.LogAttrs(ctx, errors.LogLevel(err), "grpc call failed",
+			slog.String("method", method),
+			slog.Attr{Key: "err", Value: slog.GroupValue(errors.SlogAttrs(err)...)})
+	}
+
+	if _, coded := errors.TopCode(err); !coded {
+		var se interface{ GRPCStatus() *status.Status }
+		if errors.As(err, &se) {
+			return err
+		}
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return status.FromContextError(err).Err()
+		}
+	}
+	return grpcerr.ToGRPCStatusContext(ctx, err).Err()
+}
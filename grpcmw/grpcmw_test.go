@@ -0,0 +1,120 @@
+package grpcmw
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/rtmzk/errors"
+	"github.com/rtmzk/errors/grpcerr"
+)
+
+func init() {
+	errors.Register(errors.NewCoder(909601, 404, "user not found", ""))
+}
+
+// errorCode returns the code of the ErrorInfo detail of st, or 0.
+func errorCode(st *status.Status) int {
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			code, _ := grpcerr.CodeFromErrorInfo(info)
+			return code
+		}
+	}
+	return 0
+}
+
+var unaryInfo = &grpc.UnaryServerInfo{FullMethod: "/users.Users/Get"}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	tests := []struct {
+		handler  grpc.UnaryHandler
+		wantCode codes.Code
+		wantErr  int
+	}{
+		{func(context.Context, interface{}) (interface{}, error) { return "ok", nil }, codes.OK, 0},
+		{func(context.Context, interface{}) (interface{}, error) {
+			return nil, errors.WithCode(909601, "user 7 missing")
+		}, codes.NotFound, 909601},
+		{func(context.Context, interface{}) (interface{}, error) { panic("boom") }, codes.Internal, errors.PanicCode},
+		{func(context.Context, interface{}) (interface{}, error) {
+			return nil, status.Error(codes.Unavailable, "draining")
+		}, codes.Unavailable, 0},
+		{func(context.Context, interface{}) (interface{}, error) {
+			return nil, errors.Wrap(context.DeadlineExceeded, "query")
+		}, codes.DeadlineExceeded, 0},
+	}
+
+	interceptor := UnaryServerInterceptor()
+	for i, tt := range tests {
+		_, err := interceptor(context.Background(), nil, unaryInfo, tt.handler)
+		st := status.Convert(err)
+		if st.Code() != tt.wantCode {
+			t.Errorf("test %d: got code %v, want %v", i+1, st.Code(), tt.wantCode)
+		}
+		if tt.wantErr == 0 {
+			continue
+		}
+		if got := errorCode(st); got != tt.wantErr {
+			t.Errorf("test %d: got error code %d, want %d", i+1, got, tt.wantErr)
+		}
+	}
+}
+
+func TestInterceptorOptions(t *testing.T) {
+	var buf bytes.Buffer
+	var methods []string
+	interceptor := UnaryServerInterceptor(
+		WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))),
+		OnError(func(_ context.Context, method string, err error) {
+			methods = append(methods, method)
+		}),
+	)
+
+	_, _ = interceptor(context.Background(), nil, unaryInfo, func(context.Context, interface{}) (interface{}, error) {
+		panic(io.ErrUnexpectedEOF)
+	})
+	if len(methods) != 1 || methods[0] != unaryInfo.FullMethod {
+		t.Errorf("OnError: got methods %q, want %q", methods, unaryInfo.FullMethod)
+	}
+	for _, want := range []string{`"method":"/users.Users/Get"`, `"level":"ERROR"`, `"stack":[`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("log: got %q, want it to contain %q", buf.String(), want)
+		}
+	}
+}
+
+// serverStream is the ServerStream of the stream handlers of the tests.
+type serverStream struct {
+	grpc.ServerStream
+}
+
+func (serverStream) Context() context.Context { return context.Background() }
+
+func TestStreamServerInterceptor(t *testing.T) {
+	tests := []struct {
+		handler  grpc.StreamHandler
+		wantCode codes.Code
+	}{
+		{func(interface{}, grpc.ServerStream) error { return nil }, codes.OK},
+		{func(interface{}, grpc.ServerStream) error { return errors.WithCode(909601, "user 7 missing") }, codes.NotFound},
+		{func(interface{}, grpc.ServerStream) error { panic("boom") }, codes.Internal},
+	}
+
+	interceptor := StreamServerInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/users.Users/List"}
+	for i, tt := range tests {
+		err := interceptor(nil, serverStream{}, info, tt.handler)
+		if got := status.Code(err); got != tt.wantCode {
+			t.Errorf("test %d: got code %v, want %v", i+1, got, tt.wantCode)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// HealthChecker is implemented by the reporters able to check the
+// connectivity of their sink, e.g. an exporter pinging its collector.
+// Healthy calls it for every added reporter implementing it.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+// healthTimeout bounds the checks of the reporters' sinks, and is how long
+// a call to a hook or a reporter may run before it is considered wedged.
+var healthTimeout int64 = int64(2 * time.Second)
+
+// SetHealthTimeout sets how long Healthy waits for the sinks of the
+// reporters, and how long a call to the error hook or to the reporters may
+// run before Healthy reports it as wedged. A timeout lower than 1 restores
+// the default of 2 seconds.
+func SetHealthTimeout(d time.Duration) {
+	if d < 1 {
+		d = 2 * time.Second
+	}
+	atomic.StoreInt64(&healthTimeout, int64(d))
+}
+
+func healthTimeoutNow() time.Duration {
+	return time.Duration(atomic.LoadInt64(&healthTimeout))
+}
+
+// pipeline tracks the calls to a hook, so that a wedged hook is detected
+// without calling it.
+type pipeline struct {
+	// active is the number of calls running.
+	active int64
+
+	// since is the time, in Unix nanoseconds, of the last call which
+	// started while none was running or which returned.
+	since int64
+}
+
+var (
+	hookPipeline   pipeline
+	reportPipeline pipeline
+)
+
+func (p *pipeline) begin() {
+	if atomic.AddInt64(&p.active, 1) == 1 {
+		atomic.StoreInt64(&p.since, now().UnixNano())
+	}
+}
+
+func (p *pipeline) end() {
+	atomic.StoreInt64(&p.since, now().UnixNano())
+	atomic.AddInt64(&p.active, -1)
+}
+
+// stalled returns how long the running calls have made no progress, or 0
+// if no call is running or the calls progress.
+func (p *pipeline) stalled(timeout time.Duration) time.Duration {
+	if atomic.LoadInt64(&p.active) == 0 {
+		return 0
+	}
+	d := now().Sub(time.Unix(0, atomic.LoadInt64(&p.since)))
+	if d < timeout {
+		return 0
+	}
+	return d
+}
+
+// Healthy checks the error handling of the process itself, so that its
+// failures are detected by monitoring rather than by missing reports:
+//
+//   - the registry is consistent: the unknown code is registered, every
+//     registered code is found under its code and every slug under the
+//     code owning it;
+//   - the error hook, see SetErrorHook, and the reporters, see AddReporter,
+//     are not wedged: no call has been running without progress for longer
+//     than the health timeout, see SetHealthTimeout;
+//   - the sinks of the reporters implementing HealthChecker are reachable
+//     within the health timeout.
+//
+// Healthy returns nil if every check passes, or else an Aggregate of the
+// failures. See HealthHandler to serve it.
+func Healthy() error {
+	timeout := healthTimeoutNow()
+	var errs []error
+
+	errs = append(errs, registryHealth()...)
+	if d := hookPipeline.stalled(timeout); d > 0 {
+		errs = append(errs, Errorf("error hook: no progress for %v", d.Round(time.Millisecond)))
+	}
+	if d := reportPipeline.stalled(timeout); d > 0 {
+		errs = append(errs, Errorf("reporters: no progress for %v", d.Round(time.Millisecond)))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for _, nr := range reportersNow() {
+		hc, ok := nr.Reporter.(HealthChecker)
+		if !ok {
+			continue
+		}
+		if err := checkSink(ctx, hc); err != nil {
+			errs = append(errs, Wrapf(err, "reporter %s", nr.name))
+		}
+	}
+
+	return Join(errs...)
+}
+
+// checkSink runs the check of hc, giving up when ctx is done even if the
+// check does not honor it.
+func checkSink(ctx context.Context, hc HealthChecker) error {
+	done := make(chan error, 1)
+	go func() { done <- hc.CheckHealth(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// registryHealth returns the inconsistencies of the registry.
+func registryHealth() []error {
+	ensureCatalogs()
+	codeMux.RLock()
+	defer codeMux.RUnlock()
+
+	var errs []error
+	if _, ok := codes.get(unknownCoder.Code()); !ok {
+		errs = append(errs, Errorf("registry: unknown code %d is not registered", unknownCoder.Code()))
+	}
+	for _, coder := range codes.list() {
+		if got, ok := codes.get(coder.Code()); !ok || got.Code() != coder.Code() {
+			errs = append(errs, Errorf("registry: code %d is listed but not found", coder.Code()))
+		}
+	}
+	for slug, code := range slugs {
+		coder, ok := codes.get(code)
+		if s, _ := SlugOf(coder); !ok || s != slug {
+			errs = append(errs, Errorf("registry: slug %s points to code %d which does not carry it", slug, code))
+		}
+	}
+	return errs
+}
+
+// HealthHandler returns an HTTP handler serving the result of Healthy, for
+// health check endpoints: a 200 status, or a 503 status with the failures
+// in a plain text body.
+func HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		if err := Healthy(); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(err.Error() + "\n"))
+			return
+		}
+		_, _ = w.Write([]byte("ok\n"))
+	})
+}
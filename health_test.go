@@ -0,0 +1,88 @@
+package errors
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type sinkReporter struct {
+	err error
+}
+
+func (sinkReporter) Report(error, Coder) {}
+
+func (r sinkReporter) CheckHealth(context.Context) error { return r.err }
+
+func TestHealthy(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	Register(NewCoder(911801, 400, "Bad", "", WithSlug("health_bad")))
+	AddReporter("sink", sinkReporter{})
+	if err := Healthy(); err != nil {
+		t.Fatalf("Healthy(): got %v, want nil", err)
+	}
+
+	AddReporter("sink", sinkReporter{err: New("connection refused")})
+	err := Healthy()
+	if err == nil || !strings.Contains(err.Error(), "reporter sink: connection refused") {
+		t.Errorf("Healthy(): got %v, want the failure of the sink", err)
+	}
+	RemoveReporter("sink")
+
+	codeMux.Lock()
+	slugs["health_gone"] = 911802
+	codeMux.Unlock()
+	err = Healthy()
+	if err == nil || !strings.Contains(err.Error(), "slug health_gone") {
+		t.Errorf("Healthy(): got %v, want a registry failure", err)
+	}
+}
+
+func TestHealthyWedgedHook(t *testing.T) {
+	var clock atomic.Int64
+	clock.Store(time.Unix(1700000000, 0).UnixNano())
+	release := make(chan struct{})
+	restore := NewConfig(
+		UseRegistry(MapBackend()),
+		UseClock(ClockFunc(func() time.Time { return time.Unix(0, clock.Load()) })),
+		UseHealthTimeout(time.Second),
+		OnError(func(int, error) { <-release }),
+	).Apply()
+	defer restore()
+
+	go func() { _ = WithCode(911803, "wedged") }()
+	for atomic.LoadInt64(&hookPipeline.active) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := Healthy(); err != nil {
+		t.Errorf("Healthy() within the timeout: got %v, want nil", err)
+	}
+	clock.Add(int64(3 * time.Second))
+	err := Healthy()
+	if err == nil || !strings.Contains(err.Error(), "error hook: no progress for 3s") {
+		t.Errorf("Healthy(): got %v, want a wedged error hook", err)
+	}
+
+	rec := httptest.NewRecorder()
+	HealthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("HealthHandler(): got status %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	close(release)
+	for atomic.LoadInt64(&hookPipeline.active) != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	rec = httptest.NewRecorder()
+	HealthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "ok\n" {
+		t.Errorf("HealthHandler(): got %d %q, want %d %q", rec.Code, rec.Body.String(), http.StatusOK, "ok\n")
+	}
+}
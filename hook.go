@@ -0,0 +1,69 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import "sync/atomic"
+
+var errorHook atomic.Value // errorHookFunc
+
+type errorHookFunc func(code int, err error)
+
+// SetErrorHook sets a function called with every coded error created by
+// WithCode, Wrapc and the other constructors of coded errors, e.g. to
+// increment per-code counters of error-rate dashboards without
+// instrumenting every call site:
+//
+//	errors.SetErrorHook(func(code int, err error) {
+//		created.WithLabelValues(strconv.Itoa(code)).Inc()
+//	})
+//
+// Wrapping an error which already carries a code, e.g. with Wrap, does not
+// call it again. It is called synchronously by the constructors, so it
+// must be fast and safe for concurrent use. A nil fn removes the hook.
+func SetErrorHook(fn func(code int, err error)) {
+	errorHook.Store(errorHookFunc(fn))
+}
+
+func errorHookNow() func(code int, err error) {
+	hook, _ := errorHook.Load().(errorHookFunc)
+	return hook
+}
+
+// created completes the new coded error w, built by newCoded, and returns
+// it: the strict mode is enforced unless w carries its Coder, the usage of
+// its code is counted, the StackSample detail of its stack is attached, if
+// any, and the error hook is called. The errors of a private Registry are
+// checked and counted against that registry only.
+func created(w *withCode) error {
+	switch {
+	case w.registry != nil:
+		if w.remote == nil {
+			reportUnregistered(w.code, w.pc)
+		}
+		countUsage(&w.registry.usage, w.code)
+	default:
+		if w.remote == nil {
+			checkRegistered(w.code, w.pc)
+		}
+		recordUsage(w.code)
+	}
+	attachSample(w)
+	if hook := errorHookNow(); hook != nil {
+		hookPipeline.begin()
+		defer hookPipeline.end()
+		hook(w.code, w)
+	}
+	return w
+}
@@ -0,0 +1,36 @@
+package errors
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+func init() {
+	Register(NewCoder(910201, 500, "storage failed", ""))
+	Register(NewCoder(910202, 503, "unavailable", ""))
+}
+
+func TestErrorHook(t *testing.T) {
+	var codes []int
+	var errs []error
+	restore := NewConfig(OnError(func(code int, err error) {
+		codes = append(codes, code)
+		errs = append(errs, err)
+	})).Apply()
+
+	inner := WithCode(910201, "disk full")
+	outer := Wrapc(Wrap(inner, "save"), 910202, "serve")
+	_ = Wrap(outer, "handler")
+	_ = WithStack(io.EOF)
+	_ = Ensuref(false, "broken")
+	restore()
+	_ = WithCode(910201, "after restore")
+
+	if want := []int{910201, 910202, InvariantCode}; !reflect.DeepEqual(codes, want) {
+		t.Errorf("hook codes: got %v, want %v", codes, want)
+	}
+	if len(errs) < 2 || errs[0] != inner || errs[1] != outer {
+		t.Errorf("hook errors: got %v, want the created errors", errs)
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Response headers set by SetHeaders, so that clients and proxies which
+// drop response bodies can still log the error.
+const (
+	// HeaderErrorCode carries the error code.
+	HeaderErrorCode = "X-Error-Code"
+
+	// HeaderErrorID carries the occurrence ID of the error, see ErrorID.
+	HeaderErrorID = "X-Error-ID"
+)
+
+// SetHeaders sets the error headers of err on h: the error code and, for
+// coded errors, the occurrence ID.
+// Nothing is set if err is nil.
+func SetHeaders(h http.Header, err error) {
+	if err == nil {
+		return
+	}
+	setHeaders(h, err, ParseCoder(err))
+}
+
+// setHeaders sets the error headers of err, of the given Coder, on h.
+func setHeaders(h http.Header, err error, coder Coder) {
+	h.Set(HeaderErrorCode, strconv.Itoa(coder.Code()))
+	if id := ErrorID(err); id != "" {
+		h.Set(HeaderErrorID, id)
+	}
+}
+
+// responseBody is the JSON body written by WriteError.
+type responseBody struct {
+	Code      int                    `json:"code,omitempty"`
+	Slug      string                 `json:"slug,omitempty"`
+	Message   string                 `json:"message"`
+	Reference string                 `json:"reference,omitempty"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+	Chain     []string               `json:"chain,omitempty"`
+	Stack     []string               `json:"stack,omitempty"`
+}
+
+// WriteError responds to r with the coded error err: the error headers, the
+// HTTP status of its coder and a JSON body holding the code, the external
+// message and the reference.
+// The parts of the error exposed are selected by the profile of the request
+// context, see WithProfile, the Coder is the one available in the API
+// version of the request context, if any, see WithAPIVersion, and the
+// message is translated in the language
+// of the request context, see WithLocale, or of the Accept-Language header,
+// see Message. The internal parts are never exposed if SetSealInternal is
+// enabled. The error is enriched with the request context first, see
+// AddEnricher. The attached warnings are set as headers, see SetWarnings.
+// The body is omitted for HEAD requests and for statuses which forbid a
+// body, see WriteStatus, so that every method observes the same status and
+// headers. A panic raised while rendering the body, e.g. by a faulty field
+// value, is recovered and a minimal body holding the code is written
+// instead, see SetRenderFailureHook. Nothing is written if err is nil.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	o := responseOptions{profile: ProfilePublic, lang: requestLanguage(r)}
+	method := http.MethodGet
+	if r != nil {
+		o.profile = ProfileFrom(r.Context())
+		o.version, o.versioned = APIVersionFrom(r.Context())
+		method = r.Method
+		err = Enrich(r.Context(), err)
+	}
+	writeResponse(w, method, err, o)
+}
+
+// responseOptions are the settings of WriteResponse.
+type responseOptions struct {
+	profile   Profile
+	lang      string
+	sealed    bool
+	version   int
+	versioned bool
+}
+
+// ResponseOption changes a setting of WriteResponse.
+type ResponseOption func(*responseOptions)
+
+// ResponseProfile sets the profile selecting the parts of the error
+// exposed, ProfilePublic by default.
+func ResponseProfile(p Profile) ResponseOption {
+	return func(o *responseOptions) { o.profile = p }
+}
+
+// IncludeInternal exposes the internal detail of the error, its fields,
+// chain and stack trace, with ProfileInternal, or strips them with
+// ProfilePublic.
+func IncludeInternal(include bool) ResponseOption {
+	if include {
+		return ResponseProfile(ProfileInternal)
+	}
+	return ResponseProfile(ProfilePublic)
+}
+
+// SealInternal guarantees that the internal parts of the error, its
+// fields, chain and stack, are not exposed, even if another option selects
+// a profile exposing them, see SetSealInternal.
+func SealInternal() ResponseOption {
+	return func(o *responseOptions) { o.sealed = true }
+}
+
+// ResponseLanguage sets the language the message is translated in, see
+// Message.
+func ResponseLanguage(lang string) ResponseOption {
+	return func(o *responseOptions) { o.lang = lang }
+}
+
+// ResponseAPIVersion reports the Coder available in the given API version,
+// see CoderForVersion.
+func ResponseAPIVersion(version int) ResponseOption {
+	return func(o *responseOptions) { o.version, o.versioned = version, true }
+}
+
+// WriteResponse responds with the coded error err like WriteError, for
+// callers without the request at hand: the error headers, the HTTP status
+// of its coder and a JSON body holding the code, the external message and
+// the reference. Only the public parts of err are exposed unless opts
+// select another profile, e.g. IncludeInternal(true).
+// Nothing is written if err is nil.
+func WriteResponse(w http.ResponseWriter, err error, opts ...ResponseOption) {
+	o := responseOptions{profile: ProfilePublic}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	writeResponse(w, http.MethodGet, err, o)
+}
+
+// writeResponse writes the response of WriteError, WriteResponse and
+// WriteStatus to a request made with the given method.
+func writeResponse(w http.ResponseWriter, method string, err error, o responseOptions) {
+	if err == nil {
+		return
+	}
+	if o.sealed || sealInternalNow() {
+		o.profile = seal(o.profile)
+	}
+	coder := ServedCoder(err)
+	if o.versioned {
+		coder = coderForVersion(coder, o.version)
+	}
+	if o.profile.RawCode {
+		setHeaders(w.Header(), err, coder)
+	}
+	SetWarnings(w.Header(), err)
+	if method == http.MethodHead || !bodyAllowed(coder.HTTPStatus()) {
+		w.WriteHeader(coder.HTTPStatus())
+		return
+	}
+
+	byts, encErr, failed := safeRender(err, coder, func() ([]byte, error) {
+		return json.Marshal(newResponseBody(err, coder, o.profile, o.lang))
+	})
+	if failed || encErr != nil {
+		byts = fallbackBody(coder, o.profile)
+	} else {
+		byts = append(byts, '\n')
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(coder.HTTPStatus())
+	_, _ = w.Write(byts)
+}
+
+// requestLanguage returns the preferred language of r, the language of its
+// context, see WithLocale, or else the first tag of its Accept-Language
+// header.
+func requestLanguage(r *http.Request) string {
+	if r == nil {
+		return ""
+	}
+	if lang := LocaleFrom(r.Context()); lang != "" {
+		return lang
+	}
+	lang := r.Header.Get("Accept-Language")
+	if i := strings.IndexAny(lang, ",;"); i >= 0 {
+		lang = lang[:i]
+	}
+	return strings.TrimSpace(lang)
+}
+
+// WriteStatus responds with the error headers and the HTTP status of err
+// only, without a body. It suits HEAD requests and endpoints where bodies
+// are forbidden. Like WriteResponse, the code header is only set if the
+// profile selected by opts, ProfilePublic by default, exposes the code.
+// Nothing is written if err is nil.
+func WriteStatus(w http.ResponseWriter, err error, opts ...ResponseOption) {
+	o := responseOptions{profile: ProfilePublic}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	writeResponse(w, http.MethodHead, err, o)
+}
+
+// bodyAllowed reports whether a response with the given status may carry a
+// body, see RFC 9110.
+func bodyAllowed(status int) bool {
+	switch {
+	case status >= 100 && status < 200:
+		return false
+	case status == http.StatusNoContent, status == http.StatusNotModified:
+		return false
+	}
+	return true
+}
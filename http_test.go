@@ -0,0 +1,149 @@
+package errors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteError(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(903101, 404, "not found", "https://example.com/903101"))
+	MustRegister(NewCoder(903102, 304, "not modified", ""))
+
+	tests := []struct {
+		method string
+		code   int
+		status int
+		body   string
+	}{
+		{http.MethodGet, 903101, 404, `{"code":903101,"message":"not found","reference":"https://example.com/903101"}` + "\n"},
+		{http.MethodHead, 903101, 404, ""},
+		{http.MethodGet, 903102, 304, ""},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		WriteError(rec, httptest.NewRequest(tt.method, "/", nil), WithCode(tt.code, "failed"))
+		if rec.Code != tt.status {
+			t.Errorf("%s %d: got status %d, want %d", tt.method, tt.code, rec.Code, tt.status)
+		}
+		if got := rec.Body.String(); got != tt.body {
+			t.Errorf("%s %d: got body %q, want %q", tt.method, tt.code, got, tt.body)
+		}
+		if got := rec.Header().Get(HeaderErrorCode); got == "" {
+			t.Errorf("%s %d: got no %s header", tt.method, tt.code, HeaderErrorCode)
+		}
+	}
+}
+
+func TestErrorIDHeader(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(903104, 404, "not found", ""))
+	err := WithCode(903104, "failed")
+
+	rec := httptest.NewRecorder()
+	WriteError(rec, httptest.NewRequest(http.MethodGet, "/", nil), err)
+	if got := rec.Header().Get(HeaderErrorID); got == "" || got != ErrorID(err) {
+		t.Errorf("WriteError: got %s %q, want %q", HeaderErrorID, got, ErrorID(err))
+	}
+
+	h := http.Header{}
+	SetHeaders(h, New("uncoded"))
+	if got := h.Get(HeaderErrorID); got != "" {
+		t.Errorf("SetHeaders(uncoded): got %s %q, want none", HeaderErrorID, got)
+	}
+}
+
+func TestWriteStatus(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(903103, 409, "conflict", ""))
+
+	rec := httptest.NewRecorder()
+	WriteStatus(rec, WithCode(903103, "failed"))
+	if rec.Code != 409 || rec.Body.Len() != 0 || rec.Header().Get(HeaderErrorCode) != "903103" {
+		t.Errorf("WriteStatus: got (%d, %q, %q), want (409, \"\", \"903103\")",
+			rec.Code, rec.Body.String(), rec.Header().Get(HeaderErrorCode))
+	}
+}
+
+func TestResponderCodeHeader(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(903105, 409, "conflict", ""))
+	opaque := Profile{Name: "opaque"}
+
+	for _, tt := range []struct {
+		profile Profile
+		want    string
+	}{
+		{ProfilePublic, "903105"},
+		{opaque, ""},
+	} {
+		err := WithCode(903105, "failed")
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r = r.WithContext(WithProfile(r.Context(), tt.profile))
+
+		rec := httptest.NewRecorder()
+		WriteError(rec, r, err)
+		if got := rec.Header().Get(HeaderErrorCode); got != tt.want {
+			t.Errorf("WriteError(%s): got %s %q, want %q", tt.profile.Name, HeaderErrorCode, got, tt.want)
+		}
+
+		rec = httptest.NewRecorder()
+		WriteStatus(rec, err, ResponseProfile(tt.profile))
+		if got := rec.Header().Get(HeaderErrorCode); got != tt.want {
+			t.Errorf("WriteStatus(%s): got %s %q, want %q", tt.profile.Name, HeaderErrorCode, got, tt.want)
+		}
+		if rec.Code != 409 || rec.Body.Len() != 0 {
+			t.Errorf("WriteStatus(%s): got (%d, %q), want (409, \"\")", tt.profile.Name, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestWriteResponse(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(906601, 400, "invalid", "https://example.com/906601"))
+	RegisterTranslation(906601, "fr", "invalide")
+
+	err := WithFields(WithCode(906601, "bad name"), "field", "name")
+	tests := []struct {
+		opts     []ResponseOption
+		contains []string
+		excludes []string
+	}{
+		{nil, []string{`"code":906601`, `"message":"invalid"`, `"reference":"https://example.com/906601"`}, []string{`"fields"`, `"stack"`}},
+		{[]ResponseOption{IncludeInternal(true)}, []string{`"fields":{"field":"name"}`, `"chain"`, `"stack"`}, nil},
+		{[]ResponseOption{IncludeInternal(false)}, nil, []string{`"fields"`, `"stack"`}},
+		{[]ResponseOption{ResponseProfile(ProfilePartner), ResponseLanguage("fr")}, []string{`"message":"invalide"`, `"fields"`}, []string{`"stack"`}},
+	}
+
+	for i, tt := range tests {
+		rec := httptest.NewRecorder()
+		WriteResponse(rec, err, tt.opts...)
+		if rec.Code != 400 {
+			t.Errorf("test %d: got status %d, want %d", i+1, rec.Code, 400)
+		}
+		body := rec.Body.String()
+		for _, want := range tt.contains {
+			if !strings.Contains(body, want) {
+				t.Errorf("test %d: got body %s, want it to contain %s", i+1, body, want)
+			}
+		}
+		for _, unwanted := range tt.excludes {
+			if strings.Contains(body, unwanted) {
+				t.Errorf("test %d: got body %s, want it not to contain %s", i+1, body, unwanted)
+			}
+		}
+	}
+}
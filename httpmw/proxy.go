@@ -0,0 +1,106 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpmw
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+
+	"github.com/rtmzk/errors"
+	"github.com/rtmzk/errors/codes"
+)
+
+// ProxyErrorHandler maps the transport failures of a httputil.ReverseProxy
+// to coded errors and responds with them with errors.WriteError, so that a
+// gateway reports upstream failures in the same envelope as its own errors:
+//
+//	proxy := httputil.NewSingleHostReverseProxy(upstream)
+//	proxy.ErrorHandler = (&httpmw.ProxyErrorHandler{}).ServeError
+//
+// The codes left to 0 default to those of the codes package.
+type ProxyErrorHandler struct {
+	// Dial is the code of the failures to connect to the upstream, such as
+	// refused connections and DNS failures. It defaults to
+	// codes.BadGateway.
+	Dial int
+
+	// Timeout is the code of the timeouts connecting to the upstream or
+	// awaiting its response. It defaults to codes.Timeout.
+	Timeout int
+
+	// TLS is the code of the TLS handshake and certificate failures. It
+	// defaults to codes.BadGateway.
+	TLS int
+
+	// Other is the code of the other failures, such as invalid responses.
+	// It defaults to codes.BadGateway.
+	Other int
+
+	// OnError is optionally called with every coded error and the HTTP
+	// status it is served with.
+	OnError func(r *http.Request, err error, status int)
+}
+
+// ServeError responds to r with the coded error mapped from err. It has the
+// signature of httputil.ReverseProxy.ErrorHandler. When the client went
+// away, only the status errors.StatusClientClosedRequest is written.
+func (h *ProxyErrorHandler) ServeError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, context.Canceled) && r.Context().Err() != nil {
+		w.WriteHeader(errors.StatusClientClosedRequest)
+		return
+	}
+
+	err = errors.Wrapc(err, h.code(err), "proxy %s %s", r.Method, r.URL.Path)
+	if h.OnError != nil {
+		h.OnError(r, err, errors.ParseCoder(err).HTTPStatus())
+	}
+	errors.WriteError(w, r, err)
+}
+
+// code returns the code of the transport failure err.
+func (h *ProxyErrorHandler) code(err error) int {
+	var (
+		netErr  net.Error
+		opErr   *net.OpError
+		dnsErr  *net.DNSError
+		verErr  *tls.CertificateVerificationError
+		hdrErr  tls.RecordHeaderError
+		alert   tls.AlertError
+		authErr x509.UnknownAuthorityError
+		hostErr x509.HostnameError
+		certErr x509.CertificateInvalidError
+	)
+	switch {
+	case errors.As(err, &verErr), errors.As(err, &hdrErr), errors.As(err, &alert),
+		errors.As(err, &authErr), errors.As(err, &hostErr), errors.As(err, &certErr):
+		return orDefault(h.TLS, codes.BadGateway)
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return orDefault(h.Timeout, codes.Timeout)
+	case errors.As(err, &opErr) && opErr.Op == "dial", errors.As(err, &dnsErr):
+		return orDefault(h.Dial, codes.BadGateway)
+	}
+	return orDefault(h.Other, codes.BadGateway)
+}
+
+func orDefault(code, def int) int {
+	if code == 0 {
+		return def
+	}
+	return code
+}
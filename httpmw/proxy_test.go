@@ -0,0 +1,94 @@
+package httpmw
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/rtmzk/errors"
+	"github.com/rtmzk/errors/codes"
+)
+
+func init() {
+	errors.Register(errors.NewCoder(911101, 502, "Upstream refused the connection", ""))
+}
+
+func TestProxyErrorHandlerCodes(t *testing.T) {
+	h := &ProxyErrorHandler{Dial: 911101}
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"dial", &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", fmt.Errorf("connection refused"))}, 911101},
+		{"dns", &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "upstream"}}, 911101},
+		{"dial timeout", &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded}, codes.Timeout},
+		{"deadline", fmt.Errorf("roundtrip: %w", context.DeadlineExceeded), codes.Timeout},
+		{"unknown authority", &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}, codes.BadGateway},
+		{"record header", tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}, codes.BadGateway},
+		{"read", &net.OpError{Op: "read", Net: "tcp", Err: io.ErrUnexpectedEOF}, codes.BadGateway},
+		{"other", io.ErrUnexpectedEOF, codes.BadGateway},
+	}
+
+	for _, tt := range tests {
+		if got := h.code(tt.err); got != tt.want {
+			t.Errorf("%s: got code %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestProxyErrorHandler(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	upstream := &url.URL{Scheme: "http", Host: l.Addr().String()}
+	l.Close()
+
+	var served []int
+	proxy := httputil.NewSingleHostReverseProxy(upstream)
+	proxy.ErrorHandler = (&ProxyErrorHandler{
+		Dial: 911101,
+		OnError: func(r *http.Request, err error, status int) {
+			served = append(served, status)
+		},
+	}).ServeError
+
+	rec := httptest.NewRecorder()
+	proxy.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/users", nil))
+	if rec.Code != 502 {
+		t.Errorf("status: got %d, want %d", rec.Code, 502)
+	}
+	var body struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body %q: %v", rec.Body.String(), err)
+	}
+	if body.Code != 911101 || body.Message != "Upstream refused the connection" {
+		t.Errorf("body: got %+v, want code %d", body, 911101)
+	}
+	if len(served) != 1 || served[0] != 502 {
+		t.Errorf("OnError: got %v, want [502]", served)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec = httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/v1/users", nil).WithContext(ctx)
+	(&ProxyErrorHandler{}).ServeError(rec, r, fmt.Errorf("roundtrip: %w", context.Canceled))
+	if rec.Code != errors.StatusClientClosedRequest || rec.Body.Len() != 0 {
+		t.Errorf("client gone: got %d %q, want %d and no body", rec.Code, rec.Body.String(), errors.StatusClientClosedRequest)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpmw
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/rtmzk/errors"
+)
+
+// ErrorHandler is a middleware turning the errors of the handlers into JSON
+// responses written with errors.WriteError, and their panics into errors
+// with errors.PanicCode. The zero value is ready to use.
+//
+// Handle adapts the handlers returning their error. Wrap suits the plain
+// http.Handler middleware chains, such as those of chi, whose handlers
+// report their error with SetError:
+//
+//	r := chi.NewRouter()
+//	r.Use((&httpmw.ErrorHandler{}).Wrap)
+type ErrorHandler struct {
+	// OnError is optionally called with every error of a request and the
+	// HTTP status it is served with, e.g. to count the failures per code.
+	OnError func(r *http.Request, err error, status int)
+}
+
+// Handle returns a handler responding with the error returned by next, or
+// with the error of its panic.
+func (h *ErrorHandler) Handle(next HandlerFunc) http.Handler {
+	return h.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := next(w, r); err != nil {
+			SetError(r, err)
+		}
+	}))
+}
+
+// Wrap returns a handler responding with the error stored by next with
+// SetError, or with the error of its panic. Nothing is written if next
+// already wrote the response headers. The http.ErrAbortHandler panics
+// are not recovered, so that net/http aborts the response.
+func (h *ErrorHandler) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		holder := &errorHolder{}
+		r = r.WithContext(context.WithValue(r.Context(), errorKey{}, holder))
+		tw := &trackingWriter{ResponseWriter: w}
+
+		defer func() {
+			v := recover()
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+			if err := errors.FromPanic(v); err != nil {
+				holder.err = err
+			}
+			if holder.err != nil {
+				h.respond(tw, r, holder.err)
+			}
+		}()
+		next.ServeHTTP(tw, r)
+	})
+}
+
+// respond reports err and writes its response, unless the headers were
+// written already.
+func (h *ErrorHandler) respond(w *trackingWriter, r *http.Request, err error) {
+	if h.OnError != nil {
+		h.OnError(r, err, errors.ParseCoder(err).HTTPStatus())
+	}
+	if w.wroteHeader {
+		return
+	}
+	errors.WriteError(w, r, err)
+}
+
+type errorKey struct{}
+
+// errorHolder holds the error stored by SetError.
+type errorHolder struct {
+	err error
+}
+
+// SetError stores err as the error of the request r, written by the
+// ErrorHandler once the handler returns. The last error stored wins. It
+// returns false if r is not served through an ErrorHandler.
+func SetError(r *http.Request, err error) bool {
+	holder, ok := r.Context().Value(errorKey{}).(*errorHolder)
+	if !ok {
+		return false
+	}
+	holder.err = err
+	return true
+}
+
+// trackingWriter records whether the response headers were written.
+type trackingWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *trackingWriter) WriteHeader(status int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *trackingWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the wrapped ResponseWriter, for http.ResponseController.
+func (w *trackingWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
@@ -0,0 +1,93 @@
+package httpmw
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rtmzk/errors"
+)
+
+func init() {
+	errors.Register(errors.NewCoder(909701, 404, "user not found", ""))
+}
+
+func TestErrorHandler(t *testing.T) {
+	tests := []struct {
+		handler    http.Handler
+		wantStatus int
+		wantCode   int
+	}{
+		{(&ErrorHandler{}).Handle(func(w http.ResponseWriter, _ *http.Request) error {
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		}), http.StatusNoContent, 0},
+		{(&ErrorHandler{}).Handle(func(http.ResponseWriter, *http.Request) error {
+			return errors.WithCode(909701, "user 7 missing")
+		}), http.StatusNotFound, 909701},
+		{(&ErrorHandler{}).Handle(func(http.ResponseWriter, *http.Request) error {
+			panic("boom")
+		}), http.StatusInternalServerError, errors.PanicCode},
+		{(&ErrorHandler{}).Wrap(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			SetError(r, errors.WithCode(909701, "user 7 missing"))
+		})), http.StatusNotFound, 909701},
+		{(&ErrorHandler{}).Handle(func(w http.ResponseWriter, _ *http.Request) error {
+			w.WriteHeader(http.StatusAccepted)
+			return io.ErrUnexpectedEOF
+		}), http.StatusAccepted, 0},
+	}
+
+	for i, tt := range tests {
+		rec := httptest.NewRecorder()
+		tt.handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != tt.wantStatus {
+			t.Errorf("test %d: got status %d, want %d", i+1, rec.Code, tt.wantStatus)
+		}
+		if tt.wantCode == 0 {
+			if rec.Body.Len() != 0 {
+				t.Errorf("test %d: got body %q, want none", i+1, rec.Body.String())
+			}
+			continue
+		}
+		var body struct {
+			Code int `json:"code"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Code != tt.wantCode {
+			t.Errorf("test %d: got body %q, want code %d", i+1, rec.Body.String(), tt.wantCode)
+		}
+	}
+}
+
+func TestErrorHandlerOnError(t *testing.T) {
+	var statuses []int
+	h := &ErrorHandler{OnError: func(_ *http.Request, _ error, status int) {
+		statuses = append(statuses, status)
+	}}
+	handler := h.Handle(func(http.ResponseWriter, *http.Request) error {
+		return errors.WithCode(909701, "user 7 missing")
+	})
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if len(statuses) != 1 || statuses[0] != http.StatusNotFound {
+		t.Errorf("OnError: got statuses %v, want [404]", statuses)
+	}
+}
+
+func TestErrorHandlerAbort(t *testing.T) {
+	handler := (&ErrorHandler{}).Handle(func(http.ResponseWriter, *http.Request) error {
+		panic(http.ErrAbortHandler)
+	})
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Errorf("ErrAbortHandler: got panic %v, want it to propagate", r)
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestSetErrorOutsideHandler(t *testing.T) {
+	if SetError(httptest.NewRequest(http.MethodGet, "/", nil), io.EOF) {
+		t.Errorf("SetError without ErrorHandler: got true, want false")
+	}
+}
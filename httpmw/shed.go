@@ -0,0 +1,139 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package httpmw provides net/http middlewares built around the coded errors
+// of github.com/rtmzk/errors.
+package httpmw
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rtmzk/errors"
+)
+
+// HandlerFunc is an HTTP handler which returns the error it failed with.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// Shedder turns the errors signalling an overload into 503 responses with a
+// jittered Retry-After header, so that clients back off proportionally to
+// the current load instead of retrying all at once.
+type Shedder struct {
+	// Codes are the error codes signalling an overload.
+	Codes []int
+
+	// RetryAfter is the base delay used when the coder of the error has no
+	// RetryAfter method. It defaults to one second.
+	RetryAfter time.Duration
+
+	// Jitter is the maximum fraction of the delay added at random.
+	Jitter float64
+
+	// Load optionally reports the current load, where 0 is idle and 1 is
+	// the nominal capacity. The delay is multiplied by 1 + load.
+	Load func() float64
+
+	// Rand returns a number in [0, 1). It defaults to a number drawn from
+	// errors.Entropy, so that errors.SetEntropy makes the jitter
+	// reproducible.
+	Rand func() float64
+}
+
+// Wrap returns a handler responding 503 when the chain of the error next
+// fails with holds one of the overload codes, see errors.IsCode. The error
+// is written by errors.WriteError, with the status replaced by 503. Other
+// errors are returned unchanged.
+func (s *Shedder) Wrap(next HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := next(w, r)
+		code, ok := s.overloaded(err)
+		if !ok {
+			return err
+		}
+
+		coder, _ := errors.GetCoder(code)
+		w.Header().Set("Retry-After", strconv.Itoa(s.retryAfterSeconds(coder)))
+		errors.WriteError(unavailableWriter{w}, r, err)
+		return nil
+	}
+}
+
+// overloaded returns the first of the overload codes held by err's chain.
+func (s *Shedder) overloaded(err error) (int, bool) {
+	if err == nil {
+		return 0, false
+	}
+	for _, code := range s.Codes {
+		if errors.IsCode(err, code) {
+			return code, true
+		}
+	}
+	return 0, false
+}
+
+// unavailableWriter writes the status 503 whatever the status written.
+type unavailableWriter struct {
+	http.ResponseWriter
+}
+
+func (w unavailableWriter) WriteHeader(int) {
+	w.ResponseWriter.WriteHeader(http.StatusServiceUnavailable)
+}
+
+// retryAfterSeconds computes the Retry-After delay, rounded up to at least
+// one second.
+func (s *Shedder) retryAfterSeconds(coder errors.Coder) int {
+	d := s.RetryAfter
+	if v, ok := errors.RetryAfterOf(coder); ok {
+		d = v
+	}
+	if d <= 0 {
+		d = time.Second
+	}
+
+	f := float64(d)
+	if s.Load != nil {
+		if load := s.Load(); load > 0 {
+			f *= 1 + load
+		}
+	}
+	if s.Jitter > 0 {
+		random := entropyFloat64
+		if s.Rand != nil {
+			random = s.Rand
+		}
+		f *= 1 + s.Jitter*random()
+	}
+
+	secs := int(math.Ceil(f / float64(time.Second)))
+	if secs < 1 {
+		secs = 1
+	}
+	return secs
+}
+
+// entropyFloat64 returns a number in [0, 1) drawn from errors.Entropy, or
+// from math/rand if the entropy source is exhausted.
+func entropyFloat64() float64 {
+	var b [8]byte
+	if _, err := io.ReadFull(errors.Entropy(), b[:]); err != nil {
+		return rand.Float64()
+	}
+	return float64(binary.BigEndian.Uint64(b[:])>>11) / (1 << 53)
+}
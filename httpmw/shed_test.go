@@ -0,0 +1,82 @@
+package httpmw
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rtmzk/errors"
+)
+
+func init() {
+	errors.Register(errors.NewCoder(901601, 503, "overloaded", "", errors.WithRetryAfter(2*time.Second)))
+	errors.Register(errors.NewCoder(901602, 429, "throttled", ""))
+	errors.Register(errors.NewCoder(901603, 500, "query failed", ""))
+}
+
+func TestShedder(t *testing.T) {
+	s := &Shedder{
+		Codes:  []int{901601, 901602},
+		Jitter: 0.5,
+		Load:   func() float64 { return 1 },
+		Rand:   func() float64 { return 0.5 },
+	}
+
+	tests := []struct {
+		err        error
+		shed       bool
+		retryAfter string
+	}{
+		{nil, false, ""},
+		{io.EOF, false, ""},
+		// 2s * (1 + 1) * (1 + 0.5*0.5) = 5s
+		{errors.WithCode(901601, "busy"), true, "5"},
+		// 1s * (1 + 1) * (1 + 0.5*0.5) = 2.5s, rounded up
+		{errors.Wrap(errors.WithCode(901602, "busy"), "handler"), true, "3"},
+		// The overload code is found beneath another code.
+		{errors.Wrapc(errors.WithCode(901601, "busy"), 901603, "query"), true, "5"},
+	}
+
+	for i, tt := range tests {
+		h := s.Wrap(func(http.ResponseWriter, *http.Request) error { return tt.err })
+		rec := httptest.NewRecorder()
+		err := h(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if tt.shed {
+			if err != nil || rec.Code != http.StatusServiceUnavailable {
+				t.Errorf("test %d: got (%v, %d), want (nil, 503)", i+1, err, rec.Code)
+			}
+			if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "application/json") {
+				t.Errorf("test %d: Content-Type: got %q, want the JSON body of WriteError", i+1, got)
+			}
+		} else if err != tt.err {
+			t.Errorf("test %d: got %v, want %v", i+1, err, tt.err)
+		}
+		if got := rec.Header().Get("Retry-After"); got != tt.retryAfter {
+			t.Errorf("test %d: Retry-After: got %q, want %q", i+1, got, tt.retryAfter)
+		}
+	}
+}
+
+func TestShedderEntropy(t *testing.T) {
+	s := &Shedder{Codes: []int{901601}, Jitter: 1}
+	retryAfter := func() string {
+		errors.SetEntropy(strings.NewReader(strings.Repeat("\xff", 8)))
+		defer errors.SetEntropy(nil)
+
+		rec := httptest.NewRecorder()
+		h := s.Wrap(func(http.ResponseWriter, *http.Request) error { return errors.WithCode(901601, "busy") })
+		h(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec.Header().Get("Retry-After")
+	}
+
+	// 2s * (1 + 1*0.99...) rounded up.
+	for i := 0; i < 2; i++ {
+		if got := retryAfter(); got != "4" {
+			t.Errorf("Retry-After: got %q, want %q from the entropy source", got, "4")
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// InvariantCode is the code of the invariant violations reported by
+// Ensuref. It is reserved next to the unknown and client abort codes.
+const InvariantCode = 3
+
+// invariantCoder is the Coder of the errors of Ensuref.
+var invariantCoder = NewInvariantCoder(InvariantCode, "An internal invariant was violated",
+	"https://github.com/rtmzk/errors/README.md")
+
+// NewInvariantCoder returns a Coder for violations of invariants, conditions
+// which should never happen, mapped to http.StatusInternalServerError with
+// severity fatal and class ClassBug unless opts set others.
+func NewInvariantCoder(code int, msg, ref string, opts ...CoderOption) Coder {
+	opts = append([]CoderOption{WithSeverity(SeverityFatal), WithClass(ClassBug)}, opts...)
+	opts = append(opts, WithCondition(ConditionInvariantViolated))
+	return NewCoder(code, http.StatusInternalServerError, msg, ref, opts...)
+}
+
+// Invariant returns nil if cond holds, and otherwise an error with the given
+// code, usually registered with NewInvariantCoder, and the stack of the
+// caller:
+//
+//	if err := errors.Invariant(len(rows) <= limit, code.ErrTooManyRows,
+//		"got %d rows, limit is %d", len(rows), limit); err != nil {
+//		return err
+//	}
+func Invariant(cond bool, code int, format string, args ...interface{}) error {
+	if cond {
+		return nil
+	}
+	st := codeCallers(code)
+	return created(newCoded(code, fmt.Errorf(format, args...), nil, nil, st, stackPC(st)))
+}
+
+// Ensuref is like Invariant with InvariantCode, whose Coder of severity
+// fatal needs no registration.
+func Ensuref(cond bool, format string, args ...interface{}) error {
+	if cond {
+		return nil
+	}
+	st := codeCallers(InvariantCode)
+	w := newCoded(InvariantCode, fmt.Errorf(format, args...), nil, nil, st, stackPC(st))
+	w.remote = invariantCoder
+	return created(w)
+}
+
+// IsInvariantViolation reports whether a coded layer of err's chain has a
+// Coder standing for ConditionInvariantViolated.
+func IsInvariantViolation(err error) bool {
+	return hasCondition(err, ConditionInvariantViolated)
+}
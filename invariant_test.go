@@ -0,0 +1,57 @@
+package errors
+
+import "testing"
+
+func TestInvariant(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewInvariantCoder(908101, "ledger out of balance", ""))
+	MustRegister(NewInvariantCoder(908102, "degraded", "", WithSeverity(SeverityError)))
+
+	if err := Invariant(true, 908101, "balance %d", 1); err != nil {
+		t.Errorf("Invariant(true): got %v, want nil", err)
+	}
+	if err := Ensuref(true, "unreachable"); err != nil {
+		t.Errorf("Ensuref(true): got %v, want nil", err)
+	}
+
+	tests := []struct {
+		err      error
+		code     int
+		msg      string
+		severity Severity
+	}{
+		{Invariant(false, 908101, "balance is %d", -3), 908101, "balance is -3", SeverityFatal},
+		{Invariant(false, 908102, "cache miss"), 908102, "cache miss", SeverityError},
+		{Ensuref(false, "state %q is unreachable", "done"), InvariantCode, `state "done" is unreachable`, SeverityFatal},
+		{Wrap(Ensuref(false, "nil session"), "serve"), InvariantCode, "serve: nil session", SeverityFatal},
+	}
+
+	for _, tt := range tests {
+		coder := ParseCoder(tt.err)
+		if coder.Code() != tt.code || coder.HTTPStatus() != 500 {
+			t.Errorf("%v: got code %d and status %d, want %d and 500", tt.err, coder.Code(), coder.HTTPStatus(), tt.code)
+		}
+		if got := tt.err.Error(); got != tt.msg {
+			t.Errorf("Error(): got %q, want %q", got, tt.msg)
+		}
+		if got, _ := SeverityOf(coder); got != tt.severity {
+			t.Errorf("%v: got severity %v, want %v", tt.err, got, tt.severity)
+		}
+		if class, _ := ClassOf(coder); class != ClassBug {
+			t.Errorf("%v: got class %v, want %v", tt.err, class, ClassBug)
+		}
+		if !IsInvariantViolation(tt.err) {
+			t.Errorf("IsInvariantViolation(%v): got false, want true", tt.err)
+		}
+		var st interface{ StackTrace() StackTrace }
+		if !As(tt.err, &st) || len(st.StackTrace()) == 0 {
+			t.Errorf("%v: got no stack, want the stack of the caller", tt.err)
+		}
+	}
+
+	if IsInvariantViolation(New("boom")) {
+		t.Errorf("IsInvariantViolation(New): got true, want false")
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jobresult provides the envelope job-queue consumers use to report
+// the outcome of a job, carrying the coded error of failed jobs.
+//
+// Failures are classified as retryable or terminal from the metadata of
+// their Coder, so that queues can decide between a retry and routing the job
+// to a dead-letter queue without inspecting the error themselves.
+package jobresult
+
+import (
+	"encoding/json"
+
+	"github.com/rtmzk/errors"
+)
+
+// Status is the outcome of a job.
+type Status string
+
+const (
+	// StatusSucceeded reports a job which completed successfully.
+	StatusSucceeded Status = "succeeded"
+
+	// StatusRetryable reports a failed job which may succeed if retried.
+	StatusRetryable Status = "retryable"
+
+	// StatusTerminal reports a failed job which must not be retried and
+	// belongs to the dead-letter queue.
+	StatusTerminal Status = "terminal"
+)
+
+// Result is the outcome of a job.
+type Result struct {
+	Status Status `json:"status"`
+	Error  *Error `json:"error,omitempty"`
+}
+
+// Error is the coded error of a failed job.
+type Error struct {
+	Code      int    `json:"code"`
+	Message   string `json:"message"`
+	Reference string `json:"reference,omitempty"`
+}
+
+// Success returns the result of a successful job.
+func Success() Result {
+	return Result{Status: StatusSucceeded}
+}
+
+// Failure returns the result of a job which failed with err, described by
+// the outermost coded error of its chain.
+// If err is nil, the job is considered successful.
+// A failure annotated with errors.WithAttempt as the last allowed attempt is
+// terminal, even if its error is retryable.
+func Failure(err error) Result {
+	if err == nil {
+		return Success()
+	}
+
+	coded := err
+	var ce errors.CodeError
+	if errors.As(err, &ce) {
+		coded = ce
+	}
+
+	coder := errors.ParseCoder(coded)
+	status := StatusTerminal
+	if Retryable(coder) && !errors.RetriesExhausted(err) {
+		status = StatusRetryable
+	}
+
+	return Result{
+		Status: status,
+		Error: &Error{
+			Code:      coder.Code(),
+			Message:   coder.String(),
+			Reference: coder.Reference(),
+		},
+	}
+}
+
+// Retryable reports whether the errors of coder are worth retrying.
+// The Retryable method of the coder is used when it has one, such as the
+// coders built by errors.NewCoder. Otherwise only the errors mapped to
+// statuses signalling a transient condition are retryable: 429, 502, 503
+// and 504.
+func Retryable(coder errors.Coder) bool {
+	retryable, _ := errors.RetryableOf(coder)
+	return retryable
+}
+
+// Succeeded reports whether the job succeeded.
+func (r Result) Succeeded() bool { return r.Status == StatusSucceeded }
+
+// Retryable reports whether the job failed and may be retried.
+func (r Result) Retryable() bool { return r.Status == StatusRetryable }
+
+// Terminal reports whether the job failed and must be dead-lettered.
+func (r Result) Terminal() bool { return r.Status == StatusTerminal }
+
+// Err returns the failure as a coded error, or nil if the job succeeded.
+func (r Result) Err() error {
+	if r.Error == nil {
+		return nil
+	}
+	return errors.WithCode(r.Error.Code, "%s", r.Error.Message)
+}
+
+// Encode returns the JSON encoding of the result of a job which ended with
+// err, which is nil for a successful job.
+func Encode(err error) ([]byte, error) {
+	return json.Marshal(Failure(err))
+}
+
+// Decode parses a result encoded by Encode.
+func Decode(data []byte) (Result, error) {
+	var r Result
+	if err := json.Unmarshal(data, &r); err != nil {
+		return Result{}, errors.Wrap(err, "decode job result")
+	}
+	return r, nil
+}
@@ -0,0 +1,71 @@
+package jobresult
+
+import (
+	"io"
+	"testing"
+
+	"github.com/rtmzk/errors"
+)
+
+func init() {
+	errors.Register(errors.NewCoder(901301, 503, "try again", "", errors.WithRetryable(true)))
+	errors.Register(errors.NewCoder(901302, 503, "never again", "", errors.WithRetryable(false)))
+	errors.Register(errors.NewCoder(901303, 400, "bad payload", ""))
+	errors.Register(errors.NewCoder(901304, 429, "slow down", ""))
+	errors.Register(statusCoder{901305, 429})
+}
+
+// statusCoder is a Coder without retry metadata.
+type statusCoder struct{ code, status int }
+
+func (c statusCoder) HTTPStatus() int   { return c.status }
+func (c statusCoder) String() string    { return "" }
+func (c statusCoder) Reference() string { return "" }
+func (c statusCoder) Code() int         { return c.code }
+
+func TestFailure(t *testing.T) {
+	tests := []struct {
+		err  error
+		want Status
+	}{
+		{nil, StatusSucceeded},
+		{io.EOF, StatusTerminal},
+		{errors.WithCode(901301, "unavailable"), StatusRetryable},
+		{errors.WithCode(901302, "unavailable"), StatusTerminal},
+		{errors.WithCode(901303, "invalid"), StatusTerminal},
+		{errors.WithCode(901304, "throttled"), StatusTerminal},
+		{errors.WithCode(901305, "throttled"), StatusRetryable},
+		{errors.WithAttempt(errors.WithCode(901301, "unavailable"), 1, 3), StatusRetryable},
+		{errors.WithAttempt(errors.WithCode(901301, "unavailable"), 3, 3), StatusTerminal},
+	}
+
+	for i, tt := range tests {
+		if got := Failure(tt.err).Status; got != tt.want {
+			t.Errorf("test %d: Failure(%v).Status: got %q, want %q", i+1, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	data, err := Encode(errors.WithCode(901301, "unavailable"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Retryable() || r.Error.Code != 901301 || r.Error.Message != "try again" {
+		t.Errorf("Decode(%s): got %+v", data, r)
+	}
+	if !errors.IsCode(r.Err(), 901301) {
+		t.Errorf("Decode(%s).Err(): got %v, want code 901301", data, r.Err())
+	}
+
+	if _, err := Decode([]byte("{")); err == nil {
+		t.Errorf("Decode(invalid): got nil error")
+	}
+	if r := Success(); !r.Succeeded() || r.Err() != nil {
+		t.Errorf("Success(): got %+v", r)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import "strconv"
+
+// TopCode returns the code of the outermost coded layer of err's chain.
+// It returns false if the chain carries no code.
+func TopCode(err error) (int, bool) {
+	for _, e := range list(err) {
+		if c, ok := e.(CodeError); ok {
+			return c.Code(), true
+		}
+	}
+	return 0, false
+}
+
+// CodeOf returns the code of the first error of err's tree implementing
+// CodeError, looked up with errors.As, so that the codes of joined errors
+// are found too. Unlike ParseCoder, it neither resolves the Coder of the
+// code nor falls back to the unknown code: it returns false if the tree
+// carries no code.
+func CodeOf(err error) (int, bool) {
+	var c CodeError
+	if As(err, &c) {
+		return c.Code(), true
+	}
+	return 0, false
+}
+
+// RootCode returns the code of the innermost coded layer of err's chain.
+// It returns false if the chain carries no code.
+func RootCode(err error) (int, bool) {
+	errs := list(err)
+	for i := len(errs) - 1; i >= 0; i-- {
+		if c, ok := errs[i].(CodeError); ok {
+			return c.Code(), true
+		}
+	}
+	return 0, false
+}
+
+// LabelOther is the label of the codes missing from a CodeLabeler allowlist.
+const LabelOther = "other"
+
+// CodeLabeler turns errors into metric label values with a bounded number
+// of distinct values: the codes missing from its allowlist share the
+// LabelOther label, which keeps a long tail of rare codes from exploding
+// the cardinality of a metric.
+type CodeLabeler struct {
+	allowed map[int]struct{}
+}
+
+// NewCodeLabeler returns a CodeLabeler labelling the given codes with their
+// own value.
+func NewCodeLabeler(allowed ...int) *CodeLabeler {
+	l := &CodeLabeler{allowed: make(map[int]struct{}, len(allowed))}
+	for _, code := range allowed {
+		l.allowed[code] = struct{}{}
+	}
+	return l
+}
+
+// Label returns the label value of the top code of err. Errors without a
+// code are labelled with the code of the unknown coder, which is always
+// allowed. Label returns "" if err is nil.
+func (l *CodeLabeler) Label(err error) string {
+	if err == nil {
+		return ""
+	}
+	code, ok := TopCode(err)
+	if !ok {
+		return strconv.Itoa(unknownCoder.Code())
+	}
+	if _, ok := l.allowed[code]; !ok {
+		return LabelOther
+	}
+	return strconv.Itoa(code)
+}
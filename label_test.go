@@ -0,0 +1,79 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestTopRootCode(t *testing.T) {
+	tests := []struct {
+		err       error
+		top, root int
+		ok        bool
+	}{
+		{nil, 0, 0, false},
+		{io.EOF, 0, 0, false},
+		{WithCode(902001, "only"), 902001, 902001, true},
+		{Wrap(Wrapc(WithCode(902001, "inner"), 902002, "outer"), "wrapped"), 902002, 902001, true},
+	}
+
+	for i, tt := range tests {
+		top, ok := TopCode(tt.err)
+		if top != tt.top || ok != tt.ok {
+			t.Errorf("test %d: TopCode(%v): got (%d, %v), want (%d, %v)", i+1, tt.err, top, ok, tt.top, tt.ok)
+		}
+		root, ok := RootCode(tt.err)
+		if root != tt.root || ok != tt.ok {
+			t.Errorf("test %d: RootCode(%v): got (%d, %v), want (%d, %v)", i+1, tt.err, root, ok, tt.root, tt.ok)
+		}
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+		ok   bool
+	}{
+		{nil, 0, false},
+		{io.EOF, 0, false},
+		{WithCode(902003, "unregistered"), 902003, true},
+		{fmt.Errorf("handler: %w", Wrapc(WithCode(902001, "inner"), 902002, "outer")), 902002, true},
+		{stderrors.Join(io.EOF, fmt.Errorf("item: %w", WithCode(902001, "inner"))), 902001, true},
+		{&userCodeError{code: 902004}, 902004, true},
+	}
+
+	for i, tt := range tests {
+		code, ok := CodeOf(tt.err)
+		if code != tt.code || ok != tt.ok {
+			t.Errorf("test %d: CodeOf(%v): got (%d, %v), want (%d, %v)", i+1, tt.err, code, ok, tt.code, tt.ok)
+		}
+
+		var coded CodeError
+		if got := stderrors.As(tt.err, &coded); got != tt.ok {
+			t.Errorf("test %d: errors.As(%v, CodeError): got %v, want %v", i+1, tt.err, got, tt.ok)
+		}
+	}
+}
+
+func TestCodeLabeler(t *testing.T) {
+	l := NewCodeLabeler(902001)
+
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{io.EOF, "1"},
+		{WithCode(902001, "allowed"), "902001"},
+		{WithCode(902003, "rare"), LabelOther},
+	}
+
+	for i, tt := range tests {
+		if got := l.Label(tt.err); got != tt.want {
+			t.Errorf("test %d: Label(%v): got %q, want %q", i+1, tt.err, got, tt.want)
+		}
+	}
+}
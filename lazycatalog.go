@@ -0,0 +1,139 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"io/fs"
+	"sync"
+	"sync/atomic"
+)
+
+// lazyCatalog is a catalog file registered with RegisterCatalogLazily.
+type lazyCatalog struct {
+	fsys fs.FS
+	name string
+	pc   uintptr
+	once sync.Once
+
+	// err is the failure of the loading, set once by load.
+	err error
+}
+
+var (
+	lazyMux      sync.Mutex
+	lazyCatalogs []*lazyCatalog
+
+	// lazyPending is the number of lazy catalogs not loaded yet, read
+	// atomically so that lookups skip the loading when it is 0.
+	lazyPending int32
+)
+
+// RegisterCatalogLazily registers the coders of the catalog file name of
+// fsys, written by ExportCatalog, on the first lookup of the registry
+// rather than at init time, which keeps large catalogs embedded with
+// go:embed from slowing down the start of short-lived processes:
+//
+//	//go:embed codes.json
+//	var catalog embed.FS
+//
+//	func init() { errors.RegisterCatalogLazily(catalog, "codes.json") }
+//
+// The coders registered explicitly win over those of the catalog.
+// RegisterCatalogLazily panics if the file does not exist, like
+// MustRegister, since an embedded catalog is part of the program, and if the
+// registry is frozen, see Freeze. A catalog which cannot be read or decoded
+// when it is loaded registers no coder, see LoadCatalogs.
+func RegisterCatalogLazily(fsys fs.FS, name string) {
+	lazyMux.Lock()
+	defer lazyMux.Unlock()
+
+	if Frozen() {
+		panic(fmt.Sprintf("errors: register catalog %s: %v", name, ErrFrozen))
+	}
+	if _, err := fs.Stat(fsys, name); err != nil {
+		panic(fmt.Sprintf("errors: register catalog %s: %v", name, err))
+	}
+	lazyCatalogs = append(lazyCatalogs, &lazyCatalog{fsys: fsys, name: name, pc: callerPC()})
+	atomic.AddInt32(&lazyPending, 1)
+}
+
+// ensureCatalogs loads the lazy catalogs not loaded yet. It must be called
+// without holding codeMux.
+func ensureCatalogs() {
+	if atomic.LoadInt32(&lazyPending) == 0 {
+		return
+	}
+	lazyMux.Lock()
+	pending := append([]*lazyCatalog(nil), lazyCatalogs...)
+	lazyMux.Unlock()
+
+	for _, c := range pending {
+		c.once.Do(c.load)
+	}
+}
+
+// LoadCatalogs loads the catalogs registered with RegisterCatalogLazily
+// which are not loaded yet, and returns an error listing those which could
+// not be read or decoded. Programs call it at startup to report a broken
+// catalog there rather than serve the unknown coder for its codes:
+//
+//	if err := errors.LoadCatalogs(); err != nil {
+//		log.Fatal(err)
+//	}
+func LoadCatalogs() error {
+	ensureCatalogs()
+
+	lazyMux.Lock()
+	defer lazyMux.Unlock()
+	var errs []error
+	for _, c := range lazyCatalogs {
+		if c.err != nil {
+			errs = append(errs, c.err)
+		}
+	}
+	return Join(errs...)
+}
+
+func (c *lazyCatalog) load() {
+	defer atomic.AddInt32(&lazyPending, -1)
+
+	f, err := c.fsys.Open(c.name)
+	if err != nil {
+		c.err = Wrapf(err, "load catalog %s", c.name)
+		return
+	}
+	defer f.Close()
+	coders, err := ImportCatalog(f)
+	if err != nil {
+		c.err = Wrapf(err, "load catalog %s", c.name)
+		return
+	}
+
+	codeMux.Lock()
+	defer codeMux.Unlock()
+	for _, coder := range coders {
+		if _, ok := codes.get(coder.Code()); ok {
+			continue
+		}
+		setCoder(coder)
+		origins[coder.Code()] = c.pc
+	}
+}
+
+// pendingCatalogs returns the number of lazy catalogs not loaded yet.
+func pendingCatalogs() int {
+	return int(atomic.LoadInt32(&lazyPending))
+}
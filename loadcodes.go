@@ -0,0 +1,168 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// CodeFormat converts a LoadCodes document of another format into its JSON
+// form, see RegisterCodeFormat.
+type CodeFormat func(r io.Reader) (io.Reader, error)
+
+var (
+	formatMux   sync.RWMutex
+	codeFormats = map[string]CodeFormat{}
+)
+
+// RegisterCodeFormat makes the format available to LoadCodes. It is called
+// by the packages implementing formats, so that this package only depends
+// on the standard library, e.g. the YAML and TOML formats are registered by
+// importing github.com/rtmzk/errors/yamlcodes and
+// github.com/rtmzk/errors/tomlcodes. The format name is case-insensitive.
+func RegisterCodeFormat(format string, convert CodeFormat) {
+	formatMux.Lock()
+	defer formatMux.Unlock()
+	codeFormats[strings.ToLower(format)] = convert
+}
+
+// codeFormat returns the converter of a format registered with
+// RegisterCodeFormat.
+func codeFormat(format string) (CodeFormat, bool) {
+	formatMux.RLock()
+	defer formatMux.RUnlock()
+	convert, ok := codeFormats[strings.ToLower(format)]
+	return convert, ok
+}
+
+// LoadCodes registers the coders defined in r, a document in the given
+// format, "json" or one registered with RegisterCodeFormat. The document
+// lists the coders with the schema of the catalogs written by
+// ExportCatalog, the messages in other languages being given as
+// translations:
+//
+//	[{
+//		"code": 100404,
+//		"slug": "user_not_found",
+//		"http_status": 404,
+//		"message": "User not found",
+//		"reference": "https://example.com/errors/user_not_found",
+//		"translations": {"fr": "Utilisateur introuvable"}
+//	}]
+//
+// Codes already registered are replaced, like Register does, so that the
+// messages compiled in a program can be changed by its operators without
+// recompiling it. The whole document is validated first: LoadCodes returns
+// an error listing the unknown keys, the reserved and duplicated codes,
+// the invalid HTTP statuses, the empty messages, the slugs of other codes,
+// and in strict mode the codes without an owner, and registers none of
+// them.
+func LoadCodes(r io.Reader, format string) error {
+	pc := callerPC()
+
+	entries, err := decodeCodes(r, format)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	coders := make([]Coder, len(entries))
+	for i, e := range entries {
+		var extra []CoderOption
+		if len(e.Translations) > 0 {
+			extra = append(extra, WithMessages(e.Translations))
+		}
+		coder, err := e.coder(extra...)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("#%d: %v", i, err))
+			continue
+		}
+		coders[i] = coder
+	}
+
+	codeMux.Lock()
+	defer codeMux.Unlock()
+
+	if Frozen() {
+		return Wrap(ErrFrozen, "load codes")
+	}
+
+	seen := make(map[int]bool, len(coders))
+	seenSlugs := map[string]int{}
+	for i, coder := range coders {
+		if coder == nil {
+			continue
+		}
+		code := coder.Code()
+		slug, _ := SlugOf(coder)
+		owner, slugTaken := slugOwner(slug)
+		if prev, ok := seenSlugs[slug]; ok {
+			owner, slugTaken = prev, true
+		}
+		switch err := checkCoder(coder, true); {
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("#%d: %v", i, err))
+		case seen[code]:
+			problems = append(problems, fmt.Sprintf("#%d: code %d is duplicated", i, code))
+		case slugTaken && owner != code:
+			problems = append(problems, fmt.Sprintf("#%d: code %d has the slug %s of code %d", i, code, slug, owner))
+		default:
+			if err := checkRange(code, pc); err != nil {
+				problems = append(problems, fmt.Sprintf("#%d: %v", i, err))
+			}
+		}
+		seen[code] = true
+		if slug != "" {
+			seenSlugs[slug] = code
+		}
+	}
+	if len(problems) > 0 {
+		return Errorf("invalid codes: %d problems:\n%s", len(problems), strings.Join(problems, "\n"))
+	}
+
+	for _, coder := range coders {
+		setCoder(coder)
+		origins[coder.Code()] = pc
+	}
+	return nil
+}
+
+// decodeCodes decodes the coders of a LoadCodes document. The documents of
+// the registered formats are converted to JSON first, so that every format
+// shares the same schema.
+func decodeCodes(r io.Reader, format string) ([]CoderInfo, error) {
+	if !strings.EqualFold(format, "json") {
+		convert, ok := codeFormat(format)
+		if !ok {
+			return nil, Errorf("load codes: unsupported format %q", format)
+		}
+		var err error
+		if r, err = convert(r); err != nil {
+			return nil, Wrap(err, "decode codes")
+		}
+	}
+
+	var entries []CoderInfo
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&entries); err != nil {
+		return nil, Wrap(err, "decode codes")
+	}
+	return entries, nil
+}
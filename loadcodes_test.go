@@ -0,0 +1,89 @@
+package errors
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLoadCodes(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	Register(NewCoder(911501, 500, "Compiled message", ""))
+	RegisterCodeFormat("test", func(io.Reader) (io.Reader, error) {
+		return strings.NewReader(`[
+			{"code": 911501, "http_status": 503, "message": "Try again later", "translations": {"fr": "Réessayez plus tard"}},
+			{"code": 911502, "slug": "loaded", "http_status": 404, "message": "Not found", "reference": "https://example.com/911502"}
+		]`), nil
+	})
+
+	docs := []struct {
+		format string
+		doc    string
+	}{
+		{"json", `[
+			{"code": 911501, "http_status": 503, "message": "Try again later", "translations": {"fr": "Réessayez plus tard"}},
+			{"code": 911502, "slug": "loaded", "http_status": 404, "message": "Not found", "reference": "https://example.com/911502"}
+		]`},
+		{"test", `codes`},
+	}
+
+	for _, tt := range docs {
+		if err := LoadCodes(strings.NewReader(tt.doc), tt.format); err != nil {
+			t.Fatalf("LoadCodes(%s): %v", tt.format, err)
+		}
+
+		coder, _ := GetCoder(911501)
+		if got := coder.HTTPStatus(); got != 503 {
+			t.Errorf("%s: HTTPStatus(): got %d, want %d", tt.format, got, 503)
+		}
+		if got := Message(WithCode(911501, "internal"), "fr"); got != "Réessayez plus tard" {
+			t.Errorf("%s: Message(fr): got %q, want %q", tt.format, got, "Réessayez plus tard")
+		}
+		coder, ok := CoderBySlug("loaded")
+		if !ok || coder.Code() != 911502 || coder.Reference() != "https://example.com/911502" {
+			t.Errorf("%s: CoderBySlug(): got %v, want code %d", tt.format, coder, 911502)
+		}
+	}
+}
+
+func TestLoadCodesInvalid(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	Register(NewCoder(911510, 400, "Taken", "", WithSlug("taken")))
+
+	tests := []struct {
+		name   string
+		format string
+		doc    string
+		want   string
+	}{
+		{"format", "ini", `code = 1`, `unsupported format "ini"`},
+		{"syntax", "json", `[{"code": }]`, "decode codes"},
+		{"unknown key", "json", `[{"code": 911511, "mesage": "typo"}]`, `unknown field "mesage"`},
+		{"reserved", "json", `[{"message": "zero"}]`, "code 0: code is reserved"},
+		{"status", "json", `[{"code": 911512, "http_status": 999, "message": "bad"}]`, "code 911512: invalid HTTP status: 999"},
+		{"message", "json", `[{"code": 911513, "http_status": 400}]`, "code 911513: empty message"},
+		{"duplicate", "json", `[{"code": 911514, "message": "a"}, {"code": 911514, "message": "b"}]`, "code 911514 is duplicated"},
+		{"slug", "json", `[{"code": 911515, "slug": "taken", "message": "a"}]`, "slug taken of code 911510"},
+		{"retry_after", "json", `[{"code": 911516, "message": "a", "retry_after": "soon"}]`, "invalid retry_after"},
+		{"index", "json", `[{"code": 911518, "message": "a", "retry_after": "soon"}, {"message": "zero"}]`, "#1: code 0"},
+	}
+
+	for _, tt := range tests {
+		err := LoadCodes(strings.NewReader(tt.doc), tt.format)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want an error containing %q", tt.name, err, tt.want)
+		}
+	}
+
+	err := LoadCodes(strings.NewReader(`[{"code": 911517, "message": "ok"}, {"code": 911517, "message": "dup"}]`), "json")
+	if err == nil {
+		t.Fatalf("LoadCodes(): got no error, want a duplicate error")
+	}
+	if IsRegistered(911517) {
+		t.Errorf("IsRegistered(911517): got true, want false after a failed load")
+	}
+}
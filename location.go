@@ -0,0 +1,35 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+// Location returns the origin of err: the file, line and function of the
+// top frame of the innermost stack of err's chain, where the error was
+// created, as printed by %+v once the stack filters are applied, see
+// AddStackFilter. It suits log formatters which record the origin as a
+// field without parsing the formatted stack.
+// Location reports false if err's chain holds no stack, e.g. because stacks
+// are not captured, see SetCaptureStacks.
+func Location(err error) (file string, line int, fn string, ok bool) {
+	s := innermostStack(list(err))
+	if s == nil {
+		return "", 0, "", false
+	}
+	pcs := s.frames()
+	if len(pcs) == 0 {
+		return "", 0, "", false
+	}
+	f := Frame(pcs[0])
+	return f.file(), f.line(), f.name(), true
+}
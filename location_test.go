@@ -0,0 +1,44 @@
+package errors
+
+import (
+	"io"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestLocation(t *testing.T) {
+	_, _, want, _ := runtime.Caller(0)
+	err := New("boom")
+	tests := []struct {
+		err  error
+		line int
+	}{
+		{err, want + 1},
+		{Wrap(err, "read"), want + 1},
+		{WithMessage(Wrapc(io.EOF, 912701, "load"), "serve"), want + 8},
+	}
+
+	for i, tt := range tests {
+		file, line, fn, ok := Location(tt.err)
+		if !ok {
+			t.Errorf("test %d: got no location, want one", i+1)
+			continue
+		}
+		if filepath.Base(file) != "location_test.go" || line != tt.line {
+			t.Errorf("test %d: got %s:%d, want location_test.go:%d", i+1, filepath.Base(file), line, tt.line)
+		}
+		if !strings.HasSuffix(fn, ".TestLocation") {
+			t.Errorf("test %d: function: got %q, want TestLocation", i+1, fn)
+		}
+	}
+}
+
+func TestLocationWithoutStack(t *testing.T) {
+	for _, err := range []error{nil, io.EOF, NewNoStack("boom")} {
+		if _, _, _, ok := Location(err); ok {
+			t.Errorf("Location(%v): got a location, want none", err)
+		}
+	}
+}
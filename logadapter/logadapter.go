@@ -0,0 +1,106 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logadapter expands the errors of github.com/rtmzk/errors into the
+// structured fields of zap and logrus: the code and HTTP status of the
+// Coder, the message, the fields and the stack trace. The fields are plain
+// Go values, so that the adapters are used without this package depending
+// on either logger:
+//
+//	// logrus: Fields is a map[string]interface{}, like logrus.Fields.
+//	logrus.WithFields(logadapter.Fields(err)).Error("request failed")
+//
+//	// zap: Entries are converted with zap.Any, in order.
+//	for _, e := range logadapter.Entries(err) {
+//		fields = append(fields, zap.Any(e.Key, e.Value))
+//	}
+//	logger.Error("request failed", fields...)
+//
+//	// zap's SugaredLogger takes the entries as keys and values.
+//	sugar.Errorw("request failed", logadapter.KeysAndValues(err)...)
+package logadapter
+
+import (
+	"fmt"
+
+	"github.com/rtmzk/errors"
+)
+
+// Entries returns the fields describing err, in order: "code" and
+// "http_status", from the Coder of err, "message", "fields", the fields of
+// err's chain sorted by key, and "stack", the innermost stack trace of the
+// chain as "file:line (function)" lines. The fields and the stack are
+// omitted when the chain holds none. Entries returns nil if err is nil.
+func Entries(err error) errors.FieldList {
+	if err == nil {
+		return nil
+	}
+	coder := errors.ParseCoder(err)
+	entries := errors.FieldList{
+		{Key: "code", Value: coder.Code()},
+		{Key: "http_status", Value: coder.HTTPStatus()},
+		{Key: "message", Value: err.Error()},
+	}
+	if fields := errors.SortedFields(err); len(fields) > 0 {
+		entries = append(entries, errors.Field{Key: "fields", Value: fields})
+	}
+	if stack := stackLines(err); len(stack) > 0 {
+		entries = append(entries, errors.Field{Key: "stack", Value: stack})
+	}
+	return entries
+}
+
+// Fields returns the entries describing err as a map, see Entries, which
+// converts to logrus.Fields. The fields of err's chain are a nested map
+// under "fields". Fields returns nil if err is nil.
+func Fields(err error) map[string]interface{} {
+	entries := Entries(err)
+	if entries == nil {
+		return nil
+	}
+	ret := entries.Map()
+	if fields, ok := ret["fields"].(errors.FieldList); ok {
+		ret["fields"] = fields.Map()
+	}
+	return ret
+}
+
+// KeysAndValues returns the entries describing err as alternating keys and
+// values, see Entries, as taken by the methods of zap's SugaredLogger
+// ending with "w". KeysAndValues returns nil if err is nil.
+func KeysAndValues(err error) []interface{} {
+	entries := Entries(err)
+	if entries == nil {
+		return nil
+	}
+	ret := make([]interface{}, 0, 2*len(entries))
+	for _, e := range entries {
+		ret = append(ret, e.Key, e.Value)
+	}
+	return ret
+}
+
+// stackLines returns the frames of the innermost stack trace of err's
+// chain, formatted as "file:line (function)" like errors.SlogAttrs.
+func stackLines(err error) []string {
+	frames := errors.Frames(err)
+	if len(frames) == 0 {
+		return nil
+	}
+	ret := make([]string, len(frames))
+	for i, f := range frames {
+		ret[i] = fmt.Sprintf("%s:%d (%s)", f.File, f.Line, f.Function)
+	}
+	return ret
+}
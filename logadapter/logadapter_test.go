@@ -0,0 +1,84 @@
+package logadapter
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rtmzk/errors"
+)
+
+func init() {
+	errors.Register(errors.NewCoder(913901, 409, "conflict", ""))
+}
+
+func TestEntries(t *testing.T) {
+	err := errors.WithFields(errors.WithCode(913901, "version mismatch"), "user", "u1", "attempt", 2)
+
+	got := Entries(err)
+	keys := make([]string, len(got))
+	for i, e := range got {
+		keys[i] = e.Key
+	}
+	if want := []string{"code", "http_status", "message", "fields", "stack"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("Entries keys: got %v, want %v", keys, want)
+	}
+	if got[0].Value != 913901 || got[1].Value != 409 || got[2].Value != "version mismatch" {
+		t.Errorf("Entries: got %v %v %v, want 913901 409 version mismatch", got[0].Value, got[1].Value, got[2].Value)
+	}
+	byts, _ := json.Marshal(got[3].Value)
+	if want := `{"attempt":2,"user":"u1"}`; string(byts) != want {
+		t.Errorf("Entries fields: got %s, want %s", byts, want)
+	}
+	stack := got[4].Value.([]string)
+	if len(stack) == 0 || !strings.Contains(stack[0], "logadapter_test.go") || !strings.HasSuffix(stack[0], ".TestEntries)") {
+		t.Errorf("Entries stack: got %v, want TestEntries on top", stack)
+	}
+}
+
+func TestEntriesOmitted(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, 0},
+		{errors.WithCodeNoStack(913901, "bare"), 3},
+	}
+	for _, tt := range tests {
+		if got := Entries(tt.err); len(got) != tt.want {
+			t.Errorf("Entries(%v): got %d entries, want %d", tt.err, len(got), tt.want)
+		}
+	}
+}
+
+func TestFields(t *testing.T) {
+	err := errors.WithFields(errors.WithCode(913901, "version mismatch"), "user", "u1")
+
+	got := Fields(err)
+	if got["code"] != 913901 || got["http_status"] != 409 || got["message"] != "version mismatch" {
+		t.Errorf("Fields: got %v, want the code, status and message", got)
+	}
+	if want := map[string]interface{}{"user": "u1"}; !reflect.DeepEqual(got["fields"], want) {
+		t.Errorf("Fields fields: got %#v, want %#v", got["fields"], want)
+	}
+	if _, ok := got["stack"].([]string); !ok {
+		t.Errorf("Fields stack: got %T, want []string", got["stack"])
+	}
+	if got := Fields(nil); got != nil {
+		t.Errorf("Fields(nil): got %v, want nil", got)
+	}
+}
+
+func TestKeysAndValues(t *testing.T) {
+	err := errors.WithCodeNoStack(913901, "version mismatch")
+
+	got := KeysAndValues(err)
+	want := []interface{}{"code", 913901, "http_status", 409, "message", "version mismatch"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("KeysAndValues: got %v, want %v", got, want)
+	}
+	if got := KeysAndValues(nil); got != nil {
+		t.Errorf("KeysAndValues(nil): got %v, want nil", got)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"log/slog"
+	"sync"
+)
+
+// logLevels contains the log levels overriding the derived ones, by code.
+var logLevels sync.Map // map[int]slog.Level
+
+// SetLogLevel overrides the log level of the given code.
+func SetLogLevel(code int, level slog.Level) {
+	logLevels.Store(code, level)
+}
+
+// ClearLogLevel removes the log level override of the given code.
+func ClearLogLevel(code int) {
+	logLevels.Delete(code)
+}
+
+// ErrorSeverity returns the severity of err, that of its Coder, see
+// WithSeverity and SeverityOf, so that logging and alerting layers decide
+// how to report err from the error itself. Severities are ordered, e.g.
+//
+//	if errors.ErrorSeverity(err) >= errors.SeverityFatal {
+//		page(err)
+//	}
+//
+// ErrorSeverity returns 0 if err is nil.
+func ErrorSeverity(err error) Severity {
+	if err == nil {
+		return 0
+	}
+	severity, _ := SeverityOf(ParseCoder(err))
+	return severity
+}
+
+// LogLevel returns the level err should be logged at, so that logging
+// middlewares log errors uniformly across services.
+// The level set with SetLogLevel for the code of err wins, then the
+// severity of the coder if it has one. Otherwise errors mapped to a 5xx HTTP
+// status are logged at slog.LevelError and all other errors at
+// slog.LevelInfo. A nil error is logged at slog.LevelDebug.
+func LogLevel(err error) slog.Level {
+	if err == nil {
+		return slog.LevelDebug
+	}
+
+	coder := ParseCoder(err)
+	if level, ok := logLevels.Load(coder.Code()); ok {
+		return level.(slog.Level)
+	}
+
+	severity, _ := SeverityOf(coder)
+	switch severity {
+	case SeverityDebug:
+		return slog.LevelDebug
+	case SeverityWarn:
+		return slog.LevelWarn
+	case SeverityError, SeverityFatal:
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
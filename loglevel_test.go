@@ -0,0 +1,78 @@
+package errors
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestLogLevel(t *testing.T) {
+	Register(defaultCoder{C: 901001, HTTP: 404})
+	Register(defaultCoder{C: 901002, HTTP: 503})
+	Register(defaultCoder{C: 901003, HTTP: 409})
+	SetLogLevel(901003, slog.LevelWarn)
+	defer ClearLogLevel(901003)
+
+	tests := []struct {
+		err  error
+		want slog.Level
+	}{
+		{nil, slog.LevelDebug},
+		{io.EOF, slog.LevelError},
+		{WithCode(901001, "not found"), slog.LevelInfo},
+		{WithCode(901002, "unavailable"), slog.LevelError},
+		{WithCode(901003, "conflict"), slog.LevelWarn},
+	}
+
+	for i, tt := range tests {
+		if got := LogLevel(tt.err); got != tt.want {
+			t.Errorf("test %d: LogLevel(%v): got %v, want %v", i+1, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestLogLevelSeverity(t *testing.T) {
+	Register(NewCoder(901004, 500, "degraded", "", WithSeverity(SeverityWarn)))
+	Register(NewCoder(901005, 400, "corrupted", "", WithSeverity(SeverityFatal)))
+
+	tests := []struct {
+		err  error
+		want slog.Level
+	}{
+		{WithCode(901004, "degraded"), slog.LevelWarn},
+		{WithCode(901005, "corrupted"), slog.LevelError},
+	}
+
+	for i, tt := range tests {
+		if got := LogLevel(tt.err); got != tt.want {
+			t.Errorf("test %d: LogLevel(%v): got %v, want %v", i+1, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestErrorSeverity(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(908401, 404, "not found", ""))
+	MustRegister(NewCoder(908402, 503, "unavailable", ""))
+	MustRegister(NewCoder(908403, 400, "corrupted", "", WithSeverity(SeverityFatal)))
+
+	tests := []struct {
+		err  error
+		want Severity
+	}{
+		{nil, 0},
+		{io.EOF, SeverityError},
+		{WithCode(908401, "missing"), SeverityInfo},
+		{WithCode(908402, "down"), SeverityError},
+		{Wrap(WithCode(908403, "checksum"), "read"), SeverityFatal},
+		{Join(WithCode(908401, "missing"), WithCode(908403, "checksum")), SeverityInfo},
+	}
+
+	for i, tt := range tests {
+		if got := ErrorSeverity(tt.err); got != tt.want {
+			t.Errorf("test %d: ErrorSeverity(%v): got %v, want %v", i+1, tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// marshalStacks enables the stack traces in the JSON encoding of coded
+// errors.
+var marshalStacks bool
+
+// SetMarshalStacks enables or disables the stack trace in the JSON encoding
+// of coded errors. It is disabled by default, since stack traces should
+// usually not leave the process.
+func SetMarshalStacks(enabled bool) {
+	marshalStacks = enabled
+}
+
+// jsonError is the JSON encoding of a coded error.
+type jsonError struct {
+	Code       int      `json:"code"`
+	Slug       string   `json:"slug,omitempty"`
+	Message    string   `json:"message"`
+	HTTPStatus int      `json:"http_status"`
+	Reference  string   `json:"reference,omitempty"`
+	Stack      []string `json:"stack,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler, see ToJSON.
+func (w *withCode) MarshalJSON() ([]byte, error) {
+	return ToJSON(w)
+}
+
+// ToJSON returns the JSON encoding of err: the code, slug, message, HTTP status
+// and reference of its Coder, and its innermost stack trace if enabled by
+// SetMarshalStacks.
+//
+//	{"code":100101,"slug":"...","message":"...","http_status":500,"reference":"..."}
+//
+// FromJSON decodes it back into an equivalent coded error. A panic raised
+// while rendering err is recovered and the code, the HTTP status and its
+// status text are encoded instead, see SetRenderFailureHook.
+// If err is nil, ToJSON returns the JSON null.
+func ToJSON(err error) ([]byte, error) {
+	if err == nil {
+		return []byte("null"), nil
+	}
+
+	coder := ParseCoder(err)
+	byts, encErr, failed := safeRender(err, coder, func() ([]byte, error) {
+		e := jsonError{
+			Code:       coder.Code(),
+			Message:    coder.String(),
+			HTTPStatus: coder.HTTPStatus(),
+			Reference:  coder.Reference(),
+		}
+		e.Slug, _ = SlugOf(coder)
+		if e.Message == "" {
+			e.Message = err.Error()
+		}
+		if marshalStacks {
+			errs := list(err)
+			for i := len(errs) - 1; i >= 0 && e.Stack == nil; i-- {
+				if s := stackOf(errs[i]); s != nil {
+					for _, pc := range s.frames() {
+						e.Stack = append(e.Stack, fmt.Sprintf("%+v", Frame(pc)))
+					}
+				}
+			}
+		}
+		return json.Marshal(e)
+	})
+	if failed {
+		return json.Marshal(jsonError{
+			Code:       coder.Code(),
+			Message:    http.StatusText(coder.HTTPStatus()),
+			HTTPStatus: coder.HTTPStatus(),
+		})
+	}
+	return byts, encErr
+}
+
+// FromJSON decodes an error encoded by ToJSON. The error has the decoded
+// code and message. When the code is not registered locally, ParseCoder
+// reports a Coder with the decoded HTTP status and reference.
+// The JSON null decodes to a nil error.
+func FromJSON(data []byte) (error, error) {
+	var e *jsonError
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, Wrap(err, "decode coded error")
+	}
+	if e == nil {
+		return nil, nil
+	}
+
+	return &withCode{
+		err:    fmt.Errorf("%s", e.Message),
+		code:   e.Code,
+		remote: NewCoder(e.Code, e.HTTPStatus, e.Message, e.Reference, WithSlug(e.Slug)),
+	}, nil
+}
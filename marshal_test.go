@@ -0,0 +1,62 @@
+package errors
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestToJSONFromJSON(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(905401, 404, "User not found", "https://example.com/905401"))
+
+	err := WithCode(905401, "user %d missing", 42)
+	data, jerr := json.Marshal(err)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	want := `{"code":905401,"message":"User not found","http_status":404,"reference":"https://example.com/905401"}`
+	if string(data) != want {
+		t.Errorf("json.Marshal(): got %s, want %s", data, want)
+	}
+
+	// Decode on a client which does not know the code.
+	restoreClient := NewConfig(UseRegistry(MapBackend())).Apply()
+	got, jerr := FromJSON(data)
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	coder := ParseCoder(got)
+	if !IsCode(got, 905401) || coder.HTTPStatus() != 404 || coder.Reference() != "https://example.com/905401" || got.Error() != "User not found" {
+		t.Errorf("FromJSON(): got %v with coder %d %d %q, want the decoded coder", got, coder.Code(), coder.HTTPStatus(), coder.Reference())
+	}
+	restoreClient()
+
+	if got, jerr := FromJSON([]byte("null")); got != nil || jerr != nil {
+		t.Errorf("FromJSON(null): got (%v, %v), want (nil, nil)", got, jerr)
+	}
+	if _, jerr := FromJSON([]byte("{")); jerr == nil {
+		t.Errorf("FromJSON(invalid): got nil, want an error")
+	}
+}
+
+func TestMarshalStacks(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend()), MarshalStacks(true)).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(905402, 500, "internal", ""))
+
+	data, err := ToJSON(Wrap(WithCode(905402, "boom"), "handler"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e jsonError
+	if err := json.Unmarshal(data, &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Code != 905402 || len(e.Stack) == 0 || !strings.Contains(e.Stack[0], "TestMarshalStacks") {
+		t.Errorf("ToJSON(): got %s, want code 905402 and the stack", data)
+	}
+}
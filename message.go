@@ -0,0 +1,212 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+)
+
+// translations contains the translated messages of the registered codes,
+// by code and language. It is guarded by codeMux.
+var translations = map[int]map[string]string{}
+
+// fallbackLanguage is the language used when a message has no translation
+// in the requested one.
+var fallbackLanguage = "en"
+
+// pseudoLocalize enables the pseudo-localization of messages.
+var pseudoLocalize bool
+
+// RegisterTranslation registers the message of code in the given language,
+// a BCP 47 tag such as "fr" or "pt-BR".
+func RegisterTranslation(code int, lang, msg string) {
+	codeMux.Lock()
+	defer codeMux.Unlock()
+
+	if translations[code] == nil {
+		translations[code] = map[string]string{}
+	}
+	translations[code][strings.ToLower(lang)] = msg
+}
+
+// SetFallbackLanguage sets the language used by Message when a message is
+// not translated in the requested language. It defaults to "en".
+func SetFallbackLanguage(lang string) {
+	fallbackLanguage = strings.ToLower(lang)
+}
+
+// SetPseudoLocalization enables or disables the pseudo-localization of the
+// messages returned by Message: their letters are accented and their text
+// expanded by about a third, e.g. "[Ñóţ ƒóûñð ~~~]". Messages which do not
+// go through the catalog stand out in the user interface, and layouts are
+// checked against the longer texts of most languages.
+func SetPseudoLocalization(enabled bool) {
+	pseudoLocalize = enabled
+}
+
+// Message returns the human facing message of the Coder of err in the
+// given language. The messages are looked up for lang or its base language,
+// e.g. "pt" for "pt-BR", then for the fallback language. In each language,
+// the messages of the Coder, see WithMessages, take precedence over the
+// translations registered with RegisterTranslation.
+// The message of the Coder is used when none is found. The message of an
+// error created by NewT is rendered with its parameters. A message set by
+// an enricher, see AddEnricher, takes precedence over all of them.
+func Message(err error, lang string) string {
+	if err == nil {
+		return ""
+	}
+	return coderMessage(err, ParseCoder(err), lang)
+}
+
+// coderMessage returns the message of err like Message, with coder standing
+// for the Coder of err, e.g. its fallback in the API version of a client.
+func coderMessage(err error, coder Coder, lang string) string {
+	if msg, ok := publicMessage(err); ok {
+		return msg
+	}
+	return renderTemplate(localize(coder, lang), templateParams(err))
+}
+
+// localize returns the message of coder in lang.
+func localize(coder Coder, lang string) string {
+	var msg string
+	if v, ok := coder.(interface{ StringL(string) string }); ok {
+		msg = v.StringL(lang)
+	} else if msg = translate(coder.Code(), lang); msg == "" {
+		msg = coder.String()
+	}
+	if pseudoLocalize {
+		msg = pseudo(msg)
+	}
+	return msg
+}
+
+// ParseCoderL returns the Coder of err, see ParseCoder, whose String method
+// returns its message in the given language, see Message. Transports which
+// only know about String can then serve localized messages.
+func ParseCoderL(err error, lang string) Coder {
+	c := ParseCoder(err)
+	if o, ok := c.(*onceCoder); ok {
+		c = o.get()
+	}
+	params := templateParams(err)
+	if v, ok := c.(coder); ok {
+		v.msg = renderTemplate(localize(v, lang), params)
+		return v
+	}
+	return localizedCoder{Coder: c, msg: renderTemplate(localize(c, lang), params)}
+}
+
+// localizedCoder overrides the message of a Coder which is not built by
+// NewCoder.
+type localizedCoder struct {
+	Coder
+	msg string
+}
+
+func (c localizedCoder) String() string { return c.msg }
+
+type localeKey struct{}
+
+// WithLocale returns a copy of ctx carrying the language of the caller, a
+// BCP 47 tag such as "fr" or "pt-BR". It takes precedence over the
+// Accept-Language header of the requests served by WriteError and
+// ServeProblem.
+func WithLocale(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, localeKey{}, lang)
+}
+
+// LocaleFrom returns the language set on ctx by WithLocale, or "".
+func LocaleFrom(ctx context.Context) string {
+	lang, _ := ctx.Value(localeKey{}).(string)
+	return lang
+}
+
+// MessageCtx returns the message of the Coder of err in the language of
+// ctx, see WithLocale and Message.
+func MessageCtx(ctx context.Context, err error) string {
+	return Message(err, LocaleFrom(ctx))
+}
+
+// translate returns the translation of the message of code in lang, its
+// base language or the fallback language, or "".
+func translate(code int, lang string) string {
+	for _, l := range []string{lang, fallbackLanguage} {
+		if msg, ok := translation(code, l); ok {
+			return msg
+		}
+	}
+	return ""
+}
+
+// translation returns the translation of the message of code in lang or
+// its base language.
+func translation(code int, lang string) (string, bool) {
+	codeMux.RLock()
+	defer codeMux.RUnlock()
+	return lookupLanguage(translations[code], lang)
+}
+
+// lookupLanguage returns the message of byLang in lang, or else in its base
+// language, e.g. "pt" for "pt-BR".
+func lookupLanguage(byLang map[string]string, lang string) (string, bool) {
+	if len(byLang) == 0 {
+		return "", false
+	}
+	lang = strings.ToLower(lang)
+	if msg, ok := byLang[lang]; ok {
+		return msg, true
+	}
+	if i := strings.IndexByte(lang, '-'); i > 0 {
+		if msg, ok := byLang[lang[:i]]; ok {
+			return msg, true
+		}
+	}
+	return "", false
+}
+
+// translationsOf returns a copy of the translations of code.
+func translationsOf(code int) map[string]string {
+	codeMux.RLock()
+	defer codeMux.RUnlock()
+
+	if len(translations[code]) == 0 {
+		return nil
+	}
+	ret := make(map[string]string, len(translations[code]))
+	for lang, msg := range translations[code] {
+		ret[lang] = msg
+	}
+	return ret
+}
+
+var pseudoReplacer = strings.NewReplacer(
+	"a", "à", "b", "ƀ", "c", "ç", "d", "ð", "e", "é", "f", "ƒ", "g", "ĝ",
+	"h", "ĥ", "i", "î", "j", "ĵ", "k", "ķ", "l", "ļ", "m", "ɱ", "n", "ñ",
+	"o", "ó", "p", "þ", "r", "ŕ", "s", "š", "t", "ţ", "u", "û", "w", "ŵ",
+	"y", "ý", "z", "ž",
+	"A", "Å", "C", "Ç", "D", "Ð", "E", "É", "G", "Ĝ", "H", "Ĥ", "I", "Î",
+	"J", "Ĵ", "K", "Ķ", "L", "Ļ", "N", "Ñ", "O", "Ö", "R", "Ŕ", "S", "Š",
+	"T", "Ţ", "U", "Û", "W", "Ŵ", "Y", "Ý", "Z", "Ž",
+)
+
+// pseudo returns the pseudo-localized form of msg.
+func pseudo(msg string) string {
+	pad := (utf8.RuneCountInString(msg) + 2) / 3
+	return "[" + pseudoReplacer.Replace(msg) + " " + strings.Repeat("~", pad) + "]"
+}
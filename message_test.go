@@ -0,0 +1,117 @@
+package errors
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMessage(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(904801, 404, "Not found", ""))
+	MustRegister(NewCoder(904802, 404, "Gone", ""))
+	RegisterTranslation(904801, "fr", "Introuvable")
+	RegisterTranslation(904801, "pt-BR", "Não encontrado")
+	RegisterTranslation(904801, "en", "Nothing here")
+
+	tests := []struct {
+		code int
+		lang string
+		want string
+	}{
+		{904801, "fr", "Introuvable"},
+		{904801, "fr-CA", "Introuvable"},
+		{904801, "pt-br", "Não encontrado"},
+		{904801, "de", "Nothing here"},
+		{904802, "fr", "Gone"},
+	}
+	for _, tt := range tests {
+		if got := Message(WithCode(tt.code, "failed"), tt.lang); got != tt.want {
+			t.Errorf("Message(%d, %q): got %q, want %q", tt.code, tt.lang, got, tt.want)
+		}
+	}
+
+	if info, _ := DescribeCode(904801); len(info.Translations) != 3 {
+		t.Errorf("DescribeCode(904801).Translations: got %v, want 3 languages", info.Translations)
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Language", "fr-CH, fr;q=0.9, en;q=0.8")
+	rec := httptest.NewRecorder()
+	WriteError(rec, r, WithCode(904801, "failed"))
+	if !strings.Contains(rec.Body.String(), `"message":"Introuvable"`) {
+		t.Errorf("WriteError(Accept-Language: fr-CH): got %s, want the French message", rec.Body.String())
+	}
+}
+
+func TestPseudoLocalization(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend()), PseudoLocalize(true)).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(904803, 404, "Not found", ""))
+	if got, want := Message(WithCode(904803, "failed"), "en"), "[Ñóţ ƒóûñð ~~~]"; got != want {
+		t.Errorf("Message(): got %q, want %q", got, want)
+	}
+}
+
+func TestLocalizedCoder(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(907801, 404, "Not found", "", WithMessages(map[string]string{
+		"FR": "Introuvable",
+		"de": "Nicht gefunden",
+	})))
+	RegisterTranslation(907801, "de", "Unbekannt")
+	RegisterTranslation(907801, "es", "No encontrado")
+	MustRegister(NewCoder(907802, 404, "Gone", "", WithMessages(map[string]string{"en": "Gone away"})))
+	RegisterTranslation(907802, "fr", "Parti")
+
+	tests := []struct {
+		code int
+		lang string
+		want string
+	}{
+		{907801, "fr-CA", "Introuvable"},
+		{907801, "de", "Nicht gefunden"},
+		{907801, "es", "No encontrado"},
+		{907801, "it", "Not found"},
+		{907802, "fr", "Parti"},
+		{907802, "it", "Gone away"},
+	}
+	for _, tt := range tests {
+		err := WithCode(tt.code, "failed")
+		if got := Message(err, tt.lang); got != tt.want {
+			t.Errorf("Message(%d, %q): got %q, want %q", tt.code, tt.lang, got, tt.want)
+		}
+		coder := ParseCoderL(err, tt.lang)
+		if got := coder.String(); got != tt.want {
+			t.Errorf("ParseCoderL(%d, %q).String(): got %q, want %q", tt.code, tt.lang, got, tt.want)
+		}
+		if got := coder.HTTPStatus(); got != 404 {
+			t.Errorf("ParseCoderL(%d, %q).HTTPStatus(): got %d, want 404", tt.code, tt.lang, got)
+		}
+		if got := MessageCtx(WithLocale(context.Background(), tt.lang), err); got != tt.want {
+			t.Errorf("MessageCtx(%d, %q): got %q, want %q", tt.code, tt.lang, got, tt.want)
+		}
+	}
+
+	if got := ParseCoder(WithCode(907801, "failed")).String(); got != "Not found" {
+		t.Errorf("ParseCoder after ParseCoderL: got %q, want %q", got, "Not found")
+	}
+	if info, _ := DescribeCode(907801); info.Translations["de"] != "Nicht gefunden" || info.Translations["fr"] != "Introuvable" {
+		t.Errorf("DescribeCode(907801).Translations: got %v, want the messages of the coder", info.Translations)
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Language", "de")
+	r = r.WithContext(WithLocale(r.Context(), "fr"))
+	rec := httptest.NewRecorder()
+	WriteError(rec, r, WithCode(907801, "failed"))
+	if !strings.Contains(rec.Body.String(), `"message":"Introuvable"`) {
+		t.Errorf("WriteError(WithLocale fr): got %s, want the French message", rec.Body.String())
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics exposes the counters of github.com/rtmzk/errors, such as
+// the errors served without a code, to monitoring systems.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+
+	"github.com/rtmzk/errors"
+)
+
+// Metric names written by Handler.
+const (
+	NameUncoded = "errors_uncoded_served_total"
+	NameCodes   = "errors_codes_total"
+)
+
+// Uncoded returns how many errors have been served with the unknown coder,
+// because they lacked a registered code, see errors.ServedCoder.
+func Uncoded() uint64 {
+	return errors.UncodedCount()
+}
+
+// LogUncoded logs every error served with the unknown coder on logger at
+// the warning level, with its fingerprint and origin, so that the missing
+// codes can be tracked down. A nil logger stops the logging.
+func LogUncoded(logger *slog.Logger) {
+	if logger == nil {
+		errors.SetUncodedHook(nil)
+		return
+	}
+	errors.SetUncodedHook(func(e errors.UncodedEvent) {
+		logger.LogAttrs(context.Background(), slog.LevelWarn, "uncoded error served",
+			slog.String("fingerprint", e.Fingerprint),
+			slog.String("origin", e.Origin),
+			slog.String("error", e.Err.Error()),
+		)
+	})
+}
+
+// Handler returns a handler serving the counters in the Prometheus text
+// exposition format: the number of uncoded errors served, and the usage
+// counter of every code, see errors.UsageCounts, labeled with the blame of
+// the code, see errors.CodeBlame, so that alerts can leave out the errors
+// caused by users.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+		fmt.Fprintf(w, "# HELP %s Errors served with the unknown coder.\n", NameUncoded)
+		fmt.Fprintf(w, "# TYPE %s counter\n", NameUncoded)
+		fmt.Fprintf(w, "%s %d\n", NameUncoded, Uncoded())
+
+		counts := errors.UsageCounts()
+		codes := make([]int, 0, len(counts))
+		for code := range counts {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		fmt.Fprintf(w, "# HELP %s Coded errors created, by code.\n", NameCodes)
+		fmt.Fprintf(w, "# TYPE %s counter\n", NameCodes)
+		for _, code := range codes {
+			fmt.Fprintf(w, "%s{code=\"%d\",blame=\"%s\"} %d\n", NameCodes, code, errors.CodeBlame(code), counts[code])
+		}
+	})
+}
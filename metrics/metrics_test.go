@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rtmzk/errors"
+)
+
+func TestUncoded(t *testing.T) {
+	errors.Register(errors.NewCoder(905901, http.StatusBadRequest, "bad", ""))
+
+	var buf bytes.Buffer
+	LogUncoded(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer LogUncoded(nil)
+
+	before := Uncoded()
+	errors.WriteError(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), errors.New("no code"))
+	errors.WriteError(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), errors.WithCode(905901, "coded"))
+
+	if got := Uncoded() - before; got != 1 {
+		t.Errorf("Uncoded: got %d more, want %d", got, 1)
+	}
+	for _, want := range []string{"uncoded error served", "fingerprint=", "origin=", "metrics_test.go", "error=\"no code\""} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("log: got %q, want it to contain %q", buf.String(), want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, want := range []string{NameUncoded + " ", NameCodes + `{code="905901",blame="user"} `} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("Handler: got %q, want it to contain %q", rec.Body.String(), want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+// The multi-errors of third-party libraries predating Go 1.20 expose their
+// members through their own methods: go.uber.org/multierr with
+// Errors() []error, and github.com/hashicorp/go-multierror with
+// WrappedErrors() []error. They are recognized without depending on those
+// libraries, so that IsCode, ParseCoder and Translate see through them.
+
+// members returns the members of the multi-error e, if it is one: an
+// Aggregate, an error of errors.Join or a multi-error of a third-party
+// library.
+func members(e error) ([]error, bool) {
+	switch m := e.(type) {
+	case interface{ Unwrap() []error }:
+		return m.Unwrap(), true
+	case interface{ Errors() []error }:
+		return m.Errors(), true
+	case interface{ WrappedErrors() []error }:
+		return m.WrappedErrors(), true
+	}
+	return nil, false
+}
+
+// AsAggregate returns err as an Aggregate. Aggregates are returned as is,
+// and the members of the other multi-errors, those of errors.Join,
+// go.uber.org/multierr and github.com/hashicorp/go-multierror, are gathered
+// in a new Aggregate. It returns false if err is not a multi-error, or is
+// one without any member.
+func AsAggregate(err error) (Aggregate, bool) {
+	if agg, ok := err.(Aggregate); ok {
+		return agg, true
+	}
+	errs, ok := members(err)
+	if !ok {
+		return nil, false
+	}
+	agg := NewAggregate(errs)
+	return agg, agg != nil
+}
@@ -0,0 +1,81 @@
+package errors
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+// uberMulti mimics the multi-errors of go.uber.org/multierr.
+type uberMulti struct{ errs []error }
+
+func (m *uberMulti) Error() string   { return "multi" }
+func (m *uberMulti) Errors() []error { return m.errs }
+
+// hashicorpMulti mimics the multi-errors of github.com/hashicorp/go-multierror,
+// which unwrap to a chain of their members.
+type hashicorpMulti struct{ errs []error }
+
+func (m *hashicorpMulti) Error() string          { return "multi" }
+func (m *hashicorpMulti) WrappedErrors() []error { return m.errs }
+func (m *hashicorpMulti) Unwrap() error          { return hashicorpChain(m.errs) }
+
+type hashicorpChain []error
+
+func (c hashicorpChain) Error() string { return c[0].Error() }
+func (c hashicorpChain) Unwrap() error {
+	if len(c) == 1 {
+		return nil
+	}
+	return c[1:]
+}
+
+func TestThirdPartyMultiErrors(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(907901, 400, "bad", ""))
+	MustRegister(NewCoder(907902, 409, "conflict", ""))
+	MustRegister(NewCoder(907903, 502, "translated", ""))
+
+	errs := []error{io.EOF, WithCode(907901, "a"), WithCode(907902, "b")}
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"multierr", &uberMulti{errs}},
+		{"go-multierror", &hashicorpMulti{errs}},
+		{"wrapped", Wrap(&uberMulti{errs}, "context")},
+	}
+
+	for _, tt := range tests {
+		if !IsCode(tt.err, 907901) || !IsCode(tt.err, 907902) {
+			t.Errorf("%s: IsCode: got false, want true", tt.name)
+		}
+		if got := ParseCoder(tt.err).Code(); got != 907901 {
+			t.Errorf("%s: ParseCoder: got %d, want 907901", tt.name, got)
+		}
+		translated := Translate(tt.err, 907903, "call failed")
+		if got := ParseCoder(translated).Code(); got != 907903 || !IsCode(translated, 907902) {
+			t.Errorf("%s: Translate: got code %d, want 907903 with member 907902", tt.name, got)
+		}
+	}
+
+	agg, ok := AsAggregate(&hashicorpMulti{errs})
+	if !ok || !reflect.DeepEqual(agg.Errors(), errs) {
+		t.Errorf("AsAggregate(go-multierror): got %v, %v, want %v", agg, ok, errs)
+	}
+	if got := Codes(agg); !reflect.DeepEqual(got, []int{907901, 907902}) {
+		t.Errorf("Codes(AsAggregate(go-multierror)): got %v, want [907901 907902]", got)
+	}
+	own := NewAggregate(errs)
+	if got, ok := AsAggregate(own); !ok || !reflect.DeepEqual(got, own) {
+		t.Errorf("AsAggregate(Aggregate): got %v, %v, want the aggregate", got, ok)
+	}
+	if got, ok := AsAggregate(&uberMulti{[]error{nil}}); ok {
+		t.Errorf("AsAggregate(empty): got %v, true, want false", got)
+	}
+	if _, ok := AsAggregate(io.EOF); ok {
+		t.Errorf("AsAggregate(EOF): got true, want false")
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ndjsonRecord is the record written by WriteNDJSON.
+type ndjsonRecord struct {
+	Time        time.Time              `json:"time"`
+	Fingerprint string                 `json:"fingerprint"`
+	Code        int                    `json:"code"`
+	HTTPStatus  int                    `json:"http_status"`
+	Message     string                 `json:"message"`
+	Origin      string                 `json:"origin,omitempty"`
+	Fields      map[string]interface{} `json:"fields,omitempty"`
+}
+
+// WriteNDJSON appends one JSON record describing err to w, terminated by a
+// newline. The records are normalized, so that a sidecar log shipper can
+// ingest a structured error stream separately from the application logs:
+//
+//	{"time":"...","fingerprint":"9f1c...","code":100101,"http_status":500,
+//	 "message":"...","origin":"main.run (main.go:42)","fields":{...}}
+//
+// The fingerprint is stable across processes, see Bucket. Writes of a
+// single record are not split, so concurrent writers only need w to be
+// safe for concurrent use. Nothing is written if err is nil.
+func WriteNDJSON(w io.Writer, err error) error {
+	if err == nil {
+		return nil
+	}
+	d := Describe(err)
+	byts, encErr := json.Marshal(ndjsonRecord{
+		Time:        now().UTC(),
+		Fingerprint: fmt.Sprintf("%016x", fingerprint(err)),
+		Code:        d.Code,
+		HTTPStatus:  d.HTTPStatus,
+		Message:     err.Error(),
+		Origin:      d.Origin,
+		Fields:      Fields(err),
+	})
+	if encErr != nil {
+		return encErr
+	}
+	_, encErr = w.Write(append(byts, '\n'))
+	return encErr
+}
@@ -0,0 +1,46 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWriteNDJSON(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(903301, 400, "bad input", ""))
+
+	var buf bytes.Buffer
+	if err := WriteNDJSON(&buf, nil); err != nil || buf.Len() != 0 {
+		t.Errorf("WriteNDJSON(nil): got (%v, %q), want (nil, \"\")", err, buf.String())
+	}
+
+	errs := []error{WithCode(903301, "first"), Wrap(New("second"), "wrapped")}
+	for _, err := range errs {
+		if err := WriteNDJSON(&buf, err); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(errs) {
+		t.Fatalf("WriteNDJSON: got %d lines, want %d", len(lines), len(errs))
+	}
+	for i, line := range lines {
+		var rec ndjsonRecord
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("line %d: %v", i, err)
+		}
+		want := Describe(errs[i])
+		if rec.Code != want.Code || rec.Message != errs[i].Error() || rec.Origin != want.Origin {
+			t.Errorf("line %d: got %+v, want code %d, message %q and origin %q",
+				i, rec, want.Code, errs[i].Error(), want.Origin)
+		}
+		if len(rec.Fingerprint) != 16 || rec.Time.IsZero() {
+			t.Errorf("line %d: got fingerprint %q and time %v", i, rec.Fingerprint, rec.Time)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+// The stacks of the errors of this package are captured lazily: only the
+// program counters are recorded when an error is created, and they are
+// resolved into functions, files and lines when the stack is formatted.
+// The constructors below skip even that capture, for expected errors
+// created at high rates whose stack is never printed, such as validation
+// errors. See SetCaptureStacks to disable the capture package wide.
+
+// NewNoStack returns an error with the supplied message, like New, without
+// recording a stack trace.
+func NewNoStack(message string) error {
+	return &fundamental{
+		msg: message,
+		env: environment,
+		pc:  callerPC(),
+	}
+}
+
+// WithCodeNoStack returns an error with the given code, like WithCode,
+// without recording a stack trace.
+func WithCodeNoStack(code int, format string, args ...interface{}) error {
+	return created(newCoded(code, errorf(format, args...), nil, nil, nil, callerPC()))
+}
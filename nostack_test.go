@@ -0,0 +1,37 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNoStack(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(907001, 400, "invalid", ""))
+
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{NewNoStack("expected"), "expected"},
+		{WithCodeNoStack(907001, "bad %s", "name"), "bad name"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error(): got %q, want %q", got, tt.want)
+		}
+		if st := tt.err.(interface{ StackTrace() StackTrace }).StackTrace(); st != nil {
+			t.Errorf("%q: got stack %v, want none", tt.want, st)
+		}
+		if got := fmt.Sprintf("%+v", Wrap(tt.err, "wrapped")); got == "" {
+			t.Errorf("%q: %%+v: got an empty string", tt.want)
+		}
+	}
+
+	if !IsCode(WithCodeNoStack(907001, "bad"), 907001) {
+		t.Errorf("IsCode(WithCodeNoStack()): got false, want true")
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+)
+
+// ErrorID returns the occurrence ID of err, 16 hexadecimal digits telling
+// apart the occurrences of a code, so that the report of a client can be
+// matched with the logs of the service. It is the ID of the outermost
+// coded error of err's chain, drawn from the entropy source on the first
+// call, see SetEntropy, and kept by that error, so that every later call
+// returns the same ID. ErrorID returns "" if err has no coded error.
+func ErrorID(err error) string {
+	w, ok := findAs[*withCode](err)
+	if !ok {
+		return ""
+	}
+	if id, ok := w.id.Load().(string); ok {
+		return id
+	}
+	w.id.CompareAndSwap(nil, newErrorID())
+	return w.id.Load().(string)
+}
+
+// newErrorID returns a new occurrence ID, see ErrorID. The time of the
+// package Clock stands in for an exhausted entropy source.
+func newErrorID() string {
+	var b [8]byte
+	if !readEntropy(b[:]) {
+		binary.BigEndian.PutUint64(b[:], uint64(now().UnixNano()))
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,68 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import "sync"
+
+// OnceCoder returns a Coder built by fn and registered on first use, that
+// is the first time one of its methods is called. Libraries defining many
+// rarely used codes can declare them as package variables without paying
+// for their construction and registration at init:
+//
+//	var ErrQuotaExceeded = errors.OnceCoder(func() errors.Coder {
+//		return errors.NewCoder(120042, http.StatusTooManyRequests, "Quota exceeded", "")
+//	})
+//
+//	return errors.WithCode(ErrQuotaExceeded.Code(), "quota of %s exceeded", project)
+//
+// The code is unknown to the registry until then, so errors must be
+// produced through the returned Coder rather than a literal code.
+// It is safe for concurrent use; fn is called once.
+func OnceCoder(fn func() Coder) Coder {
+	return &onceCoder{fn: fn, pc: callerPC()}
+}
+
+type onceCoder struct {
+	once  sync.Once
+	fn    func() Coder
+	coder Coder
+
+	// pc is the call site of OnceCoder, on behalf of which the coder is
+	// registered.
+	pc uintptr
+}
+
+func (o *onceCoder) get() Coder {
+	o.once.Do(func() {
+		coder := o.fn()
+		if err := registerAt(coder, o.pc, false, false); err != nil {
+			panic(err.panicMessage(false))
+		}
+		o.coder = coder
+	})
+	return o.coder
+}
+
+// HTTPStatus implements Coder.
+func (o *onceCoder) HTTPStatus() int { return o.get().HTTPStatus() }
+
+// String implements Coder.
+func (o *onceCoder) String() string { return o.get().String() }
+
+// Reference implements Coder.
+func (o *onceCoder) Reference() string { return o.get().Reference() }
+
+// Code implements Coder.
+func (o *onceCoder) Code() int { return o.get().Code() }
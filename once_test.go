@@ -0,0 +1,42 @@
+package errors
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestOnceCoder(t *testing.T) {
+	calls := 0
+	lazy := OnceCoder(func() Coder {
+		calls++
+		return NewCoder(905701, 429, "lazy", "")
+	})
+
+	if calls != 0 {
+		t.Errorf("calls before use: got %d, want %d", calls, 0)
+	}
+	if _, ok := DescribeCode(905701); ok {
+		t.Errorf("DescribeCode(905701) before use: got registered, want not registered")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = lazy.Code()
+		}()
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("calls after use: got %d, want %d", calls, 1)
+	}
+	err := WithCode(lazy.Code(), "too many")
+	if got := ParseCoder(err).HTTPStatus(); got != 429 {
+		t.Errorf("HTTPStatus: got %d, want %d", got, 429)
+	}
+	if got := lazy.String(); got != "lazy" {
+		t.Errorf("String: got %q, want %q", got, "lazy")
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"io"
+)
+
+// WithOp annotates err with the operation during which it happened, such as
+// "service.Method", in the style of the Op of upspin. Every layer of a call
+// path records its own operation and the message is rendered as
+// "op1: op2: message", which gives a readable propagation path without
+// printing the stacks. The operations are retrieved with Ops.
+// If err is nil, WithOp returns nil, and if op is empty err is returned.
+func WithOp(err error, op string) error {
+	if err == nil {
+		return nil
+	}
+	if op == "" {
+		return err
+	}
+	return &withOp{
+		error: err,
+		op:    op,
+	}
+}
+
+// Ops returns the operations annotating err's chain, outermost first, see
+// WithOp.
+func Ops(err error) []string {
+	var ops []string
+	for _, e := range list(err) {
+		if w, ok := e.(*withOp); ok {
+			ops = append(ops, w.op)
+		}
+	}
+	return ops
+}
+
+type withOp struct {
+	error
+	op string
+}
+
+func (w *withOp) Error() string { return w.op + ": " + w.error.Error() }
+
+func (w *withOp) Cause() error { return w.error }
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (w *withOp) Unwrap() error { return w.error }
+
+func (w *withOp) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "%+v\n", w.Cause())
+			io.WriteString(s, "op: "+w.op)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, w.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", w.Error())
+	}
+}
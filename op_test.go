@@ -0,0 +1,50 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func init() {
+	Register(NewCoder(909201, 404, "user not found", ""))
+}
+
+func TestWithOp(t *testing.T) {
+	tests := []struct {
+		err     error
+		wantMsg string
+		wantOps []string
+	}{
+		{WithOp(io.EOF, "store.Get"), "store.Get: EOF", []string{"store.Get"}},
+		{WithOp(WithOp(io.EOF, "store.Get"), "api.GetUser"), "api.GetUser: store.Get: EOF", []string{"api.GetUser", "store.Get"}},
+		{WithOp(WithCode(909201, "user %d", 7), "api.GetUser"), "api.GetUser: user 7", []string{"api.GetUser"}},
+		{WithOp(io.EOF, ""), "EOF", nil},
+		{Wrap(WithOp(io.EOF, "store.Get"), "load"), "load: store.Get: EOF", []string{"store.Get"}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.wantMsg {
+			t.Errorf("Error(): got %q, want %q", got, tt.wantMsg)
+		}
+		if got := Ops(tt.err); !reflect.DeepEqual(got, tt.wantOps) {
+			t.Errorf("Ops(%v): got %q, want %q", tt.err, got, tt.wantOps)
+		}
+	}
+
+	if got := WithOp(nil, "store.Get"); got != nil {
+		t.Errorf("WithOp(nil): got %v, want nil", got)
+	}
+}
+
+func TestWithOpKeepsCode(t *testing.T) {
+	err := WithOp(WithCode(909201, "user 7"), "api.GetUser")
+	if got := ParseCoder(err).Code(); got != 909201 {
+		t.Errorf("ParseCoder(WithOp(...)).Code(): got %d, want 909201", got)
+	}
+	if got := fmt.Sprintf("%+v", WithOp(New("boom"), "api.GetUser")); !strings.HasSuffix(got, "\nop: api.GetUser") {
+		t.Errorf("%%+v: got %q, want the op on the last line", got)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otelerr exports the errors of github.com/rtmzk/errors as
+// OpenTelemetry log records and span events, so that error reporting goes
+// through the same pipeline as the other OpenTelemetry signals. It is kept apart from the
+// errors package so that the latter does not depend on OpenTelemetry.
+package otelerr
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/log"
+
+	"github.com/rtmzk/errors"
+)
+
+// ScopeName is the instrumentation scope of the records.
+const ScopeName = "github.com/rtmzk/errors/otelerr"
+
+// Exporter emits errors as log records through a Logger.
+type Exporter struct {
+	logger log.Logger
+}
+
+// NewExporter returns an Exporter emitting through a Logger of provider.
+func NewExporter(provider log.LoggerProvider, opts ...log.LoggerOption) *Exporter {
+	return &Exporter{logger: provider.Logger(ScopeName, opts...)}
+}
+
+// Export emits err as a log record, see NewRecord. The record is emitted
+// with ctx, from which the SDK takes the trace and span IDs of the active
+// span. Nothing is emitted if err is nil.
+func (e *Exporter) Export(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+	e.logger.Emit(ctx, NewRecord(err))
+}
+
+// Report implements errors.Reporter, so that the errors served to clients
+// are emitted once the Exporter is added with errors.AddReporter. The
+// records are emitted without a context, since serving functions do not
+// pass one.
+func (e *Exporter) Report(err error, _ errors.Coder) {
+	e.Export(context.Background(), err)
+}
+
+// NewRecord returns the log record describing err. Its body is the message
+// of err and its severity is derived from the severity of the Coder, see
+// errors.SeverityOf. The attributes hold the code, the HTTP status, the
+// fields of err, and the exception attributes of the OpenTelemetry
+// semantic conventions.
+func NewRecord(err error) log.Record {
+	coder := errors.ParseCoder(err)
+	severity, _ := errors.SeverityOf(coder)
+
+	var r log.Record
+	r.SetTimestamp(errors.Now())
+	r.SetSeverity(Severity(severity))
+	r.SetSeverityText(strings.ToUpper(severity.String()))
+	r.SetBody(log.StringValue(err.Error()))
+	r.AddAttributes(
+		log.Int("code", coder.Code()),
+		log.Int("http_status", coder.HTTPStatus()),
+		log.String("exception.type", fmt.Sprintf("%T", errors.Cause(err))),
+		log.String("exception.message", err.Error()),
+	)
+	if fields := errors.Fields(err); len(fields) > 0 {
+		r.AddAttributes(log.Map("fields", keyValues(fields)...))
+	}
+	if stack := stackTrace(err); stack != "" {
+		r.AddAttributes(log.String("exception.stacktrace", stack))
+	}
+	return r
+}
+
+// stackTrace returns the stack trace of the outermost layer of err which
+// recorded one, in the %+v format, or "".
+func stackTrace(err error) string {
+	var tracer interface{ StackTrace() errors.StackTrace }
+	if errors.As(err, &tracer) {
+		if st := tracer.StackTrace(); len(st) > 0 {
+			return strings.TrimPrefix(fmt.Sprintf("%+v", st), "\n")
+		}
+	}
+	return ""
+}
+
+// Severity returns the log severity matching an error severity.
+func Severity(s errors.Severity) log.Severity {
+	switch s {
+	case errors.SeverityDebug:
+		return log.SeverityDebug
+	case errors.SeverityInfo:
+		return log.SeverityInfo
+	case errors.SeverityWarn:
+		return log.SeverityWarn
+	case errors.SeverityError:
+		return log.SeverityError
+	case errors.SeverityFatal:
+		return log.SeverityFatal
+	}
+	return log.SeverityUndefined
+}
+
+// keyValues converts fields into log attributes, sorted by key.
+func keyValues(fields map[string]interface{}) []log.KeyValue {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	kvs := make([]log.KeyValue, 0, len(keys))
+	for _, k := range keys {
+		kvs = append(kvs, log.KeyValue{Key: k, Value: value(fields[k])})
+	}
+	return kvs
+}
+
+// value converts a field value into a log value.
+func value(v interface{}) log.Value {
+	switch v := v.(type) {
+	case string:
+		return log.StringValue(v)
+	case bool:
+		return log.BoolValue(v)
+	case int:
+		return log.IntValue(v)
+	case int32:
+		return log.Int64Value(int64(v))
+	case int64:
+		return log.Int64Value(v)
+	case uint32:
+		return log.Int64Value(int64(v))
+	case float32:
+		return log.Float64Value(float64(v))
+	case float64:
+		return log.Float64Value(v)
+	case []byte:
+		return log.BytesValue(v)
+	case map[string]interface{}:
+		return log.MapValue(keyValues(v)...)
+	case fmt.Stringer:
+		return log.StringValue(v.String())
+	}
+	return log.StringValue(fmt.Sprint(v))
+}
@@ -0,0 +1,110 @@
+package otelerr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/logtest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rtmzk/errors"
+)
+
+func init() {
+	errors.Register(errors.NewCoder(907301, 503, "unavailable", "", errors.WithSeverity(errors.SeverityWarn)))
+}
+
+func TestExport(t *testing.T) {
+	rec := logtest.NewRecorder()
+	exp := NewExporter(rec)
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	exp.Export(ctx, errors.WithFields(errors.WithCode(907301, "db down"), "shard", 3))
+	exp.Export(ctx, nil)
+
+	scopes := rec.Result()
+	if len(scopes) != 1 || len(scopes[0].Records) != 1 {
+		t.Fatalf("got %+v, want one record", scopes)
+	}
+	r := scopes[0].Records[0]
+	if got := trace.SpanContextFromContext(r.Context()); got.TraceID() != sc.TraceID() {
+		t.Errorf("trace ID: got %v, want %v", got.TraceID(), sc.TraceID())
+	}
+	if r.Severity() != log.SeverityWarn {
+		t.Errorf("severity: got %v, want %v", r.Severity(), log.SeverityWarn)
+	}
+	if got := r.Body().AsString(); got != "db down" {
+		t.Errorf("body: got %q, want %q", got, "db down")
+	}
+
+	attrs := map[string]log.Value{}
+	r.WalkAttributes(func(kv log.KeyValue) bool {
+		attrs[kv.Key] = kv.Value
+		return true
+	})
+	if got := attrs["code"].AsInt64(); got != 907301 {
+		t.Errorf("code: got %d, want %d", got, 907301)
+	}
+	if got := attrs["http_status"].AsInt64(); got != 503 {
+		t.Errorf("http_status: got %d, want %d", got, 503)
+	}
+	fields := attrs["fields"].AsMap()
+	if len(fields) != 1 || fields[0].Key != "shard" || fields[0].Value.AsInt64() != 3 {
+		t.Errorf("fields: got %v, want shard=3", fields)
+	}
+	if attrs["exception.stacktrace"].AsString() == "" {
+		t.Errorf("exception.stacktrace: got none")
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	tests := []struct {
+		in   errors.Severity
+		want log.Severity
+	}{
+		{errors.SeverityDebug, log.SeverityDebug},
+		{errors.SeverityInfo, log.SeverityInfo},
+		{errors.SeverityError, log.SeverityError},
+		{errors.SeverityFatal, log.SeverityFatal},
+		{0, log.SeverityUndefined},
+	}
+
+	for _, tt := range tests {
+		if got := Severity(tt.in); got != tt.want {
+			t.Errorf("Severity(%v): got %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	rec := logtest.NewRecorder()
+	errors.AddReporter("otel", NewExporter(rec))
+	defer errors.RemoveReporter("otel")
+
+	errors.ServedCoder(errors.WithCode(907301, "db down"))
+
+	scopes := rec.Result()
+	if len(scopes) != 1 || len(scopes[0].Records) != 1 {
+		t.Fatalf("got %+v, want one record", scopes)
+	}
+	if got := scopes[0].Records[0].Body().AsString(); got != "db down" {
+		t.Errorf("body: got %q, want %q", got, "db down")
+	}
+}
+
+func TestNewRecordTimestamp(t *testing.T) {
+	fixed := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	errors.SetClock(errors.ClockFunc(func() time.Time { return fixed }))
+	defer errors.SetClock(nil)
+
+	r := NewRecord(errors.New("boom"))
+	if got := r.Timestamp(); !got.Equal(fixed) {
+		t.Errorf("Timestamp(): got %v, want the time of the errors Clock %v", got, fixed)
+	}
+}
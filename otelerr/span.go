@@ -0,0 +1,68 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelerr
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rtmzk/errors"
+)
+
+// RecordSpan records err on the span of ctx, so that handlers need a single
+// call instead of translating coded errors to span attributes by hand:
+//
+//	if err != nil {
+//		otelerr.RecordSpan(ctx, err)
+//		return err
+//	}
+//
+// An exception event is added, holding the code, the HTTP status and the
+// exception attributes of the OpenTelemetry semantic conventions, stack
+// trace included, and the error.type attribute of the span is set to the
+// code. Following the conventions of server spans, the span status is only
+// set to Error for errors mapped to a 5xx HTTP status, with the external
+// message of the error as description.
+// Nothing is recorded if err is nil or the span is not recording.
+func RecordSpan(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	coder := errors.ParseCoder(err)
+	attrs := []attribute.KeyValue{
+		attribute.Int("code", coder.Code()),
+		attribute.Int("http.status_code", coder.HTTPStatus()),
+		attribute.String("exception.type", fmt.Sprintf("%T", errors.Cause(err))),
+		attribute.String("exception.message", err.Error()),
+	}
+	if stack := stackTrace(err); stack != "" {
+		attrs = append(attrs, attribute.String("exception.stacktrace", stack))
+	}
+	span.AddEvent("exception", trace.WithAttributes(attrs...))
+	span.SetAttributes(attribute.String("error.type", strconv.Itoa(coder.Code())))
+	if coder.HTTPStatus() >= 500 {
+		span.SetStatus(codes.Error, coder.String())
+	}
+}
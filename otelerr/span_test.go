@@ -0,0 +1,88 @@
+package otelerr
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/rtmzk/errors"
+)
+
+func init() {
+	errors.Register(errors.NewCoder(909901, 404, "user not found", ""))
+}
+
+// recordingSpan records the calls made by RecordSpan.
+type recordingSpan struct {
+	noop.Span
+	events []string
+	attrs  map[attribute.Key]attribute.Value
+	status codes.Code
+	desc   string
+}
+
+func (s *recordingSpan) IsRecording() bool { return true }
+
+func (s *recordingSpan) AddEvent(name string, opts ...trace.EventOption) {
+	s.events = append(s.events, name)
+	cfg := trace.NewEventConfig(opts...)
+	for _, kv := range cfg.Attributes() {
+		s.attrs[kv.Key] = kv.Value
+	}
+}
+
+func (s *recordingSpan) SetAttributes(kvs ...attribute.KeyValue) {
+	for _, kv := range kvs {
+		s.attrs[kv.Key] = kv.Value
+	}
+}
+
+func (s *recordingSpan) SetStatus(code codes.Code, desc string) { s.status, s.desc = code, desc }
+
+func TestRecordSpan(t *testing.T) {
+	tests := []struct {
+		err        error
+		wantCode   int64
+		wantStatus codes.Code
+		wantDesc   string
+	}{
+		{errors.WithCode(907301, "db down"), 907301, codes.Error, "unavailable"},
+		{errors.WithCode(909901, "user 7 missing"), 909901, codes.Unset, ""},
+	}
+
+	for _, tt := range tests {
+		span := &recordingSpan{attrs: map[attribute.Key]attribute.Value{}}
+		RecordSpan(trace.ContextWithSpan(context.Background(), span), tt.err)
+
+		if len(span.events) != 1 || span.events[0] != "exception" {
+			t.Errorf("%v: got events %q, want one exception", tt.err, span.events)
+		}
+		if got := span.attrs["code"].AsInt64(); got != tt.wantCode {
+			t.Errorf("%v: got code %d, want %d", tt.err, got, tt.wantCode)
+		}
+		if got := span.attrs["error.type"].AsString(); got != errorType(tt.wantCode) {
+			t.Errorf("%v: got error.type %q, want %q", tt.err, got, errorType(tt.wantCode))
+		}
+		if got := span.attrs["exception.stacktrace"].AsString(); !strings.Contains(got, "TestRecordSpan") {
+			t.Errorf("%v: got stack %q, want the test function", tt.err, got)
+		}
+		if span.status != tt.wantStatus || span.desc != tt.wantDesc {
+			t.Errorf("%v: got status %v %q, want %v %q", tt.err, span.status, span.desc, tt.wantStatus, tt.wantDesc)
+		}
+	}
+}
+
+func errorType(code int64) string {
+	return attribute.Int64("", code).Value.Emit()
+}
+
+func TestRecordSpanNotRecording(t *testing.T) {
+	// The span of an empty context is not recording: nothing must panic.
+	RecordSpan(context.Background(), errors.WithCode(909901, "user 7 missing"))
+	RecordSpan(context.Background(), nil)
+}
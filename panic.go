@@ -0,0 +1,174 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+)
+
+// PanicCode is the code of the errors recovered from panics by FromPanic.
+// It is reserved next to the invariant code.
+const PanicCode = 4
+
+// panicCoder is the Coder of the errors of FromPanic.
+var panicCoder = NewCoder(PanicCode, http.StatusInternalServerError, "An internal error occurred",
+	"https://github.com/rtmzk/errors/README.md",
+	WithSeverity(SeverityFatal), WithClass(ClassBug), WithCondition(ConditionPanicked))
+
+// FromPanic returns the error standing for the value v recovered from a
+// panic, with PanicCode, whose Coder of severity fatal needs no
+// registration, and the stack of the panic: when called by a deferred
+// function of the panicking goroutine, the stack starts at the function
+// which panicked rather than at the deferred function. It is meant to be called by the
+// deferred functions of servers and middlewares:
+//
+//	defer func() {
+//		if err := errors.FromPanic(recover()); err != nil {
+//			errors.WriteError(w, r, err)
+//		}
+//	}()
+//
+// If v is an error, it is the cause of the returned error.
+// FromPanic returns nil if v is nil.
+func FromPanic(v interface{}) error {
+	if v == nil {
+		return nil
+	}
+	st, pc := panicStack()
+	return panicError(v, st, pc)
+}
+
+// panicError returns the error of FromPanic with the given stack, created
+// at pc.
+func panicError(v interface{}, st *stack, pc uintptr) error {
+	cause, _ := v.(error)
+	w := newCoded(PanicCode, fmt.Errorf("panic: %v", v), cause, nil, st, pc)
+	w.remote = panicCoder
+	return created(w)
+}
+
+// panicStack returns the stack of the goroutine from the function which
+// panicked, if it is panicking, or else from the caller of the function
+// calling panicStack, together with its innermost program counter. The
+// stack is nil when stacks are not captured, see SetCaptureStacks.
+func panicStack() (*stack, uintptr) {
+	pcs := make([]uintptr, stackDepth+32)
+	n := runtime.Callers(2, pcs)
+	pcs = pcs[:n]
+
+	// The function calling panicStack is skipped on its own unless the
+	// goroutine is panicking, in which case the deferred functions up to
+	// runtime.gopanic are skipped, as well as the functions of the runtime
+	// raising panics such as those of nil pointer dereferences.
+	start := 1
+	for i, pc := range pcs {
+		if runtimeFunc(pc) == "runtime.gopanic" {
+			start = i + 1
+			for start < len(pcs) && strings.HasPrefix(runtimeFunc(pcs[start]), "runtime.") {
+				start++
+			}
+			break
+		}
+	}
+	if start > len(pcs) {
+		start = len(pcs)
+	}
+	pcs = pcs[start:]
+	var pc uintptr
+	if len(pcs) > 0 {
+		pc = pcs[0]
+	}
+	if !captureStacksNow() {
+		return nil, pc
+	}
+	if len(pcs) > stackDepth {
+		pcs = pcs[:stackDepth]
+	}
+	st := stack(pcs)
+	return &st, pc
+}
+
+// runtimeFunc returns the name of the function of the program counter pc.
+func runtimeFunc(pc uintptr) string {
+	if fn := runtime.FuncForPC(pc - 1); fn != nil {
+		return fn.Name()
+	}
+	return ""
+}
+
+// Recover converts a panic of the function deferring it into an error with
+// the given code, stored in *errp, so that the panic is returned as an
+// error by the function:
+//
+//	func (w *Worker) process(job Job) (err error) {
+//		defer errors.Recover(&err, code.ErrJobCrashed)
+//		...
+//	}
+//
+// The error wraps the error of FromPanic, holding the panic value and the
+// stack at the time of the panic, so that IsPanic holds. Recover must be
+// deferred directly, not called by a deferred function, for recover to
+// stop the panic. Nothing is stored if the function did not panic.
+func Recover(errp *error, code int) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	st, pc := panicStack()
+	*errp = recoveredError(v, code, st, pc)
+}
+
+// recoveredError returns the error of Recover.
+func recoveredError(v interface{}, code int, st *stack, pc uintptr) error {
+	inner := panicError(v, st, pc)
+	if code == PanicCode {
+		return inner
+	}
+	return created(newCoded(code, fmt.Errorf("panic: %v", v), inner, nil, nil, pc))
+}
+
+// SafeGo runs fn in a new goroutine which recovers its panics, and returns
+// a channel receiving the error of FromPanic if fn panicked, or nil, then
+// closed. The error is also passed to the error hook when it is created,
+// see SetErrorHook, so that the panics of goroutines whose channel is not
+// read are still reported.
+func SafeGo(fn func()) <-chan error {
+	pc := callerPC()
+	ch := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		var err error
+		func() {
+			defer func() {
+				if v := recover(); v != nil {
+					st, pc := panicStack()
+					err = panicError(v, st, pc)
+				}
+			}()
+			fn()
+		}()
+		ch <- withHandoff(err, pc, "goroutine started")
+	}()
+	return ch
+}
+
+// IsPanic reports whether a coded layer of err's chain has a Coder standing
+// for ConditionPanicked.
+func IsPanic(err error) bool {
+	return hasCondition(err, ConditionPanicked)
+}
@@ -0,0 +1,107 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func recovered(v interface{}) (err error) {
+	defer func() { err = FromPanic(recover()) }()
+	panic(v)
+}
+
+func TestFromPanic(t *testing.T) {
+	tests := []struct {
+		v         interface{}
+		wantMsg   string
+		wantCause error
+	}{
+		{"nil map", "panic: nil map", nil},
+		{io.EOF, "panic: EOF", io.EOF},
+		{42, "panic: 42", nil},
+	}
+
+	for _, tt := range tests {
+		err := recovered(tt.v)
+		if got := err.Error(); got != tt.wantMsg {
+			t.Errorf("FromPanic(%v): got %q, want %q", tt.v, got, tt.wantMsg)
+		}
+		if !IsPanic(err) {
+			t.Errorf("IsPanic(FromPanic(%v)): got false, want true", tt.v)
+		}
+		if coder := ParseCoder(err); coder.Code() != PanicCode || coder.HTTPStatus() != 500 {
+			t.Errorf("FromPanic(%v): got coder %d/%d, want %d/500", tt.v, coder.Code(), coder.HTTPStatus(), PanicCode)
+		}
+		if tt.wantCause != nil && !Is(err, tt.wantCause) {
+			t.Errorf("FromPanic(%v): got %v, want it to wrap %v", tt.v, err, tt.wantCause)
+		}
+		if got := fmt.Sprintf("%+v", err); !strings.Contains(got, "errors.recovered") {
+			t.Errorf("FromPanic(%v): got stack %q, want the panicking function", tt.v, got)
+		}
+	}
+
+	if err := FromPanic(nil); err != nil {
+		t.Errorf("FromPanic(nil): got %v, want nil", err)
+	}
+	if IsPanic(io.EOF) {
+		t.Errorf("IsPanic(io.EOF): got true, want false")
+	}
+}
+
+func crash(m map[string]int) { m["x"] = 1 }
+
+func recoveredWith(code int, fn func()) (err error) {
+	defer Recover(&err, code)
+	fn()
+	return nil
+}
+
+func TestRecover(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(912001, 500, "Job crashed", ""))
+
+	err := recoveredWith(912001, func() { crash(nil) })
+	if coder := ParseCoder(err); coder.Code() != 912001 {
+		t.Errorf("Recover(): got code %d, want %d", coder.Code(), 912001)
+	}
+	if !IsPanic(err) {
+		t.Errorf("IsPanic(Recover()): got false, want true")
+	}
+	st := innermostStack(list(err))
+	if st == nil || len(*st) == 0 {
+		t.Fatalf("Recover(): got no stack")
+	}
+	if got := Frame((*st)[0]).name(); !strings.HasSuffix(got, "errors.crash") {
+		t.Errorf("Recover(): got top frame %s, want the panicking function errors.crash", got)
+	}
+
+	err = recoveredWith(PanicCode, func() { panic(io.EOF) })
+	if coder := ParseCoder(err); coder.Code() != PanicCode || !Is(err, io.EOF) {
+		t.Errorf("Recover(PanicCode): got %v, want a panic error wrapping %v", err, io.EOF)
+	}
+
+	if err := recoveredWith(912001, func() {}); err != nil {
+		t.Errorf("Recover() without panic: got %v, want nil", err)
+	}
+}
+
+func TestSafeGo(t *testing.T) {
+	err := <-SafeGo(func() { panic("boom") })
+	if err == nil || err.Error() != "panic: boom" || !IsPanic(err) {
+		t.Fatalf("SafeGo(): got %v, want a panic error", err)
+	}
+	if got := fmt.Sprintf("%+v", err); !strings.Contains(got, "TestSafeGo.func1") {
+		t.Errorf("SafeGo(): got stack %q, want the panicking function", got)
+	}
+	if got := Breadcrumbs(err); len(got) == 0 {
+		t.Errorf("Breadcrumbs(): got none, want the call site of SafeGo")
+	}
+
+	if err, ok := <-SafeGo(func() {}); err != nil || !ok {
+		t.Errorf("SafeGo() without panic: got %v, %v, want nil, true", err, ok)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// ContentTypeProblem is the media type of Problem Details documents.
+const ContentTypeProblem = "application/problem+json"
+
+// Problem is a Problem Details document describing an error, see RFC 7807.
+type Problem struct {
+	// Type is a URI identifying the kind of problem, the reference of the
+	// Coder, or "about:blank".
+	Type string `json:"type"`
+
+	// Title is the human facing message of the Coder.
+	Title string `json:"title"`
+
+	// Status is the HTTP status of the Coder.
+	Status int `json:"status"`
+
+	// Instance is a URI identifying the occurrence of the problem, the path
+	// of the request answered by ServeProblem.
+	Instance string `json:"instance,omitempty"`
+
+	// Code is the error code, an extension member.
+	Code int `json:"code,omitempty"`
+
+	// Slug is the string identifier of the code, an extension member, see
+	// WithSlug.
+	Slug string `json:"slug,omitempty"`
+
+	// Details are the details attached to the error, an extension member,
+	// see Details.
+	Details []map[string]interface{} `json:"details,omitempty"`
+}
+
+// ProblemDetails returns the Problem Details document describing err, built
+// from the HTTPStatus, String and Reference of its Coder.
+// The message of err itself is not exposed, since it usually carries
+// internal information. If err is nil, ProblemDetails returns the zero
+// Problem.
+func ProblemDetails(err error) Problem {
+	if err == nil {
+		return Problem{}
+	}
+	return newProblem(err, ParseCoder(err), ProfilePublic, "")
+}
+
+func newProblem(err error, coder Coder, p Profile, lang string) Problem {
+	problem := Problem{
+		Type:   coder.Reference(),
+		Title:  coderMessage(err, coder, lang),
+		Status: coder.HTTPStatus(),
+	}
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+	if p.RawCode {
+		problem.Code = coder.Code()
+		problem.Slug, _ = SlugOf(coder)
+	}
+	if details := Details(err); len(details) > 0 {
+		problem.Details = renderDetails(localizeDetails(details, lang))
+	}
+	return problem
+}
+
+// ServeProblem responds to r with err as an application/problem+json
+// document, see ProblemDetails. Like WriteError, it honors the profile and
+// the API version of the request context and the Accept-Language header, and omits the body
+// for HEAD requests and for statuses which forbid one. Panics raised while
+// rendering the document are recovered like by WriteError.
+// Nothing is written if err is nil.
+func ServeProblem(w http.ResponseWriter, r *http.Request, err error) {
+	if err == nil {
+		return
+	}
+	profile := ProfilePublic
+	ctx := context.Background()
+	if r != nil {
+		ctx = r.Context()
+		profile = ProfileFrom(ctx)
+		err = Enrich(ctx, err)
+	}
+	coder := ServedCoderContext(ctx, err)
+	status := coder.HTTPStatus()
+	if profile.RawCode {
+		setHeaders(w.Header(), err, coder)
+	}
+	SetWarnings(w.Header(), err)
+	if (r != nil && r.Method == http.MethodHead) || !bodyAllowed(status) {
+		w.WriteHeader(status)
+		return
+	}
+
+	byts, encErr, failed := safeRender(err, coder, func() ([]byte, error) {
+		problem := newProblem(err, coder, profile, requestLanguage(r))
+		if r != nil && r.URL != nil {
+			problem.Instance = r.URL.Path
+		}
+		return json.Marshal(problem)
+	})
+	if failed || encErr != nil {
+		byts = fallbackProblem(coder, profile)
+	} else {
+		byts = append(byts, '\n')
+	}
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_, _ = w.Write(byts)
+}
@@ -0,0 +1,66 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestProblemDetails(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(905801, 404, "not found", "https://example.com/905801"))
+	MustRegister(NewCoder(905802, 409, "", ""))
+
+	tests := []struct {
+		err  error
+		want Problem
+	}{
+		{WithCode(905801, "no user 42"), Problem{Type: "https://example.com/905801", Title: "not found", Status: 404, Code: 905801}},
+		{WithCode(905802, "version mismatch"), Problem{Type: "about:blank", Title: "Conflict", Status: 409, Code: 905802}},
+		{WithHelp(WithCode(905801, "no user"), HelpLink{Description: "docs", URL: "https://example.com"}), Problem{
+			Type: "https://example.com/905801", Title: "not found", Status: 404, Code: 905801,
+			Details: []map[string]interface{}{{"@type": "help", "links": []interface{}{map[string]interface{}{"description": "docs", "url": "https://example.com"}}}},
+		}},
+		{nil, Problem{}},
+	}
+
+	for i, tt := range tests {
+		if got := ProblemDetails(tt.err); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("test %d: got %+v, want %+v", i+1, got, tt.want)
+		}
+	}
+}
+
+func TestServeProblem(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(905803, 429, "slow down", "https://example.com/905803"))
+
+	rec := httptest.NewRecorder()
+	ServeProblem(rec, httptest.NewRequest(http.MethodGet, "/v1/users", nil), WithCode(905803, "rate limited"))
+	if rec.Code != 429 {
+		t.Errorf("status: got %d, want %d", rec.Code, 429)
+	}
+	if got := rec.Header().Get("Content-Type"); got != ContentTypeProblem {
+		t.Errorf("Content-Type: got %q, want %q", got, ContentTypeProblem)
+	}
+	var got Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode body %q: %v", rec.Body.String(), err)
+	}
+	want := Problem{Type: "https://example.com/905803", Title: "slow down", Status: 429, Instance: "/v1/users", Code: 905803}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("body: got %+v, want %+v", got, want)
+	}
+
+	rec = httptest.NewRecorder()
+	ServeProblem(rec, httptest.NewRequest(http.MethodHead, "/v1/users", nil), WithCode(905803, "rate limited"))
+	if rec.Code != 429 || rec.Body.Len() != 0 {
+		t.Errorf("HEAD: got status %d and body %q, want 429 and no body", rec.Code, rec.Body.String())
+	}
+}
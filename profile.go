@@ -0,0 +1,92 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import "context"
+
+// Profile selects which parts of an error an audience sees when the error
+// is serialized by WriteError. The human facing message and the reference
+// of the Coder are always visible.
+type Profile struct {
+	// Name identifies the profile, e.g. in logs.
+	Name string
+
+	// RawCode exposes the error code and its slug, see WithSlug, in the
+	// body, and the code in the X-Error-Code header.
+	RawCode bool
+
+	// Fields exposes the fields of the error, see Fields.
+	Fields bool
+
+	// Chain exposes the message of every layer of the chain.
+	Chain bool
+
+	// Stack exposes the innermost stack trace of the chain.
+	Stack bool
+}
+
+// Predefined profiles.
+var (
+	// ProfilePublic exposes the code only. It is the default profile.
+	ProfilePublic = Profile{Name: "public", RawCode: true}
+
+	// ProfilePartner additionally exposes the fields.
+	ProfilePartner = Profile{Name: "partner", RawCode: true, Fields: true}
+
+	// ProfileInternal exposes everything.
+	ProfileInternal = Profile{Name: "internal", RawCode: true, Fields: true, Chain: true, Stack: true}
+)
+
+type profileKey struct{}
+
+// WithProfile returns a copy of ctx carrying the profile applied by
+// WriteError, typically set by the authentication middleware according to
+// the authenticated principal.
+func WithProfile(ctx context.Context, p Profile) context.Context {
+	return context.WithValue(ctx, profileKey{}, p)
+}
+
+// ProfileFrom returns the profile set on ctx by WithProfile, or
+// ProfilePublic.
+func ProfileFrom(ctx context.Context) Profile {
+	if p, ok := ctx.Value(profileKey{}).(Profile); ok {
+		return p
+	}
+	return ProfilePublic
+}
+
+// newResponseBody returns the body describing err, of the given Coder, to
+// the audience of p, in the given language.
+func newResponseBody(err error, coder Coder, p Profile, lang string) responseBody {
+	body := responseBody{
+		Message:   coderMessage(err, coder, lang),
+		Reference: coder.Reference(),
+	}
+	if p.RawCode {
+		body.Code = coder.Code()
+		body.Slug, _ = SlugOf(coder)
+	}
+	if p.Fields {
+		body.Fields = Fields(err)
+	}
+
+	if p.Chain {
+		body.Chain = chainMessages(err)
+	}
+	if p.Stack {
+		body.Stack = stackLines(err)
+	}
+	return body
+}
@@ -0,0 +1,56 @@
+package errors
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteErrorProfiles(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(904101, 400, "bad input", ""))
+	err := WrapArgs(io.EOF, 904101, "parse body", Arg("size", 12))
+
+	tests := []struct {
+		profile Profile
+		code    bool
+		fields  bool
+		chain   bool
+		stack   bool
+	}{
+		{Profile{Name: "anonymous"}, false, false, false, false},
+		{ProfilePublic, true, false, false, false},
+		{ProfilePartner, true, true, false, false},
+		{ProfileInternal, true, true, true, true},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r = r.WithContext(WithProfile(r.Context(), tt.profile))
+		rec := httptest.NewRecorder()
+		WriteError(rec, r, err)
+
+		var body responseBody
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: %v", tt.profile.Name, err)
+		}
+		if body.Message != "bad input" {
+			t.Errorf("%s: got message %q, want %q", tt.profile.Name, body.Message, "bad input")
+		}
+		if got := body.Code == 904101 && rec.Header().Get(HeaderErrorCode) == "904101"; got != tt.code {
+			t.Errorf("%s: got code exposed %v, want %v", tt.profile.Name, got, tt.code)
+		}
+		if got := body.Fields["size"] != nil; got != tt.fields {
+			t.Errorf("%s: got fields exposed %v, want %v", tt.profile.Name, got, tt.fields)
+		}
+		if got := len(body.Chain) == 2 && body.Chain[1] == "EOF"; got != tt.chain {
+			t.Errorf("%s: got chain %v, want exposed %v", tt.profile.Name, body.Chain, tt.chain)
+		}
+		if got := len(body.Stack) > 0; got != tt.stack {
+			t.Errorf("%s: got stack exposed %v, want %v", tt.profile.Name, got, tt.stack)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// referenceTemplate is the template of the references of the coders
+// without one, see SetReferenceTemplate.
+var referenceTemplate atomic.Value // string
+
+// SetReferenceTemplate sets the template of the reference of the coders
+// built by NewCoder with an empty reference, such as
+// "https://errors.example.com/{code}". The placeholders {code} and {slug}
+// are replaced with the code and the slug of the coder, see WithSlug, when
+// the reference is looked up, so that the template applies to the coders
+// registered before and after it is set. An empty template, the default,
+// leaves such references empty.
+func SetReferenceTemplate(tmpl string) {
+	referenceTemplate.Store(tmpl)
+}
+
+func referenceTemplateNow() string {
+	tmpl, _ := referenceTemplate.Load().(string)
+	return tmpl
+}
+
+// expandReference returns the reference of the code and slug given by the
+// reference template, or an empty string if there is no template.
+func expandReference(code int, slug string) string {
+	tmpl := referenceTemplateNow()
+	if tmpl == "" {
+		return ""
+	}
+	return strings.NewReplacer("{code}", strconv.Itoa(code), "{slug}", slug).Replace(tmpl)
+}
@@ -0,0 +1,47 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func init() {
+	Register(NewCoder(912901, http.StatusNotFound, "user not found", ""))
+	Register(NewCoder(912902, http.StatusNotFound, "order not found", "", WithSlug("reftemplate.order_not_found")))
+	Register(NewCoder(912903, http.StatusConflict, "conflict", "https://example.com/conflict"))
+}
+
+func TestReferenceTemplate(t *testing.T) {
+	restore := NewConfig(UseReferenceTemplate("https://errors.example.com/{code}#{slug}")).Apply()
+	defer restore()
+
+	tests := []struct {
+		code int
+		want string
+	}{
+		{912901, "https://errors.example.com/912901#"},
+		{912902, "https://errors.example.com/912902#reftemplate.order_not_found"},
+		{912903, "https://example.com/conflict"},
+	}
+
+	for _, tt := range tests {
+		err := WithCode(tt.code, "failed")
+		if got := ParseCoder(err).Reference(); got != tt.want {
+			t.Errorf("code %d: Reference(): got %q, want %q", tt.code, got, tt.want)
+		}
+
+		byts, _ := ToJSON(err)
+		var decoded jsonError
+		_ = json.Unmarshal(byts, &decoded)
+		if decoded.Reference != tt.want {
+			t.Errorf("code %d: ToJSON reference: got %q, want %q", tt.code, decoded.Reference, tt.want)
+		}
+	}
+}
+
+func TestReferenceTemplateUnset(t *testing.T) {
+	if got := ParseCoder(WithCode(912901, "failed")).Reference(); got != "" {
+		t.Errorf("Reference(): got %q, want an empty reference", got)
+	}
+}
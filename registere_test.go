@@ -0,0 +1,86 @@
+package errors
+
+import "testing"
+
+func TestRegisterE(t *testing.T) {
+	RegisterRange(907690, 907699, "example.com/other")
+
+	if err := RegisterE(NewCoder(907601, 404, "not found", "")); err != nil {
+		t.Fatalf("RegisterE(907601): got %v, want nil", err)
+	}
+	if !IsRegistered(907601) {
+		t.Errorf("IsRegistered(907601): got false, want true")
+	}
+
+	tests := []struct {
+		coder Coder
+		want  error
+	}{
+		{NewCoder(0, 500, "unknown", ""), ErrCodeReserved},
+		{NewCoder(907601, 404, "again", ""), ErrCodeExists},
+		{NewCoder(907691, 400, "owned", ""), ErrCodeOwned},
+		{NewCoder(907603, 600, "bad status", ""), ErrInvalidStatus},
+		{NewCoder(907604, 400, "", ""), ErrEmptyMessage},
+	}
+
+	for _, tt := range tests {
+		err := RegisterE(tt.coder)
+		var rerr *RegistrationError
+		if !As(err, &rerr) {
+			t.Errorf("RegisterE(%d): got %v, want a *RegistrationError", tt.coder.Code(), err)
+			continue
+		}
+		if rerr.Code != tt.coder.Code() {
+			t.Errorf("RegisterE(%d): got code %d, want %d", tt.coder.Code(), rerr.Code, tt.coder.Code())
+		}
+		if !Is(err, tt.want) {
+			t.Errorf("RegisterE(%d): got %v, want %v", tt.coder.Code(), err, tt.want)
+		}
+	}
+
+	if got := ParseCoder(WithCode(907601, "x")).String(); got != "not found" {
+		t.Errorf("coder of 907601 after rejected registration: got %q, want %q", got, "not found")
+	}
+	for _, code := range []int{907603, 907604} {
+		if IsRegistered(code) {
+			t.Errorf("IsRegistered(%d): got true, want false", code)
+		}
+	}
+}
+
+func TestRegisterKeepsPanicking(t *testing.T) {
+	MustRegister(NewCoder(907610, 400, "first", ""))
+	if msg := panicMessage(func() { MustRegister(NewCoder(907610, 400, "second", "")) }); msg == "" {
+		t.Errorf("MustRegister of an existing code: got no panic, want a panic")
+	}
+	if msg := panicMessage(func() { Register(NewCoder(0, 500, "unknown", "")) }); msg == "" {
+		t.Errorf("Register of code 0: got no panic, want a panic")
+	}
+}
+
+func TestRegisterPanicMessages(t *testing.T) {
+	MustRegister(NewCoder(907611, 404, "not found", ""))
+
+	tests := []struct {
+		name string
+		fn   func()
+		want string
+	}{
+		{"Register(0)", func() { Register(NewCoder(0, 500, "zero", "")) },
+			"code `0` is reserved by `github.com/rtmzk/errors` as unknownCode error code"},
+		{"MustRegister(0)", func() { MustRegister(NewCoder(0, 500, "zero", "")) },
+			"code '0' is reserved by 'github.com/rtmzk/errors' as ErrUnknown error code"},
+		{"MustRegister(existing)", func() { MustRegister(NewCoder(907611, 404, "again", "")) },
+			"code: 907611 already exist"},
+	}
+	for _, tt := range tests {
+		got := func() (msg interface{}) {
+			defer func() { msg = recover() }()
+			tt.fn()
+			return nil
+		}()
+		if got != tt.want {
+			t.Errorf("%s: got panic %v, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"sort"
+	"sync"
+)
+
+// Registry is a set of coders. The package-level functions, such as
+// Register, ParseCoder and IsCode, use the package registry, see
+// DefaultRegistry. Libraries keep their codes in a Registry of their own,
+// created with NewRegistry, so that the codes of two libraries never
+// replace each other in the package registry:
+//
+//	var registry = errors.NewRegistry()
+//
+//	func init() {
+//		registry.MustRegister(errors.NewCoder(1001, http.StatusNotFound, "bucket not found", ""))
+//	}
+//
+//	func open(name string) error {
+//		return registry.WithCode(1001, "open %s", name)
+//	}
+//
+// The errors created by the WithCode and Wrapc methods of a Registry
+// record it, so that the package-level ParseCoder resolves their code in
+// that registry, whatever the package registry holds.
+// A Registry is safe for concurrent use.
+type Registry struct {
+	mu      sync.RWMutex
+	backend RegistryBackend
+	unknown Coder
+
+	// usage counts the errors created by the methods of r, see CodeUsage.
+	usage sync.Map // map[int]*uint64
+}
+
+// defaultRegistry stands for the package registry, whose state is kept in
+// the package variables, see SetRegistryBackend.
+var defaultRegistry = &Registry{}
+
+// DefaultRegistry returns the package registry, used by the package-level
+// functions.
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// NewRegistry returns an empty Registry, independent of the package
+// registry.
+func NewRegistry() *Registry {
+	return &Registry{backend: MapBackend()}
+}
+
+// Register registers coder in r like Register, replacing the Coder of the
+// same code. It panics if the code is reserved.
+func (r *Registry) Register(coder Coder) {
+	if r == defaultRegistry {
+		if err := registerAt(coder, callerPC(), false, false); err != nil {
+			panic(err.panicMessage(false))
+		}
+		return
+	}
+	if err := r.register(coder, false, false); err != nil {
+		panic(err.panicMessage(false))
+	}
+}
+
+// MustRegister registers coder in r like MustRegister. It panics if the
+// code is reserved or already registered in r.
+func (r *Registry) MustRegister(coder Coder) {
+	if r == defaultRegistry {
+		if err := registerAt(coder, callerPC(), true, false); err != nil {
+			panic(err.panicMessage(true))
+		}
+		return
+	}
+	if err := r.register(coder, true, false); err != nil {
+		panic(err.panicMessage(true))
+	}
+}
+
+// RegisterE registers coder in r like RegisterE, returning a
+// *RegistrationError instead of panicking.
+func (r *Registry) RegisterE(coder Coder) error {
+	if r == defaultRegistry {
+		if err := registerAt(coder, callerPC(), true, true); err != nil {
+			return err
+		}
+		return nil
+	}
+	if err := r.register(coder, true, true); err != nil {
+		return err
+	}
+	return nil
+}
+
+// register registers coder in the private registry r, see registerAt.
+func (r *Registry) register(coder Coder, unique, validate bool) *RegistrationError {
+	if err := checkCoder(coder, validate); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.backend.get(coder.Code()); ok && unique {
+		return &RegistrationError{Code: coder.Code(), Err: ErrCodeExists}
+	}
+	r.backend.set(coder)
+	return nil
+}
+
+// Lookup returns the Coder registered in r for code.
+func (r *Registry) Lookup(code int) (Coder, bool) {
+	if r == defaultRegistry {
+		return GetCoder(code)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.backend.get(code)
+}
+
+// Codes returns the coders registered in r sorted by code, leaving out the
+// unknown coder.
+func (r *Registry) Codes() []Coder {
+	if r == defaultRegistry {
+		return ListCodes()
+	}
+	r.mu.RLock()
+	ret := r.backend.list()
+	r.mu.RUnlock()
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Code() < ret[j].Code() })
+	return ret
+}
+
+// ParseCoder returns the Coder of err like ParseCoder. The errors created
+// by r are resolved through r, those of the package registry or of another
+// Registry through their own, never through r. The unknown coder is
+// replaced by the one of r, see the SetUnknownCoder method.
+func (r *Registry) ParseCoder(err error) Coder {
+	if r == defaultRegistry || err == nil {
+		return ParseCoder(err)
+	}
+	coder := ParseCoder(err)
+	if coder.Code() == unknownCoder.Code() {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		if r.unknown != nil {
+			return r.unknown
+		}
+	}
+	return coder
+}
+
+// SetUnknownCoder sets the Coder which the ParseCoder method of r returns
+// for the errors which the package-level ParseCoder resolves to the
+// unknown coder, so that a library serves its own fallback. A nil coder,
+// the default, returns the unknown coder of the package. On the package
+// registry, it is SetUnknownCoder.
+func (r *Registry) SetUnknownCoder(coder Coder) {
+	if r == defaultRegistry {
+		SetUnknownCoder(coder)
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.unknown = coder
+}
+
+// IsCode reports whether any error in err's chain was created by r with
+// the given code, see IsCode. The errors of the package registry and of
+// other registries never match.
+func (r *Registry) IsCode(err error, code int) bool {
+	if r == defaultRegistry {
+		return IsCode(err, code)
+	}
+	return walk(err, func(e error) bool {
+		w, ok := e.(*withCode)
+		return ok && w.registry == r && w.code == code
+	})
+}
+
+// WithCode returns an error with the given code like WithCode, which
+// carries the Coder registered in r for code.
+func (r *Registry) WithCode(code int, format string, args ...interface{}) error {
+	st := codeCallers(code)
+	return r.created(newCoded(code, errorf(format, args...), nil, nil, st, stackPC(st)))
+}
+
+// CodeUsage returns how many coded errors with the given code have been
+// created by the WithCode and Wrapc methods of r, like CodeUsage does for
+// the package registry. The errors created by a private registry are not
+// counted by the package-level CodeUsage.
+func (r *Registry) CodeUsage(code int) uint64 {
+	if r == defaultRegistry {
+		return CodeUsage(code)
+	}
+	return loadUsage(&r.usage, code)
+}
+
+// Wrapc returns an error annotating err with the given code like Wrapc,
+// which carries the Coder registered in r for code.
+// If err is nil, Wrapc returns nil.
+func (r *Registry) Wrapc(err error, code int, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	var st *stack
+	if !hasStack(err) {
+		st = codeCallers(code)
+	}
+	return r.created(newCoded(code, errorf(format, args...), err, pathFields(err), st, stackPC(st)))
+}
+
+// created completes the coded error w created by a method of r, which
+// records r so that its code is resolved in r only.
+func (r *Registry) created(w *withCode) error {
+	if r != defaultRegistry {
+		w.registry = r
+		w.remote, _ = r.Lookup(w.code)
+	}
+	return created(w)
+}
+
+// registryCoder returns the Coder of w in the private registry which
+// created it, or else the one it was created with.
+func (w *withCode) registryCoder() (Coder, bool) {
+	if coder, ok := w.registry.Lookup(w.code); ok {
+		return coder, true
+	}
+	return w.remote, w.remote != nil
+}
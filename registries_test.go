@@ -0,0 +1,140 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	a, b := NewRegistry(), NewRegistry()
+	a.MustRegister(NewCoder(913101, http.StatusNotFound, "bucket not found", ""))
+	b.MustRegister(NewCoder(913101, http.StatusConflict, "job exists", ""))
+
+	errA := a.WithCode(913101, "open %s", "logs")
+	errB := b.Wrapc(stderrors.New("duplicate"), 913101, "submit")
+
+	tests := []struct {
+		registry *Registry
+		err      error
+		status   int
+	}{
+		{a, errA, http.StatusNotFound},
+		{b, errB, http.StatusConflict},
+		{a, errB, http.StatusConflict},
+		{DefaultRegistry(), errA, http.StatusNotFound},
+		{DefaultRegistry(), errB, http.StatusConflict},
+	}
+
+	for i, tt := range tests {
+		if got := tt.registry.ParseCoder(tt.err).HTTPStatus(); got != tt.status {
+			t.Errorf("test %d: ParseCoder(): got status %d, want %d", i+1, got, tt.status)
+		}
+	}
+
+	if IsRegistered(913101) {
+		t.Errorf("IsRegistered(913101): got true, want the package registry untouched")
+	}
+	if !a.IsCode(errA, 913101) || a.IsCode(errA, 913102) {
+		t.Errorf("IsCode(): got %v and %v, want true and false", a.IsCode(errA, 913101), a.IsCode(errA, 913102))
+	}
+	if a.IsCode(errB, 913101) || a.IsCode(WithCode(913101, "open"), 913101) {
+		t.Errorf("IsCode(): got true, want the errors of other registries unmatched")
+	}
+	if got := len(a.Codes()); got != 1 {
+		t.Errorf("Codes(): got %d coders, want 1", got)
+	}
+}
+
+func TestRegistryScope(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	r := NewRegistry()
+	r.MustRegister(NewCoder(915101, http.StatusConflict, "job exists", ""))
+	MustRegister(NewCoder(915101, http.StatusNotFound, "bucket not found", ""))
+
+	coded := r.WithCode(915101, "submit")
+	err := Wrap(coded, "retry")
+	if got := ParseCoder(err).HTTPStatus(); got != http.StatusConflict {
+		t.Errorf("ParseCoder(): got status %d, want %d", got, http.StatusConflict)
+	}
+	if got := r.ParseCoder(WithCode(915101, "open")).HTTPStatus(); got != http.StatusNotFound {
+		t.Errorf("Registry.ParseCoder(): got status %d, want %d", got, http.StatusNotFound)
+	}
+	if got := coded.(interface{ Coder() Coder }).Coder().HTTPStatus(); got != http.StatusConflict {
+		t.Errorf("Coder(): got status %d, want %d", got, http.StatusConflict)
+	}
+	if !r.IsCode(err, 915101) {
+		t.Errorf("IsCode(): got false, want true")
+	}
+}
+
+func TestRegistryStrictAndUsage(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	r := NewRegistry()
+	r.MustRegister(NewCoder(915102, http.StatusConflict, "job exists", ""))
+	MustRegister(NewCoder(915103, http.StatusNotFound, "bucket not found", ""))
+	SetStrictMode(StrictPanic)
+
+	func() {
+		defer func() {
+			if v := recover(); v == nil || !strings.Contains(fmt.Sprint(v), "915103") {
+				t.Errorf("WithCode(registered globally only): got %v, want a strict mode panic", v)
+			}
+		}()
+		r.WithCode(915103, "open")
+	}()
+
+	before := CodeUsage(915102)
+	r.WithCode(915102, "submit")
+	if got := r.CodeUsage(915102); got != 1 {
+		t.Errorf("Registry.CodeUsage(): got %d, want 1", got)
+	}
+	if got := CodeUsage(915102); got != before {
+		t.Errorf("CodeUsage(): got %d, want the private registry errors not counted", got)
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	r := NewRegistry()
+	r.Register(NewCoder(913111, http.StatusBadRequest, "invalid", ""))
+	r.Register(NewCoder(913111, http.StatusBadRequest, "invalid input", ""))
+	if coder, _ := r.Lookup(913111); coder.String() != "invalid input" {
+		t.Errorf("Register(): got %q, want the replacing coder", coder.String())
+	}
+
+	tests := []struct {
+		coder Coder
+		want  error
+	}{
+		{NewCoder(913111, http.StatusBadRequest, "again", ""), ErrCodeExists},
+		{NewCoder(0, http.StatusBadRequest, "zero", ""), ErrCodeReserved},
+		{NewCoder(913112, 1000, "status", ""), ErrInvalidStatus},
+		{NewCoder(913113, http.StatusBadRequest, "", ""), ErrEmptyMessage},
+		{NewCoder(913114, http.StatusBadRequest, "ok", ""), nil},
+	}
+
+	for i, tt := range tests {
+		if err := r.RegisterE(tt.coder); !stderrors.Is(err, tt.want) {
+			t.Errorf("test %d: RegisterE(): got %v, want %v", i+1, err, tt.want)
+		}
+	}
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	DefaultRegistry().MustRegister(NewCoder(913121, http.StatusGone, "gone", ""))
+	if !IsRegistered(913121) {
+		t.Errorf("IsRegistered(): got false, want the coder in the package registry")
+	}
+	if got := ParseCoder(DefaultRegistry().WithCode(913121, "gone")).HTTPStatus(); got != http.StatusGone {
+		t.Errorf("ParseCoder(): got %d, want %d", got, http.StatusGone)
+	}
+}
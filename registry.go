@@ -0,0 +1,169 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// RegistryBackend is the data structure holding the registered coders.
+// It is selected with SetRegistryBackend.
+type RegistryBackend interface {
+	get(code int) (Coder, bool)
+	set(coder Coder)
+	list() []Coder
+}
+
+// MapBackend returns a registry backend storing coders in a map.
+// It is the default backend and suits sparse code spaces.
+func MapBackend() RegistryBackend {
+	return mapBackend{}
+}
+
+// DenseBackend returns a registry backend indexing the codes in [min, max)
+// directly in lazily allocated slice shards, which avoids hashing on every
+// lookup. Codes outside of the range are kept in a map.
+// It suits dense code spaces, such as 6-digit codes allocated in sequence.
+// DenseBackend panics if max is not greater than min.
+func DenseBackend(min, max int) RegistryBackend {
+	if max <= min {
+		panic(fmt.Sprintf("invalid dense registry range [%d, %d)", min, max))
+	}
+	return &denseBackend{
+		min:      min,
+		max:      max,
+		shards:   make([][]Coder, (max-min+denseShardSize-1)/denseShardSize),
+		overflow: mapBackend{},
+	}
+}
+
+// SetRegistryBackend replaces the registry backend.
+// Coders registered so far are copied into the new backend.
+// It is meant to be called once at program start, before the registry is
+// queried concurrently.
+func SetRegistryBackend(backend RegistryBackend) {
+	codeMux.Lock()
+	defer codeMux.Unlock()
+
+	if Frozen() {
+		panic("errors: set registry backend: " + ErrFrozen.Error())
+	}
+	for _, coder := range codes.list() {
+		backend.set(coder)
+	}
+	codes = backend
+}
+
+// frozen is 1 once the registry is frozen, see Freeze.
+var frozen int32
+
+// Freeze makes the registry read-only, e.g. at the end of main's setup:
+// the lazy catalogs are loaded, see RegisterCatalogLazily, and the coders
+// are then looked up without locking. Registering a code afterwards fails
+// with ErrFrozen: RegisterE returns it, the other functions panic.
+// Before Freeze, the registry is guarded by a read-write lock, so that
+// registering codes after init is safe but lookups contend on the lock.
+func Freeze() {
+	ensureCatalogs()
+	codeMux.Lock()
+	defer codeMux.Unlock()
+	atomic.StoreInt32(&frozen, 1)
+}
+
+// Frozen reports whether the registry is frozen, see Freeze.
+func Frozen() bool { return atomic.LoadInt32(&frozen) == 1 }
+
+// lookupCoder returns the Coder registered for code, or for its
+// replacement if it is deprecated, see RegisterAlias, locking the registry
+// unless it is frozen.
+func lookupCoder(code int) (Coder, bool) {
+	code = canonicalCode(code)
+	if Frozen() {
+		return codes.get(code)
+	}
+	codeMux.RLock()
+	defer codeMux.RUnlock()
+	return codes.get(code)
+}
+
+type mapBackend map[int]Coder
+
+func (m mapBackend) get(code int) (Coder, bool) {
+	coder, ok := m[code]
+	return coder, ok
+}
+
+func (m mapBackend) set(coder Coder) { m[coder.Code()] = coder }
+
+func (m mapBackend) list() []Coder {
+	ret := make([]Coder, 0, len(m))
+	for _, coder := range m {
+		ret = append(ret, coder)
+	}
+	return ret
+}
+
+const (
+	denseShardBits = 10
+	denseShardSize = 1 << denseShardBits
+	denseShardMask = denseShardSize - 1
+)
+
+type denseBackend struct {
+	min, max int
+	shards   [][]Coder
+	overflow mapBackend
+}
+
+func (d *denseBackend) get(code int) (Coder, bool) {
+	if code < d.min || code >= d.max {
+		return d.overflow.get(code)
+	}
+	i := code - d.min
+	shard := d.shards[i>>denseShardBits]
+	if shard == nil {
+		return nil, false
+	}
+	coder := shard[i&denseShardMask]
+	return coder, coder != nil
+}
+
+func (d *denseBackend) set(coder Coder) {
+	code := coder.Code()
+	if code < d.min || code >= d.max {
+		d.overflow.set(coder)
+		return
+	}
+	i := code - d.min
+	shard := d.shards[i>>denseShardBits]
+	if shard == nil {
+		shard = make([]Coder, denseShardSize)
+		d.shards[i>>denseShardBits] = shard
+	}
+	shard[i&denseShardMask] = coder
+}
+
+func (d *denseBackend) list() []Coder {
+	ret := d.overflow.list()
+	for _, shard := range d.shards {
+		for _, coder := range shard {
+			if coder != nil {
+				ret = append(ret, coder)
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,125 @@
+package errors
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDenseBackend(t *testing.T) {
+	d := DenseBackend(100000, 103000)
+	for _, c := range []int{99999, 100000, 101500, 102999, 103000} {
+		d.set(defaultCoder{C: c, HTTP: 400})
+	}
+
+	tests := []struct {
+		code int
+		want bool
+	}{
+		{99999, true},
+		{100000, true},
+		{100001, false},
+		{101500, true},
+		{102999, true},
+		{103000, true},
+		{500000, false},
+	}
+	for _, tt := range tests {
+		coder, ok := d.get(tt.code)
+		if ok != tt.want || (ok && coder.Code() != tt.code) {
+			t.Errorf("get(%d): got (%v, %v), want %v", tt.code, coder, ok, tt.want)
+		}
+	}
+
+	var got []int
+	for _, coder := range d.list() {
+		got = append(got, coder.Code())
+	}
+	sort.Ints(got)
+	want := []int{99999, 100000, 101500, 102999, 103000}
+	if len(got) != len(want) {
+		t.Fatalf("list(): got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("list(): got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSetRegistryBackend(t *testing.T) {
+	saved := codes
+	defer func() { codes = saved }()
+
+	Register(defaultCoder{C: 900601, HTTP: 404})
+	SetRegistryBackend(DenseBackend(900000, 901000))
+
+	if _, ok := codes.(*denseBackend); !ok {
+		t.Fatalf("SetRegistryBackend: got %T, want *denseBackend", codes)
+	}
+	if got := ParseCoder(WithCode(900601, "kept")).HTTPStatus(); got != 404 {
+		t.Errorf("ParseCoder after SetRegistryBackend: got %d, want %d", got, 404)
+	}
+	if got := ParseCoder(New("uncoded")).Code(); got != unknownCoder.Code() {
+		t.Errorf("ParseCoder(unknown) after SetRegistryBackend: got %d, want %d", got, unknownCoder.Code())
+	}
+}
+
+func TestRegistryConcurrent(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	err := WithCode(910800, "late")
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			Register(NewCoder(910800+i, 500, "late", ""))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = ParseCoder(err)
+			_ = IsRegistered(910850)
+		}
+	}()
+	wg.Wait()
+
+	if got := ParseCoder(err).Code(); got != 910800 {
+		t.Errorf("ParseCoder: got %d, want %d", got, 910800)
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	Register(NewCoder(910801, 404, "not found", ""))
+	Freeze()
+	if !Frozen() {
+		t.Fatalf("Frozen: got false, want true")
+	}
+
+	if got := ParseCoder(WithCode(910801, "lookup")).Code(); got != 910801 {
+		t.Errorf("ParseCoder after Freeze: got %d, want %d", got, 910801)
+	}
+	if err := RegisterE(NewCoder(910802, 500, "late", "")); !Is(err, ErrFrozen) {
+		t.Errorf("RegisterE after Freeze: got %v, want %v", err, ErrFrozen)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r == nil || !strings.Contains(r.(string), "frozen") {
+				t.Errorf("Register after Freeze: got %v, want a panic", r)
+			}
+		}()
+		Register(NewCoder(910802, 500, "late", ""))
+	}()
+
+	restore()
+	if Frozen() {
+		t.Errorf("Frozen after restore: got true, want false")
+	}
+}
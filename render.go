@@ -0,0 +1,95 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+)
+
+// RenderFailure describes a panic recovered while an error was rendered,
+// e.g. by a custom MarshalJSON of a field value or detail which
+// dereferences a nil map.
+type RenderFailure struct {
+	// Err is the error which was rendered.
+	Err error
+
+	// Code is the code of the Coder of Err.
+	Code int
+
+	// Panic is the value recovered.
+	Panic interface{}
+}
+
+var renderHook atomic.Value // renderHookFunc
+
+type renderHookFunc func(RenderFailure)
+
+// SetRenderFailureHook sets a function called every time rendering an
+// error panics. WriteError, WriteResponse, ServeProblem and ToJSON recover
+// such panics and fall back to a minimal body holding the code of the
+// error, so that a faulty value attached to an error never crashes the
+// responder. The hook is called synchronously, so it must be fast and safe
+// for concurrent use. A nil fn removes the hook.
+func SetRenderFailureHook(fn func(RenderFailure)) {
+	renderHook.Store(renderHookFunc(fn))
+}
+
+func renderHookNow() func(RenderFailure) {
+	hook, _ := renderHook.Load().(renderHookFunc)
+	return hook
+}
+
+// safeRender returns the result of render, or failed set to true if it
+// panicked, in which case the failure is given to the hook.
+func safeRender(err error, coder Coder, render func() ([]byte, error)) (byts []byte, renderErr error, failed bool) {
+	defer func() {
+		if v := recover(); v != nil {
+			byts, renderErr, failed = nil, nil, true
+			if hook := renderHookNow(); hook != nil {
+				hook(RenderFailure{Err: err, Code: coder.Code(), Panic: v})
+			}
+		}
+	}()
+	byts, renderErr = render()
+	return byts, renderErr, false
+}
+
+// fallbackBody returns the JSON body written in place of the response of
+// err when rendering it panicked: the code, if exposed by p, and the
+// status text of the coder.
+func fallbackBody(coder Coder, p Profile) []byte {
+	body := responseBody{Message: http.StatusText(coder.HTTPStatus())}
+	if p.RawCode {
+		body.Code = coder.Code()
+	}
+	byts, _ := json.Marshal(body)
+	return append(byts, '\n')
+}
+
+// fallbackProblem is the Problem Details counterpart of fallbackBody.
+func fallbackProblem(coder Coder, p Profile) []byte {
+	problem := Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(coder.HTTPStatus()),
+		Status: coder.HTTPStatus(),
+	}
+	if p.RawCode {
+		problem.Code = coder.Code()
+	}
+	byts, _ := json.Marshal(problem)
+	return append(byts, '\n')
+}
@@ -0,0 +1,81 @@
+package errors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func init() {
+	Register(NewCoder(910401, 502, "upstream failed", ""))
+}
+
+// faultyValue panics when encoded, like a user type dereferencing a nil
+// map.
+type faultyValue struct {
+	m map[string]*int
+}
+
+func (v faultyValue) MarshalJSON() ([]byte, error) {
+	return []byte(string(rune(*v.m["x"]))), nil
+}
+
+// faultyDetail panics when encoded.
+type faultyDetail struct{ faultyValue }
+
+func (faultyDetail) DetailType() string { return "faulty" }
+
+func TestRenderFailure(t *testing.T) {
+	var failures []RenderFailure
+	restore := NewConfig(OnRenderFailure(func(f RenderFailure) {
+		failures = append(failures, f)
+	})).Apply()
+	defer restore()
+
+	err := WithFields(WithCode(910401, "fetch"), "value", faultyValue{})
+	detailed := WithDetails(WithCode(910401, "fetch"), faultyDetail{})
+	tests := []struct {
+		name  string
+		write func(w http.ResponseWriter)
+		want  string
+	}{{
+		name: "WriteResponse",
+		write: func(w http.ResponseWriter) {
+			WriteResponse(w, err, IncludeInternal(true))
+		},
+		want: `{"code":910401,"message":"Bad Gateway"}` + "\n",
+	}, {
+		name: "ServeProblem",
+		write: func(w http.ResponseWriter) {
+			ServeProblem(w, httptest.NewRequest(http.MethodGet, "/", nil), detailed)
+		},
+		want: `{"type":"about:blank","title":"Bad Gateway","status":502,"code":910401}` + "\n",
+	}}
+
+	for _, tt := range tests {
+		failures = nil
+		rec := httptest.NewRecorder()
+		tt.write(rec)
+		if rec.Code != 502 {
+			t.Errorf("%s: status: got %d, want %d", tt.name, rec.Code, 502)
+		}
+		if got := rec.Body.String(); got != tt.want {
+			t.Errorf("%s: body: got %q, want %q", tt.name, got, tt.want)
+		}
+		if len(failures) != 1 || failures[0].Code != 910401 || failures[0].Panic == nil {
+			t.Errorf("%s: failures: got %+v, want one failure of code %d", tt.name, failures, 910401)
+		}
+	}
+
+	// A healthy error is rendered as usual, without calling the hook.
+	failures = nil
+	rec := httptest.NewRecorder()
+	WriteResponse(rec, WithCode(910401, "fetch"))
+	if got := rec.Body.String(); !strings.Contains(got, `"message":"upstream failed"`) {
+		t.Errorf("healthy body: got %q, want the message of the coder", got)
+	}
+	if len(failures) != 0 {
+		t.Errorf("healthy failures: got %+v, want none", failures)
+	}
+}
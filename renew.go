@@ -0,0 +1,55 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import "sync/atomic"
+
+// Renew returns a copy of err recorded at the call site of Renew, for
+// errors declared once and returned many times, such as sentinel coded
+// errors:
+//
+//	var errNotFound = errors.WithCode(ErrUserNotFound, "user not found")
+//
+//	return errors.Renew(errNotFound)
+//
+// The copy gets a fresh stack trace and its own fields and details, and
+// counts as a new occurrence of its code, see CodeUsage. Errors of other
+// types are wrapped with a stack trace instead, see WithStack.
+// If err is nil, Renew returns nil.
+func Renew(err error) error {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *fundamental:
+		cp := *e
+		cp.stack = callers()
+		cp.pc = stackPC(cp.stack)
+		return &cp
+	case *withCode:
+		cp := *e
+		cp.fields = mergeFields(nil, e.fields)
+		cp.details = append([]Detail(nil), e.details...)
+		cp.stack = codeCallers(e.code)
+		cp.pc = stackPC(cp.stack)
+		cp.id = atomic.Value{}
+		return created(&cp)
+	}
+	st := callers()
+	return &withStack{
+		err,
+		st,
+		stackPC(st),
+	}
+}
@@ -0,0 +1,65 @@
+package errors
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestRenew(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(907201, 404, "not found", ""))
+	base := WithCode(907201, "user not found")
+
+	var lines []int
+	for i := 0; i < 2; i++ {
+		err := Renew(base)
+		if err == base {
+			t.Fatalf("Renew(): got the same error, want a copy")
+		}
+		if !IsCode(err, 907201) || err.Error() != base.Error() {
+			t.Errorf("Renew(): got %v, want a copy of %v", err, base)
+		}
+		lines = append(lines, Breadcrumbs(err)[0].Line)
+	}
+	if lines[0] != lines[1] || lines[0] == Breadcrumbs(base)[0].Line {
+		t.Errorf("Renew(): got breadcrumb lines %v, want the line of the Renew call", lines)
+	}
+
+	if got := Renew(nil); got != nil {
+		t.Errorf("Renew(nil): got %v, want nil", got)
+	}
+	if got := Renew(io.EOF); Cause(got) != io.EOF || len(list(got)) != 2 {
+		t.Errorf("Renew(io.EOF): got %v, want io.EOF with a stack", got)
+	}
+}
+
+func TestLoopReuse(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(907202, 400, "invalid", ""))
+	base := WithFields(WithCode(907202, "invalid item"), "batch", 7)
+
+	for i := 0; i < 3; i++ {
+		err := WithHelp(WithFields(Wrap(base, "validate"), "item", i), HelpLink{URL: "https://example.com"})
+		if got, want := Fields(err), map[string]interface{}{"batch": 7, "item": i}; !reflect.DeepEqual(got, want) {
+			t.Errorf("iteration %d: Fields(): got %v, want %v", i, got, want)
+		}
+		if got := len(Breadcrumbs(err)); got != 2 {
+			t.Errorf("iteration %d: got %d breadcrumbs, want %d", i, got, 2)
+		}
+		if got := len(HelpLinks(err)); got != 1 {
+			t.Errorf("iteration %d: got %d help links, want %d", i, got, 1)
+		}
+	}
+
+	if got, want := Fields(base), map[string]interface{}{"batch": 7}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields(base): got %v, want %v", got, want)
+	}
+	if got := Details(base); got != nil {
+		t.Errorf("Details(base): got %v, want nil", got)
+	}
+}
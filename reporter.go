@@ -0,0 +1,106 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Reporter receives every error served to clients, see ServedCoder, with
+// the Coder it was served with. It is the interface through which this
+// package calls into the integrations living in their own packages, such
+// as otelerr, so that it depends on the standard library only:
+//
+//	errors.AddReporter("otel", otelerr.NewExporter(provider))
+//
+// Report is called synchronously by the serving function, so it must be
+// fast and safe for concurrent use.
+type Reporter interface {
+	Report(err error, coder Coder)
+}
+
+// ReporterFunc adapts a function to the Reporter interface.
+type ReporterFunc func(err error, coder Coder)
+
+// Report implements Reporter.
+func (f ReporterFunc) Report(err error, coder Coder) { f(err, coder) }
+
+// namedReporter is a Reporter added with AddReporter.
+type namedReporter struct {
+	name string
+	Reporter
+}
+
+var (
+	reportersMux sync.Mutex
+
+	// reporters holds the []namedReporter sorted by name. It is replaced
+	// on every change, so that ServedCoder reads it without locking.
+	reporters atomic.Value
+)
+
+// AddReporter adds r under the given name, replacing the Reporter added
+// under the same name, if any. A nil r removes it, see RemoveReporter.
+func AddReporter(name string, r Reporter) {
+	reportersMux.Lock()
+	defer reportersMux.Unlock()
+
+	var updated []namedReporter
+	for _, nr := range reportersNow() {
+		if nr.name != name {
+			updated = append(updated, nr)
+		}
+	}
+	if r != nil {
+		updated = append(updated, namedReporter{name: name, Reporter: r})
+	}
+	sort.Slice(updated, func(i, j int) bool { return updated[i].name < updated[j].name })
+	reporters.Store(updated)
+}
+
+// RemoveReporter removes the Reporter added under the given name.
+func RemoveReporter(name string) {
+	AddReporter(name, nil)
+}
+
+// Reporters returns the sorted names of the added reporters.
+func Reporters() []string {
+	var names []string
+	for _, nr := range reportersNow() {
+		names = append(names, nr.name)
+	}
+	return names
+}
+
+func reportersNow() []namedReporter {
+	rs, _ := reporters.Load().([]namedReporter)
+	return rs
+}
+
+// report calls the reporters with err served with coder, in the order of
+// their names.
+func report(err error, coder Coder) {
+	rs := reportersNow()
+	if len(rs) == 0 {
+		return
+	}
+	reportPipeline.begin()
+	defer reportPipeline.end()
+	for _, nr := range rs {
+		nr.Report(err, coder)
+	}
+}
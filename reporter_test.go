@@ -0,0 +1,77 @@
+package errors
+
+import (
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestReporters(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(908801, 503, "unavailable", ""))
+
+	var got []string
+	AddReporter("b", ReporterFunc(func(err error, coder Coder) {
+		got = append(got, "b:"+strconv.Itoa(coder.Code()))
+	}))
+	AddReporter("a", ReporterFunc(func(err error, coder Coder) {
+		got = append(got, "a:"+err.Error())
+	}))
+	if names := Reporters(); !reflect.DeepEqual(names, []string{"a", "b"}) {
+		t.Errorf("Reporters(): got %v, want [a b]", names)
+	}
+
+	ServedCoder(WithCode(908801, "down"))
+	ServedCoder(nil)
+	ServedCoder(io.EOF)
+	want := []string{"a:down", "b:908801", "a:EOF", "b:" + strconv.Itoa(unknownCoder.Code())}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("reports: got %v, want %v", got, want)
+	}
+
+	RemoveReporter("a")
+	AddReporter("b", ReporterFunc(func(error, Coder) { got = append(got, "b2") }))
+	got = nil
+	ServedCoder(io.EOF)
+	if !reflect.DeepEqual(got, []string{"b2"}) {
+		t.Errorf("reports after changes: got %v, want [b2]", got)
+	}
+
+	restore()
+	if names := Reporters(); len(names) != 0 {
+		t.Errorf("Reporters() after restore: got %v, want none", names)
+	}
+}
+
+// TestStandardLibraryOnly makes sure that the integrations with other
+// modules stay in subpackages.
+func TestStandardLibraryOnly(t *testing.T) {
+	entries, err := os.ReadDir(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, nil, parser.ImportsOnly)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, imp := range f.Imports {
+			path, _ := strconv.Unquote(imp.Path.Value)
+			if first, _, _ := strings.Cut(path, "/"); strings.Contains(first, ".") {
+				t.Errorf("%s imports %s, want the standard library only", name, path)
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+// IsRetryable reports whether the operation which failed with err is worth
+// retrying, so that retry loops and middlewares decide with a single call.
+// It reports true if any layer of err's chain, including the members of its
+// multi-errors, is retryable:
+//
+//   - a coded layer whose Coder is retryable, see RetryableOf and
+//     WithRetryable;
+//   - an error with a Retryable() bool method returning true;
+//   - an error with a Temporary() bool or a Timeout() bool method returning
+//     true, such as the timeouts of net.Error.
+//
+// The client aborts, see IsClientAbort, are never retryable, nor is a nil
+// err.
+func IsRetryable(err error) bool {
+	if err == nil || IsClientAbort(err) {
+		return false
+	}
+	return walk(err, func(e error) bool {
+		if _, ok := e.(CodeError); ok {
+			if retryable, _ := RetryableOf(ParseCoder(e)); retryable {
+				return true
+			}
+		}
+		if v, ok := e.(interface{ Retryable() bool }); ok && v.Retryable() {
+			return true
+		}
+		if v, ok := e.(interface{ Temporary() bool }); ok && v.Temporary() {
+			return true
+		}
+		v, ok := e.(interface{ Timeout() bool })
+		return ok && v.Timeout()
+	})
+}
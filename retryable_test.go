@@ -0,0 +1,61 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+)
+
+// temporaryCoder is a Coder reporting retryability with Temporary.
+type temporaryCoder struct{ defaultCoder }
+
+func (temporaryCoder) Temporary() bool { return true }
+
+type retryableError struct{ retryable bool }
+
+func (e retryableError) Error() string   { return "retryable" }
+func (e retryableError) Retryable() bool { return e.retryable }
+
+func TestIsRetryable(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(908201, 503, "unavailable", "", WithRetryable(true)))
+	MustRegister(NewCoder(908202, 400, "bad request", ""))
+	MustRegister(temporaryCoder{defaultCoder{C: 908203, HTTP: 500, Ext: "flaky"}})
+	MustRegister(defaultCoder{C: 908204, HTTP: 429, Ext: "slow down"})
+
+	timeout := &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{IsTimeout: true}}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"plain", io.EOF, false},
+		{"retryable code", WithCode(908201, "down"), true},
+		{"wrapped retryable code", Wrap(WithCode(908201, "down"), "call"), true},
+		{"non-retryable code", WithCode(908202, "bad"), false},
+		{"temporary coder", WithCode(908203, "flaky"), true},
+		{"status default", WithCode(908204, "slow"), true},
+		{"net timeout", timeout, true},
+		{"coded net timeout", Wrapc(timeout, 908202, "resolve"), true},
+		{"fmt wrapped timeout", fmt.Errorf("fetch: %w", timeout), true},
+		{"deadline", context.DeadlineExceeded, true},
+		{"canceled", context.Canceled, false},
+		{"retryable method", Wrap(retryableError{true}, "call"), true},
+		{"non-retryable method", retryableError{false}, false},
+		{"joined", Join(io.EOF, WithCode(908201, "down")), true},
+		{"client abort", Wrapc(syscall.EPIPE, 908201, "write"), false},
+	}
+
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("IsRetryable(%s): got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import "errors"
+
+// maxSanitizedField is the maximum length of the string fields kept by
+// Sanitize.
+const maxSanitizedField = 256
+
+// Sanitize returns a lightweight copy of err which is safe to keep in long
+// lived caches, such as negative caches keyed by request. The copy holds
+// the code of the outermost coded layer of err's chain, its message and its
+// scalar fields, leaving out stacks, causes, details and fields whose
+// values are not numbers, booleans or strings of up to 256 bytes. Several
+// copies of the same error can then be cached without retaining the
+// memory of the original chain.
+// If err is nil, Sanitize returns nil.
+func Sanitize(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	code, ok := TopCode(err)
+	if !ok {
+		code = ParseCoder(err).Code()
+	}
+
+	var fields map[string]interface{}
+	for k, v := range Fields(err) {
+		if !smallField(v) {
+			continue
+		}
+		if fields == nil {
+			fields = map[string]interface{}{}
+		}
+		fields[k] = v
+	}
+
+	return &withCode{
+		err:    errors.New(err.Error()),
+		code:   code,
+		fields: fields,
+	}
+}
+
+// smallField reports whether v is a scalar value worth keeping in a
+// sanitized error.
+func smallField(v interface{}) bool {
+	switch v := v.(type) {
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return true
+	case string:
+		return len(v) <= maxSanitizedField
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(904901, 404, "not found", ""))
+
+	if got := Sanitize(nil); got != nil {
+		t.Errorf("Sanitize(nil): got %v, want nil", got)
+	}
+
+	err := WithFields(WrapArgs(io.EOF, 904901, "load user", Arg("id", 42)), map[string]interface{}{
+		"body":  strings.Repeat("x", 4096),
+		"items": []int{1, 2, 3},
+		"ok":    true,
+	})
+	err = WithBlobRef(Wrap(err, "handler"), "s3://bucket/key")
+
+	s := Sanitize(err)
+	if s.Error() != err.Error() {
+		t.Errorf("Sanitize(): got message %q, want %q", s.Error(), err.Error())
+	}
+	if !IsCode(s, 904901) {
+		t.Errorf("Sanitize(): got code %d, want 904901", ParseCoder(s).Code())
+	}
+	if got, want := Fields(s), map[string]interface{}{"id": 42, "ok": true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields(Sanitize()): got %v, want %v", got, want)
+	}
+	if len(list(s)) != 1 || Details(s) != nil || stackOf(s) != nil {
+		t.Errorf("Sanitize(): got chain %v, details %v and stack %v, want none", list(s), Details(s), stackOf(s))
+	}
+	if got := fmt.Sprintf("%+v", s); got == "" {
+		t.Errorf("%%+v: got an empty string")
+	}
+	if got := Sanitize(io.EOF); ParseCoder(got).Code() != 1 || got.Error() != "EOF" {
+		t.Errorf("Sanitize(EOF): got %v with code %d", got, ParseCoder(got).Code())
+	}
+}
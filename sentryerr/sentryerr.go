@@ -0,0 +1,124 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sentryerr converts the errors of github.com/rtmzk/errors into the
+// exception interface of Sentry events. The types mirror the JSON of the
+// Sentry protocol, and of the Exception type of the sentry-go SDK, so that
+// they can be sent as is or converted without depending on the SDK:
+//
+//	event := sentry.NewEvent()
+//	byts, _ := json.Marshal(sentryerr.Exceptions(err))
+//	_ = json.Unmarshal(byts, &event.Exception)
+package sentryerr
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/rtmzk/errors"
+)
+
+// Exception is a value of the exception interface of a Sentry event.
+type Exception struct {
+	Type       string      `json:"type,omitempty"`
+	Value      string      `json:"value,omitempty"`
+	Module     string      `json:"module,omitempty"`
+	Stacktrace *Stacktrace `json:"stacktrace,omitempty"`
+	Mechanism  *Mechanism  `json:"mechanism,omitempty"`
+}
+
+// Stacktrace is the stack of an Exception.
+type Stacktrace struct {
+	// Frames are ordered from the outermost caller to the innermost call,
+	// as Sentry expects.
+	Frames []Frame `json:"frames"`
+}
+
+// Frame is a frame of a Stacktrace.
+type Frame struct {
+	Function string `json:"function,omitempty"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	AbsPath  string `json:"abs_path,omitempty"`
+	Lineno   int    `json:"lineno,omitempty"`
+	InApp    bool   `json:"in_app"`
+}
+
+// Mechanism describes how an Exception was captured. Its data holds the
+// error code and the Go type of coded layers.
+type Mechanism struct {
+	Type string                 `json:"type"`
+	Data map[string]interface{} `json:"data,omitempty"`
+}
+
+// Exceptions returns the exception values describing err, innermost first,
+// see errors.ExceptionChain. Coded layers are typed with their code, so that
+// Sentry groups the issues by code rather than by the Go type shared by all
+// coded errors. Exceptions returns nil if err is nil.
+func Exceptions(err error) []Exception {
+	chain := errors.ExceptionChain(err)
+	if chain == nil {
+		return nil
+	}
+	ret := make([]Exception, 0, len(chain))
+	for _, x := range chain {
+		e := Exception{
+			Type:  x.Type,
+			Value: x.Message,
+		}
+		if x.Code != 0 {
+			e.Type = strconv.Itoa(x.Code)
+			e.Mechanism = &Mechanism{
+				Type: "generic",
+				Data: map[string]interface{}{"code": x.Code, "go_type": x.Type},
+			}
+		}
+		if len(x.Frames) > 0 {
+			e.Stacktrace = &Stacktrace{Frames: frames(x.Frames)}
+			e.Module = x.Frames[0].Package
+		}
+		ret = append(ret, e)
+	}
+	return ret
+}
+
+// frames converts frames, innermost first, into Sentry frames, outermost
+// first.
+func frames(fs []errors.ExceptionFrame) []Frame {
+	ret := make([]Frame, len(fs))
+	for i, f := range fs {
+		ret[len(fs)-1-i] = Frame{
+			Function: strings.TrimPrefix(f.Function, f.Package+"."),
+			Module:   f.Package,
+			Filename: filename(f.File),
+			AbsPath:  f.File,
+			Lineno:   f.Line,
+			InApp:    f.InApp,
+		}
+	}
+	return ret
+}
+
+// filename returns the last two elements of path, the package directory
+// and the file, which is what Sentry displays.
+func filename(path string) string {
+	i := strings.LastIndexByte(path, '/')
+	if i < 0 {
+		return path
+	}
+	if j := strings.LastIndexByte(path[:i], '/'); j >= 0 {
+		return path[j+1:]
+	}
+	return path
+}
@@ -0,0 +1,57 @@
+package sentryerr
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/rtmzk/errors"
+)
+
+func init() {
+	errors.Register(errors.NewCoder(910602, 502, "upstream failed", ""))
+}
+
+func TestExceptions(t *testing.T) {
+	err := errors.Wrapc(errors.WithStack(io.EOF), 910602, "fetch profile")
+
+	got := Exceptions(err)
+	if len(got) != 2 {
+		t.Fatalf("Exceptions: got %d exceptions, want 2", len(got))
+	}
+	if got[0].Type != "*errors.errorString" || got[0].Value != "EOF" || got[0].Mechanism != nil {
+		t.Errorf("root: got %+v, want the EOF cause", got[0])
+	}
+	coded := got[1]
+	if coded.Type != "910602" || coded.Value != "fetch profile" {
+		t.Errorf("coded: got type %q value %q, want %q %q", coded.Type, coded.Value, "910602", "fetch profile")
+	}
+	if coded.Mechanism == nil || coded.Mechanism.Data["code"] != 910602 {
+		t.Errorf("coded: got mechanism %+v, want the code", coded.Mechanism)
+	}
+	if coded.Module != "github.com/rtmzk/errors/sentryerr" {
+		t.Errorf("coded: got module %q, want %q", coded.Module, "github.com/rtmzk/errors/sentryerr")
+	}
+
+	frames := coded.Stacktrace.Frames
+	last := frames[len(frames)-1]
+	if last.Function != "TestExceptions" || last.Filename != "sentryerr/sentryerr_test.go" || !last.InApp {
+		t.Errorf("innermost frame: got %+v, want TestExceptions in sentryerr/sentryerr_test.go", last)
+	}
+	if !strings.HasSuffix(last.AbsPath, "/sentryerr/sentryerr_test.go") || last.Lineno == 0 {
+		t.Errorf("innermost frame: got %s:%d, want the test file", last.AbsPath, last.Lineno)
+	}
+	if first := frames[0]; first.InApp {
+		t.Errorf("outermost frame: got %+v, want a frame of the runtime", first)
+	}
+
+	byts, jerr := json.Marshal(got[1])
+	if jerr != nil || !strings.Contains(string(byts), `"type":"910602"`) || !strings.Contains(string(byts), `"lineno":`) {
+		t.Errorf("JSON: got %s, %v", byts, jerr)
+	}
+
+	if got := Exceptions(nil); got != nil {
+		t.Errorf("Exceptions(nil): got %v, want nil", got)
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import "strconv"
+
+// Depth returns the number of layers of err's chain, err included.
+// It returns 0 if err is nil.
+func Depth(err error) int {
+	return len(list(err))
+}
+
+// Size returns the approximate size in bytes of the %+v rendering of err:
+// the message of err plus one line per stack frame of every layer. It is
+// cheaper than rendering the error, and meant to choose between a full and
+// a truncated rendering.
+// Size returns 0 if err is nil.
+func Size(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	size := len(err.Error())
+	for _, e := range list(err) {
+		s := stackOf(e)
+		if s == nil {
+			continue
+		}
+		for _, pc := range *s {
+			f := Frame(pc)
+			// "\n" name "\n\t" file ":" line
+			size += 4 + len(f.name()) + len(f.file()) + len(strconv.Itoa(f.line()))
+		}
+	}
+	return size
+}
@@ -0,0 +1,44 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestDepth(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, 0},
+		{io.EOF, 1},
+		{New("origin"), 1},
+		{WithStack(io.EOF), 2},
+		{Wrap(io.EOF, "read"), 3},
+		{Wrapc(Wrap(io.EOF, "read"), 902801, "coded"), 4},
+	}
+
+	for i, tt := range tests {
+		if got := Depth(tt.err); got != tt.want {
+			t.Errorf("test %d: Depth(%v): got %d, want %d", i+1, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestSize(t *testing.T) {
+	tests := []error{
+		io.EOF,
+		New("origin"),
+		WithStack(io.EOF),
+	}
+
+	for i, err := range tests {
+		if got, want := Size(err), len(fmt.Sprintf("%+v", err)); got != want {
+			t.Errorf("test %d: Size(%v): got %d, want %d", i+1, err, got, want)
+		}
+	}
+	if got := Size(nil); got != 0 {
+		t.Errorf("Size(nil): got %d, want 0", got)
+	}
+}
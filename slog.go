@@ -0,0 +1,72 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"log/slog"
+)
+
+// SlogAttrs returns the structured attributes describing err for log/slog:
+// the code and HTTP status of its Coder, its message, the message of every
+// layer of its chain, its fields and its innermost stack trace.
+// It returns nil if err is nil.
+//
+// The errors of this package implement slog.LogValuer with these
+// attributes, so that logging them as a value keeps their structure:
+//
+//	logger.Error("request failed", "err", err)
+func SlogAttrs(err error) []slog.Attr {
+	if err == nil {
+		return nil
+	}
+	coder := ParseCoder(err)
+	attrs := []slog.Attr{
+		slog.Int("code", coder.Code()),
+		slog.Int("http_status", coder.HTTPStatus()),
+		slog.String("message", err.Error()),
+	}
+	if chain := chainMessages(err); len(chain) > 1 {
+		attrs = append(attrs, slog.Any("chain", chain))
+	}
+	if fields := SortedFields(err); len(fields) > 0 {
+		group := make([]slog.Attr, 0, len(fields))
+		for _, f := range fields {
+			group = append(group, slog.Any(f.Key, f.Value))
+		}
+		attrs = append(attrs, slog.Attr{Key: "fields", Value: slog.GroupValue(group...)})
+	}
+	if stack := stackLines(err); len(stack) > 0 {
+		attrs = append(attrs, slog.Any("stack", stack))
+	}
+	return attrs
+}
+
+// logValue is the slog value of the errors of this package. Errors without
+// a code are logged as their message, as slog does for any error.
+func logValue(err error) slog.Value {
+	if _, ok := TopCode(err); !ok {
+		return slog.StringValue(err.Error())
+	}
+	return slog.GroupValue(SlogAttrs(err)...)
+}
+
+// LogValue implements slog.LogValuer.
+func (w *withCode) LogValue() slog.Value { return logValue(w) }
+
+// LogValue implements slog.LogValuer.
+func (w *withStack) LogValue() slog.Value { return logValue(w) }
+
+// LogValue implements slog.LogValuer.
+func (w *withMessage) LogValue() slog.Value { return logValue(w) }
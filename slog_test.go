@@ -0,0 +1,51 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestSlog(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(906301, 404, "not found", ""))
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Error("failed", "err", Wrap(WithFields(WithCode(906301, "no user"), "user_id", 42), "load"))
+
+	var record struct {
+		Err struct {
+			Code       int                    `json:"code"`
+			HTTPStatus int                    `json:"http_status"`
+			Message    string                 `json:"message"`
+			Chain      []string               `json:"chain"`
+			Fields     map[string]interface{} `json:"fields"`
+			Stack      []string               `json:"stack"`
+		} `json:"err"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("decode %q: %v", buf.String(), err)
+	}
+	got := record.Err
+	if got.Code != 906301 || got.HTTPStatus != 404 || got.Message != "load: no user" {
+		t.Errorf("attrs: got %+v, want code 906301, status 404 and message %q", got, "load: no user")
+	}
+	if len(got.Chain) < 2 || got.Fields["user_id"] != float64(42) || len(got.Stack) == 0 {
+		t.Errorf("attrs: got %+v, want a chain, the fields and a stack", got)
+	}
+
+	buf.Reset()
+	logger.Error("failed", "err", Wrap(io.EOF, "read"))
+	if want := `"err":"read: EOF"`; !bytes.Contains(buf.Bytes(), []byte(want)) {
+		t.Errorf("uncoded: got %s, want it to contain %s", buf.Bytes(), want)
+	}
+
+	if got := SlogAttrs(nil); got != nil {
+		t.Errorf("SlogAttrs(nil): got %v, want nil", got)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+// slugs indexes the registered codes by slug, see WithSlug. It is guarded
+// by codeMux.
+var slugs = map[string]int{}
+
+// setCoder registers coder in the registry backend and indexes its slug.
+// codeMux must be held.
+func setCoder(coder Coder) {
+	codes.set(coder)
+	recordRegistration(coder.Code())
+	if slug, ok := SlugOf(coder); ok {
+		slugs[slug] = coder.Code()
+	}
+}
+
+// reindexSlugs rebuilds the slug index from the registry backend, after it
+// has been replaced. codeMux must be held.
+func reindexSlugs() {
+	slugs = map[string]int{}
+	for _, coder := range codes.list() {
+		if slug, ok := SlugOf(coder); ok {
+			slugs[slug] = coder.Code()
+		}
+	}
+}
+
+// slugOwner returns the code registered with slug, if any. codeMux must be
+// held.
+func slugOwner(slug string) (int, bool) {
+	code, ok := slugs[slug]
+	return code, ok
+}
+
+// CoderBySlug returns the Coder registered with the given slug, see
+// WithSlug.
+func CoderBySlug(slug string) (Coder, bool) {
+	if slug == "" {
+		return nil, false
+	}
+	ensureCatalogs()
+	if !Frozen() {
+		codeMux.RLock()
+		defer codeMux.RUnlock()
+	}
+	code, ok := slugs[slug]
+	if !ok {
+		return nil, false
+	}
+	return codes.get(code)
+}
+
+// IsSlug reports whether any error in err's chain carries a code whose
+// Coder has the given slug, see WithSlug. The chain is walked like by
+// IsCode.
+func IsSlug(err error, slug string) bool {
+	if slug == "" {
+		return false
+	}
+	ensureCatalogs()
+	return walk(err, func(e error) bool {
+		v, ok := e.(CodeError)
+		if !ok {
+			return false
+		}
+		coder, ok := lookupCoder(v.Code())
+		if !ok {
+			if coder, ok = coderOf(e); !ok {
+				return false
+			}
+		}
+		s, ok := SlugOf(coder)
+		return ok && s == slug
+	})
+}
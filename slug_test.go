@@ -0,0 +1,70 @@
+package errors
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSlug(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(911001, 404, "User not found", "", WithSlug("user.not_found")))
+	MustRegister(NewCoder(911002, 409, "Conflict", ""))
+
+	if coder, ok := CoderBySlug("user.not_found"); !ok || coder.Code() != 911001 {
+		t.Errorf("CoderBySlug: got %v, %v, want code %d", coder, ok, 911001)
+	}
+	if _, ok := CoderBySlug("user.gone"); ok {
+		t.Errorf("CoderBySlug(unknown): got true, want false")
+	}
+
+	err := fmt.Errorf("handler: %w", Wrapc(WithCode(911001, "lookup"), 911002, "update"))
+	tests := []struct {
+		slug string
+		want bool
+	}{
+		{"user.not_found", true},
+		{"user.gone", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsSlug(err, tt.slug); got != tt.want {
+			t.Errorf("IsSlug(%q): got %v, want %v", tt.slug, got, tt.want)
+		}
+	}
+
+	byts, _ := ToJSON(WithCode(911001, "lookup"))
+	if !strings.Contains(string(byts), `"slug":"user.not_found"`) {
+		t.Errorf("ToJSON: got %s, want the slug", byts)
+	}
+	decoded, _ := FromJSON([]byte(`{"code":911099,"slug":"order.expired","message":"expired","http_status":410}`))
+	if !IsSlug(decoded, "order.expired") {
+		t.Errorf("IsSlug(decoded): got false, want true")
+	}
+
+	rec := httptest.NewRecorder()
+	WriteResponse(rec, WithCode(911001, "lookup"))
+	if got := rec.Body.String(); !strings.Contains(got, `"code":911001,"slug":"user.not_found"`) {
+		t.Errorf("WriteResponse: got %s, want the code and slug", got)
+	}
+
+	if err := RegisterE(NewCoder(911003, 404, "Gone", "", WithSlug("user.not_found"))); !Is(err, ErrSlugExists) {
+		t.Errorf("RegisterE(duplicated slug): got %v, want %v", err, ErrSlugExists)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r == nil || !strings.Contains(r.(string), "slug user.not_found") {
+				t.Errorf("MustRegisterTable(duplicated slug): got %v, want a panic", r)
+			}
+		}()
+		MustRegisterTable([]CoderSpec{{Code: 911004, Message: "Gone", Options: []CoderOption{WithSlug("user.not_found")}}})
+	}()
+
+	restore()
+	if _, ok := CoderBySlug("user.not_found"); ok {
+		t.Errorf("CoderBySlug after restore: got true, want false")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync/atomic"
 )
 
 // Frame represents a program counter inside a stack frame.
@@ -140,11 +141,14 @@ func (st StackTrace) formatSlice(s fmt.State, verb rune) {
 type stack []uintptr
 
 func (s *stack) Format(st fmt.State, verb rune) {
+	if s == nil {
+		return
+	}
 	switch verb {
 	case 'v':
 		switch {
 		case st.Flag('+'):
-			for _, pc := range *s {
+			for _, pc := range s.frames() {
 				f := Frame(pc)
 				fmt.Fprintf(st, "\n%+v", f)
 			}
@@ -153,21 +157,211 @@ func (s *stack) Format(st fmt.State, verb rune) {
 }
 
 func (s *stack) StackTrace() StackTrace {
-	f := make([]Frame, len(*s))
+	if s == nil {
+		return nil
+	}
+	pcs := s.frames()
+	f := make([]Frame, len(pcs))
 	for i := 0; i < len(f); i++ {
-		f[i] = Frame((*s)[i])
+		f[i] = Frame(pcs[i])
 	}
 	return f
 }
 
+// Stacker captures the stack traces recorded by the errors of this package.
+// A custom Stacker can be installed with SetStacker, e.g. by a framework
+// with its own scheduler which appends the stack of the goroutine which
+// created an asynchronous task. The frames it returns are printed by %+v
+// like any other.
+type Stacker interface {
+	// Stack returns the program counters of the calling goroutine,
+	// innermost first, in the form returned by runtime.Callers.
+	// skip is the number of frames to skip before recording, with 0
+	// identifying the caller of Stack.
+	Stack(skip int) []uintptr
+}
+
+// defaultStackDepth is the default maximum number of frames recorded.
+const defaultStackDepth = 32
+
+// runtimeStacker is the default Stacker, recording up to the stack depth set
+// by SetStackDepth with runtime.Callers.
+type runtimeStacker struct{}
+
+func (runtimeStacker) Stack(skip int) []uintptr {
+	pcs := make([]uintptr, stackDepth)
+	n := runtime.Callers(skip+2, pcs)
+	return pcs[0:n]
+}
+
+var (
+	// captureStacks is 1 when the stacks of new errors are captured. It is
+	// accessed atomically since it can be changed at runtime, see
+	// SetVerbosity.
+	captureStacks int32 = 1
+
+	// stackDepth is the maximum number of frames recorded.
+	stackDepth = defaultStackDepth
+
+	// stackSkip is the number of frames skipped in addition to the
+	// functions of this package.
+	stackSkip = 0
+)
+
+// SetCaptureStacks enables or disables the capture of the stacks of new
+// errors. Disabling it removes the cost of runtime.Callers from hot paths,
+// at the expense of %+v printing no frame and of StackTrace returning nil.
+func SetCaptureStacks(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&captureStacks, v)
+}
+
+func captureStacksNow() bool {
+	return atomic.LoadInt32(&captureStacks) == 1
+}
+
+// SetStackDepth sets the maximum number of frames recorded in the stacks of
+// new errors, which bounds the cost of the capture. A depth lower than 1
+// restores the default of 32 frames. Frames returned by a custom Stacker
+// beyond the depth are dropped.
+func SetStackDepth(depth int) {
+	if depth < 1 {
+		depth = defaultStackDepth
+	}
+	stackDepth = depth
+}
+
+// SetStackSkip sets how many frames are skipped in addition to the
+// functions of this package when recording the stacks of new errors, so
+// that the helpers of an application wrapping this package do not appear
+// as the origin of every error. A negative skip is treated as 0.
+func SetStackSkip(skip int) {
+	if skip < 0 {
+		skip = 0
+	}
+	stackSkip = skip
+}
+
+// stacker is the Stacker used to record the stacks of new errors.
+var stacker Stacker = runtimeStacker{}
+
+// SetStacker replaces the Stacker used to record the stacks of new errors.
+// A nil Stacker restores the default one.
+func SetStacker(s Stacker) {
+	if s == nil {
+		s = runtimeStacker{}
+	}
+	stacker = s
+}
+
 func callers() *stack {
-	const depth = 32
-	var pcs [depth]uintptr
-	n := runtime.Callers(3, pcs[:])
-	var st stack = pcs[0:n]
+	return captureStack(2)
+}
+
+// codeCallers is like callers for a coded error with the given code, whose
+// stack is not recorded if the stack policy excludes the code, see
+// SetStackPolicyFunc, or if it is not sampled, see SetStackSampling.
+func codeCallers(code int) *stack {
+	if !stackPolicyAllows(code) || !sampleStack(code) {
+		return nil
+	}
+	st := captureStack(2)
+	noteSample(code, st)
+	return st
+}
+
+// captureStack records the stack of the caller of the function calling
+// captureStack, skipping the given number of frames above captureStack.
+func captureStack(skip int) *stack {
+	if !captureStacksNow() {
+		return nil
+	}
+	// Skip captureStack itself and the functions of this package calling it.
+	if _, ok := stacker.(runtimeStacker); ok && stackDepth <= inlineStackDepth {
+		// Record the default stacks in a buffer which does not escape,
+		// copied into an allocation of their size.
+		var buf [inlineStackDepth]uintptr
+		pcs := buf[:runtime.Callers(skip+2+stackSkip, buf[:stackDepth])]
+		if !spendStack() {
+			return summarize(pcs)
+		}
+		return newStack(pcs)
+	}
+	var st stack = stacker.Stack(skip + 1 + stackSkip)
+	if len(st) > stackDepth {
+		st = st[:stackDepth]
+	}
+	if !spendStack() {
+		return summarize(st)
+	}
 	return &st
 }
 
+// inlineStackDepth is the largest stack depth recorded by the default
+// Stacker in a buffer on the goroutine stack, see captureStack.
+const inlineStackDepth = 64
+
+// newStack returns a copy of pcs allocated together with its slice header,
+// in an allocation of the size class of pcs.
+func newStack(pcs []uintptr) *stack {
+	switch n := len(pcs); {
+	case n <= 8:
+		s := new(struct {
+			st  stack
+			pcs [8]uintptr
+		})
+		copy(s.pcs[:], pcs)
+		s.st = s.pcs[:n:n]
+		return &s.st
+	case n <= 16:
+		s := new(struct {
+			st  stack
+			pcs [16]uintptr
+		})
+		copy(s.pcs[:], pcs)
+		s.st = s.pcs[:n:n]
+		return &s.st
+	case n <= 32:
+		s := new(struct {
+			st  stack
+			pcs [32]uintptr
+		})
+		copy(s.pcs[:], pcs)
+		s.st = s.pcs[:n:n]
+		return &s.st
+	}
+	st := make(stack, len(pcs))
+	copy(st, pcs)
+	return &st
+}
+
+// callerPC returns the program counter of the caller of the function
+// calling callerPC, in the same form as the elements of callers.
+func callerPC() uintptr {
+	var pcs [1]uintptr
+	if runtime.Callers(3, pcs[:]) == 0 {
+		return 0
+	}
+	return pcs[0]
+}
+
+// stackPC is like callerPC, but takes the program counter from st, the
+// stack captured by the function calling stackPC, when the default Stacker
+// recorded one, so that the call site costs no second runtime.Callers.
+func stackPC(st *stack) uintptr {
+	if _, ok := stacker.(runtimeStacker); ok && st != nil && len(*st) > 0 {
+		return (*st)[0]
+	}
+	var pcs [1]uintptr
+	if runtime.Callers(3, pcs[:]) == 0 {
+		return 0
+	}
+	return pcs[0]
+}
+
 // funcname removes the path prefix component of a function's name reported by func.Name().
 func funcname(name string) string {
 	i := strings.LastIndex(name, "/")
@@ -248,3 +248,65 @@ func caller() Frame {
 	frame, _ := frames.Next()
 	return Frame(frame.PC)
 }
+
+// taskStacker appends the frames of a task's creation site to the stack.
+type taskStacker struct {
+	created []uintptr
+}
+
+func (s taskStacker) Stack(skip int) []uintptr {
+	return append(runtimeStacker{}.Stack(skip+1), s.created...)
+}
+
+func TestSetStacker(t *testing.T) {
+	created := runtimeStacker{}.Stack(0)
+	defer SetStacker(nil)
+	SetStacker(taskStacker{created: created})
+
+	err := New("async")
+	st := err.(*fundamental).StackTrace()
+	if got := fmt.Sprintf("%n", st[0]); got != "TestSetStacker" {
+		t.Errorf("first frame: got %q, want %q", got, "TestSetStacker")
+	}
+	if len(st) <= len(created) || st[len(st)-len(created)] != Frame(created[0]) {
+		t.Errorf("stack does not end with the creation frames: %v", st)
+	}
+}
+
+func TestStackCapture(t *testing.T) {
+	tests := []struct {
+		opts []ConfigOption
+		want func(n int) bool
+	}{
+		{nil, func(n int) bool { return n > 1 }},
+		{[]ConfigOption{LimitStacks(1, 0)}, func(n int) bool { return n == 1 }},
+		{[]ConfigOption{CaptureStacks(false)}, func(n int) bool { return n == 0 }},
+	}
+
+	for i, tt := range tests {
+		restore := NewConfig(tt.opts...).Apply()
+		st := WithCode(906701, "coded").(interface{ StackTrace() StackTrace }).StackTrace()
+		restore()
+		if !tt.want(len(st)) {
+			t.Errorf("test %d: got %d frames", i+1, len(st))
+		}
+	}
+
+	restore := NewConfig(LimitStacks(0, 1)).Apply()
+	st := stackCaptureHelper()
+	restore()
+	if got := fmt.Sprintf("%n", st[0]); got != "TestStackCapture" {
+		t.Errorf("skip: got origin %q, want %q", got, "TestStackCapture")
+	}
+
+	restore = NewConfig(CaptureStacks(false)).Apply()
+	got := fmt.Sprintf("%+v", Wrap(WithCode(906701, "coded"), "wrapped"))
+	restore()
+	if got == "" {
+		t.Errorf("%%+v without stacks: got an empty string")
+	}
+}
+
+func stackCaptureHelper() StackTrace {
+	return New("helper").(interface{ StackTrace() StackTrace }).StackTrace()
+}
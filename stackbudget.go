@@ -0,0 +1,145 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+)
+
+// The stacks of new errors are normally recorded in full. During incident
+// storms, when a service creates millions of errors per second, the
+// recorded stacks are what dominates the heap. A stack budget bounds it:
+// past the given number of full stacks per second, the stacks are stored
+// as a summary only, the top frame, where the error was created, and a hash
+// of the frames below it. Summaries of the same stack are shared, so that a
+// storm of errors created at the same place costs a single stack.
+
+var (
+	// stackBudget is the number of full stacks recorded per second, 0 for
+	// no limit. It is accessed atomically.
+	stackBudget int64
+
+	// budgetWindow is the second, in Unix time, the budget is spent on,
+	// and budgetUsed is the number of full stacks recorded during it.
+	budgetWindow int64
+	budgetUsed   int64
+
+	// summarizedStacks counts the stacks stored as a summary.
+	summarizedStacks uint64
+)
+
+// maxSummaries bounds the number of distinct summaries shared between
+// errors. Once reached, every summarized stack gets its own top frame.
+const maxSummaries = 4096
+
+// summaryKey identifies a summarized stack.
+type summaryKey struct {
+	top  uintptr
+	hash uint64
+}
+
+var (
+	summaryMux sync.Mutex
+	summaries  = map[summaryKey]*stack{}
+	// summaryHashes maps the shared summaries to the hash of the frames
+	// they stand for.
+	summaryHashes sync.Map // map[*stack]uint64
+)
+
+// SetStackBudget sets the number of full stacks recorded per second. The
+// stacks of the errors created beyond the budget are stored as a summary:
+// the top frame, printed by %+v and returned by StackTrace, and a hash of
+// the other frames, see StackSummary. A budget lower than 1 records every
+// stack in full, which is the default.
+func SetStackBudget(perSecond int) {
+	if perSecond < 0 {
+		perSecond = 0
+	}
+	atomic.StoreInt64(&stackBudget, int64(perSecond))
+}
+
+func stackBudgetNow() int {
+	return int(atomic.LoadInt64(&stackBudget))
+}
+
+// SummarizedStacks returns how many stacks have been stored as a summary
+// since the process started, see SetStackBudget.
+func SummarizedStacks() uint64 {
+	return atomic.LoadUint64(&summarizedStacks)
+}
+
+// StackSummary returns the summary of the innermost stack of err's chain,
+// the frame where the error was created and the hash of the frames below
+// it, if the stack was stored as a summary, see SetStackBudget.
+// Once 4096 distinct summaries are shared, the later ones cannot be told
+// from stacks of a single frame, and StackSummary reports false for them.
+func StackSummary(err error) (top Frame, hash uint64, ok bool) {
+	s := innermostStack(list(err))
+	if s == nil {
+		return 0, 0, false
+	}
+	v, ok := summaryHashes.Load(s)
+	if !ok {
+		return 0, 0, false
+	}
+	return Frame((*s)[0]), v.(uint64), true
+}
+
+// spendStack reports whether a full stack may be recorded within the
+// budget of the current second.
+func spendStack() bool {
+	budget := atomic.LoadInt64(&stackBudget)
+	if budget == 0 {
+		return true
+	}
+	sec := now().Unix()
+	if w := atomic.LoadInt64(&budgetWindow); w != sec && atomic.CompareAndSwapInt64(&budgetWindow, w, sec) {
+		atomic.StoreInt64(&budgetUsed, 0)
+	}
+	return atomic.AddInt64(&budgetUsed, 1) <= budget
+}
+
+// summarize returns the summary of the stack st, shared with the errors
+// created at the same place.
+func summarize(st stack) *stack {
+	atomic.AddUint64(&summarizedStacks, 1)
+	if len(st) == 0 {
+		return new(stack)
+	}
+
+	h := fnv.New64a()
+	var buf [8]byte
+	for _, pc := range st[1:] {
+		for i := range buf {
+			buf[i] = byte(pc >> (8 * i))
+		}
+		h.Write(buf[:])
+	}
+	key := summaryKey{top: st[0], hash: h.Sum64()}
+
+	summaryMux.Lock()
+	defer summaryMux.Unlock()
+	if s, ok := summaries[key]; ok {
+		return s
+	}
+	s := &stack{key.top}
+	if len(summaries) < maxSummaries {
+		summaries[key] = s
+		summaryHashes.Store(s, key.hash)
+	}
+	return s
+}
@@ -0,0 +1,63 @@
+package errors
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStackBudget(t *testing.T) {
+	sec := time.Unix(1700000000, 0)
+	restore := NewConfig(UseClock(ClockFunc(func() time.Time { return sec })), BudgetStacks(2)).Apply()
+	defer restore()
+
+	create := func() error { return WithCode(911601, "storm") }
+	before := SummarizedStacks()
+
+	var errs []error
+	for i := 0; i < 5; i++ {
+		errs = append(errs, create())
+	}
+	for i, err := range errs {
+		_, _, summarized := StackSummary(err)
+		if want := i >= 2; summarized != want {
+			t.Errorf("error #%d: summarized: got %v, want %v", i, summarized, want)
+		}
+		if st := err.(interface{ StackTrace() StackTrace }).StackTrace(); len(st) == 0 {
+			t.Errorf("error #%d: StackTrace(): got no frame, want at least one", i)
+		}
+	}
+	if got := SummarizedStacks() - before; got != 3 {
+		t.Errorf("SummarizedStacks(): got %d more, want %d more", got, 3)
+	}
+
+	full := stackOf(errs[0])
+	top, hash, _ := StackSummary(errs[2])
+	if top != Frame((*full)[0]) {
+		t.Errorf("StackSummary(): got top %v, want %v", top, Frame((*full)[0]))
+	}
+	if hash == 0 {
+		t.Errorf("StackSummary(): got a zero hash")
+	}
+	if stackOf(errs[2]) != stackOf(errs[3]) {
+		t.Errorf("summaries of the same stack: got distinct stacks, want a shared one")
+	}
+
+	sec = sec.Add(time.Second)
+	if _, _, summarized := StackSummary(create()); summarized {
+		t.Errorf("next second: got a summary, want a full stack")
+	}
+}
+
+func TestStackBudgetUnlimited(t *testing.T) {
+	restore := NewConfig(BudgetStacks(0)).Apply()
+	defer restore()
+
+	for i := 0; i < 100; i++ {
+		if _, _, summarized := StackSummary(New("full")); summarized {
+			t.Fatalf("error #%d: got a summary, want a full stack", i)
+		}
+	}
+	if _, _, ok := StackSummary(nil); ok {
+		t.Errorf("StackSummary(nil): got true, want false")
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import "sync/atomic"
+
+// dedupStacks is 1 when the wrappers skip the capture of a stack already
+// recorded by the chain they wrap, see SetStackDedup.
+var dedupStacks int32
+
+// SetStackDedup enables or disables the deduplication of the stacks of
+// wrapped errors. Once enabled, Wrap, Wrapf, WithStack and Wrapc do not
+// record a new stack trace when the chain they wrap already holds one: Wrap
+// and Wrapf only annotate err with the message and their call site, and
+// WithStack returns err unchanged. The detailed format %+v then prints the
+// messages of the chain followed by its deepest stack, instead of one
+// nearly identical stack per wrapping layer.
+// It is disabled by default.
+func SetStackDedup(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&dedupStacks, v)
+}
+
+func stackDedupNow() bool {
+	return atomic.LoadInt32(&dedupStacks) == 1
+}
+
+// hasStack reports whether the stack of a wrapper of err is a duplicate
+// which is not recorded, see SetStackDedup.
+func hasStack(err error) bool {
+	return stackDedupNow() && innermostStack(list(err)) != nil
+}
@@ -0,0 +1,80 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func init() {
+	Register(NewCoder(912601, http.StatusInternalServerError, "load failed", ""))
+}
+
+func TestStackDedup(t *testing.T) {
+	restore := NewConfig(DedupStacks(true)).Apply()
+	defer restore()
+
+	root := New("boom")
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"Wrap", Wrap(root, "read")},
+		{"Wrapf", Wrapf(root, "read %d", 1)},
+		{"WithStack", WithStack(root)},
+		{"Wrapc", Wrapc(root, 912601, "load")},
+		{"nested", Wrap(Wrapc(Wrap(root, "read"), 912601, "load"), "serve")},
+	}
+
+	for _, tt := range tests {
+		errs := list(tt.err)
+		stacks := 0
+		for _, e := range errs {
+			if stackOf(e) != nil {
+				stacks++
+			}
+		}
+		if stacks != 1 {
+			t.Errorf("%s: stacks: got %d, want 1", tt.name, stacks)
+		}
+		if got := innermostStack(errs); got != root.(*fundamental).stack {
+			t.Errorf("%s: stack: got %v, want the stack of the root", tt.name, got)
+		}
+	}
+
+	if err := WithStack(root); err != root {
+		t.Errorf("WithStack(): got %v, want the wrapped error", err)
+	}
+	if w, ok := Wrap(root, "read").(*withMessage); !ok || w.pc == 0 {
+		t.Errorf("Wrap(): got %#v, want a message recording its call site", w)
+	}
+}
+
+func TestStackDedupFormat(t *testing.T) {
+	err := Wrap(Wrap(New("boom"), "read"), "serve")
+
+	restore := NewConfig(DedupStacks(true)).Apply()
+	defer restore()
+
+	out := fmt.Sprintf("%+v", err)
+	if got := strings.Count(out, "TestStackDedupFormat"); got != 1 {
+		t.Errorf("%%+v: got %d stacks, want 1:\n%s", got, out)
+	}
+	for _, msg := range []string{"boom", "read", "serve"} {
+		if !strings.Contains(out, msg) {
+			t.Errorf("%%+v: got %q, want the message %q", out, msg)
+		}
+	}
+}
+
+func TestStackDedupDisabled(t *testing.T) {
+	err := Wrap(io.EOF, "read")
+	if stackOf(err) == nil {
+		t.Errorf("Wrap(): got no stack, want one")
+	}
+	if stackOf(Wrap(err, "serve")) == nil {
+		t.Errorf("Wrap() of a stack carrying error: got no stack, want one")
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// StackFilter reports whether a frame is stripped from the printed stack
+// traces. Filters are added with AddStackFilter.
+type StackFilter func(f Frame) bool
+
+var (
+	stackFiltersMux sync.Mutex
+
+	// stackFilters holds the []StackFilter applied to the stacks. It is
+	// replaced on every change, so that formatting reads it without
+	// locking.
+	stackFilters atomic.Value
+)
+
+// AddStackFilter adds a filter stripping the frames for which it returns
+// true from the output of %+v, from StackTrace and from the stacks of the
+// JSON and Describe outputs:
+//
+//	errors.AddStackFilter(errors.StripRuntime)
+//	errors.AddStackFilter(errors.StripPackages("net/http", "github.com/acme/middleware"))
+//
+// Filters apply when the stacks are rendered, not when they are captured,
+// so they affect the errors created before they were added too. The
+// innermost frame is kept when every frame of a stack is stripped, so that
+// the origin of an error is never lost.
+func AddStackFilter(f StackFilter) {
+	if f == nil {
+		return
+	}
+	stackFiltersMux.Lock()
+	defer stackFiltersMux.Unlock()
+
+	prev := stackFiltersNow()
+	updated := make([]StackFilter, 0, len(prev)+1)
+	updated = append(updated, prev...)
+	updated = append(updated, f)
+	stackFilters.Store(updated)
+}
+
+// ClearStackFilters removes the filters added with AddStackFilter.
+func ClearStackFilters() {
+	stackFiltersMux.Lock()
+	defer stackFiltersMux.Unlock()
+	stackFilters.Store([]StackFilter(nil))
+}
+
+func stackFiltersNow() []StackFilter {
+	fs, _ := stackFilters.Load().([]StackFilter)
+	return fs
+}
+
+// StripRuntime strips the frames of the Go runtime, such as runtime.goexit
+// and runtime.main.
+func StripRuntime(f Frame) bool {
+	return funcPackage(f.name()) == "runtime"
+}
+
+// StripTesting strips the frames of the testing package, such as
+// testing.tRunner.
+func StripTesting(f Frame) bool {
+	return funcPackage(f.name()) == "testing"
+}
+
+// StripVendored strips the frames of the packages vendored in a vendor
+// directory.
+func StripVendored(f Frame) bool {
+	pkg := funcPackage(f.name())
+	return strings.HasPrefix(pkg, "vendor/") || strings.Contains(pkg, "/vendor/")
+}
+
+// StripPackages returns a filter stripping the frames of the given packages
+// and of the packages below them, e.g. StripPackages("net/http") strips
+// net/http and net/http/httputil but not net/httpx.
+func StripPackages(prefixes ...string) StackFilter {
+	return func(f Frame) bool {
+		pkg := funcPackage(f.name())
+		for _, p := range prefixes {
+			if pkg == p || strings.HasPrefix(pkg, p+"/") {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// frames returns the program counters of s which are not stripped by the
+// stack filters.
+func (s *stack) frames() []uintptr {
+	if s == nil {
+		return nil
+	}
+	filters := stackFiltersNow()
+	if len(filters) == 0 || len(*s) == 0 {
+		return *s
+	}
+
+	ret := make([]uintptr, 0, len(*s))
+next:
+	for _, pc := range *s {
+		for _, filter := range filters {
+			if filter(Frame(pc)) {
+				continue next
+			}
+		}
+		ret = append(ret, pc)
+	}
+	if len(ret) == 0 {
+		ret = append(ret, (*s)[0])
+	}
+	return ret
+}
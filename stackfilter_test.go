@@ -0,0 +1,86 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func stackFunctions(st StackTrace) []string {
+	var ret []string
+	for _, f := range st {
+		ret = append(ret, f.name())
+	}
+	return ret
+}
+
+func TestAddStackFilter(t *testing.T) {
+	restore := NewConfig().Apply()
+	defer restore()
+
+	err := New("filtered")
+	st := err.(*fundamental).StackTrace()
+
+	AddStackFilter(StripRuntime)
+	AddStackFilter(StripTesting)
+	filtered := err.(*fundamental).StackTrace()
+	if len(filtered) >= len(st) {
+		t.Fatalf("filtered frames: got %d, want less than %d", len(filtered), len(st))
+	}
+	for _, fn := range stackFunctions(filtered) {
+		if strings.HasPrefix(fn, "runtime.") || strings.HasPrefix(fn, "testing.") {
+			t.Errorf("filtered stack: got frame %s", fn)
+		}
+	}
+	if got := fmt.Sprintf("%+v", err); strings.Contains(got, "testing.tRunner") {
+		t.Errorf("%%+v: got testing.tRunner in %q", got)
+	}
+
+	ClearStackFilters()
+	if got := err.(*fundamental).StackTrace(); len(got) != len(st) {
+		t.Errorf("cleared filters: got %d frames, want %d", len(got), len(st))
+	}
+}
+
+func TestStripPackages(t *testing.T) {
+	tests := []struct {
+		prefixes []string
+		want     bool
+	}{
+		{[]string{"github.com/rtmzk/errors"}, true},
+		{[]string{"github.com/rtmzk"}, true},
+		{[]string{"github.com/rtmzk/err"}, false},
+		{[]string{"net/http", "github.com/rtmzk/errors"}, true},
+		{nil, false},
+	}
+
+	f := Frame((*New("frame").(*fundamental).stack)[0])
+	for _, tt := range tests {
+		if got := StripPackages(tt.prefixes...)(f); got != tt.want {
+			t.Errorf("StripPackages(%q)(%s): got %v, want %v", tt.prefixes, f.name(), got, tt.want)
+		}
+	}
+}
+
+func TestStackFilterKeepsInnermostFrame(t *testing.T) {
+	restore := NewConfig(FilterStacks(func(Frame) bool { return true })).Apply()
+	defer restore()
+
+	err := New("all stripped")
+	raw := *err.(*fundamental).stack
+	got := err.(*fundamental).StackTrace()
+	if len(got) != 1 || uintptr(got[0]) != raw[0] {
+		t.Errorf("all frames stripped: got %v, want the innermost frame", got)
+	}
+}
+
+func TestFilterStacksRestore(t *testing.T) {
+	restore := NewConfig(FilterStacks(StripRuntime)).Apply()
+	if got := len(stackFiltersNow()); got != 1 {
+		t.Errorf("applied filters: got %d, want 1", got)
+	}
+	restore()
+	if got := len(stackFiltersNow()); got != 0 {
+		t.Errorf("restored filters: got %d, want 0", got)
+	}
+}
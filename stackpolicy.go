@@ -0,0 +1,61 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// stackPolicy holds the function selecting the codes whose errors record a
+// stack, see SetStackPolicyFunc.
+var stackPolicy atomic.Value // stackPolicyFunc
+
+// stackPolicyFunc wraps the policy, since atomic.Value cannot hold a nil
+// function.
+type stackPolicyFunc struct {
+	fn func(code int) bool
+}
+
+// SetStackPolicyFunc sets the function reporting whether the coded errors
+// with the given code record a stack trace, e.g. ServerErrorStacks so that
+// the stacks of the client errors, which dominate the traffic of most
+// services, are not captured. It applies to every constructor of coded
+// errors, such as WithCode and Wrapc; the errors without a code always
+// record their stack, see SetCaptureStacks. A nil fn, the default, records
+// the stacks of every code.
+func SetStackPolicyFunc(fn func(code int) bool) {
+	stackPolicy.Store(stackPolicyFunc{fn})
+}
+
+func stackPolicyNow() func(code int) bool {
+	p, _ := stackPolicy.Load().(stackPolicyFunc)
+	return p.fn
+}
+
+// stackPolicyAllows reports whether the stack of a coded error with the
+// given code is recorded.
+func stackPolicyAllows(code int) bool {
+	fn := stackPolicyNow()
+	return fn == nil || fn(code)
+}
+
+// ServerErrorStacks is a stack policy recording the stacks of the codes
+// whose HTTP status is a server error, 5xx, and of the codes which are not
+// registered, see SetStackPolicyFunc.
+func ServerErrorStacks(code int) bool {
+	coder, ok := GetCoder(code)
+	return !ok || coder.HTTPStatus() >= http.StatusInternalServerError
+}
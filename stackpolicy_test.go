@@ -0,0 +1,48 @@
+package errors
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+func init() {
+	Register(NewCoder(913401, http.StatusBadRequest, "invalid name", ""))
+	Register(NewCoder(913402, http.StatusServiceUnavailable, "store unavailable", ""))
+}
+
+func TestStackPolicy(t *testing.T) {
+	restore := NewConfig(UseStackPolicy(ServerErrorStacks)).Apply()
+	defer restore()
+
+	tests := []struct {
+		name  string
+		err   error
+		stack bool
+	}{
+		{"WithCode 4xx", WithCode(913401, "bad name"), false},
+		{"WithCode 5xx", WithCode(913402, "down"), true},
+		{"WithCode unregistered", WithCode(913499, "unknown"), true},
+		{"Wrapc 4xx", Wrapc(io.EOF, 913401, "bad name"), false},
+		{"Wrapc 5xx", Wrapc(io.EOF, 913402, "down"), true},
+		{"NewCoded 4xx", NewCoded(913401, "bad name"), false},
+		{"WrapWithCode 4xx", WrapWithCode(io.EOF, 913401, "bad name"), false},
+		{"New", New("boom"), true},
+	}
+
+	for _, tt := range tests {
+		if got := stackOf(tt.err) != nil; got != tt.stack {
+			t.Errorf("%s: stack recorded: got %v, want %v", tt.name, got, tt.stack)
+		}
+	}
+}
+
+func TestStackPolicyDefault(t *testing.T) {
+	err := WithCode(913401, "bad name")
+	if stackOf(err) == nil {
+		t.Errorf("WithCode(): got no stack, want one without a policy")
+	}
+	if _, _, fn, ok := Location(err); !ok || fn != "github.com/rtmzk/errors.TestStackPolicyDefault" {
+		t.Errorf("Location(): got %q, want the caller of WithCode", fn)
+	}
+}
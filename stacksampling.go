@@ -0,0 +1,128 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// stackSampling is the number of coded errors of a code among which one
+// records a stack, or 0 to record them all, see SetStackSampling.
+var stackSampling int64
+
+// sampleCounters counts the coded errors created for each code while the
+// stacks are sampled, and suppressedCounters those whose stack has been
+// suppressed since the last sample of their code. They are created lazily
+// and only removed by SetStackSampling.
+var (
+	sampleCounters     sync.Map // map[int]*uint64
+	suppressedCounters sync.Map // map[int]*uint64
+)
+
+// pendingSamples holds the number of suppressed stacks of the stacks just
+// sampled, until created attaches it to their error.
+var pendingSamples sync.Map // map[*stack]uint64
+
+// SetStackSampling records the stacks of a fraction of the coded errors of
+// each code, e.g. 0.01 for one in a hundred, so that error storms do not
+// spend their CPU capturing and printing identical stacks. The other errors
+// are created without a stack, and each sampled error carries a
+// StackSample detail counting the errors of its code created without a
+// stack since the previous sample. A rate lower than or equal to 0, or
+// greater than or equal to 1, the default, records every stack.
+// The errors without a code always record their stack. Setting the rate
+// restarts the sampling of every code.
+func SetStackSampling(rate float64) {
+	var every int64
+	if rate > 0 && rate < 1 {
+		every = int64(math.Round(1 / rate))
+	}
+	if every == 1 {
+		every = 0
+	}
+	atomic.StoreInt64(&stackSampling, every)
+	for _, m := range []*sync.Map{&sampleCounters, &suppressedCounters, &pendingSamples} {
+		m.Range(func(k, _ interface{}) bool {
+			m.Delete(k)
+			return true
+		})
+	}
+}
+
+// stackSamplingNow returns the sampling rate set by SetStackSampling.
+func stackSamplingNow() float64 {
+	every := atomic.LoadInt64(&stackSampling)
+	if every == 0 {
+		return 1
+	}
+	return 1 / float64(every)
+}
+
+// StackSample is a Detail attached to a coded error whose stack has been
+// sampled, see SetStackSampling.
+type StackSample struct {
+	// Suppressed is the number of errors of the same code created without
+	// a stack since the previous sample.
+	Suppressed uint64 `json:"suppressed"`
+}
+
+// DetailType implements Detail.
+func (StackSample) DetailType() string { return "stack_sample" }
+
+// sampleStack reports whether the stack of a new coded error with the given
+// code is recorded, counting it as suppressed otherwise.
+func sampleStack(code int) bool {
+	every := atomic.LoadInt64(&stackSampling)
+	if every == 0 {
+		return true
+	}
+	if atomic.AddUint64(codeCounter(&sampleCounters, code), 1)%uint64(every) == 1 {
+		return true
+	}
+	atomic.AddUint64(codeCounter(&suppressedCounters, code), 1)
+	return false
+}
+
+// noteSample records the number of stacks of code suppressed before st.
+func noteSample(code int, st *stack) {
+	if atomic.LoadInt64(&stackSampling) == 0 || st == nil {
+		return
+	}
+	if n := atomic.SwapUint64(codeCounter(&suppressedCounters, code), 0); n > 0 {
+		pendingSamples.Store(st, n)
+	}
+}
+
+// attachSample attaches the StackSample detail of the stack of w, if it has
+// been sampled after suppressed ones.
+func attachSample(w *withCode) {
+	if w.stack == nil || atomic.LoadInt64(&stackSampling) == 0 {
+		return
+	}
+	if n, ok := pendingSamples.LoadAndDelete(w.stack); ok {
+		w.details = append(append([]Detail(nil), w.details...), StackSample{Suppressed: n.(uint64)})
+	}
+}
+
+// codeCounter returns the counter of code in m, creating it if needed.
+func codeCounter(m *sync.Map, code int) *uint64 {
+	v, ok := m.Load(code)
+	if !ok {
+		v, _ = m.LoadOrStore(code, new(uint64))
+	}
+	return v.(*uint64)
+}
@@ -0,0 +1,59 @@
+package errors
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestStackSampling(t *testing.T) {
+	restore := NewConfig(SampleStacks(0.25)).Apply()
+	defer restore()
+
+	var errs []error
+	for i := 0; i < 9; i++ {
+		errs = append(errs, Wrapc(io.EOF, 913801, "storm"))
+	}
+
+	for i, err := range errs {
+		if got, want := stackOf(err) != nil, i%4 == 0; got != want {
+			t.Errorf("error #%d: stack recorded: got %v, want %v", i, got, want)
+		}
+		var want []Detail
+		if i > 0 && i%4 == 0 {
+			want = []Detail{StackSample{Suppressed: 3}}
+		}
+		if got := Details(err); !reflect.DeepEqual(got, want) {
+			t.Errorf("error #%d: details: got %v, want %v", i, got, want)
+		}
+	}
+
+	if stackOf(WithCode(913802, "other code")) == nil {
+		t.Errorf("first error of another code: got no stack, want one")
+	}
+	if stackOf(New("uncoded")) == nil {
+		t.Errorf("uncoded error: got no stack, want one")
+	}
+}
+
+func TestStackSamplingRate(t *testing.T) {
+	tests := []struct {
+		rate float64
+		want float64
+	}{
+		{0.01, 0.01},
+		{0.3, 1.0 / 3},
+		{0, 1},
+		{1, 1},
+		{2, 1},
+	}
+
+	for _, tt := range tests {
+		restore := NewConfig(SampleStacks(tt.rate)).Apply()
+		got := stackSamplingNow()
+		restore()
+		if got != tt.want {
+			t.Errorf("SampleStacks(%v): got rate %v, want %v", tt.rate, got, tt.want)
+		}
+	}
+}
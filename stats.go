@@ -0,0 +1,81 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import "reflect"
+
+// registryEntryOverhead is the estimated cost in bytes of a registry entry
+// besides its Coder: the map or shard slot, the interface header and the
+// origin of the code.
+const registryEntryOverhead = 64
+
+// RegistryStats describes the size of the registry, see Stats.
+type RegistryStats struct {
+	// Codes is the number of registered codes, the unknown code excluded.
+	Codes int
+
+	// Bytes is an estimate of the memory held by the registered coders:
+	// their values, messages, references, translations and remediation
+	// steps, plus the cost of the registry entries.
+	Bytes int
+
+	// Domains is the number of codes by domain, see WithDomain. The codes
+	// without a domain are counted under "".
+	Domains map[string]int
+
+	// PendingCatalogs is the number of catalogs registered with
+	// RegisterCatalogLazily which are not loaded yet.
+	PendingCatalogs int
+}
+
+// Stats returns the size of the registry, e.g. to audit the cost of the
+// catalogs of a binary. It does not load the pending lazy catalogs.
+func Stats() RegistryStats {
+	codeMux.RLock()
+	all := codes.list()
+	codeMux.RUnlock()
+
+	stats := RegistryStats{
+		Domains:         map[string]int{},
+		PendingCatalogs: pendingCatalogs(),
+	}
+	for _, coder := range all {
+		if coder.Code() == unknownCoder.Code() {
+			continue
+		}
+		stats.Codes++
+		stats.Bytes += coderSize(coder)
+		domain, _ := DomainOf(coder)
+		stats.Domains[domain]++
+	}
+	return stats
+}
+
+// coderSize estimates the memory held by coder.
+func coderSize(coder Coder) int {
+	size := registryEntryOverhead + int(reflect.TypeOf(coder).Size())
+	size += len(coder.String()) + len(coder.Reference())
+	if msgs, ok := MessagesOf(coder); ok {
+		for lang, msg := range msgs {
+			size += len(lang) + len(msg)
+		}
+	}
+	if steps, ok := RemediationOf(coder); ok {
+		for _, step := range steps {
+			size += len(step)
+		}
+	}
+	return size
+}
@@ -0,0 +1,81 @@
+package errors
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestStats(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	Register(NewCoder(910001, 404, "user not found", "", WithDomain("users")))
+	Register(NewCoder(910002, 409, "user exists", "", WithDomain("users")))
+	Register(NewCoder(910003, 500, "internal", ""))
+
+	stats := Stats()
+	if stats.Codes != 3 {
+		t.Errorf("Codes: got %d, want 3", stats.Codes)
+	}
+	if stats.Domains["users"] != 2 || stats.Domains[""] != 1 {
+		t.Errorf("Domains: got %v, want users:2 and :1", stats.Domains)
+	}
+	if min := 3 * registryEntryOverhead; stats.Bytes < min {
+		t.Errorf("Bytes: got %d, want at least %d", stats.Bytes, min)
+	}
+
+	Register(NewCoder(910004, 500, "internal", "", WithMessages(map[string]string{"fr": "erreur interne"})))
+	if got := Stats().Bytes; got <= stats.Bytes {
+		t.Errorf("Bytes after a registration: got %d, want more than %d", got, stats.Bytes)
+	}
+}
+
+func TestRegisterCatalogLazily(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	Register(NewCoder(910012, 400, "explicit", ""))
+	fsys := fstest.MapFS{"codes.json": {Data: []byte(`[
+		{"code": 910011, "http_status": 404, "message": "from catalog", "domain": "lazy"},
+		{"code": 910012, "http_status": 400, "message": "from catalog"}
+	]`)}}
+	RegisterCatalogLazily(fsys, "codes.json")
+
+	if got := Stats(); got.PendingCatalogs != 1 || got.Codes != 1 {
+		t.Errorf("Stats before use: got %+v, want 1 pending catalog and 1 code", got)
+	}
+
+	coder := ParseCoder(WithCode(910011, "lookup"))
+	if coder.Code() != 910011 || coder.String() != "from catalog" {
+		t.Errorf("lazy coder: got %d %q, want 910011 %q", coder.Code(), coder.String(), "from catalog")
+	}
+	if got, _ := GetCoder(910012); got.String() != "explicit" {
+		t.Errorf("explicit coder: got %q, want it to win over the catalog", got.String())
+	}
+	if got := Stats(); got.PendingCatalogs != 0 || got.Codes != 2 || got.Domains["lazy"] != 1 {
+		t.Errorf("Stats after use: got %+v, want no pending catalog and 2 codes", got)
+	}
+}
+
+func TestRegisterCatalogLazilyInvalid(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("missing catalog: got no panic, want one")
+			}
+		}()
+		RegisterCatalogLazily(fstest.MapFS{}, "missing.json")
+	}()
+
+	RegisterCatalogLazily(fstest.MapFS{"corrupt.json": {Data: []byte(`[{"code": 910021,`)}}, "corrupt.json")
+	if _, ok := GetCoder(910021); ok {
+		t.Errorf("GetCoder(910021): got true, want false for a corrupt catalog")
+	}
+	if err := LoadCatalogs(); err == nil || !strings.Contains(err.Error(), "load catalog corrupt.json") {
+		t.Errorf("LoadCatalogs(): got %v, want the corrupt catalog reported", err)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// Trailers carrying the coded error of a streaming response.
+const (
+	TrailerErrorCode    = "X-Error-Code"
+	TrailerErrorMessage = "X-Error-Message"
+)
+
+// WriteTrailers reports err through the HTTP trailers of a response whose
+// headers have already been sent, such as a chunked or streaming response.
+// The trailers are set with the http.TrailerPrefix convention, so they do
+// not need to be declared before the body is written. They are sent once
+// the handler returns. Nothing is set if err is nil.
+func WriteTrailers(w http.ResponseWriter, err error) {
+	if err == nil {
+		return
+	}
+	coder := ServedCoder(err)
+	h := w.Header()
+	h.Set(http.TrailerPrefix+TrailerErrorCode, strconv.Itoa(coder.Code()))
+	h.Set(http.TrailerPrefix+TrailerErrorMessage, coder.String())
+}
+
+// streamRecord is the terminal record written by WriteStreamError.
+type streamRecord struct {
+	Error responseBody `json:"error"`
+}
+
+// WriteStreamError appends a terminal NDJSON record describing err to a
+// streaming response, for clients which cannot read trailers:
+//
+//	{"error":{"code":100101,"message":"...","reference":"..."}}
+//
+// The record is written on its own line and flushed if w is an
+// http.Flusher. Nothing is written if err is nil.
+func WriteStreamError(w io.Writer, err error) error {
+	if err == nil {
+		return nil
+	}
+	coder := ServedCoder(err)
+	if encErr := json.NewEncoder(w).Encode(streamRecord{Error: responseBody{
+		Code:      coder.Code(),
+		Message:   coder.String(),
+		Reference: coder.Reference(),
+	}}); encErr != nil {
+		return encErr
+	}
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
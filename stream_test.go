@@ -0,0 +1,56 @@
+package errors
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteTrailers(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(903201, 500, "stream broken", ""))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "partial")
+		w.(http.Flusher).Flush()
+		WriteTrailers(w, WithCode(903201, "failed"))
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	io.ReadAll(resp.Body)
+
+	if got := resp.Trailer.Get(TrailerErrorCode); got != "903201" {
+		t.Errorf("trailer %s: got %q, want %q", TrailerErrorCode, got, "903201")
+	}
+	if got := resp.Trailer.Get(TrailerErrorMessage); got != "stream broken" {
+		t.Errorf("trailer %s: got %q, want %q", TrailerErrorMessage, got, "stream broken")
+	}
+}
+
+func TestWriteStreamError(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(903202, 500, "stream broken", "https://example.com/903202"))
+
+	var buf bytes.Buffer
+	if err := WriteStreamError(&buf, nil); err != nil || buf.Len() != 0 {
+		t.Errorf("WriteStreamError(nil): got (%v, %q), want (nil, \"\")", err, buf.String())
+	}
+	if err := WriteStreamError(&buf, WithCode(903202, "failed")); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"error":{"code":903202,"message":"stream broken","reference":"https://example.com/903202"}}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteStreamError: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// StrictMode selects what happens when a coded error is created with a
+// code which is not registered, and when a coder without an owner is
+// registered, see WithOwner. It is meant for development and tests, to
+// catch typos in codes before they surface as the unknown coder.
+type StrictMode int
+
+const (
+	// StrictOff accepts unregistered codes silently. It is the default.
+	StrictOff StrictMode = iota
+
+	// StrictLog logs unregistered codes and coders without an owner at
+	// error level with log/slog.
+	StrictLog
+
+	// StrictPanic panics on unregistered codes, and rejects coders
+	// without an owner at registration.
+	StrictPanic
+)
+
+// strictMode is the current StrictMode.
+var strictMode = StrictOff
+
+// SetStrictMode sets what happens when a coded error is created with an
+// unregistered code, or when a coder without an owner is registered.
+func SetStrictMode(mode StrictMode) {
+	strictMode = mode
+}
+
+// checkOwner enforces the strict mode for the owner of a registered
+// coder: in strict mode, every registered coder must have an owner, see
+// WithOwner.
+func checkOwner(coder Coder) *RegistrationError {
+	if strictMode == StrictOff {
+		return nil
+	}
+	if _, ok := OwnerOf(coder); ok {
+		return nil
+	}
+	if strictMode == StrictPanic {
+		return &RegistrationError{Code: coder.Code(), Err: ErrNoOwner}
+	}
+	slog.Error(fmt.Sprintf("errors: code %d has no owner", coder.Code()), "code", coder.Code())
+	return nil
+}
+
+// checkRegistered enforces the strict mode for the given code, used at pc.
+func checkRegistered(code int, pc uintptr) {
+	if strictMode == StrictOff {
+		return
+	}
+	ensureCatalogs()
+	if _, ok := lookupCoder(code); ok {
+		return
+	}
+	reportUnregistered(code, pc)
+}
+
+// reportUnregistered enforces the strict mode for the given code, used at
+// pc and known not to be registered.
+func reportUnregistered(code int, pc uintptr) {
+	if strictMode == StrictOff {
+		return
+	}
+	msg := fmt.Sprintf("errors: code %d is not registered", code)
+	if strictMode == StrictPanic {
+		panic(msg)
+	}
+	// Report the caller of WithCode or Wrapc.
+	var caller string
+	if pc != 0 {
+		f := Frame(pc)
+		caller = fmt.Sprintf("%s:%d", f.file(), f.line())
+	}
+	slog.Error(msg, "code", code, "caller", caller)
+}
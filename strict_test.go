@@ -0,0 +1,101 @@
+package errors
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestStrictMode(t *testing.T) {
+	Register(defaultCoder{C: 902701, HTTP: 400})
+	defer SetStrictMode(StrictOff)
+
+	SetStrictMode(StrictPanic)
+	_ = WithCode(902701, "registered")
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil || !strings.Contains(r.(string), "902710") {
+				t.Errorf("WithCode(unregistered) in StrictPanic: got %v, want a panic", r)
+			}
+		}()
+		_ = WithCode(902710, "typo")
+	}()
+
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer slog.SetDefault(prev)
+
+	SetStrictMode(StrictLog)
+	_ = Wrapc(New("cause"), 902710, "typo")
+	if got := buf.String(); !strings.Contains(got, "code=902710") || !strings.Contains(got, "strict_test.go") {
+		t.Errorf("Wrapc(unregistered) in StrictLog: got log %q", got)
+	}
+}
+
+func TestStrictConstructors(t *testing.T) {
+	SetStrictMode(StrictPanic)
+	defer SetStrictMode(StrictOff)
+
+	ctx := WithDefaultCode(context.Background(), 915201)
+	tests := map[string]func(){
+		"Ensure":        func() { Ensure(ctx, New("cause")) },
+		"Invariant":     func() { Invariant(false, 915201, "broken") },
+		"NewCoded":      func() { NewCoded(915201, "typo") },
+		"WrapArgs":      func() { WrapArgs(New("cause"), 915201, "typo") },
+		"Translate":     func() { Translate(New("cause"), 915201, "typo") },
+		"Unimplemented": func() { Unimplemented(915201, "export") },
+	}
+	for name, fn := range tests {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("%s(unregistered) in StrictPanic: got no panic", name)
+				}
+			}()
+			fn()
+		}()
+	}
+	_ = FromPanic("boom")
+	_ = Ensuref(false, "broken")
+}
+
+func TestStrictOwner(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend()), UseStrictMode(StrictPanic)).Apply()
+	defer restore()
+
+	if err := RegisterE(NewCoder(910501, 500, "owned", "", WithOwner("team-storage"))); err != nil {
+		t.Errorf("RegisterE(owned): got %v, want nil", err)
+	}
+	if err := RegisterE(NewCoder(910502, 500, "orphan", "")); !Is(err, ErrNoOwner) {
+		t.Errorf("RegisterE(orphan): got %v, want %v", err, ErrNoOwner)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r == nil || !strings.Contains(r.(string), "910503 has no owner") {
+				t.Errorf("MustRegisterTable(orphan): got %v, want a panic", r)
+			}
+		}()
+		MustRegisterTable([]CoderSpec{{Code: 910503, HTTPStatus: 500, Message: "orphan"}})
+	}()
+	if info, _ := DescribeCode(910501); info.Owner != "team-storage" {
+		t.Errorf("DescribeCode: got owner %q, want %q", info.Owner, "team-storage")
+	}
+
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer slog.SetDefault(prev)
+
+	SetStrictMode(StrictLog)
+	MustRegister(NewCoder(910504, 500, "orphan", ""))
+	if got := buf.String(); !strings.Contains(got, "code=910504") {
+		t.Errorf("MustRegister(orphan) in StrictLog: got log %q", got)
+	}
+	if _, ok := GetCoder(910504); !ok {
+		t.Errorf("MustRegister(orphan) in StrictLog: code not registered")
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"crypto/sha256"
+	"debug/elf"
+	"debug/gosym"
+	"debug/macho"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+)
+
+// anchorName is the symbol of symbolAnchor, whose address relates the
+// program counters of a process to the symbol table of its binary, which
+// differ when the binary is position independent.
+const anchorName = "github.com/rtmzk/errors.symbolAnchor"
+
+//go:noinline
+func symbolAnchor() {}
+
+// PCStack is a stack of bare program counters, cheap to ship over the wire
+// to a central error collector which symbolizes it with the symbol table of
+// the producing binary, see Symbolizer.
+type PCStack struct {
+	// BuildID identifies the binary which captured the stack.
+	BuildID string `json:"build_id"`
+
+	// Anchor is the address of a known function in the capturing process.
+	Anchor uint64 `json:"anchor"`
+
+	// PCs are the program counters of the stack, innermost first.
+	PCs []uint64 `json:"pcs"`
+}
+
+// SymbolizedFrame is a frame of a PCStack resolved by a Symbolizer.
+type SymbolizedFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+var (
+	buildIDOnce sync.Once
+	buildID     string
+)
+
+// BuildID returns the identifier of the running binary, a hash of its
+// line table, or "" if the binary cannot be read.
+func BuildID() string {
+	buildIDOnce.Do(func() {
+		path, err := os.Executable()
+		if err != nil {
+			return
+		}
+		if t, err := LoadSymbols(path); err == nil {
+			buildID = t.BuildID()
+		}
+	})
+	return buildID
+}
+
+// CapturePCs returns the innermost stack recorded in err's chain as bare
+// program counters. It returns false if the chain has no stack.
+func CapturePCs(err error) (PCStack, bool) {
+	errs := list(err)
+	for i := len(errs) - 1; i >= 0; i-- {
+		s := stackOf(errs[i])
+		if s == nil {
+			continue
+		}
+		pcs := make([]uint64, len(*s))
+		for j, pc := range *s {
+			pcs[j] = uint64(pc)
+		}
+		return PCStack{
+			BuildID: BuildID(),
+			Anchor:  uint64(reflect.ValueOf(symbolAnchor).Pointer()),
+			PCs:     pcs,
+		}, true
+	}
+	return PCStack{}, false
+}
+
+// SymbolTable is the symbol table of a Go binary.
+type SymbolTable struct {
+	id     string
+	table  *gosym.Table
+	anchor uint64
+}
+
+// LoadSymbols reads the symbol table of the Go binary at path, an ELF or
+// Mach-O executable. No cgo is involved.
+func LoadSymbols(path string) (*SymbolTable, error) {
+	pclntab, text, err := readPclntab(path)
+	if err != nil {
+		return nil, err
+	}
+	table, err := gosym.NewTable(nil, gosym.NewLineTable(pclntab, text))
+	if err != nil {
+		return nil, Wrapf(err, "%s: invalid line table", path)
+	}
+
+	sum := sha256.Sum256(pclntab)
+	t := &SymbolTable{
+		id:    hex.EncodeToString(sum[:16]),
+		table: table,
+	}
+	if fn := table.LookupFunc(anchorName); fn != nil {
+		t.anchor = fn.Entry
+	}
+	return t, nil
+}
+
+// readPclntab returns the line table of the binary at path and the address
+// of its text section.
+func readPclntab(path string) ([]byte, uint64, error) {
+	if f, err := elf.Open(path); err == nil {
+		defer f.Close()
+		sect, text := f.Section(".gopclntab"), f.Section(".text")
+		if sect == nil || text == nil {
+			return nil, 0, Errorf("%s: no Go line table", path)
+		}
+		data, err := sect.Data()
+		return data, text.Addr, err
+	}
+	if f, err := macho.Open(path); err == nil {
+		defer f.Close()
+		sect, text := f.Section("__gopclntab"), f.Section("__text")
+		if sect == nil || text == nil {
+			return nil, 0, Errorf("%s: no Go line table", path)
+		}
+		data, err := sect.Data()
+		return data, text.Addr, err
+	}
+	return nil, 0, Errorf("%s: unsupported binary format", path)
+}
+
+// BuildID returns the identifier of the binary, matching the BuildID of the
+// stacks it captures.
+func (t *SymbolTable) BuildID() string { return t.id }
+
+// Symbolizer resolves stacks captured by other processes with the symbol
+// tables of their binaries, selected by build ID.
+type Symbolizer struct {
+	mu     sync.RWMutex
+	tables map[string]*SymbolTable
+}
+
+// NewSymbolizer returns a Symbolizer knowing the given symbol tables.
+func NewSymbolizer(tables ...*SymbolTable) *Symbolizer {
+	s := &Symbolizer{tables: map[string]*SymbolTable{}}
+	for _, t := range tables {
+		s.Add(t)
+	}
+	return s
+}
+
+// Add makes the symbol table t known to s.
+func (s *Symbolizer) Add(t *SymbolTable) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tables[t.id] = t
+}
+
+// Symbolize resolves the frames of st. It fails if the symbol table of the
+// binary which captured st is unknown. Frames which cannot be resolved are
+// reported as "unknown".
+func (s *Symbolizer) Symbolize(st PCStack) ([]SymbolizedFrame, error) {
+	s.mu.RLock()
+	t, ok := s.tables[st.BuildID]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, Errorf("no symbol table for build %q", st.BuildID)
+	}
+
+	var offset uint64
+	if t.anchor != 0 && st.Anchor != 0 {
+		offset = st.Anchor - t.anchor
+	}
+
+	frames := make([]SymbolizedFrame, 0, len(st.PCs))
+	for _, pc := range st.PCs {
+		// The program counters are return addresses, see Frame.pc.
+		file, line, fn := t.table.PCToLine(pc - offset - 1)
+		f := SymbolizedFrame{Function: "unknown", File: "unknown"}
+		if fn != nil {
+			f = SymbolizedFrame{Function: fn.Name, File: file, Line: line}
+		}
+		frames = append(frames, f)
+	}
+	return frames, nil
+}
+
+// String returns the frame as "function (file:line)".
+func (f SymbolizedFrame) String() string {
+	return fmt.Sprintf("%s (%s:%d)", f.Function, f.File, f.Line)
+}
@@ -0,0 +1,42 @@
+package errors
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSymbolizer(t *testing.T) {
+	path, err := os.Executable()
+	if err != nil {
+		t.Skip(err)
+	}
+	table, err := LoadSymbols(path)
+	if err != nil {
+		t.Skip(err)
+	}
+	if table.BuildID() != BuildID() {
+		t.Errorf("BuildID(): got %q, want %q", BuildID(), table.BuildID())
+	}
+
+	e := New("boom")
+	st, ok := CapturePCs(e)
+	if !ok || len(st.PCs) == 0 {
+		t.Fatalf("CapturePCs(): got (%v, %v), want a stack", st, ok)
+	}
+	if _, ok := CapturePCs(os.ErrNotExist); ok {
+		t.Errorf("CapturePCs(ErrNotExist): got true, want false")
+	}
+
+	frames, err := NewSymbolizer(table).Symbolize(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Frame((*stackOf(e))[0])
+	if got := frames[0]; got.Function != want.name() || got.File != want.file() || got.Line != want.line() {
+		t.Errorf("Symbolize()[0]: got %v, want %s (%s:%d)", got, want.name(), want.file(), want.line())
+	}
+
+	if _, err := NewSymbolizer().Symbolize(st); err == nil {
+		t.Errorf("Symbolize() without table: got nil, want an error")
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package syncerr gives codes to the failures of the golang.org/x/sync
+// primitives, so that the saturation of a worker pool or of a semaphore is
+// reported as a coded error naming the saturated resource, rather than as a
+// bare context error.
+package syncerr
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
+
+	"github.com/rtmzk/errors"
+)
+
+// Fields recorded on the saturation errors.
+const (
+	FieldResource = "resource"
+	FieldWeight   = "weight"
+)
+
+// Acquire acquires a weight of n of sem, like sem.Acquire. If the context
+// is done first, the context error is wrapped with code and labeled with
+// resource.
+func Acquire(ctx context.Context, sem *semaphore.Weighted, n int64, code int, resource string) error {
+	if err := sem.Acquire(ctx, n); err != nil {
+		return errors.WithFields(errors.Wrapc(err, code, "acquire %d of %s", n, resource),
+			FieldResource, resource, FieldWeight, n)
+	}
+	return nil
+}
+
+// TryAcquire acquires a weight of n of sem without blocking, like
+// sem.TryAcquire. If sem is saturated, it returns an error with code labeled
+// with resource.
+func TryAcquire(sem *semaphore.Weighted, n int64, code int, resource string) error {
+	if !sem.TryAcquire(n) {
+		return errors.WithFields(errors.WithCode(code, "%s is saturated", resource),
+			FieldResource, resource, FieldWeight, n)
+	}
+	return nil
+}
+
+// TryGo calls fn in a new goroutine of g, like g.TryGo. If g has reached its
+// limit, see errgroup.Group.SetLimit, it returns an error with code labeled
+// with resource.
+func TryGo(g *errgroup.Group, code int, resource string, fn func() error) error {
+	if !g.TryGo(fn) {
+		return errors.WithFields(errors.WithCode(code, "%s is saturated", resource),
+			FieldResource, resource)
+	}
+	return nil
+}
+
+// Wait waits for the goroutines of g, like g.Wait. An error without a code
+// caused by the cancellation or the deadline of a context, as returned by
+// the goroutines of a group built by errgroup.WithContext once their
+// context is done, is wrapped with code and labeled with resource.
+// Other errors are returned unchanged.
+func Wait(g *errgroup.Group, code int, resource string) error {
+	err := g.Wait()
+	if err == nil {
+		return nil
+	}
+	if _, coded := errors.TopCode(err); coded {
+		return err
+	}
+	if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return errors.WithFields(errors.Wrapc(err, code, "wait for %s", resource),
+		FieldResource, resource)
+}
@@ -0,0 +1,74 @@
+package syncerr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
+
+	"github.com/rtmzk/errors"
+)
+
+func init() {
+	errors.Register(errors.NewCoder(906201, 503, "pool saturated", ""))
+}
+
+func TestAcquire(t *testing.T) {
+	sem := semaphore.NewWeighted(1)
+	if err := Acquire(context.Background(), sem, 1, 906201, "db-pool"); err != nil {
+		t.Fatalf("Acquire: got %v, want nil", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	err := Acquire(ctx, sem, 1, 906201, "db-pool")
+	if !errors.IsCode(err, 906201) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Acquire(saturated): got %v, want code 906201 caused by the deadline", err)
+	}
+	if got := errors.Fields(err)[FieldResource]; got != "db-pool" {
+		t.Errorf("resource: got %v, want %q", got, "db-pool")
+	}
+
+	if err := TryAcquire(sem, 1, 906201, "db-pool"); !errors.IsCode(err, 906201) {
+		t.Errorf("TryAcquire(saturated): got %v, want code 906201", err)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	var g errgroup.Group
+	g.SetLimit(1)
+	release := make(chan struct{})
+	if err := TryGo(&g, 906201, "workers", func() error { <-release; return nil }); err != nil {
+		t.Fatalf("TryGo: got %v, want nil", err)
+	}
+	if err := TryGo(&g, 906201, "workers", func() error { return nil }); !errors.IsCode(err, 906201) {
+		t.Errorf("TryGo(saturated): got %v, want code 906201", err)
+	}
+	close(release)
+	if err := Wait(&g, 906201, "workers"); err != nil {
+		t.Errorf("Wait: got %v, want nil", err)
+	}
+
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{context.Canceled, 906201},
+		{errors.New("boom"), 0},
+		{errors.WithCode(906201, "already coded"), 906201},
+	}
+
+	for _, tt := range tests {
+		var g errgroup.Group
+		g.Go(func() error { return tt.err })
+		err := Wait(&g, 906201, "workers")
+		if code, _ := errors.TopCode(err); code != tt.code {
+			t.Errorf("Wait(%v): got code %d, want %d", tt.err, code, tt.code)
+		}
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Wait(%v): got %v, want it to wrap the error", tt.err, err)
+		}
+	}
+}
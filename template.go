@@ -0,0 +1,91 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Params are the named parameters of a message template, see NewT.
+type Params map[string]interface{}
+
+// NewT returns an error with the given code whose message is rendered from
+// the message of the registered Coder of the code, a template with named
+// placeholders such as "user {user_id} not found", so that the wording of
+// the messages stays in the registry:
+//
+//	errors.MustRegister(errors.NewCoder(100201, http.StatusNotFound, "user {user_id} not found", ""))
+//
+//	return errors.NewT(100201, errors.Params{"user_id": id})
+//
+// The placeholders are replaced with the parameters formatted with %v,
+// and those without a parameter are left as is. The parameters are
+// attached as the fields of the error, and Message renders the translated
+// templates with them, see WithMessages.
+// NewT also records the stack trace at the point it was called.
+func NewT(code int, params Params) error {
+	coder, ok := GetCoder(code)
+	if !ok {
+		coder = unknownCoder
+	}
+	msg := fmt.Errorf("%s", renderTemplate(coder.String(), params))
+	st := codeCallers(code)
+	w := newCoded(code, msg, nil, limitFields(nil, params), st, stackPC(st))
+	w.params = params
+	return created(w)
+}
+
+// templateParams returns the parameters of the outermost coded error of
+// err's chain, if it is created by NewT.
+func templateParams(err error) Params {
+	for _, e := range list(err) {
+		if v, ok := e.(CodeError); ok {
+			if w, ok := v.(*withCode); ok {
+				return w.params
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// renderTemplate replaces the placeholders of tmpl with params.
+func renderTemplate(tmpl string, params Params) string {
+	if len(params) == 0 || !strings.Contains(tmpl, "{") {
+		return tmpl
+	}
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(tmpl, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(tmpl[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+		b.WriteString(tmpl[:start])
+		if v, ok := params[tmpl[start+1:end]]; ok {
+			fmt.Fprintf(&b, "%v", v)
+		} else {
+			b.WriteString(tmpl[start : end+1])
+		}
+		tmpl = tmpl[end+1:]
+	}
+	b.WriteString(tmpl)
+	return b.String()
+}
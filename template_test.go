@@ -0,0 +1,64 @@
+package errors
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func init() {
+	Register(NewCoder(913501, http.StatusNotFound, "user {user_id} not found", "",
+		WithMessages(map[string]string{"fr": "utilisateur {user_id} introuvable"})))
+	Register(NewCoder(913502, http.StatusConflict, "{kind} {name} exists, {hint}", ""))
+}
+
+func TestNewT(t *testing.T) {
+	tests := []struct {
+		err    error
+		lang   string
+		want   string
+		fields map[string]interface{}
+	}{
+		{NewT(913501, Params{"user_id": 42}), "", "user 42 not found", map[string]interface{}{"user_id": 42}},
+		{NewT(913501, Params{"user_id": 42}), "fr", "utilisateur 42 introuvable", map[string]interface{}{"user_id": 42}},
+		{NewT(913502, Params{"kind": "bucket", "name": "logs"}), "", "bucket logs exists, {hint}",
+			map[string]interface{}{"kind": "bucket", "name": "logs"}},
+		{Wrap(NewT(913501, nil), "lookup"), "", "user {user_id} not found", nil},
+	}
+
+	for i, tt := range tests {
+		if got := Message(tt.err, tt.lang); got != tt.want {
+			t.Errorf("test %d: Message(): got %q, want %q", i+1, got, tt.want)
+		}
+		if got := Fields(tt.err); !reflect.DeepEqual(got, tt.fields) {
+			t.Errorf("test %d: Fields(): got %v, want %v", i+1, got, tt.fields)
+		}
+	}
+
+	err := NewT(913501, Params{"user_id": "u-7"})
+	if got := err.Error(); got != "user u-7 not found" {
+		t.Errorf("Error(): got %q, want %q", got, "user u-7 not found")
+	}
+	if got := ParseCoderL(err, "fr").String(); got != "utilisateur u-7 introuvable" {
+		t.Errorf("ParseCoderL(): got %q, want %q", got, "utilisateur u-7 introuvable")
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	tests := []struct {
+		tmpl   string
+		params Params
+		want   string
+	}{
+		{"no placeholder", Params{"a": 1}, "no placeholder"},
+		{"{a}{b}", Params{"a": 1, "b": "x"}, "1x"},
+		{"{a} {missing} {", Params{"a": 1}, "1 {missing} {"},
+		{"{a}", nil, "{a}"},
+	}
+
+	for _, tt := range tests {
+		if got := renderTemplate(tt.tmpl, tt.params); got != tt.want {
+			t.Errorf("renderTemplate(%q): got %q, want %q", tt.tmpl, got, tt.want)
+		}
+	}
+}
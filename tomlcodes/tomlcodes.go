@@ -0,0 +1,73 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tomlcodes adds the TOML format to errors.LoadCodes. It is
+// imported for its side effect:
+//
+//	import _ "github.com/rtmzk/errors/tomlcodes"
+//
+//	err := errors.LoadCodes(f, "toml")
+//
+// A TOML document cannot be a list, so the coders are given as the array
+// of tables codes, with the schema of the JSON documents, e.g.
+//
+//	# codes.toml
+//	[[codes]]
+//	code = 100404
+//	http_status = 404
+//	message = "User not found"
+//	translations = { fr = "Utilisateur introuvable" }
+//
+// It is kept apart from the errors package so that the latter does not
+// depend on a TOML parser.
+package tomlcodes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/rtmzk/errors"
+)
+
+func init() {
+	errors.RegisterCodeFormat("toml", ToJSON)
+}
+
+// ToJSON converts the TOML document read from r into JSON, the list of
+// its codes tables. A document without codes is converted to an empty
+// list.
+func ToJSON(r io.Reader) (io.Reader, error) {
+	var doc map[string]interface{}
+	if _, err := toml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	for key := range doc {
+		if key != "codes" {
+			return nil, fmt.Errorf("unknown key %q", key)
+		}
+	}
+	codes, ok := doc["codes"]
+	if !ok {
+		codes = []interface{}{}
+	}
+	byts, err := json.Marshal(codes)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(byts), nil
+}
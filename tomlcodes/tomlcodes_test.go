@@ -0,0 +1,70 @@
+package tomlcodes
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/rtmzk/errors"
+)
+
+func TestLoadCodes(t *testing.T) {
+	doc := `
+[[codes]]
+code = 911530
+slug = "toml_loaded"
+http_status = 404
+message = "Not found"
+reference = "https://example.com/911530"
+translations = { fr = "Introuvable" }
+`
+	if err := errors.LoadCodes(strings.NewReader(doc), "TOML"); err != nil {
+		t.Fatalf("LoadCodes(): %v", err)
+	}
+
+	coder, ok := errors.GetCoder(911530)
+	if !ok {
+		t.Fatalf("GetCoder(911530): got false, want true")
+	}
+	if got := coder.HTTPStatus(); got != 404 {
+		t.Errorf("HTTPStatus(): got %d, want %d", got, 404)
+	}
+	if got := coder.Reference(); got != "https://example.com/911530" {
+		t.Errorf("Reference(): got %q, want %q", got, "https://example.com/911530")
+	}
+	if got := errors.Message(errors.WithCode(911530, "internal"), "fr"); got != "Introuvable" {
+		t.Errorf("Message(fr): got %q, want %q", got, "Introuvable")
+	}
+	if _, ok := errors.CoderBySlug("toml_loaded"); !ok {
+		t.Errorf("CoderBySlug(): got false, want true")
+	}
+}
+
+func TestToJSON(t *testing.T) {
+	tests := []struct {
+		doc  string
+		want string
+	}{
+		{"", "[]"},
+		{"[[codes]]\ncode = 1\nmessage = \"a\"", `[{"code":1,"message":"a"}]`},
+		{"[[codes]]\ncode = 2\n[codes.translations]\nfr = \"b\"", `[{"code":2,"translations":{"fr":"b"}}]`},
+	}
+
+	for _, tt := range tests {
+		r, err := ToJSON(strings.NewReader(tt.doc))
+		if err != nil {
+			t.Errorf("ToJSON(%q): %v", tt.doc, err)
+			continue
+		}
+		byts, _ := io.ReadAll(r)
+		if got := string(byts); got != tt.want {
+			t.Errorf("ToJSON(%q): got %s, want %s", tt.doc, got, tt.want)
+		}
+	}
+
+	for _, doc := range []string{"[[codes]\n", "code = 1"} {
+		if _, err := ToJSON(strings.NewReader(doc)); err == nil {
+			t.Errorf("ToJSON(%q): got no error, want one", doc)
+		}
+	}
+}
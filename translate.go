@@ -0,0 +1,129 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// FieldOriginModule is the field holding the module which produced the
+// root cause of an error translated by Translate.
+const FieldOriginModule = "origin_module"
+
+// ModuleStd is the origin module of the errors of the standard library.
+const ModuleStd = "std"
+
+// Translate returns an error annotating err, produced by a third-party
+// library or by the library of another team, with the given code and
+// message, like Wrapc. The module which produced the root cause of err is
+// stamped as the FieldOriginModule field, so that dashboards can attribute
+// failures to the owning dependency.
+//
+// The module is derived from the stack recorded by the root cause when it
+// was created by this package, or else from the package of its type, and
+// matched against the modules the binary was built with.
+// The members of the multi-errors of third-party libraries, such as
+// go.uber.org/multierr, keep their codes, see AsAggregate.
+// The translations of uncoded errors are recorded by the conversion audit,
+// see SetConversionAudit. If err is nil, Translate returns nil.
+func Translate(err error, code int, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	st := codeCallers(code)
+	pc := stackPC(st)
+	if _, ok := TopCode(err); !ok {
+		recordConversion(pc, code, err)
+	}
+
+	fields := pathFields(err)
+	if module := originModule(err); module != "" {
+		fields = mergeFields(fields, map[string]interface{}{FieldOriginModule: module})
+	}
+	return created(newCoded(code, fmt.Errorf(format, args...), err, fields, st, pc))
+}
+
+// originModule returns the module which produced the root cause of err.
+func originModule(err error) string {
+	errs := list(err)
+	root := errs[len(errs)-1]
+	if s := stackOf(root); s != nil && len(*s) > 0 {
+		return moduleOf(funcPackage(Frame((*s)[0]).name()))
+	}
+
+	t := reflect.TypeOf(root)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return moduleOf(t.PkgPath())
+}
+
+// funcPackage returns the import path of the package of the function name
+// reported by the runtime, e.g. "example.com/a/b" for
+// "example.com/a/b.(*T).Method".
+func funcPackage(fn string) string {
+	slash := strings.LastIndex(fn, "/")
+	if dot := strings.Index(fn[slash+1:], "."); dot >= 0 {
+		return fn[:slash+1+dot]
+	}
+	return fn
+}
+
+var (
+	modulesOnce sync.Once
+	modules     []string
+)
+
+// moduleOf returns the module of the binary providing the package pkg.
+// Packages of the standard library belong to ModuleStd, and those of
+// unknown modules are returned as is.
+func moduleOf(pkg string) string {
+	if pkg == "" || pkg == "unknown" {
+		return ""
+	}
+	first := pkg
+	if i := strings.Index(first, "/"); i >= 0 {
+		first = first[:i]
+	}
+	if !strings.Contains(first, ".") {
+		return ModuleStd
+	}
+
+	modulesOnce.Do(func() {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		modules = append(modules, info.Main.Path)
+		for _, dep := range info.Deps {
+			modules = append(modules, dep.Path)
+		}
+	})
+
+	best := ""
+	for _, m := range modules {
+		if (pkg == m || strings.HasPrefix(pkg, m+"/")) && len(m) > len(best) {
+			best = m
+		}
+	}
+	if best == "" {
+		return pkg
+	}
+	return best
+}
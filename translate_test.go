@@ -0,0 +1,43 @@
+package errors
+
+import (
+	"io"
+	"net"
+	"net/url"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+func TestTranslate(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(905501, 502, "dependency failed", ""))
+
+	if got := Translate(nil, 905501, "call"); got != nil {
+		t.Errorf("Translate(nil): got %v, want nil", got)
+	}
+
+	tests := []struct {
+		err    error
+		module string
+	}{
+		{io.EOF, ModuleStd},
+		{&url.Error{Op: "Get", URL: "http://x", Err: &net.OpError{Op: "dial"}}, ModuleStd},
+		{pkgerrors.New("legacy"), "github.com/pkg/errors"},
+		{Wrap(pkgerrors.New("legacy"), "wrapped"), "github.com/pkg/errors"},
+		{New("ours"), "github.com/rtmzk/errors"},
+	}
+
+	for _, tt := range tests {
+		cause := tt.err
+		err := Translate(cause, 905501, "call dependency")
+		if !IsCode(err, 905501) {
+			t.Errorf("Translate(%v): got code %d, want 905501", cause, ParseCoder(err).Code())
+		}
+		if got := Fields(err)[FieldOriginModule]; got != tt.module {
+			t.Errorf("Translate(%T): got origin module %v, want %q", cause, got, tt.module)
+		}
+	}
+}
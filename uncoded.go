@@ -0,0 +1,86 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// UncodedEvent describes an error served with the unknown coder, because
+// it carries no registered code.
+type UncodedEvent struct {
+	// Err is the error served.
+	Err error
+
+	// Fingerprint identifies the kind of error, see WriteNDJSON.
+	Fingerprint string
+
+	// Origin is the innermost frame of the error, see Describe.
+	Origin string
+}
+
+var (
+	uncodedCount uint64
+	uncodedHook  atomic.Value // uncodedHookFunc
+)
+
+type uncodedHookFunc func(UncodedEvent)
+
+// SetUncodedHook sets a function called every time an error is served with
+// the unknown coder, e.g. to log it. It is called synchronously by the
+// serving function, so it must be fast and safe for concurrent use.
+// A nil fn removes the hook.
+func SetUncodedHook(fn func(UncodedEvent)) {
+	uncodedHook.Store(uncodedHookFunc(fn))
+}
+
+func uncodedHookNow() func(UncodedEvent) {
+	hook, _ := uncodedHook.Load().(uncodedHookFunc)
+	return hook
+}
+
+// UncodedCount returns how many errors have been served with the unknown
+// coder since the process started, see ServedCoder.
+func UncodedCount() uint64 {
+	return atomic.LoadUint64(&uncodedCount)
+}
+
+// ServedCoder returns the Coder of err, like ParseCoder, and records err as
+// uncoded if it is the unknown coder. Functions serving errors to clients,
+// such as WriteError or the conversions of the grpcerr package, use it so
+// that UncodedCount tracks the errors which reach clients without a code.
+// The served errors are also given to the reporters, see AddReporter, and
+// recorded by the debug ring buffer while it is enabled, see SetDebugRing.
+func ServedCoder(err error) Coder {
+	coder := ParseCoder(err)
+	if err != nil {
+		report(err, coder)
+		recordDebug(err, coder)
+	}
+	if err == nil || coder.Code() != unknownCoder.Code() {
+		return coder
+	}
+
+	atomic.AddUint64(&uncodedCount, 1)
+	if hook := uncodedHookNow(); hook != nil {
+		hook(UncodedEvent{
+			Err:         err,
+			Fingerprint: fmt.Sprintf("%016x", fingerprint(err)),
+			Origin:      Describe(err).Origin,
+		})
+	}
+	return coder
+}
@@ -0,0 +1,43 @@
+package errors
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServedCoder(t *testing.T) {
+	var events []UncodedEvent
+	restore := NewConfig(UseRegistry(MapBackend()), OnUncoded(func(e UncodedEvent) {
+		events = append(events, e)
+	})).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(905902, 400, "bad", ""))
+
+	tests := []struct {
+		err     error
+		uncoded bool
+	}{
+		{nil, false},
+		{WithCode(905902, "coded"), false},
+		{New("no code"), true},
+		{WithCode(905903, "unregistered code"), true},
+	}
+
+	for i, tt := range tests {
+		before, n := UncodedCount(), len(events)
+		ServedCoder(tt.err)
+		if got := UncodedCount() != before; got != tt.uncoded {
+			t.Errorf("test %d: counted: got %v, want %v", i+1, got, tt.uncoded)
+		}
+		if got := len(events) != n; got != tt.uncoded {
+			t.Errorf("test %d: hook called: got %v, want %v", i+1, got, tt.uncoded)
+		}
+	}
+
+	events = nil
+	ServeProblem(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), New("no code"))
+	if len(events) != 1 || events[0].Fingerprint == "" || events[0].Origin == "" {
+		t.Errorf("ServeProblem: got events %+v, want one with a fingerprint and an origin", events)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"sync/atomic"
+)
+
+// defaultUnknownCoder is the Coder of the errors without a registered code
+// until SetUnknownCoder replaces it.
+var defaultUnknownCoder = unknownCoder
+
+// SetUnknownCoder replaces the Coder of the errors without a registered
+// code, e.g. with the message and the reference of the public API of the
+// service. Its code is registered with it, unless a Coder other than the
+// previous unknown coder is registered for it. A nil coder restores the
+// default. It is meant to be called once at program start, see also
+// UseUnknownCoder. SetUnknownCoder panics if the registry is frozen.
+func SetUnknownCoder(coder Coder) {
+	if coder == nil {
+		coder = defaultUnknownCoder
+	}
+
+	codeMux.Lock()
+	defer codeMux.Unlock()
+
+	if Frozen() {
+		panic("errors: set unknown coder: " + ErrFrozen.Error())
+	}
+	installUnknownCoder(unknownCoder, coder)
+}
+
+// installUnknownCoder makes coder the unknown coder in place of prev. The
+// registry entry of its code is replaced only if it is missing or is one
+// the package installed, so that a Coder registered by the program for the
+// code is kept. codeMux must be held.
+func installUnknownCoder(prev, coder Coder) {
+	unknownCoder = coder
+	cur, ok := codes.get(coder.Code())
+	if !ok || sameCoder(cur, prev) || sameCoder(cur, coder) {
+		setCoder(coder)
+	}
+}
+
+// sameCoder reports whether a and b describe the same code alike, since
+// the values of many Coder types cannot be compared.
+func sameCoder(a, b Coder) bool {
+	return a.Code() == b.Code() && a.HTTPStatus() == b.HTTPStatus() &&
+		a.String() == b.String() && a.Reference() == b.Reference()
+}
+
+// UnknownCoder returns the Coder of the errors without a registered code.
+func UnknownCoder() Coder {
+	return unknownCoder
+}
+
+// unknownCode holds the function mapping the unregistered codes to a
+// Coder, see SetUnknownCodeFunc.
+var unknownCode atomic.Value // unknownCodeFunc
+
+// unknownCodeFunc wraps the mapping, since atomic.Value cannot hold a nil
+// function.
+type unknownCodeFunc struct {
+	fn func(code int) Coder
+}
+
+// SetUnknownCodeFunc sets the function returning the Coder of the coded
+// errors whose code is not registered, which ParseCoder otherwise resolves
+// to the unknown coder, see SetUnknownCoder. It is called with the code of
+// the coded error the search would have resolved, see SetCoderSearch, and
+// a nil Coder it returns resolves to the unknown coder. A nil fn, the
+// default, resolves all of them to the unknown coder. See HTTPClassDefaults
+// for a mapping by HTTP class.
+func SetUnknownCodeFunc(fn func(code int) Coder) {
+	unknownCode.Store(unknownCodeFunc{fn})
+}
+
+func unknownCodeNow() func(code int) Coder {
+	f, _ := unknownCode.Load().(unknownCodeFunc)
+	return f.fn
+}
+
+// HTTPClassDefaults returns a function for SetUnknownCodeFunc mapping the
+// unregistered codes to the Coder of their HTTP class, 4 for the client
+// errors and 5 for the server errors, where status returns the HTTP status
+// of a code in the numbering scheme of the service:
+//
+//	errors.SetUnknownCodeFunc(errors.HTTPClassDefaults(
+//		func(code int) int { return code / 1000 },
+//		map[int]errors.Coder{4: badRequest, 5: internal},
+//	))
+//
+// The codes of a class without a Coder resolve to the unknown coder.
+func HTTPClassDefaults(status func(code int) int, defaults map[int]Coder) func(code int) Coder {
+	return func(code int) Coder {
+		return defaults[status(code)/100]
+	}
+}
+
+// unregisteredCoder returns the Coder of the unregistered code of the
+// outermost, or deepest, coded error of errs, see SetUnknownCodeFunc.
+func unregisteredCoder(errs []error) (Coder, bool) {
+	fn := unknownCodeNow()
+	if fn == nil {
+		return nil, false
+	}
+
+	var coded CodeError
+	for i := range errs {
+		e := errs[i]
+		if coderSearchNow() == SearchDeepest {
+			e = errs[len(errs)-1-i]
+		}
+		if v, ok := e.(CodeError); ok {
+			coded = v
+			break
+		}
+	}
+	if coded == nil {
+		return nil, false
+	}
+	coder := fn(coded.Code())
+	return coder, coder != nil
+}
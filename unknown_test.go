@@ -0,0 +1,126 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func init() {
+	Register(NewCoder(914101, http.StatusNotFound, "not here", ""))
+}
+
+func TestSetUnknownCoder(t *testing.T) {
+	restore := NewConfig().Apply()
+	defer restore()
+
+	custom := NewCoder(914100, http.StatusInternalServerError, "Something went wrong", "https://example.com/support")
+	SetUnknownCoder(custom)
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"uncoded", io.EOF, 914100},
+		{"unregistered", WithCode(914199, "lost"), 914100},
+		{"registered", WithCode(914101, "gone"), 914101},
+	}
+	for _, tt := range tests {
+		if got := ParseCoder(tt.err).Code(); got != tt.want {
+			t.Errorf("%s: ParseCoder(): got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+	if got := UnknownCoder(); got.Reference() != "https://example.com/support" {
+		t.Errorf("UnknownCoder(): got %q, want the custom reference", got.Reference())
+	}
+	if got, ok := GetCoder(914100); !ok || got.String() != "Something went wrong" {
+		t.Errorf("GetCoder(): got (%v, %v), want the custom coder registered", got, ok)
+	}
+
+	SetUnknownCoder(nil)
+	if got := ParseCoder(io.EOF); got.Code() != 1 || got.Reference() != "" {
+		t.Errorf("SetUnknownCoder(nil): got %d %q, want the default without reference", got.Code(), got.Reference())
+	}
+}
+
+func TestUnknownCoderKeepsRegistered(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	mine := NewCoder(1, http.StatusServiceUnavailable, "Try again later", "")
+	Register(mine)
+	NewConfig().Apply()
+	if got, _ := GetCoder(1); got.String() != mine.String() {
+		t.Errorf("Apply(): got %v, want the registered coder of code 1 kept", got)
+	}
+
+	SetUnknownCoder(NewCoder(1, http.StatusInternalServerError, "Something went wrong", ""))
+	if got, _ := GetCoder(1); got.String() != mine.String() {
+		t.Errorf("SetUnknownCoder(): got %v, want the registered coder of code 1 kept", got)
+	}
+	SetUnknownCoder(nil)
+	if got, _ := GetCoder(1); got.String() != mine.String() {
+		t.Errorf("SetUnknownCoder(nil): got %v, want the registered coder of code 1 kept", got)
+	}
+}
+
+func TestSetUnknownCodeFunc(t *testing.T) {
+	client := NewCoder(914102, http.StatusBadRequest, "Bad request", "")
+	server := NewCoder(914103, http.StatusInternalServerError, "Server error", "")
+	restore := NewConfig(UseUnknownCodeFunc(HTTPClassDefaults(
+		func(code int) int { return code % 1000 },
+		map[int]Coder{4: client, 5: server},
+	))).Apply()
+	defer restore()
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"client class", WithCode(914404, "lost"), 914102},
+		{"server class", WithCode(914503, "down"), 914103},
+		{"no class", WithCode(914200, "odd"), 1},
+		{"registered", WithCode(914101, "gone"), 914101},
+		{"wrapped", fmt.Errorf("call: %w", WithCode(914409, "conflict")), 914102},
+		{"uncoded", io.EOF, 1},
+	}
+	for _, tt := range tests {
+		if got := ParseCoder(tt.err).Code(); got != tt.want {
+			t.Errorf("%s: ParseCoder(): got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	var coded interface{ Coder() Coder }
+	if !As(WithCode(914404, "lost"), &coded) || coded.Coder().Code() != 914102 {
+		t.Errorf("Coder(): got %v, want the client class coder", coded.Coder().Code())
+	}
+}
+
+func TestRegistrySetUnknownCoder(t *testing.T) {
+	r := NewRegistry()
+	r.MustRegister(NewCoder(914104, http.StatusConflict, "taken", ""))
+	fallback := NewCoder(914105, http.StatusBadGateway, "library failed", "")
+	r.SetUnknownCoder(fallback)
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"registered in r", r.WithCode(914104, "taken"), 914104},
+		{"registered in the package", WithCode(914101, "gone"), 914101},
+		{"unregistered", WithCode(914198, "lost"), 914105},
+		{"uncoded", io.EOF, 914105},
+	}
+	for _, tt := range tests {
+		if got := r.ParseCoder(tt.err).Code(); got != tt.want {
+			t.Errorf("%s: r.ParseCoder(): got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+	if got := ParseCoder(io.EOF).Code(); got != 1 {
+		t.Errorf("ParseCoder(): got %d, want the package unknown coder", got)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// usage counts how many coded errors have been created for each code.
+// The counters are created lazily and never removed, so the hot path
+// only needs a lookup plus an atomic increment.
+var usage sync.Map // map[int]*uint64
+
+// trackUsage enables the usage counters when it is 1, read atomically.
+var trackUsage int32 = 1
+
+func setTrackUsage(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&trackUsage, v)
+}
+
+func trackUsageNow() bool { return atomic.LoadInt32(&trackUsage) == 1 }
+
+// recordUsage increments the usage counter of the given code.
+func recordUsage(code int) { countUsage(&usage, code) }
+
+// countUsage increments the counter of the given code in the usage table
+// m, of the package registry or of a private Registry.
+func countUsage(m *sync.Map, code int) {
+	if !trackUsageNow() {
+		return
+	}
+	v, ok := m.Load(code)
+	if !ok {
+		v, _ = m.LoadOrStore(code, new(uint64))
+	}
+	atomic.AddUint64(v.(*uint64), 1)
+}
+
+// loadUsage returns the counter of the given code in the usage table m.
+func loadUsage(m *sync.Map, code int) uint64 {
+	v, ok := m.Load(code)
+	if !ok {
+		return 0
+	}
+	return atomic.LoadUint64(v.(*uint64))
+}
+
+// CodeUsage returns how many coded errors with the given code have been
+// created by WithCode and Wrapc since the process started or since the
+// last call to ResetUsage.
+func CodeUsage(code int) uint64 {
+	return loadUsage(&usage, code)
+}
+
+// UsageCounts returns a snapshot of the usage counters of every code that
+// has been produced at least once, registered or not.
+func UsageCounts() map[int]uint64 {
+	counts := map[int]uint64{}
+	usage.Range(func(k, v interface{}) bool {
+		if n := atomic.LoadUint64(v.(*uint64)); n > 0 {
+			counts[k.(int)] = n
+		}
+		return true
+	})
+	return counts
+}
+
+// UnusedCodes returns the sorted list of registered codes that have never
+// been produced. The reserved unknown code is not reported.
+func UnusedCodes() []int {
+	ensureCatalogs()
+	codeMux.RLock()
+	registered := codes.list()
+	codeMux.RUnlock()
+
+	unused := []int{}
+	for _, coder := range registered {
+		code := coder.Code()
+		if code == unknownCoder.Code() {
+			continue
+		}
+		if CodeUsage(code) == 0 {
+			unused = append(unused, code)
+		}
+	}
+	sort.Ints(unused)
+	return unused
+}
+
+// ResetUsage sets all usage counters back to zero.
+func ResetUsage() {
+	usage.Range(func(_, v interface{}) bool {
+		atomic.StoreUint64(v.(*uint64), 0)
+		return true
+	})
+}
@@ -0,0 +1,48 @@
+package errors
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCodeUsage(t *testing.T) {
+	Register(defaultCoder{C: 900101, HTTP: 400, Ext: "used"})
+	Register(defaultCoder{C: 900102, HTTP: 400, Ext: "unused"})
+	ResetUsage()
+
+	_ = WithCode(900101, "first")
+	_ = Wrapc(New("cause"), 900101, "second")
+	_ = Wrapc(nil, 900101, "nil cause is not counted")
+
+	if got := CodeUsage(900101); got != 2 {
+		t.Errorf("CodeUsage(900101): got %d, want %d", got, 2)
+	}
+	if got := CodeUsage(900102); got != 0 {
+		t.Errorf("CodeUsage(900102): got %d, want %d", got, 0)
+	}
+	if got := UsageCounts()[900101]; got != 2 {
+		t.Errorf("UsageCounts()[900101]: got %d, want %d", got, 2)
+	}
+
+	unused := UnusedCodes()
+	if !hasInt(unused, 900102) || hasInt(unused, 900101) {
+		t.Errorf("UnusedCodes(): got %v, want 900102 but not 900101", unused)
+	}
+	if hasInt(unused, unknownCoder.Code()) {
+		t.Errorf("UnusedCodes(): got %v, must not contain the unknown code", unused)
+	}
+
+	ResetUsage()
+	if got := UsageCounts(); !reflect.DeepEqual(got, map[int]uint64{}) {
+		t.Errorf("UsageCounts() after ResetUsage: got %v, want empty", got)
+	}
+}
+
+func hasInt(list []int, v int) bool {
+	for _, i := range list {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
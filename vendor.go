@@ -0,0 +1,142 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxVendorBody is the maximum size of a response body read by
+// DecodeResponse.
+const maxVendorBody = 1 << 20
+
+// Rule maps the error responses of a third-party API to a code:
+//
+//	errors.Rule{Path: "$.error.type", Equals: "rate_limited", Code: code.ErrPartnerRateLimited}
+//
+// reads "when $.error.type == "rate_limited", the error has the code
+// ErrPartnerRateLimited".
+type Rule struct {
+	// Path selects a value of the JSON body, in the $.field.list[0].field
+	// notation.
+	Path string
+
+	// Equals is the expected value, compared with the selected value
+	// formatted with fmt.Sprint. An empty Equals matches any value, so the
+	// rule only checks that the path exists.
+	Equals string
+
+	// Status optionally restricts the rule to a HTTP status.
+	Status int
+
+	// Code is the code of the error when the rule matches.
+	Code int
+}
+
+// match reports whether r matches a response with the given status and
+// decoded JSON body.
+func (r Rule) match(status int, body interface{}) bool {
+	if r.Status != 0 && r.Status != status {
+		return false
+	}
+	v, ok := lookupPath(body, r.Path)
+	if !ok {
+		return false
+	}
+	return r.Equals == "" || fmt.Sprint(v) == r.Equals
+}
+
+// DecodeResponse converts the error response of a third-party API into a
+// coded error, so that partner errors enter the coded model at the HTTP
+// client boundary. It returns nil if the status of resp is below 400.
+//
+// The rules are evaluated in order against the JSON body and the first one
+// matching gives the code of the error. If none matches, the error has the
+// unknown code. The status and the matched rule path are recorded as
+// fields, and the error blames the upstream, see BlameOf. The body is read up to 1MiB, and resp.Body is replaced so that
+// it can still be read by the caller.
+func DecodeResponse(resp *http.Response, rules ...Rule) error {
+	if resp == nil || resp.StatusCode < 400 {
+		return nil
+	}
+
+	var raw []byte
+	if resp.Body != nil {
+		raw, _ = io.ReadAll(io.LimitReader(resp.Body, maxVendorBody))
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(raw))
+	}
+
+	var body interface{}
+	_ = json.Unmarshal(raw, &body)
+
+	code := unknownCoder.Code()
+	fields := map[string]interface{}{"status": resp.StatusCode, FieldBlame: BlameUpstream}
+	for _, rule := range rules {
+		if rule.match(resp.StatusCode, body) {
+			code = rule.Code
+			fields["rule"] = rule.Path
+			break
+		}
+	}
+
+	msg := fmt.Errorf("upstream responded %s", resp.Status)
+	st := codeCallers(code)
+	return created(newCoded(code, msg, nil, fields, st, stackPC(st)))
+}
+
+// lookupPath returns the value of body selected by path.
+func lookupPath(body interface{}, path string) (interface{}, bool) {
+	path = strings.TrimPrefix(path, "$")
+	v := body
+	for path != "" {
+		switch path[0] {
+		case '.':
+			end := strings.IndexAny(path[1:], ".[")
+			if end < 0 {
+				end = len(path) - 1
+			}
+			obj, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if v, ok = obj[path[1:end+1]]; !ok {
+				return nil, false
+			}
+			path = path[end+1:]
+		case '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return nil, false
+			}
+			i, err := strconv.Atoi(path[1:end])
+			arr, ok := v.([]interface{})
+			if err != nil || !ok || i < 0 || i >= len(arr) {
+				return nil, false
+			}
+			v = arr[i]
+			path = path[end+1:]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
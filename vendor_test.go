@@ -0,0 +1,57 @@
+package errors
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDecodeResponse(t *testing.T) {
+	restore := NewConfig(UseRegistry(MapBackend())).Apply()
+	defer restore()
+
+	MustRegister(NewCoder(903801, 429, "partner rate limited", ""))
+	MustRegister(NewCoder(903802, 502, "partner rejected the item", ""))
+	MustRegister(NewCoder(903803, 502, "partner unavailable", ""))
+
+	rules := []Rule{
+		{Path: "$.error.type", Equals: "rate_limited", Code: 903801},
+		{Path: "$.errors[0].code", Equals: "42", Code: 903802},
+		{Path: "$.error", Status: 503, Code: 903803},
+	}
+
+	tests := []struct {
+		status int
+		body   string
+		code   int
+	}{
+		{200, `{}`, 0},
+		{429, `{"error":{"type":"rate_limited"}}`, 903801},
+		{400, `{"errors":[{"code":42}]}`, 903802},
+		{503, `{"error":"down"}`, 903803},
+		{500, `{"error":"down"}`, 1},
+		{500, `not json`, 1},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{
+			StatusCode: tt.status,
+			Status:     http.StatusText(tt.status),
+			Body:       io.NopCloser(strings.NewReader(tt.body)),
+		}
+		err := DecodeResponse(resp, rules...)
+		if tt.code == 0 {
+			if err != nil {
+				t.Errorf("%d %s: got %v, want nil", tt.status, tt.body, err)
+			}
+			continue
+		}
+		if !IsCode(err, tt.code) {
+			t.Errorf("%d %s: got code %d, want %d", tt.status, tt.body, ParseCoder(err).Code(), tt.code)
+		}
+		if got, _ := io.ReadAll(resp.Body); string(got) != tt.body {
+			t.Errorf("%d %s: got body %q after decoding, want it unchanged", tt.status, tt.body, got)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultVerbosityTTL is the time after which the settings changed by
+// VerbosityHandler are reverted, unless the request sets another one.
+const DefaultVerbosityTTL = 15 * time.Minute
+
+// Verbosity holds the runtime settings controlling how much detail errors
+// record, which on-call engineers raise while debugging an incident.
+type Verbosity struct {
+	// CaptureStacks enables the capture of stacks, see SetCaptureStacks.
+	CaptureStacks bool `json:"capture_stacks"`
+
+	// Redaction enables the redaction of the secret arguments, see
+	// SetRedaction.
+	Redaction bool `json:"redaction"`
+
+	// DebugRing enables the debug ring buffer, see SetDebugRing.
+	DebugRing bool `json:"debug_ring"`
+}
+
+var (
+	verbosityMux sync.Mutex
+
+	// verbosityBaseline holds the settings restored when the ttl of
+	// SetVerbosity expires, nil if no revert is pending.
+	verbosityBaseline *Verbosity
+	verbosityRevertAt time.Time
+	verbosityTimer    *time.Timer
+
+	// verbosityGen identifies the last call to SetVerbosity, so that a
+	// timer firing after being replaced does not revert newer settings.
+	verbosityGen uint64
+)
+
+// CurrentVerbosity returns the current verbosity settings. Settings whose
+// ttl expired according to the package Clock are reverted first, so that a
+// fixed Clock set with SetClock drives the revert in tests.
+func CurrentVerbosity() Verbosity {
+	expireVerbosity()
+	return currentVerbosity()
+}
+
+func currentVerbosity() Verbosity {
+	return Verbosity{
+		CaptureStacks: captureStacksNow(),
+		Redaction:     redactionNow(),
+		DebugRing:     debugRingNow(),
+	}
+}
+
+func (v Verbosity) apply() {
+	SetCaptureStacks(v.CaptureStacks)
+	SetRedaction(v.Redaction)
+	SetDebugRing(v.DebugRing)
+}
+
+// SetVerbosity makes v the current verbosity settings. If ttl is positive,
+// the settings which were current before are restored once ttl elapsed, so
+// that a raised verbosity never outlives an incident. Successive calls with
+// a ttl extend the revert, and still restore the settings which were
+// current before the first of them. A ttl lower than 1 makes v permanent.
+// The revert also happens when the settings are read after ttl elapsed on
+// the package Clock, see CurrentVerbosity.
+// SetVerbosity is safe for concurrent use.
+func SetVerbosity(v Verbosity, ttl time.Duration) {
+	verbosityMux.Lock()
+	defer verbosityMux.Unlock()
+
+	verbosityGen++
+	if verbosityTimer != nil {
+		verbosityTimer.Stop()
+		verbosityTimer = nil
+	}
+	if ttl > 0 {
+		if verbosityBaseline == nil {
+			baseline := currentVerbosity()
+			verbosityBaseline = &baseline
+		}
+		gen := verbosityGen
+		verbosityRevertAt = now().Add(ttl)
+		verbosityTimer = time.AfterFunc(ttl, func() { revertVerbosity(gen) })
+	} else {
+		verbosityBaseline = nil
+		verbosityRevertAt = time.Time{}
+	}
+	v.apply()
+}
+
+// RevertVerbosity restores at once the settings which were current before
+// SetVerbosity was called with a ttl. It does nothing if no revert is
+// pending.
+func RevertVerbosity() {
+	verbosityMux.Lock()
+	gen := verbosityGen
+	verbosityMux.Unlock()
+	revertVerbosity(gen)
+}
+
+// expireVerbosity reverts the settings if their ttl expired according to
+// the package Clock, which the timer set by SetVerbosity does not follow.
+func expireVerbosity() {
+	verbosityMux.Lock()
+	gen := verbosityGen
+	expired := verbosityBaseline != nil && !now().Before(verbosityRevertAt)
+	verbosityMux.Unlock()
+	if expired {
+		revertVerbosity(gen)
+	}
+}
+
+func revertVerbosity(gen uint64) {
+	verbosityMux.Lock()
+	defer verbosityMux.Unlock()
+
+	if gen != verbosityGen || verbosityBaseline == nil {
+		return
+	}
+	verbosityGen++
+	if verbosityTimer != nil {
+		verbosityTimer.Stop()
+		verbosityTimer = nil
+	}
+	verbosityBaseline.apply()
+	verbosityBaseline = nil
+	verbosityRevertAt = time.Time{}
+}
+
+// verbosityStatus is the JSON body written by VerbosityHandler.
+type verbosityStatus struct {
+	Verbosity
+	RevertAt    *time.Time   `json:"revert_at,omitempty"`
+	DebugErrors []DebugEntry `json:"debug_errors,omitempty"`
+}
+
+// VerbosityHandler returns a handler exposing the verbosity settings:
+//
+//   - GET responds with the current settings, the time they are reverted
+//     at, if any, and the errors recorded by the debug ring buffer.
+//   - POST changes the settings given by the "stacks", "redaction" and
+//     "debug_ring" boolean parameters, of the query or of a form, for the
+//     duration of the "ttl" parameter, DefaultVerbosityTTL by default.
+//     A ttl of 0 makes the change permanent, see SetVerbosity.
+//   - DELETE reverts the settings at once, see RevertVerbosity.
+//
+// POST and DELETE respond like GET. The handler is meant to be mounted on
+// an internal endpoint, since it can disable redaction.
+func VerbosityHandler() http.Handler {
+	return http.HandlerFunc(serveVerbosity)
+}
+
+func serveVerbosity(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPost:
+		v, ttl, err := parseVerbosity(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		SetVerbosity(v, ttl)
+	case http.MethodDelete:
+		RevertVerbosity()
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST, DELETE")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	expireVerbosity()
+	verbosityMux.Lock()
+	status := verbosityStatus{Verbosity: currentVerbosity()}
+	if !verbosityRevertAt.IsZero() {
+		at := verbosityRevertAt
+		status.RevertAt = &at
+	}
+	verbosityMux.Unlock()
+	status.DebugErrors = DebugErrors()
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+// parseVerbosity returns the settings and the ttl requested by r, starting
+// from the current settings.
+func parseVerbosity(r *http.Request) (Verbosity, time.Duration, error) {
+	v := CurrentVerbosity()
+	if err := r.ParseForm(); err != nil {
+		return v, 0, err
+	}
+
+	for name, setting := range map[string]*bool{
+		"stacks":     &v.CaptureStacks,
+		"redaction":  &v.Redaction,
+		"debug_ring": &v.DebugRing,
+	} {
+		value := r.Form.Get(name)
+		if value == "" {
+			continue
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return v, 0, Errorf("invalid %s parameter %q", name, value)
+		}
+		*setting = b
+	}
+
+	ttl := DefaultVerbosityTTL
+	if value := r.Form.Get("ttl"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return v, 0, Errorf("invalid ttl parameter %q", value)
+		}
+		ttl = d
+	}
+	return v, ttl, nil
+}
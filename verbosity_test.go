@@ -0,0 +1,113 @@
+package errors
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetVerbosity(t *testing.T) {
+	restore := NewConfig(CaptureStacks(false), RedactSecrets(true), RecordDebugRing(false)).Apply()
+	defer restore()
+	defer RevertVerbosity()
+
+	raised := Verbosity{CaptureStacks: true, Redaction: false, DebugRing: true}
+	SetVerbosity(raised, time.Hour)
+	if got := CurrentVerbosity(); got != raised {
+		t.Errorf("raised: got %+v, want %+v", got, raised)
+	}
+	if got := Fields(WrapArgs(io.EOF, 1, "call", SecretArg("token", "s3cr3t")))["token"]; got != "s3cr3t" {
+		t.Errorf("secret without redaction: got %v, want %q", got, "s3cr3t")
+	}
+
+	SetVerbosity(Verbosity{DebugRing: true}, time.Hour)
+	RevertVerbosity()
+	want := Verbosity{Redaction: true}
+	if got := CurrentVerbosity(); got != want {
+		t.Errorf("reverted: got %+v, want %+v", got, want)
+	}
+
+	clock := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	SetClock(ClockFunc(func() time.Time { return clock }))
+	SetVerbosity(raised, time.Hour)
+	clock = clock.Add(59 * time.Minute)
+	if got := CurrentVerbosity(); got != raised {
+		t.Errorf("before the ttl: got %+v, want %+v", got, raised)
+	}
+	clock = clock.Add(time.Minute)
+	if got := CurrentVerbosity(); got != want {
+		t.Errorf("expired: got %+v, want %+v", got, want)
+	}
+	SetClock(nil)
+
+	SetVerbosity(raised, 0)
+	RevertVerbosity()
+	if got := CurrentVerbosity(); got != raised {
+		t.Errorf("permanent: got %+v, want %+v", got, raised)
+	}
+}
+
+func TestVerbosityHandler(t *testing.T) {
+	restore := NewConfig(CaptureStacks(false), RedactSecrets(true), RecordDebugRing(false)).Apply()
+	defer restore()
+	defer RevertVerbosity()
+
+	h := VerbosityHandler()
+	serve := func(method, target string) (int, verbosityStatus) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		var status verbosityStatus
+		if rec.Code == http.StatusOK {
+			if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+				t.Fatalf("%s %s: invalid body: %v", method, target, err)
+			}
+		}
+		return rec.Code, status
+	}
+
+	code, status := serve(http.MethodPost, "/?stacks=true&debug_ring=1")
+	if code != http.StatusOK || !status.CaptureStacks || !status.DebugRing || !status.Redaction {
+		t.Errorf("POST: got %d %+v, want 200 with stacks and debug ring", code, status.Verbosity)
+	}
+	if status.RevertAt == nil || status.RevertAt.Before(time.Now().Add(DefaultVerbosityTTL-time.Minute)) {
+		t.Errorf("POST: got revert at %v, want in %v", status.RevertAt, DefaultVerbosityTTL)
+	}
+
+	ServedCoder(New("boom"))
+	if _, status = serve(http.MethodGet, "/"); len(status.DebugErrors) == 0 || status.DebugErrors[len(status.DebugErrors)-1].Message != "boom" {
+		t.Errorf("GET: got debug errors %+v, want the last one to be %q", status.DebugErrors, "boom")
+	}
+	ResetDebugErrors()
+
+	if code, status = serve(http.MethodDelete, "/"); code != http.StatusOK || status.CaptureStacks || status.DebugRing || status.RevertAt != nil {
+		t.Errorf("DELETE: got %d %+v, want 200 with the initial settings", code, status)
+	}
+
+	tests := []struct {
+		method string
+		target string
+		want   int
+	}{
+		{http.MethodPost, "/?stacks=maybe", http.StatusBadRequest},
+		{http.MethodPost, "/?ttl=-1m", http.StatusBadRequest},
+		{http.MethodPost, "/?ttl=soon", http.StatusBadRequest},
+		{http.MethodPut, "/", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		if code, _ := serve(tt.method, tt.target); code != tt.want {
+			t.Errorf("%s %s: got %d, want %d", tt.method, tt.target, code, tt.want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("redaction=false&ttl=0"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || CurrentVerbosity().Redaction {
+		t.Errorf("POST form: got %d and redaction %v, want 200 and false", rec.Code, CurrentVerbosity().Redaction)
+	}
+}
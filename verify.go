@@ -0,0 +1,54 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// VerifyHTTPMappings checks the HTTP statuses of the registered codes, for
+// the self-tests run when a service starts:
+//
+//   - every code maps to a valid HTTP status,
+//   - no code maps to a 2xx status, since errors never succeed,
+//   - the 4xx and 5xx families each have at least one code, so that both
+//     client and server failures can be reported with a code.
+//
+// It returns an Aggregate listing all the problems found, or nil.
+func VerifyHTTPMappings() error {
+	var problems []error
+	families := map[int]bool{}
+	for _, coder := range registeredCoders() {
+		status := coder.HTTPStatus()
+		switch {
+		case http.StatusText(status) == "":
+			problems = append(problems, fmt.Errorf("code %d: invalid HTTP status %d", coder.Code(), status))
+		case status >= 200 && status < 300:
+			problems = append(problems, fmt.Errorf("code %d: success HTTP status %d", coder.Code(), status))
+		default:
+			families[status/100] = true
+		}
+	}
+	for _, family := range []int{4, 5} {
+		if !families[family] {
+			problems = append(problems, fmt.Errorf("no code maps to a %dxx HTTP status", family))
+		}
+	}
+	if agg := NewAggregate(problems); agg != nil {
+		return agg
+	}
+	return nil
+}
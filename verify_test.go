@@ -0,0 +1,35 @@
+package errors
+
+import "testing"
+
+func TestVerifyHTTPMappings(t *testing.T) {
+	tests := []struct {
+		coders []Coder
+		want   string
+	}{
+		{[]Coder{NewCoder(906801, 404, "not found", ""), NewCoder(906802, 503, "unavailable", "")}, ""},
+		{[]Coder{NewCoder(906801, 404, "not found", "")}, "no code maps to a 5xx HTTP status"},
+		{[]Coder{NewCoder(906801, 404, "not found", ""), NewCoder(906802, 503, "unavailable", ""), NewCoder(906803, 200, "ok", "")},
+			"code 906803: success HTTP status 200"},
+		{[]Coder{NewCoder(906801, 404, "not found", ""), NewCoder(906802, 503, "unavailable", ""), NewCoder(906804, 999, "odd", "")},
+			"code 906804: invalid HTTP status 999"},
+		{nil, "[no code maps to a 4xx HTTP status, no code maps to a 5xx HTTP status]"},
+	}
+
+	for i, tt := range tests {
+		restore := NewConfig(UseRegistry(MapBackend())).Apply()
+		for _, coder := range tt.coders {
+			Register(coder)
+		}
+		err := VerifyHTTPMappings()
+		restore()
+
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.want {
+			t.Errorf("test %d: got %q, want %q", i+1, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import "context"
+
+// AvailableIn reports whether the codes of coder may be emitted to clients
+// of the given API version. Coders without API versions, see
+// WithAPIVersions, are available in every version.
+func AvailableIn(coder Coder, version int) bool {
+	introduced, retired, ok := APIVersionsOf(coder)
+	if !ok {
+		return true
+	}
+	if introduced != 0 && version < introduced {
+		return false
+	}
+	if retired != 0 && version >= retired {
+		return false
+	}
+	return true
+}
+
+// CoderForVersion returns the Coder of err to report to clients of the
+// given API version. When the Coder of err is not available in that
+// version, its fallback code is followed, see WithFallback, until an
+// available Coder is found. The unknown coder is returned if there is none.
+func CoderForVersion(err error, version int) Coder {
+	return coderForVersion(ParseCoder(err), version)
+}
+
+// coderForVersion returns coder or the fallback of coder available in the
+// given API version, see CoderForVersion.
+func coderForVersion(coder Coder, version int) Coder {
+	if coder == nil {
+		return nil
+	}
+
+	seen := map[int]bool{}
+	for !AvailableIn(coder, version) {
+		seen[coder.Code()] = true
+
+		fallback, ok := FallbackOf(coder)
+		if !ok || seen[fallback] {
+			return unknownCoder
+		}
+		next, ok := lookupCoder(fallback)
+		if !ok {
+			return unknownCoder
+		}
+		coder = next
+	}
+	return coder
+}
+
+type apiVersionKey struct{}
+
+// WithAPIVersion returns a copy of ctx carrying the API version of the
+// client, typically set by the middleware reading the version requested,
+// so that WriteError and ServeProblem report the Coder available in that
+// version, see CoderForVersion.
+func WithAPIVersion(ctx context.Context, version int) context.Context {
+	return context.WithValue(ctx, apiVersionKey{}, version)
+}
+
+// APIVersionFrom returns the API version set on ctx by WithAPIVersion.
+func APIVersionFrom(ctx context.Context) (int, bool) {
+	version, ok := ctx.Value(apiVersionKey{}).(int)
+	return version, ok
+}
+
+// ServedCoderContext returns the Coder of err served to clients like
+// ServedCoder, followed to its fallback when it is not available in the
+// API version of ctx, see WithAPIVersion and CoderForVersion.
+func ServedCoderContext(ctx context.Context, err error) Coder {
+	coder := ServedCoder(err)
+	if version, ok := APIVersionFrom(ctx); ok {
+		return coderForVersion(coder, version)
+	}
+	return coder
+}
@@ -0,0 +1,70 @@
+package errors
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCoderForVersion(t *testing.T) {
+	Register(NewCoder(902201, 400, "invalid request", ""))
+	Register(NewCoder(902202, 422, "invalid field", "", WithAPIVersions(2, 0), WithFallback(902201)))
+	Register(NewCoder(902203, 422, "invalid email", "", WithAPIVersions(3, 0), WithFallback(902202)))
+	Register(NewCoder(902204, 410, "retired", "", WithAPIVersions(0, 2)))
+	Register(NewCoder(902205, 400, "loop a", "", WithAPIVersions(5, 0), WithFallback(902206)))
+	Register(NewCoder(902206, 400, "loop b", "", WithAPIVersions(5, 0), WithFallback(902205)))
+
+	tests := []struct {
+		code    int
+		version int
+		want    int
+	}{
+		{902201, 1, 902201},
+		{902203, 3, 902203},
+		{902203, 2, 902202},
+		{902203, 1, 902201},
+		{902204, 1, 902204},
+		{902204, 2, 1},
+		{902205, 1, 1},
+	}
+
+	for i, tt := range tests {
+		if got := CoderForVersion(WithCode(tt.code, "test"), tt.version).Code(); got != tt.want {
+			t.Errorf("test %d: CoderForVersion(%d, %d): got %d, want %d", i+1, tt.code, tt.version, got, tt.want)
+		}
+	}
+	if CoderForVersion(nil, 1) != nil {
+		t.Errorf("CoderForVersion(nil): got non-nil, want nil")
+	}
+}
+
+func TestWriteErrorAPIVersion(t *testing.T) {
+	Register(NewCoder(915301, http.StatusBadRequest, "invalid request", ""))
+	Register(NewCoder(915302, http.StatusUnprocessableEntity, "invalid email", "", WithAPIVersions(2, 0), WithFallback(915301)))
+	err := WithCode(915302, "bad email")
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(WithAPIVersion(r.Context(), 1))
+	w := httptest.NewRecorder()
+	WriteError(w, r, err)
+	if w.Code != http.StatusBadRequest || w.Header().Get(HeaderErrorCode) != "915301" || !strings.Contains(w.Body.String(), "invalid request") {
+		t.Errorf("WriteError(version 1): got %d %q %s", w.Code, w.Header().Get(HeaderErrorCode), w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	ServeProblem(w, r, err)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"code":915301`) {
+		t.Errorf("ServeProblem(version 1): got %d %s", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	WriteResponse(w, err, ResponseAPIVersion(2))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("WriteResponse(version 2): got %d, want %d", w.Code, http.StatusUnprocessableEntity)
+	}
+	if v, ok := APIVersionFrom(context.Background()); ok {
+		t.Errorf("APIVersionFrom(): got %d, want none", v)
+	}
+}
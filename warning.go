@@ -0,0 +1,117 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Warning is a Detail describing a non-fatal problem, such as a degraded
+// dependency whose data was left out of a response. SetWarnings reports
+// the warnings of an error as HTTP headers, so that clients learn about
+// the degradation while the status of the response stays a success.
+type Warning struct {
+	// Code is the warn-code of RFC 7234, 199 by default for miscellaneous
+	// warnings, or 299 for persistent ones.
+	Code int `json:"code,omitempty"`
+
+	// Agent names the server adding the warning, "-" by default.
+	Agent string `json:"agent,omitempty"`
+
+	// Text describes the problem.
+	Text string `json:"text"`
+}
+
+// DetailType implements Detail.
+func (Warning) DetailType() string { return "warning" }
+
+// String returns the warning formatted as a Warning header value of
+// RFC 7234.
+func (w Warning) String() string {
+	code, agent := w.Code, w.Agent
+	if code == 0 {
+		code = 199
+	}
+	if agent == "" {
+		agent = "-"
+	}
+	return strconv.Itoa(code) + " " + agent + " " + quoteWarning(w.Text)
+}
+
+// quoteWarning returns text as a quoted-string of RFC 9110.
+func quoteWarning(text string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range text {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\r', '\n':
+			b.WriteByte(' ')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// WithWarning attaches warnings to err, see Warning.
+// If err is nil, WithWarning returns nil.
+func WithWarning(err error, warnings ...Warning) error {
+	details := make([]Detail, len(warnings))
+	for i, w := range warnings {
+		details[i] = w
+	}
+	return WithDetails(err, details...)
+}
+
+// Warnings returns all warnings attached to err's chain.
+func Warnings(err error) []Warning {
+	var ret []Warning
+	for _, d := range Details(err) {
+		if w, ok := d.(Warning); ok {
+			ret = append(ret, w)
+		}
+	}
+	return ret
+}
+
+// warningHeader is the name of the header written by SetWarnings.
+var warningHeader = "Warning"
+
+// SetWarningHeader sets the name of the header written by SetWarnings,
+// "Warning" by default, for clients which expect a custom header since
+// RFC 9111 obsoleted the Warning header. An empty name restores the
+// default.
+func SetWarningHeader(name string) {
+	if name == "" {
+		name = "Warning"
+	}
+	warningHeader = name
+}
+
+// SetWarnings adds a header for every warning attached to err's chain, see
+// SetWarningHeader. Handlers serving a partial result call it with the
+// non-fatal error before writing their success status; WriteError calls it
+// as well. Nothing is set if err is nil.
+func SetWarnings(h http.Header, err error) {
+	for _, w := range Warnings(err) {
+		h.Add(warningHeader, w.String())
+	}
+}
@@ -0,0 +1,51 @@
+package errors
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestWarning(t *testing.T) {
+	tests := []struct {
+		warning Warning
+		want    string
+	}{
+		{Warning{Text: "recommendations unavailable"}, `199 - "recommendations unavailable"`},
+		{Warning{Code: 299, Agent: "api.example.com", Text: `say "hi"` + "\n"}, `299 api.example.com "say \"hi\" "`},
+	}
+
+	for _, tt := range tests {
+		if got := tt.warning.String(); got != tt.want {
+			t.Errorf("String(): got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestSetWarnings(t *testing.T) {
+	err := WithWarning(Wrap(io.EOF, "load recommendations"),
+		Warning{Text: "recommendations unavailable"}, Warning{Code: 299, Text: "stale prices"})
+
+	h := http.Header{}
+	SetWarnings(h, err)
+	want := []string{`199 - "recommendations unavailable"`, `299 - "stale prices"`}
+	if got := h.Values("Warning"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Warning: got %q, want %q", got, want)
+	}
+
+	restore := NewConfig(UseWarningHeader("X-Warning")).Apply()
+	rec := httptest.NewRecorder()
+	WriteError(rec, httptest.NewRequest(http.MethodGet, "/", nil), err)
+	restore()
+	if got := rec.Header().Values("X-Warning"); !reflect.DeepEqual(got, want) {
+		t.Errorf("X-Warning: got %q, want %q", got, want)
+	}
+
+	h = http.Header{}
+	SetWarnings(h, nil)
+	if len(h) != 0 {
+		t.Errorf("SetWarnings(nil): got %v, want no header", h)
+	}
+}
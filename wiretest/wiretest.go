@@ -0,0 +1,127 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package wiretest ships the golden test vectors of the wire contract of
+// github.com/rtmzk/errors: for a set of errors, the exact bytes of the JSON
+// body written by errors.WriteError, of the application/problem+json
+// document written by errors.ServeProblem and of the google.rpc.Status
+// built by grpcerr.ToGRPCStatus. Implementations of the contract in other
+// languages validate their encoders and decoders against them:
+//
+//	for _, v := range wiretest.Vectors() {
+//		// build v.Error, render it, and compare with v.JSON,
+//		// v.ProblemJSON and v.Proto
+//	}
+//
+// The vectors live in testdata/vectors_v<Version>.json, which can be
+// consumed without Go. A change of the bytes of an existing vector is a
+// breaking change of the contract, and bumps Version.
+package wiretest
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rtmzk/errors"
+)
+
+// Version is the version of the wire contract the vectors describe.
+const Version = 1
+
+//go:embed testdata/vectors_v1.json
+var vectorsJSON []byte
+
+// Vector is a golden test vector: an error and its encodings.
+type Vector struct {
+	// Name identifies the vector, e.g. "not_found".
+	Name string `json:"name"`
+
+	// Description explains what the vector covers.
+	Description string `json:"description"`
+
+	// Error describes the error rendered.
+	Error Spec `json:"error"`
+
+	// JSON is the body written by errors.WriteError.
+	JSON string `json:"json"`
+
+	// ProblemJSON is the body written by errors.ServeProblem.
+	ProblemJSON string `json:"problem_json"`
+
+	// Proto is the binary google.rpc.Status built by grpcerr.ToGRPCStatus,
+	// marshaled deterministically, details included. It is base64 encoded
+	// in the file.
+	Proto []byte `json:"proto"`
+}
+
+// Spec describes an error of a vector: its Coder, the internal message of
+// the error, and what is attached to it.
+type Spec struct {
+	Code       int    `json:"code"`
+	HTTPStatus int    `json:"http_status"`
+	Message    string `json:"message"`
+	Reference  string `json:"reference,omitempty"`
+
+	// Internal is the message the error is created with, never exposed.
+	Internal string `json:"internal"`
+
+	// Profile is the name of the profile of the request, "public" or
+	// "partner", see errors.Profile. The internal profile exposes stack
+	// traces, which are not part of the contract.
+	Profile string `json:"profile"`
+
+	// Fields are the fields attached to the error.
+	Fields map[string]interface{} `json:"fields,omitempty"`
+
+	// Violations are the field violations attached to the error as an
+	// errors.BadRequest detail.
+	Violations []errors.FieldViolation `json:"violations,omitempty"`
+}
+
+// Vectors returns the test vectors of the wire contract.
+func Vectors() []Vector {
+	var vectors []Vector
+	if err := json.Unmarshal(vectorsJSON, &vectors); err != nil {
+		panic(fmt.Sprintf("wiretest: decode vectors: %v", err))
+	}
+	return vectors
+}
+
+// Coder returns the Coder of the error of the spec, to be registered before
+// the error is rendered.
+func (s Spec) Coder() errors.Coder {
+	return errors.NewCoder(s.Code, s.HTTPStatus, s.Message, s.Reference)
+}
+
+// Err returns the error of the spec. Its Coder must be registered.
+func (s Spec) Err() error {
+	err := errors.WithCode(s.Code, "%s", s.Internal)
+	if len(s.Fields) > 0 {
+		err = errors.WithFields(err, s.Fields)
+	}
+	if len(s.Violations) > 0 {
+		err = errors.WithDetails(err, errors.BadRequest{FieldViolations: s.Violations})
+	}
+	return err
+}
+
+// RequestProfile returns the profile of the request of the spec. Unknown
+// names stand for errors.ProfilePublic.
+func (s Spec) RequestProfile() errors.Profile {
+	if s.Profile == errors.ProfilePartner.Name {
+		return errors.ProfilePartner
+	}
+	return errors.ProfilePublic
+}
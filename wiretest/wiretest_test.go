@@ -0,0 +1,165 @@
+package wiretest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/rtmzk/errors"
+	"github.com/rtmzk/errors/grpcerr"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/vectors_v1.json")
+
+// vectors are the vectors of the contract, whose encodings are rendered by
+// render.
+var vectors = []Vector{
+	{
+		Name:        "not_found",
+		Description: "A public error with a reference.",
+		Error: Spec{
+			Code: 110404, HTTPStatus: 404, Message: "The user was not found",
+			Reference: "https://docs.example.com/errors/110404",
+			Internal:  "select user 42: no rows", Profile: "public",
+		},
+	},
+	{
+		Name:        "internal_no_reference",
+		Description: "A 5xx error without a reference, whose problem type is about:blank.",
+		Error: Spec{
+			Code: 110500, HTTPStatus: 500, Message: "An internal error occurred",
+			Internal: "dial tcp 10.0.0.1:5432: connection refused", Profile: "public",
+		},
+	},
+	{
+		Name:        "validation",
+		Description: "An error carrying field violations, exposed as a bad_request detail.",
+		Error: Spec{
+			Code: 110400, HTTPStatus: 400, Message: "The request is invalid",
+			Internal: "validate signup", Profile: "public",
+			Violations: []errors.FieldViolation{
+				{Field: "email", Description: "must be an email address", Reason: "INVALID_EMAIL"},
+				{Field: "age", Description: "must be positive"},
+			},
+		},
+	},
+	{
+		Name:        "partner_fields",
+		Description: "An error whose fields are exposed to partners, sorted by key.",
+		Error: Spec{
+			Code: 110429, HTTPStatus: 429, Message: "Too many requests",
+			Internal: "quota exceeded", Profile: "partner",
+			Fields: map[string]interface{}{"tenant": "acme", "limit": 100, "window": "1m"},
+		},
+	},
+	{
+		Name:        "unicode",
+		Description: "A message with non-ASCII characters and HTML sensitive ones.",
+		Error: Spec{
+			Code: 110409, HTTPStatus: 409, Message: "Le nom « <admin> » & l'e-mail sont déjà pris",
+			Internal: "unique violation", Profile: "public",
+		},
+	},
+}
+
+// render fills the encodings of v with those of this implementation.
+func render(t *testing.T, v Vector) Vector {
+	restore := errors.NewConfig(errors.UseRegistry(errors.MapBackend())).Apply()
+	defer restore()
+	errors.Register(v.Error.Coder())
+	err := v.Error.Err()
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/users/42", nil)
+	r = r.WithContext(errors.WithProfile(r.Context(), v.Error.RequestProfile()))
+
+	rec := httptest.NewRecorder()
+	errors.WriteError(rec, r, err)
+	v.JSON = rec.Body.String()
+
+	rec = httptest.NewRecorder()
+	errors.ServeProblem(rec, r, err)
+	v.ProblemJSON = rec.Body.String()
+
+	// The details are packed again deterministically, so that the
+	// metadata maps they hold are encoded in a stable order.
+	st := grpcerr.ToGRPCStatus(err).Proto()
+	for _, d := range st.Details {
+		m, uerr := d.UnmarshalNew()
+		if uerr != nil {
+			t.Fatalf("%s: unpack detail: %v", v.Name, uerr)
+		}
+		if perr := anypb.MarshalFrom(d, m, proto.MarshalOptions{Deterministic: true}); perr != nil {
+			t.Fatalf("%s: pack detail: %v", v.Name, perr)
+		}
+	}
+	pb, merr := proto.MarshalOptions{Deterministic: true}.Marshal(st)
+	if merr != nil {
+		t.Fatalf("%s: marshal status: %v", v.Name, merr)
+	}
+	v.Proto = pb
+	return v
+}
+
+func TestVectors(t *testing.T) {
+	rendered := make([]Vector, 0, len(vectors))
+	for _, v := range vectors {
+		rendered = append(rendered, render(t, v))
+	}
+
+	if *update {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rendered); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile("testdata/vectors_v1.json", buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	golden := Vectors()
+	if len(golden) != len(rendered) {
+		t.Fatalf("vectors: got %d golden vectors, want %d, run go test -update", len(golden), len(rendered))
+	}
+	for i, want := range golden {
+		got := rendered[i]
+		if got.Name != want.Name {
+			t.Errorf("vector %d: got name %q, want %q", i, got.Name, want.Name)
+			continue
+		}
+		if got.JSON != want.JSON {
+			t.Errorf("%s: JSON: got %q, want %q", want.Name, got.JSON, want.JSON)
+		}
+		if got.ProblemJSON != want.ProblemJSON {
+			t.Errorf("%s: problem+json: got %q, want %q", want.Name, got.ProblemJSON, want.ProblemJSON)
+		}
+		if !bytes.Equal(got.Proto, want.Proto) {
+			t.Errorf("%s: proto: got %x, want %x", want.Name, got.Proto, want.Proto)
+		}
+	}
+}
+
+func TestVectorsDecode(t *testing.T) {
+	for _, v := range Vectors() {
+		var body struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal([]byte(v.JSON), &body); err != nil {
+			t.Errorf("%s: JSON: %v", v.Name, err)
+			continue
+		}
+		if body.Code != v.Error.Code || body.Message != v.Error.Message {
+			t.Errorf("%s: got %d %q, want %d %q", v.Name, body.Code, body.Message, v.Error.Code, v.Error.Message)
+		}
+	}
+}
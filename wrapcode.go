@@ -0,0 +1,53 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import "fmt"
+
+// WrapWithCode returns an error annotating err with the given code and
+// message, like Wrapc, in a single layer: the code overrides the codes of
+// err's chain, see ParseCoder, and the stack trace recorded by err's chain
+// is kept, so that only errors without one record a new stack trace at the
+// point WrapWithCode is called.
+// If err is nil, WrapWithCode returns nil.
+func WrapWithCode(err error, code int, message string) error {
+	if err == nil {
+		return nil
+	}
+	if st := innermostStack(list(err)); st != nil {
+		return wrapWithCode(err, code, fmt.Errorf("%s", message), st, callerPC())
+	}
+	st := codeCallers(code)
+	return wrapWithCode(err, code, fmt.Errorf("%s", message), st, stackPC(st))
+}
+
+// WrapWithCodef returns an error annotating err with the given code and the
+// format specifier, like WrapWithCode.
+// If err is nil, WrapWithCodef returns nil.
+func WrapWithCodef(err error, code int, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	if st := innermostStack(list(err)); st != nil {
+		return wrapWithCode(err, code, errorf(format, args...), st, callerPC())
+	}
+	st := codeCallers(code)
+	return wrapWithCode(err, code, errorf(format, args...), st, stackPC(st))
+}
+
+// wrapWithCode returns the coded layer of WrapWithCode and WrapWithCodef.
+func wrapWithCode(err error, code int, msg error, st *stack, pc uintptr) error {
+	return created(newCoded(code, msg, err, pathFields(err), st, pc))
+}
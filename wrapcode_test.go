@@ -0,0 +1,55 @@
+package errors
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func init() {
+	Register(NewCoder(912501, http.StatusNotFound, "not found", ""))
+	Register(NewCoder(912502, http.StatusBadGateway, "upstream failed", ""))
+}
+
+func TestWrapWithCode(t *testing.T) {
+	inner := WithCode(912501, "no row")
+	tests := []struct {
+		err       error
+		code      int
+		message   string
+		keepStack *stack
+	}{
+		{WrapWithCode(inner, 912502, "load user"), 912502, "load user", inner.(*withCode).stack},
+		{WrapWithCodef(inner, 912502, "load user %d", 42), 912502, "load user 42", inner.(*withCode).stack},
+		{WrapWithCode(io.EOF, 912501, "read"), 912501, "read", nil},
+	}
+
+	for i, tt := range tests {
+		if got := ParseCoder(tt.err).Code(); got != tt.code {
+			t.Errorf("test %d: code: got %v, want %v", i+1, got, tt.code)
+		}
+		if got := tt.err.Error(); got != tt.message {
+			t.Errorf("test %d: message: got %q, want %q", i+1, got, tt.message)
+		}
+		w := tt.err.(*withCode)
+		if w.cause == nil {
+			t.Errorf("test %d: cause: got nil, want the wrapped error", i+1)
+		}
+		if tt.keepStack != nil && w.stack != tt.keepStack {
+			t.Errorf("test %d: stack: got a new stack, want the stack of the cause", i+1)
+		}
+		if tt.keepStack == nil && (w.stack == nil || !strings.Contains(Frame((*w.stack)[0]).name(), "TestWrapWithCode")) {
+			t.Errorf("test %d: stack: got %v, want a stack recorded by the caller", i+1, w.stack)
+		}
+	}
+}
+
+func TestWrapWithCodeNil(t *testing.T) {
+	if err := WrapWithCode(nil, 912501, "x"); err != nil {
+		t.Errorf("WrapWithCode(nil): got %v, want nil", err)
+	}
+	if err := WrapWithCodef(nil, 912501, "x %d", 1); err != nil {
+		t.Errorf("WrapWithCodef(nil): got %v, want nil", err)
+	}
+}
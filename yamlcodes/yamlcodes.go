@@ -0,0 +1,65 @@
+// Copyright 2024 rtmzk
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package yamlcodes adds the YAML format to errors.LoadCodes. It is
+// imported for its side effect:
+//
+//	import _ "github.com/rtmzk/errors/yamlcodes"
+//
+//	err := errors.LoadCodes(f, "yaml")
+//
+// The documents use the schema of the JSON documents, e.g.
+//
+//	# codes.yaml
+//	- code: 100404
+//	  http_status: 404
+//	  message: User not found
+//	  translations:
+//	    fr: Utilisateur introuvable
+//
+// It is kept apart from the errors package so that the latter does not
+// depend on a YAML parser.
+package yamlcodes
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/rtmzk/errors"
+)
+
+func init() {
+	errors.RegisterCodeFormat("yaml", ToJSON)
+	errors.RegisterCodeFormat("yml", ToJSON)
+}
+
+// ToJSON converts the YAML document read from r into JSON.
+// An empty document is converted to an empty list.
+func ToJSON(r io.Reader) (io.Reader, error) {
+	var doc interface{}
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil && err != io.EOF {
+		return nil, err
+	}
+	if doc == nil {
+		doc = []interface{}{}
+	}
+	byts, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(byts), nil
+}
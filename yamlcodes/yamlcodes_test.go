@@ -0,0 +1,68 @@
+package yamlcodes
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/rtmzk/errors"
+)
+
+func TestLoadCodes(t *testing.T) {
+	doc := `
+- code: 911520
+  slug: yaml_loaded
+  http_status: 404
+  message: Not found
+  reference: https://example.com/911520
+  translations:
+    fr: Introuvable
+`
+	if err := errors.LoadCodes(strings.NewReader(doc), "YAML"); err != nil {
+		t.Fatalf("LoadCodes(): %v", err)
+	}
+
+	coder, ok := errors.GetCoder(911520)
+	if !ok {
+		t.Fatalf("GetCoder(911520): got false, want true")
+	}
+	if got := coder.HTTPStatus(); got != 404 {
+		t.Errorf("HTTPStatus(): got %d, want %d", got, 404)
+	}
+	if got := coder.Reference(); got != "https://example.com/911520" {
+		t.Errorf("Reference(): got %q, want %q", got, "https://example.com/911520")
+	}
+	if got := errors.Message(errors.WithCode(911520, "internal"), "fr"); got != "Introuvable" {
+		t.Errorf("Message(fr): got %q, want %q", got, "Introuvable")
+	}
+	if _, ok := errors.CoderBySlug("yaml_loaded"); !ok {
+		t.Errorf("CoderBySlug(): got false, want true")
+	}
+}
+
+func TestToJSON(t *testing.T) {
+	tests := []struct {
+		doc  string
+		want string
+	}{
+		{"", "[]"},
+		{"- {code: 1, message: a}", `[{"code":1,"message":"a"}]`},
+		{"- code: 2\n  translations: {fr: b}", `[{"code":2,"translations":{"fr":"b"}}]`},
+	}
+
+	for _, tt := range tests {
+		r, err := ToJSON(strings.NewReader(tt.doc))
+		if err != nil {
+			t.Errorf("ToJSON(%q): %v", tt.doc, err)
+			continue
+		}
+		byts, _ := io.ReadAll(r)
+		if got := string(byts); got != tt.want {
+			t.Errorf("ToJSON(%q): got %s, want %s", tt.doc, got, tt.want)
+		}
+	}
+
+	if _, err := ToJSON(strings.NewReader("- [")); err == nil {
+		t.Errorf("ToJSON(invalid): got no error, want one")
+	}
+}